@@ -4,6 +4,7 @@ Copyright © 2025 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"github.com/charmbracelet/log"
 	"github.com/metruzanca/checkpoint-bot/internal/server"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -19,7 +20,10 @@ var clearCmd = &cobra.Command{
 		dbPath := viper.GetString("DB_PATH")
 
 		bot := server.NewBot(token, dbPath)
-		bot.Start()
+		// Only the session is needed, starting the bot would register commands and run its services
+		if err := bot.Open(); err != nil {
+			log.Fatal("Error connecting to Discord", "err", err)
+		}
 		defer bot.Stop()
 		bot.UnregisterCommands()
 	},
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"context"
+	"io"
+	"os"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/metruzanca/checkpoint-bot/internal/config"
+	"github.com/metruzanca/checkpoint-bot/internal/database/sqlite"
+	"github.com/metruzanca/checkpoint-bot/internal/history"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// exportCmd dumps a guild's history to stdout or a file
+var exportCmd = &cobra.Command{
+	Use:               "export",
+	Short:             "Export a guild's checkpoint history as JSON or CSV",
+	PersistentPreRunE: config.PersistentPreRunE,
+	Long: `Export guilds, checkpoints, goals, RSVPs and attendance.
+
+Examples:
+  checkpoint export --guild 123 --format json > history.json
+  checkpoint export --guild 123 --format csv --from 2025-01-01 --to 2025-03-31 --output q1.csv
+  checkpoint export --guild 123 --channel 456`,
+	Run: func(cmd *cobra.Command, args []string) {
+		guildID, _ := cmd.Flags().GetString("guild")
+		format, _ := cmd.Flags().GetString("format")
+		channelID, _ := cmd.Flags().GetString("channel")
+		fromDate, _ := cmd.Flags().GetString("from")
+		toDate, _ := cmd.Flags().GetString("to")
+		output, _ := cmd.Flags().GetString("output")
+
+		if guildID == "" {
+			log.Fatal("--guild is required")
+		}
+
+		db := sqlite.NewSqliteDatabase(viper.GetString("DB_PATH"))
+		defer db.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		export, err := history.Build(ctx, db, history.Filter{
+			GuildID:   guildID,
+			ChannelID: channelID,
+			FromDate:  fromDate,
+			ToDate:    toDate,
+		})
+		if err != nil {
+			log.Fatal("Failed to build export", "err", err)
+		}
+
+		var w io.Writer = os.Stdout
+		if output != "" {
+			file, err := os.Create(output)
+			if err != nil {
+				log.Fatal("Failed to create output file", "err", err, "path", output)
+			}
+			defer file.Close()
+			w = file
+		}
+
+		if err := history.Write(w, export, format); err != nil {
+			log.Fatal("Failed to write export", "err", err)
+		}
+	},
+}
+
+func init() {
+	exportCmd.Flags().String("guild", "", "Discord guild ID to export (required)")
+	exportCmd.Flags().String("format", history.FormatJSON, "Export format: json or csv")
+	exportCmd.Flags().String("channel", "", "Only export checkpoints from this channel ID")
+	exportCmd.Flags().String("from", "", "Only export checkpoints on or after this date (YYYY-MM-DD)")
+	exportCmd.Flags().String("to", "", "Only export checkpoints on or before this date (YYYY-MM-DD)")
+	exportCmd.Flags().String("output", "", "Write to this file instead of stdout")
+	rootCmd.AddCommand(exportCmd)
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/metruzanca/checkpoint-bot/internal/config"
+	"github.com/metruzanca/checkpoint-bot/internal/database/sqlite"
+	"github.com/metruzanca/checkpoint-bot/internal/history"
+	"github.com/metruzanca/checkpoint-bot/internal/util"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// importCmd loads history from an export or a simple spreadsheet CSV
+var importCmd = &cobra.Command{
+	Use:               "import",
+	Short:             "Import checkpoint history from JSON or CSV",
+	PersistentPreRunE: config.PersistentPreRunE,
+	Long: `Import history from a file produced by "checkpoint export", or from a simple
+CSV with a date,user,goal,status header (status is optional).
+
+Simple CSV dates are YYYY-MM-DD or "YYYY-MM-DD HH:MM" in the guild's timezone,
+one checkpoint is created per distinct date in the given channel.
+Everything is imported in a single transaction.
+
+Created and updated goals get a revision in their /goal-history, edited by
+--actor (the guild's owner by default). Webhooks and the mod-log aren't
+notified, they're only sent by the running bot.
+
+User and channel IDs are only checked to look like Discord IDs, not that they
+exist in the guild. Exports of another guild are rejected unless
+--allow-other-guild is given.
+
+Examples:
+  checkpoint import --guild 123 --file history.json --dry-run
+  checkpoint import --guild 123 --file goals.csv --channel 456 --time 19:00`,
+	Run: func(cmd *cobra.Command, args []string) {
+		guildID, _ := cmd.Flags().GetString("guild")
+		path, _ := cmd.Flags().GetString("file")
+		channelID, _ := cmd.Flags().GetString("channel")
+		defaultTime, _ := cmd.Flags().GetString("time")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		allowOtherGuild, _ := cmd.Flags().GetBool("allow-other-guild")
+		actorID, _ := cmd.Flags().GetString("actor")
+
+		if guildID == "" || path == "" {
+			log.Fatal("--guild and --file are required")
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			log.Fatal("Failed to open import file", "err", err, "path", path)
+		}
+		defer file.Close()
+
+		db := sqlite.NewSqliteDatabase(viper.GetString("DB_PATH"))
+		defer db.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		var export *history.Export
+		if strings.EqualFold(filepath.Ext(path), ".json") {
+			export, err = history.ReadJSON(file)
+		} else {
+			// Simple CSV dates are interpreted in the guild's timezone
+			loc := time.UTC
+			guild, guildErr := db.GetGuild(ctx, guildID)
+			if guildErr == nil {
+				loc = util.GuildLocation(*guild)
+			} else if guildErr != sql.ErrNoRows {
+				log.Fatal("Failed to get guild", "err", guildErr, "guild", guildID)
+			}
+			export, err = history.ReadCSV(file, history.SimpleCSVOptions{
+				ChannelID: channelID,
+				Time:      defaultTime,
+				Location:  loc,
+			})
+		}
+		if err != nil {
+			log.Fatal("Failed to read import file", "err", err, "path", path)
+		}
+
+		summary, err := history.Import(ctx, db, export, history.ImportOptions{
+			GuildID:         guildID,
+			DryRun:          dryRun,
+			AllowOtherGuild: allowOtherGuild,
+			ActorID:         actorID,
+		})
+		if err != nil {
+			log.Fatal("Failed to import history", "err", err)
+		}
+
+		if dryRun {
+			fmt.Println("Dry run, nothing was written:")
+		}
+		fmt.Print(summary)
+	},
+}
+
+func init() {
+	importCmd.Flags().String("guild", "", "Discord guild ID to import into (required)")
+	importCmd.Flags().String("file", "", "Path to a .json export or a .csv file (required)")
+	importCmd.Flags().String("channel", "", "Channel ID for checkpoints in a simple CSV")
+	importCmd.Flags().String("time", "00:00", "Time of day for simple CSV dates without a time (HH:MM)")
+	importCmd.Flags().Bool("dry-run", false, "Show what would change without writing anything")
+	importCmd.Flags().Bool("allow-other-guild", false, "Import an export of a different guild")
+	importCmd.Flags().String("actor", "", "Discord user ID recorded as the editor of imported goals (default the guild's owner)")
+	rootCmd.AddCommand(importCmd)
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/charmbracelet/log"
 	"github.com/metruzanca/checkpoint-bot/internal/config"
 	"github.com/metruzanca/checkpoint-bot/internal/server"
+	"github.com/metruzanca/checkpoint-bot/internal/version"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -15,6 +16,7 @@ import (
 var rootCmd = &cobra.Command{
 	Use:               "checkpoint",
 	Short:             "A Discord bot for managing our accountability group's checkpoints",
+	Version:           version.Version,
 	PersistentPreRunE: config.PersistentPreRunE,
 	Run: func(cmd *cobra.Command, args []string) {
 		token := viper.GetString("TOKEN")
@@ -24,6 +26,9 @@ var rootCmd = &cobra.Command{
 
 		bot := server.NewBot(token, dbPath)
 
+		if err := bot.StartServices(); err != nil {
+			log.Fatal("Error starting services", "err", err)
+		}
 		if err := bot.Start(); err != nil {
 			log.Fatal("Error starting bot", "err", err)
 		}
@@ -51,4 +56,8 @@ func init() {
 	rootCmd.PersistentFlags().String("TOKEN", "", "Discord bot token (required)")
 	rootCmd.PersistentFlags().String("CHANNEL_ID", "", "Discord channel ID")
 	rootCmd.PersistentFlags().String("DB_PATH", "./db/checkpoint.db", "Path to SQLite database file")
+	rootCmd.PersistentFlags().String("HTTP_ADDR", "", "Address for the optional HTTP server (e.g. :8080), disabled when empty")
+	rootCmd.PersistentFlags().String("PUBLIC_URL", "", "Public base URL of the HTTP server (e.g. https://bot.example.com), used in links")
+	rootCmd.PersistentFlags().String("OAUTH_CLIENT_ID", "", "Discord application client ID for the web dashboard login, dashboard disabled when empty")
+	rootCmd.PersistentFlags().String("OAUTH_CLIENT_SECRET", "", "Discord application client secret for the web dashboard login")
 }
@@ -0,0 +1,53 @@
+// apitoken package generates and hashes the bearer tokens used by the REST API.
+package apitoken
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+)
+
+// Prefix makes tokens recognizable, e.g. by secret scanners
+const Prefix = "cpb_"
+
+const (
+	// ScopeRead only allows reading the guild's data
+	ScopeRead = "read"
+	// ScopeWrite also allows creating checkpoints and editing the goals of the token's user
+	ScopeWrite = "write"
+	// ScopeAdmin also allows editing every user's goals, like an administrator in Discord
+	ScopeAdmin = "admin"
+)
+
+// CanWrite reports whether a token with the given scope may make changes
+func CanWrite(scope string) bool {
+	return scope == ScopeWrite || scope == ScopeAdmin
+}
+
+// Generate returns a new random token and the hash to store in the database.
+// The token itself is only shown once and never persisted.
+func Generate() (token string, hash string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	token = Prefix + base64.RawURLEncoding.EncodeToString(buf)
+	return token, Hash(token), nil
+}
+
+// Hash returns the hex encoded SHA-256 of a token
+func Hash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// FromHeader extracts the token from an "Authorization: Bearer <token>" header value
+func FromHeader(header string) (string, bool) {
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || !strings.HasPrefix(token, Prefix) {
+		return "", false
+	}
+	return token, true
+}
@@ -0,0 +1,192 @@
+// audit package records administrative actions and mirrors them to a guild's mod-log channel.
+package audit
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/charmbracelet/log"
+	"github.com/metruzanca/checkpoint-bot/internal/database"
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+	"github.com/metruzanca/checkpoint-bot/internal/events"
+	"github.com/metruzanca/checkpoint-bot/internal/settings"
+)
+
+const (
+	CheckpointRescheduled = "checkpoint.rescheduled"
+	CheckpointCancelled   = "checkpoint.cancelled"
+	// GoalOverride is a goal written by someone other than its owner
+	GoalOverride      = "goal.override"
+	GoalStatusChanged = "goal.status_changed"
+	SettingChanged    = "setting.changed"
+	SettingReset      = "setting.reset"
+
+	TargetCheckpoint = "checkpoint"
+	TargetGoal       = "goal"
+	// TargetPendingGoal is a goal set for a channel's next checkpoint before it's scheduled
+	TargetPendingGoal = "pending_goal"
+	TargetSetting     = "setting"
+
+	SourceDiscord   = "discord"
+	SourceAPI       = "api"
+	SourceDashboard = "dashboard"
+)
+
+// Actions lists every action, in the order they're documented
+var Actions = []string{CheckpointRescheduled, CheckpointCancelled, GoalOverride, GoalStatusChanged, SettingChanged, SettingReset}
+
+// Entry describes an action to record
+type Entry struct {
+	GuildID string
+	ActorID string
+	// Source defaults to SourceDiscord
+	Source     string
+	Action     string
+	TargetType string
+	TargetID   string
+	// TargetUser is the member affected, e.g. the owner of an overridden goal
+	TargetUser string
+	Before     string
+	After      string
+}
+
+// Record stores the entry. The change it describes already happened, so failures are only logged.
+func Record(ctx context.Context, db database.CheckpointDatabase, entry Entry) {
+	if entry.Source == "" {
+		entry.Source = SourceDiscord
+	}
+	_, err := db.CreateAuditEntry(ctx, queries.CreateAuditEntryParams{
+		GuildID:     entry.GuildID,
+		ActorID:     entry.ActorID,
+		Source:      entry.Source,
+		Action:      entry.Action,
+		TargetType:  entry.TargetType,
+		TargetID:    entry.TargetID,
+		TargetUser:  entry.TargetUser,
+		BeforeValue: entry.Before,
+		AfterValue:  entry.After,
+	})
+	if err != nil {
+		log.Error("cannot record audit entry", "err", err, "guild_id", entry.GuildID, "actor", entry.ActorID, "action", entry.Action)
+	}
+}
+
+// Mirror posts new audit entries to the guild's mod_log_channel
+type Mirror struct {
+	db      database.CheckpointDatabase
+	discord *discordgo.Session
+
+	queue chan events.Event
+	stop  chan struct{}
+	wg    sync.WaitGroup
+}
+
+func NewMirror(db database.CheckpointDatabase, discord *discordgo.Session) *Mirror {
+	return &Mirror{
+		db:      db,
+		discord: discord,
+		queue:   make(chan events.Event, 64),
+		stop:    make(chan struct{}),
+	}
+}
+
+// Start subscribes to the database's events and posts entries until Stop is called
+func (m *Mirror) Start() {
+	m.db.Events().Subscribe(m.handle)
+
+	m.wg.Add(1)
+	go m.loop()
+}
+
+func (m *Mirror) Stop() {
+	close(m.stop)
+	m.wg.Wait()
+}
+
+// handle runs on the publisher's goroutine, so it only hands the entry over
+func (m *Mirror) handle(event events.Event) {
+	if event.Type != events.AuditRecorded {
+		return
+	}
+	select {
+	case m.queue <- event:
+	default:
+		log.Warn("audit mirror queue full, dropping entry", "event_id", event.ID, "guild_id", event.GuildID)
+	}
+}
+
+func (m *Mirror) loop() {
+	defer m.wg.Done()
+	for {
+		select {
+		case <-m.stop:
+			return
+		case event := <-m.queue:
+			if entry, ok := event.Data.(events.AuditData); ok {
+				m.post(event.GuildID, entry)
+			}
+		}
+	}
+}
+
+// post sends the entry to the guild's mod-log channel, if one is configured
+func (m *Mirror) post(guildID string, entry events.AuditData) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	guildSettings, err := settings.Load(ctx, m.db, guildID)
+	if err != nil {
+		log.Error("cannot load guild settings", "err", err, "guild_id", guildID)
+		return
+	}
+	if guildSettings.ModLogChannelID == "" {
+		return
+	}
+
+	if _, err := m.discord.ChannelMessageSendEmbed(guildSettings.ModLogChannelID, Embed(entry)); err != nil {
+		log.Error("cannot post audit entry to mod-log channel", "err", err, "guild_id", guildID, "channel", guildSettings.ModLogChannelID, "id", entry.ID)
+	}
+}
+
+// Embed formats an audit entry for Discord
+func Embed(entry events.AuditData) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Title:       entry.Action,
+		Description: Summary(entry.ActorID, entry.Source, entry.TargetType, entry.TargetID, entry.TargetUser),
+		Color:       0xffa500,
+		Footer:      &discordgo.MessageEmbedFooter{Text: fmt.Sprintf("Audit entry #%d", entry.ID)},
+		Timestamp:   time.Now().UTC().Format(time.RFC3339),
+	}
+	if entry.Before != "" {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Before", Value: Truncate(entry.Before, 1024)})
+	}
+	if entry.After != "" {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "After", Value: Truncate(entry.After, 1024)})
+	}
+	return embed
+}
+
+// Summary describes who did something to what, e.g. "<@1> on checkpoint 3 for <@2>"
+func Summary(actorID string, source string, targetType string, targetID string, targetUser string) string {
+	summary := fmt.Sprintf("<@%s> on %s `%s`", actorID, targetType, targetID)
+	if targetUser != "" {
+		summary += fmt.Sprintf(" for <@%s>", targetUser)
+	}
+	if source != SourceDiscord {
+		summary += fmt.Sprintf(" via %s", source)
+	}
+	return summary
+}
+
+// Truncate shortens s to at most max runes, marking the cut with an ellipsis
+func Truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return strings.TrimSpace(string(runes[:max-1])) + "…"
+}
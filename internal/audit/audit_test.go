@@ -0,0 +1,78 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+	"github.com/metruzanca/checkpoint-bot/internal/database/sqlite"
+	"github.com/metruzanca/checkpoint-bot/internal/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRecord tests storing entries, filtering them and publishing audit.recorded
+func TestRecord(t *testing.T) {
+	db := sqlite.NewSqliteDatabase(filepath.Join(t.TempDir(), "checkpoint.db"))
+	t.Cleanup(func() { db.Close() })
+	ctx := context.Background()
+
+	_, err := db.CreateGuild(ctx, queries.CreateGuildParams{GuildID: "10", Timezone: "UTC", OwnerID: "20"})
+	require.NoError(t, err)
+
+	var published []events.AuditData
+	db.Events().Subscribe(func(event events.Event) {
+		if event.Type == events.AuditRecorded {
+			published = append(published, event.Data.(events.AuditData))
+		}
+	})
+
+	Record(ctx, db, Entry{GuildID: "10", ActorID: "20", Action: SettingChanged, TargetType: TargetSetting, TargetID: "locale", Before: "en-US", After: "it"})
+	Record(ctx, db, Entry{GuildID: "10", ActorID: "20", Source: SourceAPI, Action: GoalOverride, TargetType: TargetGoal, TargetID: "1", TargetUser: "40", After: "Ship it"})
+	Record(ctx, db, Entry{GuildID: "10", ActorID: "50", Action: GoalStatusChanged, TargetType: TargetGoal, TargetID: "1", TargetUser: "40", Before: "incomplete", After: "completed"})
+
+	require.Len(t, published, 3)
+	assert.Equal(t, SourceDiscord, published[0].Source)
+	assert.Equal(t, SourceAPI, published[1].Source)
+
+	entries, err := db.ListAuditEntries(ctx, queries.ListAuditEntriesParams{GuildID: "10", MaxEntries: 10})
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, GoalStatusChanged, entries[0].Action, "newest entries come first")
+
+	entries, err = db.ListAuditEntries(ctx, queries.ListAuditEntriesParams{GuildID: "10", Action: sql.NullString{String: SettingChanged, Valid: true}, MaxEntries: 10})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "en-US", entries[0].BeforeValue)
+	assert.Equal(t, "it", entries[0].AfterValue)
+
+	// The user filter matches both the actor and the affected member
+	entries, err = db.ListAuditEntries(ctx, queries.ListAuditEntriesParams{GuildID: "10", UserID: sql.NullString{String: "40", Valid: true}, MaxEntries: 10})
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+	entries, err = db.ListAuditEntries(ctx, queries.ListAuditEntriesParams{GuildID: "10", UserID: sql.NullString{String: "50", Valid: true}, MaxEntries: 10})
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	entries, err = db.ListAuditEntries(ctx, queries.ListAuditEntriesParams{GuildID: "10", MaxEntries: 1})
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	entries, err = db.ListAuditEntries(ctx, queries.ListAuditEntriesParams{GuildID: "11", MaxEntries: 10})
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+// TestEmbed tests the mod-log formatting
+func TestEmbed(t *testing.T) {
+	embed := Embed(events.AuditData{ID: 3, ActorID: "20", Source: SourceAPI, Action: GoalOverride, TargetType: TargetGoal, TargetID: "1", TargetUser: "40", After: "Ship it"})
+	assert.Equal(t, GoalOverride, embed.Title)
+	assert.Equal(t, "<@20> on goal `1` for <@40> via api", embed.Description)
+	require.Len(t, embed.Fields, 1)
+	assert.Equal(t, "After", embed.Fields[0].Name)
+
+	assert.Equal(t, "abc", Truncate("abc", 3))
+	assert.Equal(t, "ab…", Truncate("abcd", 3))
+}
@@ -0,0 +1,263 @@
+// calendar package generates iCalendar (RFC 5545) feeds of checkpoints.
+package calendar
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/metruzanca/checkpoint-bot/internal/database"
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+	"github.com/metruzanca/checkpoint-bot/internal/util"
+)
+
+const (
+	prodID = "-//metruzanca//checkpoint-bot//EN"
+	// uidDomain keeps UIDs globally unique while staying stable for a checkpoint ID
+	uidDomain = "checkpoint-bot"
+	// EventDuration is how long a checkpoint event lasts in calendars, checkpoints don't have an end time
+	EventDuration = time.Hour
+	// maxLineLength is the RFC 5545 limit in octets, longer lines are folded
+	maxLineLength = 75
+
+	localFormat = "20060102T150405"
+	utcFormat   = "20060102T150405Z"
+)
+
+// Calendar is a set of checkpoints rendered in a single timezone
+type Calendar struct {
+	// Name is shown by calendar clients as the calendar's title
+	Name        string
+	Location    *time.Location
+	Checkpoints []queries.Checkpoint
+}
+
+// Filename returns the attachment name for a calendar of the given guild
+func Filename(guildID string) string {
+	return fmt.Sprintf("checkpoints-%s.ics", guildID)
+}
+
+// Write renders the calendar as an .ics file
+func Write(w io.Writer, cal Calendar) error {
+	loc := cal.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	var lines []string
+	lines = append(lines,
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:"+prodID,
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"X-WR-CALNAME:"+escapeText(cal.Name),
+		"X-WR-TIMEZONE:"+loc.String(),
+	)
+
+	events := make([]event, 0, len(cal.Checkpoints))
+	for _, checkpoint := range cal.Checkpoints {
+		scheduledAt, err := time.Parse(time.RFC3339, checkpoint.ScheduledAt)
+		if err != nil {
+			return fmt.Errorf("cannot parse scheduled_at of checkpoint %d: %w", checkpoint.ID, err)
+		}
+		events = append(events, event{checkpoint: checkpoint, start: scheduledAt.In(loc)})
+	}
+
+	if !isUTC(loc) && len(events) > 0 {
+		from, to := events[0].start, events[0].start
+		for _, e := range events {
+			if e.start.Before(from) {
+				from = e.start
+			}
+			if e.start.After(to) {
+				to = e.start
+			}
+		}
+		lines = append(lines, timezoneLines(loc, from, to.Add(EventDuration))...)
+	}
+
+	now := time.Now()
+	for _, e := range events {
+		lines = append(lines, e.lines(loc, now)...)
+	}
+
+	lines = append(lines, "END:VCALENDAR")
+
+	for _, line := range lines {
+		if _, err := io.WriteString(w, fold(line)+"\r\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type event struct {
+	checkpoint queries.Checkpoint
+	start      time.Time
+}
+
+func (e event) lines(loc *time.Location, now time.Time) []string {
+	checkpoint := e.checkpoint
+
+	// DTSTAMP changes with every reschedule, alongside SEQUENCE, so clients replace their copy
+	stamp := now
+	if checkpoint.UpdatedAt.Valid {
+		stamp = checkpoint.UpdatedAt.Time
+	} else if checkpoint.CreatedAt.Valid {
+		stamp = checkpoint.CreatedAt.Time
+	}
+
+	url := fmt.Sprintf("https://discord.com/channels/%s/%s", checkpoint.GuildID, checkpoint.ChannelID)
+	lines := []string{
+		"BEGIN:VEVENT",
+		fmt.Sprintf("UID:checkpoint-%d@%s", checkpoint.ID, uidDomain),
+		"DTSTAMP:" + stamp.UTC().Format(utcFormat),
+		dateTimeLine("DTSTART", e.start, loc),
+		dateTimeLine("DTEND", e.start.Add(EventDuration), loc),
+		fmt.Sprintf("SEQUENCE:%d", checkpoint.Sequence),
+		"SUMMARY:" + escapeText(fmt.Sprintf("Checkpoint #%d", checkpoint.ID)),
+		"DESCRIPTION:" + escapeText(fmt.Sprintf("Accountability checkpoint, share your progress on your goals.\n%s", url)),
+		"URL:" + url,
+	}
+	if checkpoint.UpdatedAt.Valid {
+		lines = append(lines, "LAST-MODIFIED:"+checkpoint.UpdatedAt.Time.UTC().Format(utcFormat))
+	}
+	return append(lines, "END:VEVENT")
+}
+
+// dateTimeLine formats a DATE-TIME property, referencing the VTIMEZONE unless the calendar is in UTC
+func dateTimeLine(name string, t time.Time, loc *time.Location) string {
+	if isUTC(loc) {
+		return name + ":" + t.UTC().Format(utcFormat)
+	}
+	return fmt.Sprintf("%s;TZID=%s:%s", name, loc.String(), t.In(loc).Format(localFormat))
+}
+
+// timezoneLines builds a VTIMEZONE covering from..to, with one sub-component per offset change.
+// Go doesn't expose the tz rules, so transitions are found by scanning the range day by day.
+func timezoneLines(loc *time.Location, from, to time.Time) []string {
+	// Cover whole years so events added later in the same year still resolve
+	from = time.Date(from.Year(), time.January, 1, 0, 0, 0, 0, loc)
+	to = time.Date(to.Year()+1, time.January, 1, 0, 0, 0, 0, loc)
+
+	lines := []string{"BEGIN:VTIMEZONE", "TZID:" + loc.String()}
+
+	// Offset in effect at the start of the range
+	_, offset := from.Zone()
+	lines = append(lines, zoneLines(from, offset)...)
+
+	for t := from; t.Before(to); t = t.Add(24 * time.Hour) {
+		next := t.Add(24 * time.Hour)
+		_, nextOffset := next.Zone()
+		if nextOffset == offset {
+			continue
+		}
+		transition := findTransition(t, next, offset)
+		lines = append(lines, zoneLines(transition, offset)...)
+		offset = nextOffset
+	}
+
+	return append(lines, "END:VTIMEZONE")
+}
+
+// findTransition binary searches the first second in (lo, hi] whose offset differs from offset
+func findTransition(lo, hi time.Time, offset int) time.Time {
+	for hi.Sub(lo) > time.Second {
+		mid := lo.Add(hi.Sub(lo) / 2)
+		if _, midOffset := mid.Zone(); midOffset == offset {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return hi
+}
+
+// zoneLines renders a STANDARD or DAYLIGHT sub-component starting at onset.
+// DTSTART is the onset's local time expressed with the previous offset, as RFC 5545 requires.
+func zoneLines(onset time.Time, offsetFrom int) []string {
+	name, offsetTo := onset.Zone()
+	kind := "STANDARD"
+	if onset.IsDST() {
+		kind = "DAYLIGHT"
+	}
+	start := onset.UTC().Add(time.Duration(offsetFrom) * time.Second)
+	return []string{
+		"BEGIN:" + kind,
+		"DTSTART:" + start.Format(localFormat),
+		"TZOFFSETFROM:" + formatOffset(offsetFrom),
+		"TZOFFSETTO:" + formatOffset(offsetTo),
+		"TZNAME:" + escapeText(name),
+		"END:" + kind,
+	}
+}
+
+// formatOffset formats seconds east of UTC as +HHMM
+func formatOffset(seconds int) string {
+	sign := "+"
+	if seconds < 0 {
+		sign = "-"
+		seconds = -seconds
+	}
+	return fmt.Sprintf("%s%02d%02d", sign, seconds/3600, (seconds%3600)/60)
+}
+
+func isUTC(loc *time.Location) bool {
+	return loc == time.UTC || loc.String() == "UTC"
+}
+
+// escapeText escapes a TEXT value (RFC 5545 section 3.3.11)
+func escapeText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// fold splits lines longer than 75 octets, continuation lines start with a space.
+// Splits never happen inside a multi-byte UTF-8 character.
+func fold(line string) string {
+	if len(line) <= maxLineLength {
+		return line
+	}
+
+	var sb strings.Builder
+	limit := maxLineLength
+	count := 0
+	for _, r := range line {
+		size := len(string(r))
+		if count+size > limit {
+			sb.WriteString("\r\n ")
+			// The leading space counts towards the continuation line's length
+			limit = maxLineLength - 1
+			count = 0
+		}
+		sb.WriteRune(r)
+		count += size
+	}
+	return sb.String()
+}
+
+// ForGuild loads the guild's checkpoints, optionally limited to one channel, in the guild's timezone
+func ForGuild(ctx context.Context, db database.CheckpointDatabase, guildID string, channelID string) (Calendar, error) {
+	guild, err := db.GetGuild(ctx, guildID)
+	if err != nil {
+		return Calendar{}, err
+	}
+
+	params := queries.ListCheckpointsParams{GuildID: guildID}
+	if channelID != "" {
+		params.ChannelID = sql.NullString{String: channelID, Valid: true}
+	}
+	checkpoints, err := db.ListCheckpoints(ctx, params)
+	if err != nil {
+		return Calendar{}, err
+	}
+
+	return Calendar{
+		Name:        "Checkpoints",
+		Location:    util.GuildLocation(*guild),
+		Checkpoints: checkpoints,
+	}, nil
+}
@@ -0,0 +1,80 @@
+package calendar
+
+import (
+	"bytes"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWrite tests events, UIDs and the generated VTIMEZONE for a DST observing timezone
+func TestWrite(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	updatedAt := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	cal := Calendar{
+		Name:     "Checkpoints",
+		Location: loc,
+		Checkpoints: []queries.Checkpoint{
+			{ID: 1, ScheduledAt: "2025-01-15T19:00:00-05:00", GuildID: "10", ChannelID: "20"},
+			{ID: 2, ScheduledAt: "2025-07-01T23:30:00Z", GuildID: "10", ChannelID: "20", Sequence: 2, UpdatedAt: sql.NullTime{Time: updatedAt, Valid: true}},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, cal))
+	ics := buf.String()
+
+	assert.True(t, strings.HasPrefix(ics, "BEGIN:VCALENDAR\r\n"))
+	assert.True(t, strings.HasSuffix(ics, "END:VCALENDAR\r\n"))
+
+	// Spring forward at 2am EST and fall back at 2am EDT
+	assert.Contains(t, ics, "BEGIN:DAYLIGHT\r\nDTSTART:20250309T020000\r\nTZOFFSETFROM:-0500\r\nTZOFFSETTO:-0400\r\nTZNAME:EDT\r\n")
+	assert.Contains(t, ics, "BEGIN:STANDARD\r\nDTSTART:20251102T020000\r\nTZOFFSETFROM:-0400\r\nTZOFFSETTO:-0500\r\nTZNAME:EST\r\n")
+
+	assert.Contains(t, ics, "UID:checkpoint-1@checkpoint-bot\r\n")
+	assert.Contains(t, ics, "DTSTART;TZID=America/New_York:20250115T190000\r\n")
+	assert.Contains(t, ics, "DTEND;TZID=America/New_York:20250115T200000\r\n")
+
+	// Rescheduled checkpoints keep their UID and bump SEQUENCE
+	assert.Contains(t, ics, "UID:checkpoint-2@checkpoint-bot\r\n")
+	assert.Contains(t, ics, "DTSTART;TZID=America/New_York:20250701T193000\r\n")
+	assert.Contains(t, ics, "SEQUENCE:2\r\n")
+	assert.Contains(t, ics, "LAST-MODIFIED:20250110T120000Z\r\n")
+
+	for _, line := range strings.Split(ics, "\r\n") {
+		assert.LessOrEqual(t, len(line), maxLineLength, "line should be folded: %q", line)
+	}
+}
+
+// TestWrite_UTC tests that UTC calendars skip the VTIMEZONE and use UTC times
+func TestWrite_UTC(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, Calendar{
+		Name:        "Checkpoints",
+		Location:    time.UTC,
+		Checkpoints: []queries.Checkpoint{{ID: 3, ScheduledAt: "2025-01-15T19:00:00+01:00"}},
+	}))
+
+	assert.NotContains(t, buf.String(), "BEGIN:VTIMEZONE")
+	assert.Contains(t, buf.String(), "DTSTART:20250115T180000Z\r\n")
+}
+
+// TestFold tests that long lines are folded without splitting multi-byte characters
+func TestFold(t *testing.T) {
+	line := "SUMMARY:" + strings.Repeat("é", 60)
+	folded := fold(line)
+
+	parts := strings.Split(folded, "\r\n ")
+	require.Greater(t, len(parts), 1)
+	assert.Equal(t, line, strings.Join(parts, ""))
+	for _, part := range parts {
+		assert.LessOrEqual(t, len(part), maxLineLength)
+	}
+}
@@ -0,0 +1,40 @@
+package calendar
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/metruzanca/checkpoint-bot/internal/apitoken"
+	"github.com/metruzanca/checkpoint-bot/internal/database"
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+)
+
+// NewFeedToken creates a new secret for the guild's calendar feed URL, the previous one stops working.
+// Only its hash is stored, so the token can't be shown again.
+func NewFeedToken(ctx context.Context, db database.CheckpointDatabase, guildID string, actorID string) (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("cannot generate calendar token: %w", err)
+	}
+	token := hex.EncodeToString(buf)
+	err := db.SetCalendarToken(ctx, queries.SetCalendarTokenParams{GuildID: guildID, TokenHash: apitoken.Hash(token), CreatedBy: actorID})
+	if err != nil {
+		return "", fmt.Errorf("cannot set calendar token: %w", err)
+	}
+	return token, nil
+}
+
+// ValidFeedToken reports whether token is the secret of the guild's calendar feed
+func ValidFeedToken(ctx context.Context, db database.CheckpointDatabase, guildID string, token string) (bool, error) {
+	stored, err := db.GetCalendarTokenHash(ctx, guildID)
+	if err == sql.ErrNoRows {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("cannot get calendar token: %w", err)
+	}
+	return subtle.ConstantTimeCompare([]byte(stored), []byte(apitoken.Hash(token))) == 1, nil
+}
@@ -0,0 +1,363 @@
+// dashboard package serves a web UI for guild admins, logged in with Discord OAuth2.
+package dashboard
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/metruzanca/checkpoint-bot/internal/audit"
+	"github.com/metruzanca/checkpoint-bot/internal/database"
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+	"github.com/metruzanca/checkpoint-bot/internal/settings"
+)
+
+// DefaultDiscordURL is Discord's API base URL, tests point Config.DiscordURL at a stub instead
+const DefaultDiscordURL = "https://discord.com/api"
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+// Config holds the Discord application credentials used for OAuth2
+type Config struct {
+	ClientID     string
+	ClientSecret string
+	// PublicURL is the externally reachable base URL, the OAuth2 redirect is PublicURL/dashboard/callback
+	PublicURL string
+	// DiscordURL defaults to DefaultDiscordURL
+	DiscordURL string
+}
+
+// Dashboard serves the /dashboard/ pages backed by the bot's database
+type Dashboard struct {
+	db        database.CheckpointDatabase
+	cfg       Config
+	client    *http.Client
+	sessions  *sessionStore
+	templates map[string]*template.Template
+}
+
+// New creates a dashboard, call Register to serve it
+func New(db database.CheckpointDatabase, cfg Config) (*Dashboard, error) {
+	if cfg.ClientID == "" || cfg.ClientSecret == "" {
+		return nil, errors.New("dashboard needs an OAuth2 client ID and secret")
+	}
+	if cfg.PublicURL == "" {
+		return nil, errors.New("dashboard needs PUBLIC_URL for the OAuth2 redirect")
+	}
+	if cfg.DiscordURL == "" {
+		cfg.DiscordURL = DefaultDiscordURL
+	}
+	cfg.DiscordURL = strings.TrimSuffix(cfg.DiscordURL, "/")
+
+	templates, err := parseTemplates()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Dashboard{
+		db:        db,
+		cfg:       cfg,
+		client:    &http.Client{Timeout: 10 * time.Second},
+		sessions:  newSessionStore(),
+		templates: templates,
+	}, nil
+}
+
+// parseTemplates parses every page together with the shared layout
+func parseTemplates() (map[string]*template.Template, error) {
+	funcs := template.FuncMap{"percent": percent, "completion": completion}
+	templates := make(map[string]*template.Template)
+	for _, page := range []string{"index.html", "guild.html", "checkpoint.html"} {
+		tmpl, err := template.New(page).Funcs(funcs).ParseFS(templateFS, "templates/layout.html", "templates/"+page)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse dashboard template %s: %w", page, err)
+		}
+		templates[page] = tmpl
+	}
+	return templates, nil
+}
+
+// Register adds the dashboard routes to mux
+func (d *Dashboard) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET /dashboard/{$}", d.handleIndex)
+	mux.HandleFunc("GET /dashboard/login", d.handleLogin)
+	mux.HandleFunc("GET /dashboard/callback", d.handleCallback)
+	mux.HandleFunc("POST /dashboard/logout", d.handleLogout)
+	mux.HandleFunc("GET /dashboard/guilds/{guildID}", d.requireGuild(d.handleGuild))
+	mux.HandleFunc("GET /dashboard/guilds/{guildID}/checkpoints/{checkpointID}", d.requireGuild(d.handleCheckpoint))
+	mux.HandleFunc("POST /dashboard/guilds/{guildID}/settings", d.requireGuild(d.handleSettings))
+}
+
+// secureCookies is true when the dashboard is served over HTTPS
+func (d *Dashboard) secureCookies() bool {
+	return strings.HasPrefix(d.cfg.PublicURL, "https://")
+}
+
+// currentSession returns the logged in user's session, if any
+func (d *Dashboard) currentSession(r *http.Request) (*session, bool) {
+	cookie, err := r.Cookie(sessionCookie)
+	if err != nil {
+		return nil, false
+	}
+	return d.sessions.get(cookie.Value)
+}
+
+// checkCSRF validates a form's CSRF token against the session, responding with an error when it doesn't match
+func (d *Dashboard) checkCSRF(w http.ResponseWriter, r *http.Request) (*session, bool) {
+	sess, ok := d.currentSession(r)
+	if !ok {
+		http.Redirect(w, r, "/dashboard/", http.StatusFound)
+		return nil, false
+	}
+	if subtle.ConstantTimeCompare([]byte(r.PostFormValue("csrf_token")), []byte(sess.CSRFToken)) != 1 {
+		http.Error(w, "invalid form token, please reload the page", http.StatusForbidden)
+		return nil, false
+	}
+	return sess, true
+}
+
+type guildHandler func(w http.ResponseWriter, r *http.Request, sess *session, guild *queries.Guild)
+
+// requireGuild only lets through users administering the guild in the path
+func (d *Dashboard) requireGuild(next guildHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		sess, ok := d.currentSession(r)
+		if !ok {
+			http.Redirect(w, r, "/dashboard/", http.StatusFound)
+			return
+		}
+		guildID := r.PathValue("guildID")
+		if !sess.canManage(guildID) {
+			http.Error(w, "you need to be an admin of this server", http.StatusForbidden)
+			return
+		}
+
+		ctx, cancel := dbContext(r)
+		defer cancel()
+		guild, err := d.db.GetGuild(ctx, guildID)
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "the bot has no data for this server yet", http.StatusNotFound)
+			return
+		} else if err != nil {
+			log.Error("cannot get guild", "err", err, "guild", guildID)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		next(w, r, sess, guild)
+	}
+}
+
+type guildLink struct {
+	ID   string
+	Name string
+}
+
+// handleIndex shows the login button or the guilds the user can manage
+func (d *Dashboard) handleIndex(w http.ResponseWriter, r *http.Request) {
+	sess, _ := d.currentSession(r)
+	var guilds []guildLink
+	if sess != nil {
+		for id, name := range sess.Guilds {
+			guilds = append(guilds, guildLink{ID: id, Name: name})
+		}
+		sort.Slice(guilds, func(a, b int) bool { return guilds[a].Name < guilds[b].Name })
+	}
+	d.render(w, "index.html", map[string]any{"Session": sess, "Guilds": guilds})
+}
+
+type checkpointRow struct {
+	ID          int64
+	ChannelID   string
+	ScheduledAt string
+}
+
+type settingRow struct {
+	Key         string
+	Value       string
+	Description string
+}
+
+// handleGuild shows a guild's checkpoints, member stats and settings
+func (d *Dashboard) handleGuild(w http.ResponseWriter, r *http.Request, sess *session, guild *queries.Guild) {
+	ctx, cancel := dbContext(r)
+	defer cancel()
+
+	checkpoints, err := d.db.ListCheckpoints(ctx, queries.ListCheckpointsParams{GuildID: guild.GuildID})
+	if err != nil {
+		log.Error("cannot list checkpoints", "err", err, "guild", guild.GuildID)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	stats, err := d.db.GetGuildUserStats(ctx, guild.GuildID)
+	if err != nil {
+		log.Error("cannot get guild user stats", "err", err, "guild", guild.GuildID)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	values, err := settings.Values(ctx, d.db, guild.GuildID)
+	if err != nil {
+		log.Error("cannot get guild settings", "err", err, "guild", guild.GuildID)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	var settingRows []settingRow
+	for _, def := range settings.Definitions {
+		settingRows = append(settingRows, settingRow{Key: def.Key, Value: values[def.Key], Description: def.Description})
+	}
+
+	loc, err := time.LoadLocation(guild.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	now := time.Now()
+	var upcoming, past []checkpointRow
+	for _, checkpoint := range checkpoints {
+		scheduledAt, err := time.Parse(time.RFC3339, checkpoint.ScheduledAt)
+		if err != nil {
+			continue
+		}
+		row := checkpointRow{
+			ID:          checkpoint.ID,
+			ChannelID:   checkpoint.ChannelID,
+			ScheduledAt: scheduledAt.In(loc).Format("Mon 2006-01-02 15:04"),
+		}
+		if scheduledAt.After(now) {
+			upcoming = append(upcoming, row)
+		} else {
+			past = append(past, row)
+		}
+	}
+	// Most recent first
+	for a, b := 0, len(past)-1; a < b; a, b = a+1, b-1 {
+		past[a], past[b] = past[b], past[a]
+	}
+
+	d.render(w, "guild.html", map[string]any{
+		"Session":  sess,
+		"Guild":    guild,
+		"Name":     sess.Guilds[guild.GuildID],
+		"Upcoming": upcoming,
+		"Past":     past,
+		"Stats":    stats,
+		"Settings": settingRows,
+		"Saved":    r.URL.Query().Get("saved") == "1",
+	})
+}
+
+// handleCheckpoint shows the goals set for a checkpoint
+func (d *Dashboard) handleCheckpoint(w http.ResponseWriter, r *http.Request, sess *session, guild *queries.Guild) {
+	ctx, cancel := dbContext(r)
+	defer cancel()
+
+	checkpointID, err := strconv.ParseInt(r.PathValue("checkpointID"), 10, 64)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	checkpoint, err := d.db.GetCheckpoint(ctx, checkpointID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && checkpoint.GuildID != guild.GuildID) {
+		http.NotFound(w, r)
+		return
+	} else if err != nil {
+		log.Error("cannot get checkpoint", "err", err, "checkpoint_id", checkpointID)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	goals, err := d.db.GetGoalsByCheckpoint(ctx, checkpoint.ID)
+	if err != nil {
+		log.Error("cannot get goals", "err", err, "checkpoint_id", checkpoint.ID)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	loc, err := time.LoadLocation(guild.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	scheduledAt := checkpoint.ScheduledAt
+	if t, err := time.Parse(time.RFC3339, checkpoint.ScheduledAt); err == nil {
+		scheduledAt = t.In(loc).Format("Mon 2006-01-02 15:04")
+	}
+
+	d.render(w, "checkpoint.html", map[string]any{
+		"Session":     sess,
+		"Guild":       guild,
+		"Name":        sess.Guilds[guild.GuildID],
+		"Checkpoint":  checkpoint,
+		"ScheduledAt": scheduledAt,
+		"Goals":       goals,
+	})
+}
+
+// handleSettings updates the guild's settings from the settings form
+func (d *Dashboard) handleSettings(w http.ResponseWriter, r *http.Request, sess *session, guild *queries.Guild) {
+	if _, ok := d.checkCSRF(w, r); !ok {
+		return
+	}
+
+	timezone := strings.TrimSpace(r.PostFormValue("timezone"))
+	if _, err := time.LoadLocation(timezone); err != nil || timezone == "" {
+		http.Error(w, fmt.Sprintf("unknown timezone %q, use an IANA name such as Europe/Rome", timezone), http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := dbContext(r)
+	defer cancel()
+	if _, err := d.db.UpdateGuildTimezone(ctx, queries.UpdateGuildTimezoneParams{GuildID: guild.GuildID, Timezone: timezone}); err != nil {
+		log.Error("cannot update guild timezone", "err", err, "guild", guild.GuildID)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	if timezone != guild.Timezone {
+		audit.Record(ctx, d.db, audit.Entry{
+			GuildID:    guild.GuildID,
+			ActorID:    sess.UserID,
+			Source:     audit.SourceDashboard,
+			Action:     audit.SettingChanged,
+			TargetType: audit.TargetSetting,
+			TargetID:   "timezone",
+			Before:     guild.Timezone,
+			After:      timezone,
+		})
+	}
+	log.Info("Dashboard updated guild settings", "guild", guild.GuildID, "user", sess.UserID, "timezone", timezone)
+	http.Redirect(w, r, "/dashboard/guilds/"+guild.GuildID+"?saved=1", http.StatusSeeOther)
+}
+
+func (d *Dashboard) render(w http.ResponseWriter, page string, data map[string]any) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("X-Frame-Options", "DENY")
+	if err := d.templates[page].ExecuteTemplate(w, "layout", data); err != nil {
+		log.Error("cannot render dashboard page", "err", err, "page", page)
+	}
+}
+
+// percent formats part/total as a whole percentage
+func percent(part, total int64) string {
+	if total == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%d%%", part*100/total)
+}
+
+// completion formats the share of goals done, partial goals count for their progress
+func completion(completed, partialProgress, goals int64) string {
+	return percent(completed*100+partialProgress, goals*100)
+}
+
+// dbContext creates a context with timeout for database operations tied to the request
+func dbContext(r *http.Request) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(r.Context(), 10*time.Second)
+}
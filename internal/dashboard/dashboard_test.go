@@ -0,0 +1,202 @@
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+	"github.com/metruzanca/checkpoint-bot/internal/database/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubDiscord serves the OAuth2 token and user endpoints the dashboard calls
+func stubDiscord(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /oauth2/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.PostFormValue("code") != "good-code" || r.PostFormValue("client_secret") != "secret" {
+			http.Error(w, `{"error": "invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"access_token": "access", "token_type": "Bearer"})
+	})
+	mux.HandleFunc("GET /users/@me", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer access", r.Header.Get("Authorization"))
+		json.NewEncoder(w).Encode(discordUser{ID: "42", Username: "admin"})
+	})
+	mux.HandleFunc("GET /users/@me/guilds", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]discordGuild{
+			{ID: "1", Name: "Admin Server", Permissions: "8"},
+			{ID: "2", Name: "Owned Server", Owner: true, Permissions: "0"},
+			{ID: "3", Name: "Member Server", Permissions: "1024"},
+		})
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// login runs the OAuth2 flow against the stub and returns a client holding the session cookie
+func login(t *testing.T, baseURL string) *http.Client {
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+	client := &http.Client{
+		Jar: jar,
+		// Stop at redirects so the test can follow the flow step by step
+		CheckRedirect: func(req *http.Request, via []*http.Request) error { return http.ErrUseLastResponse },
+	}
+
+	resp, err := client.Get(baseURL + "/dashboard/login")
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusFound, resp.StatusCode)
+	authorize, err := url.Parse(resp.Header.Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, "/oauth2/authorize", authorize.Path)
+	assert.Equal(t, baseURL+"/dashboard/callback", authorize.Query().Get("redirect_uri"))
+
+	// A wrong state is rejected
+	resp, err = client.Get(baseURL + "/dashboard/callback?code=good-code&state=forged")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, err = client.Get(baseURL + "/dashboard/callback?code=good-code&state=" + authorize.Query().Get("state"))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusFound, resp.StatusCode)
+	return client
+}
+
+func get(t *testing.T, client *http.Client, target string) (int, string) {
+	resp, err := client.Get(target)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, string(body)
+}
+
+// TestDashboard tests logging in through the stub provider, viewing a guild and changing its timezone
+func TestDashboard(t *testing.T) {
+	db := sqlite.NewSqliteDatabase(filepath.Join(t.TempDir(), "checkpoint.db"))
+	t.Cleanup(func() { db.Close() })
+	ctx := context.Background()
+
+	_, err := db.CreateGuild(ctx, queries.CreateGuildParams{GuildID: "1", Timezone: "UTC", OwnerID: "42"})
+	require.NoError(t, err)
+	_, err = db.CreateGuild(ctx, queries.CreateGuildParams{GuildID: "3", Timezone: "UTC", OwnerID: "7"})
+	require.NoError(t, err)
+	checkpoint, err := db.CreateCheckpoint(ctx, queries.CreateCheckpointParams{
+		ScheduledAt: time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339),
+		ChannelID:   "10",
+		GuildID:     "1",
+		DiscordUser: "42",
+	})
+	require.NoError(t, err)
+	_, err = db.CreateGoal(ctx, queries.CreateGoalParams{CheckpointID: checkpoint.ID, DiscordUser: "42", Description: "Ship <the> dashboard"})
+	require.NoError(t, err)
+	require.NoError(t, db.CompleteGoal(ctx, queries.CompleteGoalParams{CheckpointID: checkpoint.ID, DiscordUser: "42"}))
+
+	discord := stubDiscord(t)
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	d, err := New(db, Config{ClientID: "client", ClientSecret: "secret", PublicURL: server.URL, DiscordURL: discord.URL})
+	require.NoError(t, err)
+	d.Register(mux)
+
+	t.Run("logged out", func(t *testing.T) {
+		status, body := get(t, http.DefaultClient, server.URL+"/dashboard/")
+		assert.Equal(t, http.StatusOK, status)
+		assert.Contains(t, body, "/dashboard/login")
+
+		client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+		status, _ = get(t, client, server.URL+"/dashboard/guilds/1")
+		assert.Equal(t, http.StatusFound, status)
+	})
+
+	client := login(t, server.URL)
+
+	t.Run("guild list only has admin guilds", func(t *testing.T) {
+		status, body := get(t, client, server.URL+"/dashboard/")
+		assert.Equal(t, http.StatusOK, status)
+		assert.Contains(t, body, "Admin Server")
+		assert.Contains(t, body, "Owned Server")
+		assert.NotContains(t, body, "Member Server")
+	})
+
+	t.Run("guild and checkpoint pages", func(t *testing.T) {
+		status, body := get(t, client, server.URL+"/dashboard/guilds/1")
+		assert.Equal(t, http.StatusOK, status)
+		assert.Contains(t, body, "/dashboard/guilds/1/checkpoints/")
+		assert.Contains(t, body, "<td>100%</td>")
+
+		status, body = get(t, client, server.URL+"/dashboard/guilds/1/checkpoints/"+strconv.FormatInt(checkpoint.ID, 10))
+		assert.Equal(t, http.StatusOK, status)
+		assert.Contains(t, body, "Ship &lt;the&gt; dashboard")
+	})
+
+	t.Run("non admin guild is forbidden", func(t *testing.T) {
+		status, _ := get(t, client, server.URL+"/dashboard/guilds/3")
+		assert.Equal(t, http.StatusForbidden, status)
+	})
+
+	t.Run("unknown guild is not found", func(t *testing.T) {
+		status, _ := get(t, client, server.URL+"/dashboard/guilds/2")
+		assert.Equal(t, http.StatusNotFound, status)
+	})
+
+	t.Run("update timezone", func(t *testing.T) {
+		sess := findSession(t, d)
+
+		resp, err := client.PostForm(server.URL+"/dashboard/guilds/1/settings", url.Values{"timezone": {"Europe/Rome"}, "csrf_token": {"wrong"}})
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		resp, err = client.PostForm(server.URL+"/dashboard/guilds/1/settings", url.Values{"timezone": {"Mars/Olympus"}, "csrf_token": {sess.CSRFToken}})
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		resp, err = client.PostForm(server.URL+"/dashboard/guilds/1/settings", url.Values{"timezone": {"Europe/Rome"}, "csrf_token": {sess.CSRFToken}})
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusSeeOther, resp.StatusCode)
+
+		guild, err := db.GetGuild(ctx, "1")
+		require.NoError(t, err)
+		assert.Equal(t, "Europe/Rome", guild.Timezone)
+	})
+
+	t.Run("logout", func(t *testing.T) {
+		sess := findSession(t, d)
+		resp, err := client.PostForm(server.URL+"/dashboard/logout", url.Values{"csrf_token": {sess.CSRFToken}})
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		status, _ := get(t, client, server.URL+"/dashboard/guilds/1")
+		assert.Equal(t, http.StatusFound, status)
+	})
+}
+
+// findSession returns the only session in the store
+func findSession(t *testing.T, d *Dashboard) *session {
+	d.sessions.mu.Lock()
+	defer d.sessions.mu.Unlock()
+	require.Len(t, d.sessions.sessions, 1)
+	for _, sess := range d.sessions.sessions {
+		return sess
+	}
+	return nil
+}
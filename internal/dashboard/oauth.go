@@ -0,0 +1,188 @@
+package dashboard
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/charmbracelet/log"
+)
+
+const (
+	sessionCookie = "checkpoint_session"
+	stateCookie   = "checkpoint_oauth_state"
+)
+
+// discordUser is the part of GET /users/@me the dashboard uses
+type discordUser struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+}
+
+// discordGuild is an entry of GET /users/@me/guilds
+type discordGuild struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Owner       bool   `json:"owner"`
+	Permissions string `json:"permissions"`
+}
+
+// isAdmin reports whether the user owns or administers the guild
+func (g discordGuild) isAdmin() bool {
+	permissions, _ := strconv.ParseInt(g.Permissions, 10, 64)
+	return g.Owner || permissions&discordgo.PermissionAdministrator != 0
+}
+
+func (d *Dashboard) redirectURL() string {
+	return strings.TrimSuffix(d.cfg.PublicURL, "/") + "/dashboard/callback"
+}
+
+// handleLogin redirects to Discord's consent screen, the state cookie protects the callback against CSRF
+func (d *Dashboard) handleLogin(w http.ResponseWriter, r *http.Request) {
+	state := randomToken()
+	http.SetCookie(w, &http.Cookie{
+		Name:     stateCookie,
+		Value:    state,
+		Path:     "/dashboard/",
+		MaxAge:   int((10 * time.Minute).Seconds()),
+		HttpOnly: true,
+		Secure:   d.secureCookies(),
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	query := url.Values{
+		"client_id":     {d.cfg.ClientID},
+		"redirect_uri":  {d.redirectURL()},
+		"response_type": {"code"},
+		"scope":         {"identify guilds"},
+		"state":         {state},
+	}
+	http.Redirect(w, r, d.cfg.DiscordURL+"/oauth2/authorize?"+query.Encode(), http.StatusFound)
+}
+
+// handleCallback exchanges the code for a token and creates a session with the guilds the user administers
+func (d *Dashboard) handleCallback(w http.ResponseWriter, r *http.Request) {
+	state, err := r.Cookie(stateCookie)
+	if err != nil || subtle.ConstantTimeCompare([]byte(state.Value), []byte(r.URL.Query().Get("state"))) != 1 {
+		http.Error(w, "invalid OAuth2 state, please try logging in again", http.StatusBadRequest)
+		return
+	}
+	if errParam := r.URL.Query().Get("error"); errParam != "" {
+		http.Redirect(w, r, "/dashboard/", http.StatusFound)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	accessToken, err := d.exchangeCode(ctx, r.URL.Query().Get("code"))
+	if err != nil {
+		log.Error("cannot exchange OAuth2 code", "err", err)
+		http.Error(w, "cannot log in with Discord", http.StatusBadGateway)
+		return
+	}
+
+	var user discordUser
+	if err := d.discordGet(ctx, accessToken, "/users/@me", &user); err != nil {
+		log.Error("cannot get Discord user", "err", err)
+		http.Error(w, "cannot log in with Discord", http.StatusBadGateway)
+		return
+	}
+	var guilds []discordGuild
+	if err := d.discordGet(ctx, accessToken, "/users/@me/guilds", &guilds); err != nil {
+		log.Error("cannot get Discord guilds", "err", err, "user", user.ID)
+		http.Error(w, "cannot log in with Discord", http.StatusBadGateway)
+		return
+	}
+
+	sess := &session{UserID: user.ID, Username: user.Username, Guilds: make(map[string]string)}
+	for _, guild := range guilds {
+		if guild.isAdmin() {
+			sess.Guilds[guild.ID] = guild.Name
+		}
+	}
+
+	http.SetCookie(w, &http.Cookie{Name: stateCookie, Path: "/dashboard/", MaxAge: -1})
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    d.sessions.create(sess),
+		Path:     "/dashboard/",
+		MaxAge:   int(sessionTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   d.secureCookies(),
+		SameSite: http.SameSiteLaxMode,
+	})
+	log.Info("Dashboard login", "user", user.ID, "admin_guilds", len(sess.Guilds))
+	http.Redirect(w, r, "/dashboard/", http.StatusFound)
+}
+
+// handleLogout ends the session
+func (d *Dashboard) handleLogout(w http.ResponseWriter, r *http.Request) {
+	if _, ok := d.checkCSRF(w, r); !ok {
+		return
+	}
+	if cookie, err := r.Cookie(sessionCookie); err == nil {
+		d.sessions.delete(cookie.Value)
+	}
+	http.SetCookie(w, &http.Cookie{Name: sessionCookie, Path: "/dashboard/", MaxAge: -1})
+	http.Redirect(w, r, "/dashboard/", http.StatusFound)
+}
+
+// exchangeCode trades an authorization code for an access token
+func (d *Dashboard) exchangeCode(ctx context.Context, code string) (string, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {d.redirectURL()},
+		"client_id":     {d.cfg.ClientID},
+		"client_secret": {d.cfg.ClientSecret},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.cfg.DiscordURL+"/oauth2/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := d.do(req, &token); err != nil {
+		return "", err
+	}
+	if token.AccessToken == "" {
+		return "", fmt.Errorf("no access token in response")
+	}
+	return token.AccessToken, nil
+}
+
+// discordGet calls a Discord API endpoint on behalf of the user
+func (d *Dashboard) discordGet(ctx context.Context, accessToken string, path string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.cfg.DiscordURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	return d.do(req, v)
+}
+
+func (d *Dashboard) do(req *http.Request, v any) error {
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s %s: status %d: %s", req.Method, req.URL.Path, resp.StatusCode, body)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,78 @@
+package dashboard
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// sessionTTL is how long a login lasts, guild permissions are refreshed by logging in again
+const sessionTTL = 12 * time.Hour
+
+// session is a logged in Discord user
+type session struct {
+	UserID   string
+	Username string
+	// Guilds the user administers, by ID
+	Guilds map[string]string
+	// CSRFToken must be sent back with every form
+	CSRFToken string
+	ExpiresAt time.Time
+}
+
+// canManage reports whether the user administers the guild
+func (s *session) canManage(guildID string) bool {
+	_, ok := s.Guilds[guildID]
+	return ok
+}
+
+// sessionStore keeps sessions in memory, a restart logs everyone out
+type sessionStore struct {
+	mu       sync.Mutex
+	sessions map[string]*session
+}
+
+func newSessionStore() *sessionStore {
+	return &sessionStore{sessions: make(map[string]*session)}
+}
+
+// create stores a session and returns its ID
+func (st *sessionStore) create(sess *session) string {
+	id := randomToken()
+	sess.CSRFToken = randomToken()
+	sess.ExpiresAt = time.Now().Add(sessionTTL)
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	// Drop expired sessions while we hold the lock
+	for key, existing := range st.sessions {
+		if time.Now().After(existing.ExpiresAt) {
+			delete(st.sessions, key)
+		}
+	}
+	st.sessions[id] = sess
+	return id
+}
+
+func (st *sessionStore) get(id string) (*session, bool) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	sess, ok := st.sessions[id]
+	if !ok || time.Now().After(sess.ExpiresAt) {
+		return nil, false
+	}
+	return sess, true
+}
+
+func (st *sessionStore) delete(id string) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	delete(st.sessions, id)
+}
+
+func randomToken() string {
+	buf := make([]byte, 32)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
@@ -4,31 +4,166 @@ import (
 	"context"
 
 	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+	"github.com/metruzanca/checkpoint-bot/internal/events"
 )
 
 // Defines all operations used by the checkpoint bot
 type CheckpointDatabase interface {
 	CreateCheckpoint(ctx context.Context, params queries.CreateCheckpointParams) (*queries.Checkpoint, error)
+	GetCheckpoint(ctx context.Context, id int64) (*queries.Checkpoint, error)
+	RescheduleCheckpoint(ctx context.Context, params queries.RescheduleCheckpointParams) (*queries.Checkpoint, error)
+	// DeleteCheckpoint removes a checkpoint with its goals, RSVPs and attendance
+	DeleteCheckpoint(ctx context.Context, id int64) (*queries.Checkpoint, error)
 	GetUpcomingCheckpoints(ctx context.Context) ([]queries.Checkpoint, error)
+	GetCheckpointsScheduledBetween(ctx context.Context, params queries.GetCheckpointsScheduledBetweenParams) ([]queries.Checkpoint, error)
 	MarkAttendance(ctx context.Context, params queries.MarkAttendanceParams) error
+	CreateRsvp(ctx context.Context, params queries.CreateRsvpParams) error
 
 	CreateGoal(ctx context.Context, params queries.CreateGoalParams) (*queries.Goal, error)
 	CompleteGoal(ctx context.Context, params queries.CompleteGoalParams) error
 	FailedGoal(ctx context.Context, params queries.FailedGoalParams) error
 
 	GetGuild(ctx context.Context, guildID string) (*queries.Guild, error)
+	GetGuildTotals(ctx context.Context) ([]queries.GetGuildTotalsRow, error)
 	CreateGuild(ctx context.Context, params queries.CreateGuildParams) (*queries.Guild, error)
+	UpdateGuildTimezone(ctx context.Context, params queries.UpdateGuildTimezoneParams) (*queries.Guild, error)
+
+	GetGuildSettings(ctx context.Context, guildID string) ([]queries.GuildSetting, error)
+	SetGuildSetting(ctx context.Context, params queries.SetGuildSettingParams) (*queries.GuildSetting, error)
+	DeleteGuildSetting(ctx context.Context, params queries.DeleteGuildSettingParams) (int64, error)
 
 	GetCheckpointByScheduledAtAndChannel(ctx context.Context, params queries.GetCheckpointByScheduledAtAndChannelParams) (*queries.Checkpoint, error)
 	GetPastCheckpointsByChannel(ctx context.Context, channelID string) ([]queries.Checkpoint, error)
+	// GetPastCheckpointsByChannelAndTag returns the past checkpoints with goals tagged tag, with how many there were
+	GetPastCheckpointsByChannelAndTag(ctx context.Context, params queries.GetPastCheckpointsByChannelAndTagParams) ([]queries.GetPastCheckpointsByChannelAndTagRow, error)
+	GetPreviousCheckpointByChannel(ctx context.Context, params queries.GetPreviousCheckpointByChannelParams) (*queries.Checkpoint, error)
+	CountCheckpointsByChannelBefore(ctx context.Context, params queries.CountCheckpointsByChannelBeforeParams) (int64, error)
 	GetUpcomingCheckpointByGuildAndChannel(ctx context.Context, params queries.GetUpcomingCheckpointByGuildAndChannelParams) (*queries.Checkpoint, error)
 
+	GetGoal(ctx context.Context, id int64) (*queries.Goal, error)
 	GetGoalByCheckpointAndUser(ctx context.Context, params queries.GetGoalByCheckpointAndUserParams) (*queries.Goal, error)
+	// GetUpcomingGoalsByGuildAndUser returns the user's goals for upcoming checkpoints, the soonest first
+	GetUpcomingGoalsByGuildAndUser(ctx context.Context, params queries.GetUpcomingGoalsByGuildAndUserParams) ([]queries.Goal, error)
 	UpdateGoalDescription(ctx context.Context, params queries.UpdateGoalDescriptionParams) error
 	UpdateGoalStatus(ctx context.Context, params queries.UpdateGoalStatusParams) error
+	UpdateGoalProgress(ctx context.Context, params queries.UpdateGoalProgressParams) error
+	UpdateGoalTarget(ctx context.Context, params queries.UpdateGoalTargetParams) error
+	// UpdateGoalVisibility sets who can see the goal's text, see settings.VisibilityMembers
+	UpdateGoalVisibility(ctx context.Context, params queries.UpdateGoalVisibilityParams) error
+
+	// Check-ins log amounts towards a goal's target, AddGoalTotal adds one to the goal's total
+	CreateGoalCheckin(ctx context.Context, params queries.CreateGoalCheckinParams) (*queries.GoalCheckin, error)
+	AddGoalTotal(ctx context.Context, params queries.AddGoalTotalParams) (*queries.Goal, error)
+	GetGoalCheckins(ctx context.Context, goalID int64) ([]queries.GoalCheckin, error)
+
+	// Goal evidence is proof attached to a goal with /goal-proof, returned oldest first
+	CreateGoalEvidence(ctx context.Context, params queries.CreateGoalEvidenceParams) (*queries.GoalEvidence, error)
+	GetGoalEvidence(ctx context.Context, goalID int64) ([]queries.GoalEvidence, error)
+	GetGoalEvidenceByCheckpoint(ctx context.Context, checkpointID int64) ([]queries.GetGoalEvidenceByCheckpointRow, error)
+
+	// Goal templates are personal (DiscordUser set) or guild-wide (DiscordUser empty), SaveGoalTemplate replaces one with the same name
+	SaveGoalTemplate(ctx context.Context, params queries.SaveGoalTemplateParams) (*queries.GoalTemplate, error)
+	GetGoalTemplate(ctx context.Context, id int64) (*queries.GoalTemplate, error)
+	GetGoalTemplatesByGuildAndUser(ctx context.Context, params queries.GetGoalTemplatesByGuildAndUserParams) ([]queries.GoalTemplate, error)
+	IncrementGoalTemplateUses(ctx context.Context, id int64) error
+	DeleteGoalTemplate(ctx context.Context, id int64) (int64, error)
+
+	// Goal tags are lowercase, SetGoalTags replaces all of a goal's tags in one transaction
+	SetGoalTags(ctx context.Context, goalID int64, tags []string) error
+	GetGoalTags(ctx context.Context, goalID int64) ([]string, error)
+	GetGoalTagsByCheckpoint(ctx context.Context, checkpointID int64) ([]queries.GoalTag, error)
+
+	// Objectives are long-term goals of a member, GetObjectivesByGuildAndUser sums up the goals linked to each
+	CreateObjective(ctx context.Context, params queries.CreateObjectiveParams) (*queries.Objective, error)
+	GetObjective(ctx context.Context, id int64) (*queries.Objective, error)
+	GetObjectivesByGuildAndUser(ctx context.Context, params queries.GetObjectivesByGuildAndUserParams) ([]queries.GetObjectivesByGuildAndUserRow, error)
+	UpdateObjectiveStatus(ctx context.Context, params queries.UpdateObjectiveStatusParams) error
+	DeleteObjective(ctx context.Context, id int64) (int64, error)
+	UpdateGoalObjective(ctx context.Context, params queries.UpdateGoalObjectiveParams) error
+	GetGoalsByObjective(ctx context.Context, objectiveID int64) ([]queries.Goal, error)
+
+	// Calendar tokens are the secret part of a guild's calendar feed URL, only their hash is stored.
+	// SetCalendarToken replaces the guild's token.
+	SetCalendarToken(ctx context.Context, params queries.SetCalendarTokenParams) error
+	GetCalendarTokenHash(ctx context.Context, guildID string) (string, error)
+
+	// Goal updates are progress notes posted between checkpoints, returned oldest first
+	CreateGoalUpdate(ctx context.Context, params queries.CreateGoalUpdateParams) (*queries.GoalUpdate, error)
+	GetGoalUpdatesByCheckpoint(ctx context.Context, checkpointID int64) ([]queries.GoalUpdate, error)
+
+	// Update prompts ask members for a goal update by DM
+	SaveUpdatePrompt(ctx context.Context, params queries.SaveUpdatePromptParams) (*queries.UpdatePrompt, error)
+	DeleteUpdatePrompt(ctx context.Context, params queries.DeleteUpdatePromptParams) (int64, error)
+	GetDueUpdatePrompts(ctx context.Context, now string) ([]queries.UpdatePrompt, error)
+	SetUpdatePromptNext(ctx context.Context, params queries.SetUpdatePromptNextParams) error
+
+	// Partners are set with /partner, a pair has a row per member. Checkpoint partners are
+	// assigned by rotation for a single checkpoint and take precedence. SetPartner writes both rows of the pair
+	// in one transaction, deleting the members' previous pairs.
+	SetPartner(ctx context.Context, params queries.SetPartnerParams) error
+	GetPartner(ctx context.Context, params queries.GetPartnerParams) (*queries.Partner, error)
+	DeletePartner(ctx context.Context, params queries.DeletePartnerParams) (int64, error)
+	SetCheckpointPartner(ctx context.Context, params queries.SetCheckpointPartnerParams) error
+	GetCheckpointPartner(ctx context.Context, params queries.GetCheckpointPartnerParams) (*queries.CheckpointPartner, error)
+	// UpdateGoalVerification publishes events.GoalVerified once a partner verifies a goal
+	UpdateGoalVerification(ctx context.Context, params queries.UpdateGoalVerificationParams) error
+	GetGoalsPendingVerification(ctx context.Context, guildID string) ([]queries.Goal, error)
+	// Goal verifications are peer confirmations of a completed goal, CreateGoalVerification returns 0 for duplicates
+	CreateGoalVerification(ctx context.Context, params queries.CreateGoalVerificationParams) (int64, error)
+	GetGoalVerifications(ctx context.Context, goalID int64) ([]queries.GoalVerification, error)
+	DeleteGoalVerifications(ctx context.Context, goalID int64) error
+
+	// CreateGoalRevision snapshots a goal after a change, GetGoalRevisions returns them oldest first
+	CreateGoalRevision(ctx context.Context, params queries.CreateGoalRevisionParams) (*queries.GoalRevision, error)
+	GetGoalRevisions(ctx context.Context, goalID int64) ([]queries.GoalRevision, error)
+	// GetEditedGoalIDsByCheckpoint returns the goals whose description changed after they were set
+	GetEditedGoalIDsByCheckpoint(ctx context.Context, checkpointID int64) ([]int64, error)
+
+	// Pending goals are set for a channel's next checkpoint before it's scheduled
+	SavePendingGoal(ctx context.Context, params queries.SavePendingGoalParams) (*queries.PendingGoal, error)
+	GetPendingGoal(ctx context.Context, params queries.GetPendingGoalParams) (*queries.PendingGoal, error)
+	GetPendingGoalsByChannel(ctx context.Context, channelID string) ([]queries.PendingGoal, error)
+	DeletePendingGoal(ctx context.Context, id int64) error
 
 	GetUpcomingCheckpointsByGuildAndChannel(ctx context.Context, params queries.GetUpcomingCheckpointsByGuildAndChannelParams) ([]queries.Checkpoint, error)
 	GetGoalsByCheckpoint(ctx context.Context, checkpointID int64) ([]queries.Goal, error)
 
+	ListCheckpoints(ctx context.Context, params queries.ListCheckpointsParams) ([]queries.Checkpoint, error)
+	GetRsvpsByCheckpoint(ctx context.Context, checkpointID int64) ([]queries.CheckpointRsvp, error)
+	GetAttendanceByCheckpoint(ctx context.Context, checkpointID int64) ([]queries.Attendance, error)
+
+	GetUserGoalStats(ctx context.Context, params queries.GetUserGoalStatsParams) (*queries.GetUserGoalStatsRow, error)
+	GetUserGoalStatsByTag(ctx context.Context, params queries.GetUserGoalStatsByTagParams) (*queries.GetUserGoalStatsByTagRow, error)
+	GetUserTagStats(ctx context.Context, params queries.GetUserTagStatsParams) ([]queries.GetUserTagStatsRow, error)
+	GetUserAttendanceCount(ctx context.Context, params queries.GetUserAttendanceCountParams) (int64, error)
+	GetGuildUserStats(ctx context.Context, guildID string) ([]queries.GetGuildUserStatsRow, error)
+
+	CreateApiToken(ctx context.Context, params queries.CreateApiTokenParams) (*queries.ApiToken, error)
+	GetApiTokenByHash(ctx context.Context, tokenHash string) (*queries.ApiToken, error)
+	ListApiTokensByGuild(ctx context.Context, guildID string) ([]queries.ApiToken, error)
+	DeleteApiToken(ctx context.Context, params queries.DeleteApiTokenParams) (int64, error)
+	TouchApiToken(ctx context.Context, id int64) error
+
+	// CreateAuditEntry records an administrative action and publishes it as events.AuditRecorded
+	CreateAuditEntry(ctx context.Context, params queries.CreateAuditEntryParams) (*queries.AuditLog, error)
+	ListAuditEntries(ctx context.Context, params queries.ListAuditEntriesParams) ([]queries.AuditLog, error)
+
+	CreateWebhook(ctx context.Context, params queries.CreateWebhookParams) (*queries.Webhook, error)
+	ListWebhooksByGuild(ctx context.Context, guildID string) ([]queries.Webhook, error)
+	DeleteWebhook(ctx context.Context, params queries.DeleteWebhookParams) (int64, error)
+	CreateWebhookDelivery(ctx context.Context, params queries.CreateWebhookDeliveryParams) (*queries.WebhookDelivery, error)
+	GetDueWebhookDeliveries(ctx context.Context, params queries.GetDueWebhookDeliveriesParams) ([]queries.GetDueWebhookDeliveriesRow, error)
+	UpdateWebhookDelivery(ctx context.Context, params queries.UpdateWebhookDeliveryParams) error
+	ListWebhookDeliveries(ctx context.Context, params queries.ListWebhookDeliveriesParams) ([]queries.WebhookDelivery, error)
+
+	// Events publishes checkpoint, goal, RSVP and attendance changes after each successful write.
+	// Writes made through WithTx don't publish events, callers publish them once committed (see history.Import).
+	Events() *events.Bus
+
+	// WithTx runs fn inside a single transaction, committing only if fn returns nil
+	WithTx(ctx context.Context, fn func(q *queries.Queries) error) error
+
+	// Ping checks the database is reachable and accepts writes
+	Ping(ctx context.Context) error
 	Close() error
 }
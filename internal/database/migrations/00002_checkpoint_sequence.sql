@@ -0,0 +1,8 @@
+-- +goose Up
+-- Track checkpoint revisions so calendar clients pick up reschedules (iCalendar SEQUENCE)
+ALTER TABLE checkpoints ADD COLUMN sequence INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE checkpoints ADD COLUMN updated_at DATETIME;
+
+-- +goose Down
+ALTER TABLE checkpoints DROP COLUMN updated_at;
+ALTER TABLE checkpoints DROP COLUMN sequence;
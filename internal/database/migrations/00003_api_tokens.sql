@@ -0,0 +1,17 @@
+-- +goose Up
+-- API tokens: per-guild bearer tokens for the REST API, only the SHA-256 hash is stored
+CREATE TABLE IF NOT EXISTS api_tokens (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    guild_id TEXT NOT NULL,
+    name TEXT NOT NULL, -- Label chosen by the admin, e.g. "dashboard"
+    token_hash TEXT NOT NULL UNIQUE, -- Hex encoded SHA-256 of the token
+    created_by TEXT NOT NULL, -- Discord user ID of the admin who created the token
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    last_used_at DATETIME,
+    FOREIGN KEY (guild_id) REFERENCES guilds(guild_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_tokens_guild_id ON api_tokens(guild_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS api_tokens;
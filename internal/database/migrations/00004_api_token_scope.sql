@@ -0,0 +1,9 @@
+-- +goose Up
+-- API token permissions: 'read', 'write' (acts as discord_user) or 'admin' (may edit anyone's goals)
+ALTER TABLE api_tokens ADD COLUMN scope TEXT NOT NULL DEFAULT 'read';
+ALTER TABLE api_tokens ADD COLUMN discord_user TEXT NOT NULL DEFAULT ''; -- Discord user ID the token acts as
+UPDATE api_tokens SET discord_user = created_by;
+
+-- +goose Down
+ALTER TABLE api_tokens DROP COLUMN discord_user;
+ALTER TABLE api_tokens DROP COLUMN scope;
@@ -0,0 +1,37 @@
+-- +goose Up
+-- Webhooks: per-guild URLs receiving signed JSON events
+CREATE TABLE IF NOT EXISTS webhooks (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    guild_id TEXT NOT NULL,
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL, -- HMAC-SHA256 key for the X-Checkpoint-Signature header
+    event_types TEXT NOT NULL DEFAULT '', -- Comma separated event types, empty for every event
+    created_by TEXT NOT NULL, -- Discord user ID of the admin who added the webhook
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (guild_id) REFERENCES guilds(guild_id) ON DELETE CASCADE
+);
+
+-- Webhook deliveries: one row per event and webhook, retried until delivered or out of attempts
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    webhook_id INTEGER NOT NULL,
+    event_id TEXT NOT NULL,
+    event_type TEXT NOT NULL,
+    payload TEXT NOT NULL, -- JSON body, signed as is
+    status TEXT NOT NULL DEFAULT 'pending', -- 'pending', 'delivered', 'failed'
+    attempts INTEGER NOT NULL DEFAULT 0,
+    response_status INTEGER, -- HTTP status of the last attempt
+    last_error TEXT,
+    next_attempt_at TEXT NOT NULL, -- RFC3339
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    delivered_at DATETIME,
+    FOREIGN KEY (webhook_id) REFERENCES webhooks(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhooks_guild_id ON webhooks(guild_id);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook_id ON webhook_deliveries(webhook_id);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_pending ON webhook_deliveries(status, next_attempt_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhooks;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- Guild settings: one row per overridden key, keys without a row use the default from internal/settings
+CREATE TABLE IF NOT EXISTS guild_settings (
+    guild_id TEXT NOT NULL,
+    key TEXT NOT NULL,
+    value TEXT NOT NULL, -- Normalized value, validated by internal/settings before it's stored
+    updated_by TEXT NOT NULL, -- Discord user ID of the admin who last changed it
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (guild_id, key),
+    FOREIGN KEY (guild_id) REFERENCES guilds(guild_id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS guild_settings;
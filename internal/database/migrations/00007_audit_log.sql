@@ -0,0 +1,21 @@
+-- +goose Up
+-- Audit log: administrative actions and changes made on behalf of other members
+CREATE TABLE IF NOT EXISTS audit_log (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    guild_id TEXT NOT NULL,
+    actor_id TEXT NOT NULL, -- Discord user ID of who made the change
+    source TEXT NOT NULL DEFAULT 'discord', -- 'discord', 'api' or 'dashboard'
+    action TEXT NOT NULL, -- e.g. 'checkpoint.rescheduled', see internal/audit
+    target_type TEXT NOT NULL, -- 'checkpoint', 'goal' or 'setting'
+    target_id TEXT NOT NULL, -- Checkpoint ID or setting key
+    target_user TEXT NOT NULL DEFAULT '', -- Discord user ID of the member affected, if any
+    before_value TEXT NOT NULL DEFAULT '',
+    after_value TEXT NOT NULL DEFAULT '',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (guild_id) REFERENCES guilds(guild_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_guild_id ON audit_log(guild_id, id);
+
+-- +goose Down
+DROP TABLE IF EXISTS audit_log;
@@ -0,0 +1,20 @@
+-- +goose Up
+-- Goal revisions: a snapshot of a goal after every description or status change
+CREATE TABLE IF NOT EXISTS goal_revisions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    goal_id INTEGER NOT NULL,
+    description TEXT NOT NULL,
+    status TEXT NOT NULL,
+    edited_by TEXT NOT NULL, -- Discord user ID of who made the change
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (goal_id) REFERENCES goals(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_goal_revisions_goal_id ON goal_revisions(goal_id, id);
+
+-- Existing goals start their history with their current state
+INSERT INTO goal_revisions (goal_id, description, status, edited_by, created_at)
+SELECT id, description, status, discord_user, created_at FROM goals;
+
+-- +goose Down
+DROP TABLE IF EXISTS goal_revisions;
@@ -0,0 +1,18 @@
+-- +goose Up
+-- Pending goals: goals set for a channel's next checkpoint before it's scheduled,
+-- they become regular goals when a checkpoint is created in the channel
+CREATE TABLE IF NOT EXISTS pending_goals (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    guild_id TEXT NOT NULL,
+    channel_id TEXT NOT NULL,
+    discord_user TEXT NOT NULL,
+    description TEXT NOT NULL,
+    updated_by TEXT NOT NULL, -- Discord user ID of who last set the goal
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (channel_id, discord_user),
+    FOREIGN KEY (guild_id) REFERENCES guilds(guild_id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS pending_goals;
@@ -0,0 +1,11 @@
+-- +goose Up
+-- Goal progress: how much of a goal is done, from 0 to 100, partially done goals have the 'partial' status
+ALTER TABLE goals ADD COLUMN progress INTEGER NOT NULL DEFAULT 0;
+UPDATE goals SET progress = 100 WHERE status = 'completed';
+ALTER TABLE goal_revisions ADD COLUMN progress INTEGER NOT NULL DEFAULT 0;
+UPDATE goal_revisions SET progress = 100 WHERE status = 'completed';
+
+-- +goose Down
+ALTER TABLE goal_revisions DROP COLUMN progress;
+UPDATE goals SET status = 'incomplete' WHERE status = 'partial';
+ALTER TABLE goals DROP COLUMN progress;
@@ -0,0 +1,28 @@
+-- +goose Up
+-- Quantitative goals: a goal with a target (e.g. 20 km) is completed once its check-ins add up to it
+ALTER TABLE goals ADD COLUMN target REAL NOT NULL DEFAULT 0; -- 0 for goals without a target
+ALTER TABLE goals ADD COLUMN unit TEXT NOT NULL DEFAULT '';
+ALTER TABLE goals ADD COLUMN total REAL NOT NULL DEFAULT 0; -- sum of the goal's check-ins
+ALTER TABLE pending_goals ADD COLUMN target REAL NOT NULL DEFAULT 0;
+ALTER TABLE pending_goals ADD COLUMN unit TEXT NOT NULL DEFAULT '';
+
+-- Goal check-ins: amounts logged towards a goal's target between checkpoints
+CREATE TABLE IF NOT EXISTS goal_checkins (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    goal_id INTEGER NOT NULL,
+    amount REAL NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    logged_by TEXT NOT NULL, -- Discord user ID of who logged the amount
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (goal_id) REFERENCES goals(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_goal_checkins_goal_id ON goal_checkins(goal_id, id);
+
+-- +goose Down
+DROP TABLE IF EXISTS goal_checkins;
+ALTER TABLE pending_goals DROP COLUMN unit;
+ALTER TABLE pending_goals DROP COLUMN target;
+ALTER TABLE goals DROP COLUMN total;
+ALTER TABLE goals DROP COLUMN unit;
+ALTER TABLE goals DROP COLUMN target;
@@ -0,0 +1,27 @@
+-- +goose Up
+-- Goal updates: short progress notes posted between checkpoints, usually answering an update prompt
+CREATE TABLE IF NOT EXISTS goal_updates (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    goal_id INTEGER NOT NULL,
+    discord_user TEXT NOT NULL,
+    content TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (goal_id) REFERENCES goals(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_goal_updates_goal_id ON goal_updates(goal_id, id);
+
+-- Update prompts: members who opted in to be asked for an update by DM every interval_days
+CREATE TABLE IF NOT EXISTS update_prompts (
+    guild_id TEXT NOT NULL,
+    discord_user TEXT NOT NULL,
+    interval_days INTEGER NOT NULL,
+    next_prompt_at TEXT NOT NULL, -- RFC3339
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (guild_id, discord_user),
+    FOREIGN KEY (guild_id) REFERENCES guilds(guild_id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS update_prompts;
+DROP TABLE IF EXISTS goal_updates;
@@ -0,0 +1,30 @@
+-- +goose Up
+-- Accountability partners set with /partner set, stored once per member so pairs have two rows
+CREATE TABLE IF NOT EXISTS partners (
+    guild_id TEXT NOT NULL,
+    discord_user TEXT NOT NULL,
+    partner_user TEXT NOT NULL,
+    created_by TEXT NOT NULL, -- Discord user ID of who paired them
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (guild_id, discord_user),
+    FOREIGN KEY (guild_id) REFERENCES guilds(guild_id) ON DELETE CASCADE
+);
+
+-- Rotating partners assigned for a single checkpoint, they take precedence over partners
+CREATE TABLE IF NOT EXISTS checkpoint_partners (
+    checkpoint_id INTEGER NOT NULL,
+    discord_user TEXT NOT NULL,
+    partner_user TEXT NOT NULL,
+    PRIMARY KEY (checkpoint_id, discord_user),
+    FOREIGN KEY (checkpoint_id) REFERENCES checkpoints(id) ON DELETE CASCADE
+);
+
+-- Completed goals of members with a partner count in stats once their partner verifies them
+ALTER TABLE goals ADD COLUMN verification TEXT NOT NULL DEFAULT ''; -- '', 'pending' or 'verified'
+ALTER TABLE goals ADD COLUMN verified_by TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE goals DROP COLUMN verified_by;
+ALTER TABLE goals DROP COLUMN verification;
+DROP TABLE IF EXISTS checkpoint_partners;
+DROP TABLE IF EXISTS partners;
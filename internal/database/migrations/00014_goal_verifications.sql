@@ -0,0 +1,12 @@
+-- +goose Up
+-- Peer confirmations of completed goals, required when the guild sets verification_confirmations
+CREATE TABLE IF NOT EXISTS goal_verifications (
+    goal_id INTEGER NOT NULL,
+    discord_user TEXT NOT NULL, -- Discord user ID of the member confirming the goal
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (goal_id, discord_user),
+    FOREIGN KEY (goal_id) REFERENCES goals(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS goal_verifications;
@@ -0,0 +1,18 @@
+-- +goose Up
+-- Proof attached to a goal with /goal-proof, an uploaded file or a link
+CREATE TABLE IF NOT EXISTS goal_evidence (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    goal_id INTEGER NOT NULL,
+    kind TEXT NOT NULL, -- 'attachment' or 'link'
+    url TEXT NOT NULL,
+    filename TEXT NOT NULL DEFAULT '', -- only set for attachments
+    added_by TEXT NOT NULL, -- Discord user ID of who attached it
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (goal_id) REFERENCES goals(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_goal_evidence_goal_id ON goal_evidence(goal_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_goal_evidence_goal_id;
+DROP TABLE IF EXISTS goal_evidence;
@@ -0,0 +1,19 @@
+-- +goose Up
+-- Reusable goals saved with /template, personal ones belong to a member and guild-wide ones have no discord_user
+CREATE TABLE IF NOT EXISTS goal_templates (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    guild_id TEXT NOT NULL,
+    discord_user TEXT NOT NULL DEFAULT '', -- '' for guild-wide templates
+    name TEXT NOT NULL COLLATE NOCASE,
+    description TEXT NOT NULL,
+    target REAL NOT NULL DEFAULT 0,
+    unit TEXT NOT NULL DEFAULT '',
+    uses INTEGER NOT NULL DEFAULT 0, -- how many goals were started from the template
+    created_by TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (guild_id, discord_user, name),
+    FOREIGN KEY (guild_id) REFERENCES guilds(guild_id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS goal_templates;
@@ -0,0 +1,18 @@
+-- +goose Up
+-- Tags on goals, either one of the guild's goal_categories or a free tag
+CREATE TABLE IF NOT EXISTS goal_tags (
+    goal_id INTEGER NOT NULL,
+    tag TEXT NOT NULL, -- lowercase, see settings.NormalizeTag
+    PRIMARY KEY (goal_id, tag),
+    FOREIGN KEY (goal_id) REFERENCES goals(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_goal_tags_tag ON goal_tags(tag);
+
+-- Comma-separated tags of a pending goal, added when it's attached to a checkpoint
+ALTER TABLE pending_goals ADD COLUMN tags TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_goal_tags_tag;
+DROP TABLE IF EXISTS goal_tags;
+ALTER TABLE pending_goals DROP COLUMN tags;
@@ -0,0 +1,23 @@
+-- +goose Up
+-- Long-term objectives of a member, such as "ship side project by March", reached through goals over several checkpoints
+CREATE TABLE IF NOT EXISTS objectives (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    guild_id TEXT NOT NULL,
+    discord_user TEXT NOT NULL,
+    title TEXT NOT NULL,
+    deadline TEXT NOT NULL, -- RFC3339, the end of the deadline's day in the guild's timezone
+    status TEXT NOT NULL DEFAULT 'active', -- 'active' or 'achieved'
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (guild_id) REFERENCES guilds(guild_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_objectives_guild_user ON objectives(guild_id, discord_user);
+
+-- Goals can work towards one of their owner's objectives
+ALTER TABLE goals ADD COLUMN objective_id INTEGER REFERENCES objectives(id) ON DELETE SET NULL;
+
+-- +goose Down
+ALTER TABLE goals DROP COLUMN objective_id;
+DROP INDEX IF EXISTS idx_objectives_guild_user;
+DROP TABLE IF EXISTS objectives;
@@ -0,0 +1,10 @@
+-- +goose Up
+-- Who can see a goal's text: public, members (a summary for everyone else) or private (owner and moderators)
+ALTER TABLE goals ADD COLUMN visibility TEXT NOT NULL DEFAULT 'public';
+
+-- Visibility of a pending goal, empty for the guild's goal_visibility setting
+ALTER TABLE pending_goals ADD COLUMN visibility TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE pending_goals DROP COLUMN visibility;
+ALTER TABLE goals DROP COLUMN visibility;
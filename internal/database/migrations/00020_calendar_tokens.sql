@@ -0,0 +1,11 @@
+-- +goose Up
+-- Secret part of each guild's calendar feed URL, handed out by /calendar
+CREATE TABLE IF NOT EXISTS calendar_tokens (
+    guild_id TEXT PRIMARY KEY,
+    token TEXT NOT NULL UNIQUE,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (guild_id) REFERENCES guilds(guild_id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS calendar_tokens;
@@ -0,0 +1,20 @@
+-- +goose Up
+-- Calendar tokens are stored as SHA-256 hashes like API tokens. The plaintext ones can't be hashed
+-- in SQL, so they're dropped and admins hand out a new link with /calendar-link.
+DROP TABLE IF EXISTS calendar_tokens;
+CREATE TABLE IF NOT EXISTS calendar_tokens (
+    guild_id TEXT PRIMARY KEY,
+    token_hash TEXT NOT NULL UNIQUE, -- Hex encoded SHA-256 of the token
+    created_by TEXT NOT NULL, -- Discord user ID of the admin who created the link
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (guild_id) REFERENCES guilds(guild_id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS calendar_tokens;
+CREATE TABLE IF NOT EXISTS calendar_tokens (
+    guild_id TEXT PRIMARY KEY,
+    token TEXT NOT NULL UNIQUE,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (guild_id) REFERENCES guilds(guild_id) ON DELETE CASCADE
+);
@@ -8,6 +8,18 @@ import (
 	"database/sql"
 )
 
+type ApiToken struct {
+	ID          int64        `json:"id"`
+	GuildID     string       `json:"guild_id"`
+	Name        string       `json:"name"`
+	TokenHash   string       `json:"token_hash"`
+	CreatedBy   string       `json:"created_by"`
+	CreatedAt   sql.NullTime `json:"created_at"`
+	LastUsedAt  sql.NullTime `json:"last_used_at"`
+	Scope       string       `json:"scope"`
+	DiscordUser string       `json:"discord_user"`
+}
+
 type Attendance struct {
 	ID           int64        `json:"id"`
 	DiscordUser  string       `json:"discord_user"`
@@ -15,6 +27,27 @@ type Attendance struct {
 	CreatedAt    sql.NullTime `json:"created_at"`
 }
 
+type AuditLog struct {
+	ID          int64        `json:"id"`
+	GuildID     string       `json:"guild_id"`
+	ActorID     string       `json:"actor_id"`
+	Source      string       `json:"source"`
+	Action      string       `json:"action"`
+	TargetType  string       `json:"target_type"`
+	TargetID    string       `json:"target_id"`
+	TargetUser  string       `json:"target_user"`
+	BeforeValue string       `json:"before_value"`
+	AfterValue  string       `json:"after_value"`
+	CreatedAt   sql.NullTime `json:"created_at"`
+}
+
+type CalendarToken struct {
+	GuildID   string       `json:"guild_id"`
+	TokenHash string       `json:"token_hash"`
+	CreatedBy string       `json:"created_by"`
+	CreatedAt sql.NullTime `json:"created_at"`
+}
+
 type Checkpoint struct {
 	ID          int64        `json:"id"`
 	ScheduledAt string       `json:"scheduled_at"`
@@ -22,6 +55,14 @@ type Checkpoint struct {
 	GuildID     string       `json:"guild_id"`
 	DiscordUser string       `json:"discord_user"`
 	CreatedAt   sql.NullTime `json:"created_at"`
+	Sequence    int64        `json:"sequence"`
+	UpdatedAt   sql.NullTime `json:"updated_at"`
+}
+
+type CheckpointPartner struct {
+	CheckpointID int64  `json:"checkpoint_id"`
+	DiscordUser  string `json:"discord_user"`
+	PartnerUser  string `json:"partner_user"`
 }
 
 type CheckpointRsvp struct {
@@ -36,12 +77,81 @@ type DiscordUser struct {
 }
 
 type Goal struct {
-	ID           int64        `json:"id"`
-	DiscordUser  string       `json:"discord_user"`
-	Description  string       `json:"description"`
-	CheckpointID int64        `json:"checkpoint_id"`
-	Status       string       `json:"status"`
-	CreatedAt    sql.NullTime `json:"created_at"`
+	ID           int64         `json:"id"`
+	DiscordUser  string        `json:"discord_user"`
+	Description  string        `json:"description"`
+	CheckpointID int64         `json:"checkpoint_id"`
+	Status       string        `json:"status"`
+	CreatedAt    sql.NullTime  `json:"created_at"`
+	Progress     int64         `json:"progress"`
+	Target       float64       `json:"target"`
+	Unit         string        `json:"unit"`
+	Total        float64       `json:"total"`
+	Verification string        `json:"verification"`
+	VerifiedBy   string        `json:"verified_by"`
+	ObjectiveID  sql.NullInt64 `json:"objective_id"`
+	Visibility   string        `json:"visibility"`
+}
+
+type GoalCheckin struct {
+	ID        int64        `json:"id"`
+	GoalID    int64        `json:"goal_id"`
+	Amount    float64      `json:"amount"`
+	Note      string       `json:"note"`
+	LoggedBy  string       `json:"logged_by"`
+	CreatedAt sql.NullTime `json:"created_at"`
+}
+
+type GoalEvidence struct {
+	ID        int64        `json:"id"`
+	GoalID    int64        `json:"goal_id"`
+	Kind      string       `json:"kind"`
+	Url       string       `json:"url"`
+	Filename  string       `json:"filename"`
+	AddedBy   string       `json:"added_by"`
+	CreatedAt sql.NullTime `json:"created_at"`
+}
+
+type GoalRevision struct {
+	ID          int64        `json:"id"`
+	GoalID      int64        `json:"goal_id"`
+	Description string       `json:"description"`
+	Status      string       `json:"status"`
+	EditedBy    string       `json:"edited_by"`
+	CreatedAt   sql.NullTime `json:"created_at"`
+	Progress    int64        `json:"progress"`
+}
+
+type GoalTag struct {
+	GoalID int64  `json:"goal_id"`
+	Tag    string `json:"tag"`
+}
+
+type GoalTemplate struct {
+	ID          int64        `json:"id"`
+	GuildID     string       `json:"guild_id"`
+	DiscordUser string       `json:"discord_user"`
+	Name        string       `json:"name"`
+	Description string       `json:"description"`
+	Target      float64      `json:"target"`
+	Unit        string       `json:"unit"`
+	Uses        int64        `json:"uses"`
+	CreatedBy   string       `json:"created_by"`
+	CreatedAt   sql.NullTime `json:"created_at"`
+}
+
+type GoalUpdate struct {
+	ID          int64        `json:"id"`
+	GoalID      int64        `json:"goal_id"`
+	DiscordUser string       `json:"discord_user"`
+	Content     string       `json:"content"`
+	CreatedAt   sql.NullTime `json:"created_at"`
+}
+
+type GoalVerification struct {
+	GoalID      int64        `json:"goal_id"`
+	DiscordUser string       `json:"discord_user"`
+	CreatedAt   sql.NullTime `json:"created_at"`
 }
 
 type Guild struct {
@@ -50,3 +160,78 @@ type Guild struct {
 	OwnerID   string       `json:"owner_id"`
 	CreatedAt sql.NullTime `json:"created_at"`
 }
+
+type GuildSetting struct {
+	GuildID   string       `json:"guild_id"`
+	Key       string       `json:"key"`
+	Value     string       `json:"value"`
+	UpdatedBy string       `json:"updated_by"`
+	UpdatedAt sql.NullTime `json:"updated_at"`
+}
+
+type Objective struct {
+	ID          int64        `json:"id"`
+	GuildID     string       `json:"guild_id"`
+	DiscordUser string       `json:"discord_user"`
+	Title       string       `json:"title"`
+	Deadline    string       `json:"deadline"`
+	Status      string       `json:"status"`
+	CreatedAt   sql.NullTime `json:"created_at"`
+	UpdatedAt   sql.NullTime `json:"updated_at"`
+}
+
+type Partner struct {
+	GuildID     string       `json:"guild_id"`
+	DiscordUser string       `json:"discord_user"`
+	PartnerUser string       `json:"partner_user"`
+	CreatedBy   string       `json:"created_by"`
+	CreatedAt   sql.NullTime `json:"created_at"`
+}
+
+type PendingGoal struct {
+	ID          int64        `json:"id"`
+	GuildID     string       `json:"guild_id"`
+	ChannelID   string       `json:"channel_id"`
+	DiscordUser string       `json:"discord_user"`
+	Description string       `json:"description"`
+	UpdatedBy   string       `json:"updated_by"`
+	CreatedAt   sql.NullTime `json:"created_at"`
+	UpdatedAt   sql.NullTime `json:"updated_at"`
+	Target      float64      `json:"target"`
+	Unit        string       `json:"unit"`
+	Tags        string       `json:"tags"`
+	Visibility  string       `json:"visibility"`
+}
+
+type UpdatePrompt struct {
+	GuildID      string       `json:"guild_id"`
+	DiscordUser  string       `json:"discord_user"`
+	IntervalDays int64        `json:"interval_days"`
+	NextPromptAt string       `json:"next_prompt_at"`
+	CreatedAt    sql.NullTime `json:"created_at"`
+}
+
+type Webhook struct {
+	ID         int64        `json:"id"`
+	GuildID    string       `json:"guild_id"`
+	Url        string       `json:"url"`
+	Secret     string       `json:"secret"`
+	EventTypes string       `json:"event_types"`
+	CreatedBy  string       `json:"created_by"`
+	CreatedAt  sql.NullTime `json:"created_at"`
+}
+
+type WebhookDelivery struct {
+	ID             int64          `json:"id"`
+	WebhookID      int64          `json:"webhook_id"`
+	EventID        string         `json:"event_id"`
+	EventType      string         `json:"event_type"`
+	Payload        string         `json:"payload"`
+	Status         string         `json:"status"`
+	Attempts       int64          `json:"attempts"`
+	ResponseStatus sql.NullInt64  `json:"response_status"`
+	LastError      sql.NullString `json:"last_error"`
+	NextAttemptAt  string         `json:"next_attempt_at"`
+	CreatedAt      sql.NullTime   `json:"created_at"`
+	DeliveredAt    sql.NullTime   `json:"delivered_at"`
+}
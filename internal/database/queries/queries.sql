@@ -8,12 +8,13 @@ INSERT INTO checkpoints (scheduled_at, channel_id, guild_id, discord_user)
 VALUES (?, ?, ?, ?) RETURNING *;
 
 -- name: CreateGoal :one
-INSERT INTO goals (discord_user, description, checkpoint_id)
-VALUES (?, ?, ?) RETURNING *;
+-- An empty visibility is public
+INSERT INTO goals (discord_user, description, checkpoint_id, visibility)
+VALUES (?, ?, ?, COALESCE(NULLIF(CAST(sqlc.arg(visibility) AS TEXT), ''), 'public')) RETURNING *;
 
 -- name: CompleteGoal :exec
 UPDATE goals
-SET status = 'completed'
+SET status = 'completed', progress = 100
 WHERE discord_user = ? AND checkpoint_id = ?;
 
 -- name: FailedGoal :exec
@@ -47,6 +48,16 @@ SELECT * FROM checkpoints
 WHERE channel_id = ? AND datetime(scheduled_at) < datetime('now')
 ORDER BY datetime(scheduled_at) DESC;
 
+-- name: GetPastCheckpointsByChannelAndTag :many
+SELECT sqlc.embed(checkpoints), COUNT(*) AS tagged_goals,
+    CAST(COALESCE(SUM(goals.status = 'completed' AND goals.verification != 'pending'), 0) AS INTEGER) AS tagged_completed
+FROM checkpoints
+JOIN goals ON goals.checkpoint_id = checkpoints.id
+JOIN goal_tags ON goal_tags.goal_id = goals.id
+WHERE checkpoints.channel_id = ? AND goal_tags.tag = ? AND datetime(checkpoints.scheduled_at) < datetime('now')
+GROUP BY checkpoints.id
+ORDER BY datetime(checkpoints.scheduled_at) DESC;
+
 -- name: GetUpcomingCheckpointByGuildAndChannel :one
 SELECT * FROM checkpoints
 WHERE guild_id = ? AND channel_id = ? AND datetime(scheduled_at) >= datetime('now')
@@ -72,8 +83,503 @@ UPDATE goals
 SET status = ?
 WHERE checkpoint_id = ? AND discord_user = ?;
 
+-- name: UpdateGoalProgress :exec
+UPDATE goals
+SET progress = ?
+WHERE checkpoint_id = ? AND discord_user = ?;
+
+-- name: UpdateGoalTarget :exec
+UPDATE goals
+SET target = ?, unit = ?
+WHERE checkpoint_id = ? AND discord_user = ?;
+
 -- name: GetGoalsByCheckpoint :many
 SELECT * FROM goals
 WHERE checkpoint_id = ?
 ORDER BY created_at ASC;
 
+-- name: ListCheckpoints :many
+SELECT * FROM checkpoints
+WHERE guild_id = sqlc.arg(guild_id)
+  AND (CAST(sqlc.narg(channel_id) AS TEXT) IS NULL OR channel_id = sqlc.narg(channel_id))
+  AND (CAST(sqlc.narg(scheduled_from) AS TEXT) IS NULL OR datetime(scheduled_at) >= datetime(sqlc.narg(scheduled_from)))
+  AND (CAST(sqlc.narg(scheduled_to) AS TEXT) IS NULL OR datetime(scheduled_at) < datetime(sqlc.narg(scheduled_to)))
+ORDER BY datetime(scheduled_at) ASC;
+
+-- name: GetRsvpsByCheckpoint :many
+SELECT * FROM checkpoint_rsvp
+WHERE checkpoint_id = ?
+ORDER BY created_at ASC;
+
+-- name: GetAttendanceByCheckpoint :many
+SELECT * FROM attendance
+WHERE checkpoint_id = ?
+ORDER BY created_at ASC;
+
+-- name: CreateRsvp :one
+INSERT OR IGNORE INTO checkpoint_rsvp (checkpoint_id, discord_user)
+VALUES (?, ?) RETURNING *;
+
+-- name: CreateAttendance :one
+INSERT OR IGNORE INTO attendance (discord_user, checkpoint_id)
+VALUES (?, ?) RETURNING *;
+
+-- name: GetCheckpoint :one
+SELECT * FROM checkpoints
+WHERE id = ?;
+
+-- name: RescheduleCheckpoint :one
+UPDATE checkpoints
+SET scheduled_at = ?, sequence = sequence + 1, updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+RETURNING *;
+
+-- name: GetGuildTotals :many
+SELECT
+    guilds.guild_id,
+    (SELECT COUNT(*) FROM checkpoints WHERE checkpoints.guild_id = guilds.guild_id) AS checkpoints,
+    (SELECT COUNT(*) FROM goals JOIN checkpoints ON checkpoints.id = goals.checkpoint_id WHERE checkpoints.guild_id = guilds.guild_id) AS goals
+FROM guilds;
+
+-- name: CreateApiToken :one
+INSERT INTO api_tokens (guild_id, name, token_hash, created_by, scope, discord_user)
+VALUES (?, ?, ?, ?, ?, ?) RETURNING *;
+
+-- name: GetApiTokenByHash :one
+SELECT * FROM api_tokens
+WHERE token_hash = ?;
+
+-- name: ListApiTokensByGuild :many
+SELECT * FROM api_tokens
+WHERE guild_id = ?
+ORDER BY created_at ASC;
+
+-- name: DeleteApiToken :execrows
+DELETE FROM api_tokens
+WHERE id = ? AND guild_id = ?;
+
+-- name: TouchApiToken :exec
+UPDATE api_tokens
+SET last_used_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: GetUserGoalStats :one
+SELECT
+    COUNT(*) AS goals,
+    CAST(COALESCE(SUM(goals.status = 'completed' AND goals.verification != 'pending'), 0) AS INTEGER) AS completed,
+    CAST(COALESCE(SUM(goals.status = 'completed' AND goals.verification = 'pending'), 0) AS INTEGER) AS unverified,
+    CAST(COALESCE(SUM(goals.status = 'failed'), 0) AS INTEGER) AS failed,
+    CAST(COALESCE(SUM(goals.status = 'incomplete'), 0) AS INTEGER) AS incomplete,
+    CAST(COALESCE(SUM(goals.status = 'partial'), 0) AS INTEGER) AS partial,
+    CAST(COALESCE(SUM(CASE WHEN goals.status = 'partial' THEN goals.progress ELSE 0 END), 0) AS INTEGER) AS partial_progress
+FROM goals
+JOIN checkpoints ON checkpoints.id = goals.checkpoint_id
+WHERE checkpoints.guild_id = ? AND goals.discord_user = ?;
+
+-- name: GetUserGoalStatsByTag :one
+SELECT
+    COUNT(*) AS goals,
+    CAST(COALESCE(SUM(goals.status = 'completed' AND goals.verification != 'pending'), 0) AS INTEGER) AS completed,
+    CAST(COALESCE(SUM(goals.status = 'completed' AND goals.verification = 'pending'), 0) AS INTEGER) AS unverified,
+    CAST(COALESCE(SUM(goals.status = 'failed'), 0) AS INTEGER) AS failed,
+    CAST(COALESCE(SUM(goals.status = 'incomplete'), 0) AS INTEGER) AS incomplete,
+    CAST(COALESCE(SUM(goals.status = 'partial'), 0) AS INTEGER) AS partial,
+    CAST(COALESCE(SUM(CASE WHEN goals.status = 'partial' THEN goals.progress ELSE 0 END), 0) AS INTEGER) AS partial_progress
+FROM goals
+JOIN checkpoints ON checkpoints.id = goals.checkpoint_id
+JOIN goal_tags ON goal_tags.goal_id = goals.id
+WHERE checkpoints.guild_id = ? AND goals.discord_user = ? AND goal_tags.tag = ?;
+
+-- name: GetUserTagStats :many
+SELECT
+    goal_tags.tag,
+    COUNT(*) AS goals,
+    CAST(COALESCE(SUM(goals.status = 'completed' AND goals.verification != 'pending'), 0) AS INTEGER) AS completed,
+    CAST(COALESCE(SUM(CASE WHEN goals.status = 'partial' THEN goals.progress ELSE 0 END), 0) AS INTEGER) AS partial_progress
+FROM goals
+JOIN checkpoints ON checkpoints.id = goals.checkpoint_id
+JOIN goal_tags ON goal_tags.goal_id = goals.id
+WHERE checkpoints.guild_id = ? AND goals.discord_user = ?
+GROUP BY goal_tags.tag
+ORDER BY goals DESC, goal_tags.tag ASC;
+
+-- name: GetUserAttendanceCount :one
+SELECT COUNT(*) FROM attendance
+JOIN checkpoints ON checkpoints.id = attendance.checkpoint_id
+WHERE checkpoints.guild_id = ? AND attendance.discord_user = ?;
+
+-- name: GetCheckpointsScheduledBetween :many
+SELECT * FROM checkpoints
+WHERE datetime(scheduled_at) > datetime(CAST(sqlc.arg(from) AS TEXT))
+  AND datetime(scheduled_at) <= datetime(CAST(sqlc.arg(to) AS TEXT))
+ORDER BY datetime(scheduled_at) ASC;
+
+-- name: CreateWebhook :one
+INSERT INTO webhooks (guild_id, url, secret, event_types, created_by)
+VALUES (?, ?, ?, ?, ?) RETURNING *;
+
+-- name: ListWebhooksByGuild :many
+SELECT * FROM webhooks
+WHERE guild_id = ?
+ORDER BY id ASC;
+
+-- name: DeleteWebhook :execrows
+DELETE FROM webhooks
+WHERE id = ? AND guild_id = ?;
+
+-- name: CreateWebhookDelivery :one
+INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, payload, next_attempt_at)
+VALUES (?, ?, ?, ?, ?) RETURNING *;
+
+-- name: GetDueWebhookDeliveries :many
+SELECT webhook_deliveries.id, webhook_deliveries.event_id, webhook_deliveries.event_type, webhook_deliveries.payload,
+    webhook_deliveries.attempts, webhooks.url, webhooks.secret
+FROM webhook_deliveries
+JOIN webhooks ON webhooks.id = webhook_deliveries.webhook_id
+WHERE webhook_deliveries.status = 'pending'
+  AND datetime(webhook_deliveries.next_attempt_at) <= datetime(CAST(sqlc.arg(now) AS TEXT))
+ORDER BY datetime(webhook_deliveries.next_attempt_at) ASC
+LIMIT sqlc.arg(max_deliveries);
+
+-- name: UpdateWebhookDelivery :exec
+UPDATE webhook_deliveries
+SET status = ?, attempts = ?, response_status = ?, last_error = ?, next_attempt_at = ?, delivered_at = ?
+WHERE id = ?;
+
+-- name: ListWebhookDeliveries :many
+SELECT webhook_deliveries.* FROM webhook_deliveries
+JOIN webhooks ON webhooks.id = webhook_deliveries.webhook_id
+WHERE webhook_deliveries.webhook_id = ? AND webhooks.guild_id = ?
+ORDER BY webhook_deliveries.id DESC
+LIMIT ?;
+
+-- name: UpdateGuildTimezone :one
+UPDATE guilds
+SET timezone = ?
+WHERE guild_id = ?
+RETURNING *;
+
+-- name: GetGuildUserStats :many
+SELECT
+    goals.discord_user,
+    COUNT(*) AS goals,
+    CAST(COALESCE(SUM(goals.status = 'completed' AND goals.verification != 'pending'), 0) AS INTEGER) AS completed,
+    CAST(COALESCE(SUM(goals.status = 'completed' AND goals.verification = 'pending'), 0) AS INTEGER) AS unverified,
+    CAST(COALESCE(SUM(goals.status = 'failed'), 0) AS INTEGER) AS failed,
+    CAST(COALESCE(SUM(goals.status = 'incomplete'), 0) AS INTEGER) AS incomplete,
+    CAST(COALESCE(SUM(goals.status = 'partial'), 0) AS INTEGER) AS partial,
+    CAST(COALESCE(SUM(CASE WHEN goals.status = 'partial' THEN goals.progress ELSE 0 END), 0) AS INTEGER) AS partial_progress
+FROM goals
+JOIN checkpoints ON checkpoints.id = goals.checkpoint_id
+WHERE checkpoints.guild_id = ?
+GROUP BY goals.discord_user
+ORDER BY completed DESC, goals DESC;
+
+-- name: GetGuildSettings :many
+SELECT * FROM guild_settings
+WHERE guild_id = ?
+ORDER BY key ASC;
+
+-- name: SetGuildSetting :one
+INSERT INTO guild_settings (guild_id, key, value, updated_by)
+VALUES (?, ?, ?, ?)
+ON CONFLICT (guild_id, key) DO UPDATE
+SET value = excluded.value, updated_by = excluded.updated_by, updated_at = CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: DeleteGuildSetting :execrows
+DELETE FROM guild_settings
+WHERE guild_id = ? AND key = ?;
+
+-- name: DeleteCheckpoint :one
+DELETE FROM checkpoints
+WHERE id = ?
+RETURNING *;
+
+-- name: CreateAuditEntry :one
+INSERT INTO audit_log (guild_id, actor_id, source, action, target_type, target_id, target_user, before_value, after_value)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING *;
+
+-- name: ListAuditEntries :many
+SELECT * FROM audit_log
+WHERE guild_id = sqlc.arg(guild_id)
+  AND (CAST(sqlc.narg(action) AS TEXT) IS NULL OR action = sqlc.narg(action))
+  AND (CAST(sqlc.narg(user_id) AS TEXT) IS NULL OR actor_id = sqlc.narg(user_id) OR target_user = sqlc.narg(user_id))
+ORDER BY id DESC
+LIMIT sqlc.arg(max_entries);
+
+-- name: CreateGoalRevision :one
+INSERT INTO goal_revisions (goal_id, description, status, progress, edited_by)
+VALUES (?, ?, ?, ?, ?) RETURNING *;
+
+-- name: GetGoalRevisions :many
+SELECT * FROM goal_revisions
+WHERE goal_id = ?
+ORDER BY id ASC;
+
+-- name: GetEditedGoalIDsByCheckpoint :many
+SELECT goal_revisions.goal_id FROM goal_revisions
+JOIN goals ON goals.id = goal_revisions.goal_id
+WHERE goals.checkpoint_id = ?
+GROUP BY goal_revisions.goal_id
+HAVING COUNT(DISTINCT goal_revisions.description) > 1;
+
+-- name: SavePendingGoal :one
+INSERT INTO pending_goals (guild_id, channel_id, discord_user, description, target, unit, tags, visibility, updated_by)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (channel_id, discord_user) DO UPDATE
+SET description = excluded.description, target = excluded.target, unit = excluded.unit, tags = excluded.tags,
+    visibility = CASE WHEN excluded.visibility = '' THEN pending_goals.visibility ELSE excluded.visibility END,
+    updated_by = excluded.updated_by, updated_at = CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: GetPendingGoal :one
+SELECT * FROM pending_goals
+WHERE channel_id = ? AND discord_user = ?;
+
+-- name: GetPendingGoalsByChannel :many
+SELECT * FROM pending_goals
+WHERE channel_id = ?
+ORDER BY created_at ASC;
+
+-- name: DeletePendingGoal :exec
+DELETE FROM pending_goals
+WHERE id = ?;
+
+-- name: CreateGoalCheckin :one
+INSERT INTO goal_checkins (goal_id, amount, note, logged_by)
+VALUES (?, ?, ?, ?) RETURNING *;
+
+-- name: AddGoalTotal :one
+UPDATE goals
+SET total = total + sqlc.arg(amount)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: GetGoalCheckins :many
+SELECT * FROM goal_checkins
+WHERE goal_id = ?
+ORDER BY id ASC;
+
+-- name: GetGoal :one
+SELECT * FROM goals
+WHERE id = ?;
+
+-- name: GetUpcomingGoalsByGuildAndUser :many
+SELECT goals.* FROM goals
+JOIN checkpoints ON checkpoints.id = goals.checkpoint_id
+WHERE checkpoints.guild_id = ? AND goals.discord_user = ?
+AND datetime(checkpoints.scheduled_at) >= datetime('now')
+ORDER BY datetime(checkpoints.scheduled_at) ASC;
+
+-- name: CreateGoalUpdate :one
+INSERT INTO goal_updates (goal_id, discord_user, content)
+VALUES (?, ?, ?) RETURNING *;
+
+-- name: GetGoalUpdatesByCheckpoint :many
+SELECT goal_updates.* FROM goal_updates
+JOIN goals ON goals.id = goal_updates.goal_id
+WHERE goals.checkpoint_id = ?
+ORDER BY goal_updates.id ASC;
+
+-- name: SaveUpdatePrompt :one
+INSERT INTO update_prompts (guild_id, discord_user, interval_days, next_prompt_at)
+VALUES (?, ?, ?, ?)
+ON CONFLICT (guild_id, discord_user) DO UPDATE
+SET interval_days = excluded.interval_days, next_prompt_at = excluded.next_prompt_at
+RETURNING *;
+
+-- name: DeleteUpdatePrompt :execrows
+DELETE FROM update_prompts
+WHERE guild_id = ? AND discord_user = ?;
+
+-- name: GetDueUpdatePrompts :many
+SELECT * FROM update_prompts
+WHERE datetime(next_prompt_at) <= datetime(CAST(sqlc.arg(now) AS TEXT))
+ORDER BY datetime(next_prompt_at) ASC;
+
+-- name: SetUpdatePromptNext :exec
+UPDATE update_prompts
+SET next_prompt_at = ?
+WHERE guild_id = ? AND discord_user = ?;
+
+-- name: SetPartner :exec
+INSERT INTO partners (guild_id, discord_user, partner_user, created_by)
+VALUES (?, ?, ?, ?)
+ON CONFLICT (guild_id, discord_user) DO UPDATE
+SET partner_user = excluded.partner_user, created_by = excluded.created_by, created_at = CURRENT_TIMESTAMP;
+
+-- name: GetPartner :one
+SELECT * FROM partners
+WHERE guild_id = ? AND discord_user = ?;
+
+-- name: DeletePartner :execrows
+DELETE FROM partners
+WHERE guild_id = ? AND discord_user = ?;
+
+-- name: DeletePartnerPairs :exec
+DELETE FROM partners
+WHERE guild_id = sqlc.arg(guild_id)
+  AND (discord_user IN (sqlc.arg(user_a), sqlc.arg(user_b)) OR partner_user IN (sqlc.arg(user_a), sqlc.arg(user_b)));
+
+-- name: SetCheckpointPartner :exec
+INSERT INTO checkpoint_partners (checkpoint_id, discord_user, partner_user)
+VALUES (?, ?, ?)
+ON CONFLICT (checkpoint_id, discord_user) DO UPDATE
+SET partner_user = excluded.partner_user;
+
+-- name: GetCheckpointPartner :one
+SELECT * FROM checkpoint_partners
+WHERE checkpoint_id = ? AND discord_user = ?;
+
+-- name: GetPreviousCheckpointByChannel :one
+SELECT * FROM checkpoints
+WHERE channel_id = ? AND datetime(scheduled_at) < datetime(CAST(sqlc.arg(before) AS TEXT))
+ORDER BY datetime(scheduled_at) DESC
+LIMIT 1;
+
+-- name: CountCheckpointsByChannelBefore :one
+SELECT COUNT(*) FROM checkpoints
+WHERE channel_id = ? AND datetime(scheduled_at) < datetime(CAST(sqlc.arg(before) AS TEXT));
+
+-- name: UpdateGoalVisibility :exec
+UPDATE goals
+SET visibility = ?
+WHERE id = ?;
+
+-- name: UpdateGoalVerification :exec
+UPDATE goals
+SET verification = ?, verified_by = ?
+WHERE id = ?;
+
+-- name: GetGoalsPendingVerification :many
+SELECT goals.* FROM goals
+JOIN checkpoints ON checkpoints.id = goals.checkpoint_id
+WHERE checkpoints.guild_id = ? AND goals.status = 'completed' AND goals.verification = 'pending'
+ORDER BY goals.id ASC;
+
+-- name: CreateGoalVerification :execrows
+INSERT INTO goal_verifications (goal_id, discord_user)
+VALUES (?, ?)
+ON CONFLICT (goal_id, discord_user) DO NOTHING;
+
+-- name: GetGoalVerifications :many
+SELECT * FROM goal_verifications
+WHERE goal_id = ?
+ORDER BY created_at ASC, discord_user ASC;
+
+-- name: DeleteGoalVerifications :exec
+DELETE FROM goal_verifications
+WHERE goal_id = ?;
+
+-- name: CreateGoalEvidence :one
+INSERT INTO goal_evidence (goal_id, kind, url, filename, added_by)
+VALUES (?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetGoalEvidence :many
+SELECT * FROM goal_evidence
+WHERE goal_id = ?
+ORDER BY id ASC;
+
+-- name: GetGoalEvidenceByCheckpoint :many
+SELECT goal_evidence.*, goals.discord_user FROM goal_evidence
+JOIN goals ON goals.id = goal_evidence.goal_id
+WHERE goals.checkpoint_id = ?
+ORDER BY goal_evidence.id ASC;
+
+-- name: SaveGoalTemplate :one
+INSERT INTO goal_templates (guild_id, discord_user, name, description, target, unit, created_by)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (guild_id, discord_user, name) DO UPDATE
+SET description = excluded.description, target = excluded.target, unit = excluded.unit, created_by = excluded.created_by
+RETURNING *;
+
+-- name: GetGoalTemplate :one
+SELECT * FROM goal_templates
+WHERE id = ?;
+
+-- name: GetGoalTemplatesByGuildAndUser :many
+SELECT * FROM goal_templates
+WHERE guild_id = ? AND (discord_user = sqlc.arg(discord_user) OR discord_user = '')
+ORDER BY uses DESC, name ASC;
+
+-- name: IncrementGoalTemplateUses :exec
+UPDATE goal_templates
+SET uses = uses + 1
+WHERE id = ?;
+
+-- name: DeleteGoalTemplate :execrows
+DELETE FROM goal_templates
+WHERE id = ?;
+
+-- name: CreateGoalTag :exec
+INSERT INTO goal_tags (goal_id, tag)
+VALUES (?, ?)
+ON CONFLICT (goal_id, tag) DO NOTHING;
+
+-- name: DeleteGoalTags :exec
+DELETE FROM goal_tags
+WHERE goal_id = ?;
+
+-- name: GetGoalTags :many
+SELECT tag FROM goal_tags
+WHERE goal_id = ?
+ORDER BY tag ASC;
+
+-- name: GetGoalTagsByCheckpoint :many
+SELECT goal_tags.goal_id, goal_tags.tag FROM goal_tags
+JOIN goals ON goals.id = goal_tags.goal_id
+WHERE goals.checkpoint_id = ?
+ORDER BY goal_tags.goal_id ASC, goal_tags.tag ASC;
+
+-- name: CreateObjective :one
+INSERT INTO objectives (guild_id, discord_user, title, deadline)
+VALUES (?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetObjective :one
+SELECT * FROM objectives
+WHERE id = ?;
+
+-- name: GetObjectivesByGuildAndUser :many
+SELECT sqlc.embed(objectives),
+    COUNT(goals.id) AS goals,
+    COUNT(DISTINCT goals.checkpoint_id) AS checkpoints,
+    CAST(COALESCE(SUM(goals.status = 'completed' AND goals.verification != 'pending'), 0) AS INTEGER) AS completed,
+    CAST(COALESCE(SUM(CASE WHEN goals.status = 'partial' THEN goals.progress ELSE 0 END), 0) AS INTEGER) AS partial_progress
+FROM objectives
+LEFT JOIN goals ON goals.objective_id = objectives.id
+WHERE objectives.guild_id = ? AND objectives.discord_user = ?
+GROUP BY objectives.id
+ORDER BY objectives.status = 'achieved' ASC, objectives.deadline ASC;
+
+-- name: UpdateObjectiveStatus :exec
+UPDATE objectives
+SET status = ?, updated_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: DeleteObjective :execrows
+DELETE FROM objectives
+WHERE id = ?;
+
+-- name: UpdateGoalObjective :exec
+UPDATE goals
+SET objective_id = ?
+WHERE id = ?;
+
+-- name: GetGoalsByObjective :many
+SELECT * FROM goals
+WHERE objective_id = ?
+ORDER BY created_at ASC;
+
+-- name: SetCalendarToken :exec
+-- Replaces the guild's token, the previous link stops working
+INSERT INTO calendar_tokens (guild_id, token_hash, created_by)
+VALUES (?, ?, ?)
+ON CONFLICT (guild_id) DO UPDATE
+SET token_hash = excluded.token_hash, created_by = excluded.created_by, created_at = CURRENT_TIMESTAMP;
+
+-- name: GetCalendarTokenHash :one
+SELECT token_hash FROM calendar_tokens
+WHERE guild_id = ?;
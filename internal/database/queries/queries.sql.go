@@ -7,11 +7,46 @@ package queries
 
 import (
 	"context"
+	"database/sql"
 )
 
+const addGoalTotal = `-- name: AddGoalTotal :one
+UPDATE goals
+SET total = total + ?1
+WHERE id = ?2
+RETURNING id, discord_user, description, checkpoint_id, status, created_at, progress, target, unit, total, verification, verified_by, objective_id, visibility
+`
+
+type AddGoalTotalParams struct {
+	Amount float64 `json:"amount"`
+	ID     int64   `json:"id"`
+}
+
+func (q *Queries) AddGoalTotal(ctx context.Context, arg AddGoalTotalParams) (Goal, error) {
+	row := q.db.QueryRowContext(ctx, addGoalTotal, arg.Amount, arg.ID)
+	var i Goal
+	err := row.Scan(
+		&i.ID,
+		&i.DiscordUser,
+		&i.Description,
+		&i.CheckpointID,
+		&i.Status,
+		&i.CreatedAt,
+		&i.Progress,
+		&i.Target,
+		&i.Unit,
+		&i.Total,
+		&i.Verification,
+		&i.VerifiedBy,
+		&i.ObjectiveID,
+		&i.Visibility,
+	)
+	return i, err
+}
+
 const completeGoal = `-- name: CompleteGoal :exec
 UPDATE goals
-SET status = 'completed'
+SET status = 'completed', progress = 100
 WHERE discord_user = ? AND checkpoint_id = ?
 `
 
@@ -25,6 +60,129 @@ func (q *Queries) CompleteGoal(ctx context.Context, arg CompleteGoalParams) erro
 	return err
 }
 
+const countCheckpointsByChannelBefore = `-- name: CountCheckpointsByChannelBefore :one
+SELECT COUNT(*) FROM checkpoints
+WHERE channel_id = ? AND datetime(scheduled_at) < datetime(CAST(?2 AS TEXT))
+`
+
+type CountCheckpointsByChannelBeforeParams struct {
+	ChannelID string `json:"channel_id"`
+	Before    string `json:"before"`
+}
+
+func (q *Queries) CountCheckpointsByChannelBefore(ctx context.Context, arg CountCheckpointsByChannelBeforeParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countCheckpointsByChannelBefore, arg.ChannelID, arg.Before)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createApiToken = `-- name: CreateApiToken :one
+INSERT INTO api_tokens (guild_id, name, token_hash, created_by, scope, discord_user)
+VALUES (?, ?, ?, ?, ?, ?) RETURNING id, guild_id, name, token_hash, created_by, created_at, last_used_at, scope, discord_user
+`
+
+type CreateApiTokenParams struct {
+	GuildID     string `json:"guild_id"`
+	Name        string `json:"name"`
+	TokenHash   string `json:"token_hash"`
+	CreatedBy   string `json:"created_by"`
+	Scope       string `json:"scope"`
+	DiscordUser string `json:"discord_user"`
+}
+
+func (q *Queries) CreateApiToken(ctx context.Context, arg CreateApiTokenParams) (ApiToken, error) {
+	row := q.db.QueryRowContext(ctx, createApiToken,
+		arg.GuildID,
+		arg.Name,
+		arg.TokenHash,
+		arg.CreatedBy,
+		arg.Scope,
+		arg.DiscordUser,
+	)
+	var i ApiToken
+	err := row.Scan(
+		&i.ID,
+		&i.GuildID,
+		&i.Name,
+		&i.TokenHash,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.LastUsedAt,
+		&i.Scope,
+		&i.DiscordUser,
+	)
+	return i, err
+}
+
+const createAttendance = `-- name: CreateAttendance :one
+INSERT OR IGNORE INTO attendance (discord_user, checkpoint_id)
+VALUES (?, ?) RETURNING id, discord_user, checkpoint_id, created_at
+`
+
+type CreateAttendanceParams struct {
+	DiscordUser  string `json:"discord_user"`
+	CheckpointID int64  `json:"checkpoint_id"`
+}
+
+func (q *Queries) CreateAttendance(ctx context.Context, arg CreateAttendanceParams) (Attendance, error) {
+	row := q.db.QueryRowContext(ctx, createAttendance, arg.DiscordUser, arg.CheckpointID)
+	var i Attendance
+	err := row.Scan(
+		&i.ID,
+		&i.DiscordUser,
+		&i.CheckpointID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createAuditEntry = `-- name: CreateAuditEntry :one
+INSERT INTO audit_log (guild_id, actor_id, source, action, target_type, target_id, target_user, before_value, after_value)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING id, guild_id, actor_id, source, "action", target_type, target_id, target_user, before_value, after_value, created_at
+`
+
+type CreateAuditEntryParams struct {
+	GuildID     string `json:"guild_id"`
+	ActorID     string `json:"actor_id"`
+	Source      string `json:"source"`
+	Action      string `json:"action"`
+	TargetType  string `json:"target_type"`
+	TargetID    string `json:"target_id"`
+	TargetUser  string `json:"target_user"`
+	BeforeValue string `json:"before_value"`
+	AfterValue  string `json:"after_value"`
+}
+
+func (q *Queries) CreateAuditEntry(ctx context.Context, arg CreateAuditEntryParams) (AuditLog, error) {
+	row := q.db.QueryRowContext(ctx, createAuditEntry,
+		arg.GuildID,
+		arg.ActorID,
+		arg.Source,
+		arg.Action,
+		arg.TargetType,
+		arg.TargetID,
+		arg.TargetUser,
+		arg.BeforeValue,
+		arg.AfterValue,
+	)
+	var i AuditLog
+	err := row.Scan(
+		&i.ID,
+		&i.GuildID,
+		&i.ActorID,
+		&i.Source,
+		&i.Action,
+		&i.TargetType,
+		&i.TargetID,
+		&i.TargetUser,
+		&i.BeforeValue,
+		&i.AfterValue,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
 const createCheckpoint = `-- name: CreateCheckpoint :one
 /*
   File Conventions:
@@ -32,7 +190,7 @@ const createCheckpoint = `-- name: CreateCheckpoint :one
 */
 
 INSERT INTO checkpoints (scheduled_at, channel_id, guild_id, discord_user)
-VALUES (?, ?, ?, ?) RETURNING id, scheduled_at, channel_id, guild_id, discord_user, created_at
+VALUES (?, ?, ?, ?) RETURNING id, scheduled_at, channel_id, guild_id, discord_user, created_at, sequence, updated_at
 `
 
 type CreateCheckpointParams struct {
@@ -57,23 +215,32 @@ func (q *Queries) CreateCheckpoint(ctx context.Context, arg CreateCheckpointPara
 		&i.GuildID,
 		&i.DiscordUser,
 		&i.CreatedAt,
+		&i.Sequence,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
 
 const createGoal = `-- name: CreateGoal :one
-INSERT INTO goals (discord_user, description, checkpoint_id)
-VALUES (?, ?, ?) RETURNING id, discord_user, description, checkpoint_id, status, created_at
+INSERT INTO goals (discord_user, description, checkpoint_id, visibility)
+VALUES (?, ?, ?, COALESCE(NULLIF(CAST(?4 AS TEXT), ''), 'public')) RETURNING id, discord_user, description, checkpoint_id, status, created_at, progress, target, unit, total, verification, verified_by, objective_id, visibility
 `
 
 type CreateGoalParams struct {
 	DiscordUser  string `json:"discord_user"`
 	Description  string `json:"description"`
 	CheckpointID int64  `json:"checkpoint_id"`
+	Visibility   string `json:"visibility"`
 }
 
+// An empty visibility is public
 func (q *Queries) CreateGoal(ctx context.Context, arg CreateGoalParams) (Goal, error) {
-	row := q.db.QueryRowContext(ctx, createGoal, arg.DiscordUser, arg.Description, arg.CheckpointID)
+	row := q.db.QueryRowContext(ctx, createGoal,
+		arg.DiscordUser,
+		arg.Description,
+		arg.CheckpointID,
+		arg.Visibility,
+	)
 	var i Goal
 	err := row.Scan(
 		&i.ID,
@@ -82,140 +249,190 @@ func (q *Queries) CreateGoal(ctx context.Context, arg CreateGoalParams) (Goal, e
 		&i.CheckpointID,
 		&i.Status,
 		&i.CreatedAt,
+		&i.Progress,
+		&i.Target,
+		&i.Unit,
+		&i.Total,
+		&i.Verification,
+		&i.VerifiedBy,
+		&i.ObjectiveID,
+		&i.Visibility,
 	)
 	return i, err
 }
 
-const createGuild = `-- name: CreateGuild :one
-INSERT INTO guilds (guild_id, timezone, owner_id)
-VALUES (?, ?, ?) RETURNING guild_id, timezone, owner_id, created_at
+const createGoalCheckin = `-- name: CreateGoalCheckin :one
+INSERT INTO goal_checkins (goal_id, amount, note, logged_by)
+VALUES (?, ?, ?, ?) RETURNING id, goal_id, amount, note, logged_by, created_at
 `
 
-type CreateGuildParams struct {
-	GuildID  string `json:"guild_id"`
-	Timezone string `json:"timezone"`
-	OwnerID  string `json:"owner_id"`
+type CreateGoalCheckinParams struct {
+	GoalID   int64   `json:"goal_id"`
+	Amount   float64 `json:"amount"`
+	Note     string  `json:"note"`
+	LoggedBy string  `json:"logged_by"`
 }
 
-func (q *Queries) CreateGuild(ctx context.Context, arg CreateGuildParams) (Guild, error) {
-	row := q.db.QueryRowContext(ctx, createGuild, arg.GuildID, arg.Timezone, arg.OwnerID)
-	var i Guild
+func (q *Queries) CreateGoalCheckin(ctx context.Context, arg CreateGoalCheckinParams) (GoalCheckin, error) {
+	row := q.db.QueryRowContext(ctx, createGoalCheckin,
+		arg.GoalID,
+		arg.Amount,
+		arg.Note,
+		arg.LoggedBy,
+	)
+	var i GoalCheckin
 	err := row.Scan(
-		&i.GuildID,
-		&i.Timezone,
-		&i.OwnerID,
+		&i.ID,
+		&i.GoalID,
+		&i.Amount,
+		&i.Note,
+		&i.LoggedBy,
 		&i.CreatedAt,
 	)
 	return i, err
 }
 
-const failedGoal = `-- name: FailedGoal :exec
-UPDATE goals
-SET status = 'failed'
-WHERE discord_user = ? AND checkpoint_id = ?
+const createGoalEvidence = `-- name: CreateGoalEvidence :one
+INSERT INTO goal_evidence (goal_id, kind, url, filename, added_by)
+VALUES (?, ?, ?, ?, ?)
+RETURNING id, goal_id, kind, url, filename, added_by, created_at
 `
 
-type FailedGoalParams struct {
-	DiscordUser  string `json:"discord_user"`
-	CheckpointID int64  `json:"checkpoint_id"`
+type CreateGoalEvidenceParams struct {
+	GoalID   int64  `json:"goal_id"`
+	Kind     string `json:"kind"`
+	Url      string `json:"url"`
+	Filename string `json:"filename"`
+	AddedBy  string `json:"added_by"`
 }
 
-func (q *Queries) FailedGoal(ctx context.Context, arg FailedGoalParams) error {
-	_, err := q.db.ExecContext(ctx, failedGoal, arg.DiscordUser, arg.CheckpointID)
-	return err
+func (q *Queries) CreateGoalEvidence(ctx context.Context, arg CreateGoalEvidenceParams) (GoalEvidence, error) {
+	row := q.db.QueryRowContext(ctx, createGoalEvidence,
+		arg.GoalID,
+		arg.Kind,
+		arg.Url,
+		arg.Filename,
+		arg.AddedBy,
+	)
+	var i GoalEvidence
+	err := row.Scan(
+		&i.ID,
+		&i.GoalID,
+		&i.Kind,
+		&i.Url,
+		&i.Filename,
+		&i.AddedBy,
+		&i.CreatedAt,
+	)
+	return i, err
 }
 
-const getCheckpointByScheduledAtAndChannel = `-- name: GetCheckpointByScheduledAtAndChannel :one
-SELECT id, scheduled_at, channel_id, guild_id, discord_user, created_at FROM checkpoints
-WHERE scheduled_at = ? AND channel_id = ?
+const createGoalRevision = `-- name: CreateGoalRevision :one
+INSERT INTO goal_revisions (goal_id, description, status, progress, edited_by)
+VALUES (?, ?, ?, ?, ?) RETURNING id, goal_id, description, status, edited_by, created_at, progress
 `
 
-type GetCheckpointByScheduledAtAndChannelParams struct {
-	ScheduledAt string `json:"scheduled_at"`
-	ChannelID   string `json:"channel_id"`
+type CreateGoalRevisionParams struct {
+	GoalID      int64  `json:"goal_id"`
+	Description string `json:"description"`
+	Status      string `json:"status"`
+	Progress    int64  `json:"progress"`
+	EditedBy    string `json:"edited_by"`
 }
 
-func (q *Queries) GetCheckpointByScheduledAtAndChannel(ctx context.Context, arg GetCheckpointByScheduledAtAndChannelParams) (Checkpoint, error) {
-	row := q.db.QueryRowContext(ctx, getCheckpointByScheduledAtAndChannel, arg.ScheduledAt, arg.ChannelID)
-	var i Checkpoint
+func (q *Queries) CreateGoalRevision(ctx context.Context, arg CreateGoalRevisionParams) (GoalRevision, error) {
+	row := q.db.QueryRowContext(ctx, createGoalRevision,
+		arg.GoalID,
+		arg.Description,
+		arg.Status,
+		arg.Progress,
+		arg.EditedBy,
+	)
+	var i GoalRevision
 	err := row.Scan(
 		&i.ID,
-		&i.ScheduledAt,
-		&i.ChannelID,
-		&i.GuildID,
-		&i.DiscordUser,
+		&i.GoalID,
+		&i.Description,
+		&i.Status,
+		&i.EditedBy,
 		&i.CreatedAt,
+		&i.Progress,
 	)
 	return i, err
 }
 
-const getGoalByCheckpointAndUser = `-- name: GetGoalByCheckpointAndUser :one
-SELECT id, discord_user, description, checkpoint_id, status, created_at FROM goals
-WHERE checkpoint_id = ? AND discord_user = ?
+const createGoalTag = `-- name: CreateGoalTag :exec
+INSERT INTO goal_tags (goal_id, tag)
+VALUES (?, ?)
+ON CONFLICT (goal_id, tag) DO NOTHING
 `
 
-type GetGoalByCheckpointAndUserParams struct {
-	CheckpointID int64  `json:"checkpoint_id"`
-	DiscordUser  string `json:"discord_user"`
+type CreateGoalTagParams struct {
+	GoalID int64  `json:"goal_id"`
+	Tag    string `json:"tag"`
 }
 
-func (q *Queries) GetGoalByCheckpointAndUser(ctx context.Context, arg GetGoalByCheckpointAndUserParams) (Goal, error) {
-	row := q.db.QueryRowContext(ctx, getGoalByCheckpointAndUser, arg.CheckpointID, arg.DiscordUser)
-	var i Goal
+func (q *Queries) CreateGoalTag(ctx context.Context, arg CreateGoalTagParams) error {
+	_, err := q.db.ExecContext(ctx, createGoalTag, arg.GoalID, arg.Tag)
+	return err
+}
+
+const createGoalUpdate = `-- name: CreateGoalUpdate :one
+INSERT INTO goal_updates (goal_id, discord_user, content)
+VALUES (?, ?, ?) RETURNING id, goal_id, discord_user, content, created_at
+`
+
+type CreateGoalUpdateParams struct {
+	GoalID      int64  `json:"goal_id"`
+	DiscordUser string `json:"discord_user"`
+	Content     string `json:"content"`
+}
+
+func (q *Queries) CreateGoalUpdate(ctx context.Context, arg CreateGoalUpdateParams) (GoalUpdate, error) {
+	row := q.db.QueryRowContext(ctx, createGoalUpdate, arg.GoalID, arg.DiscordUser, arg.Content)
+	var i GoalUpdate
 	err := row.Scan(
 		&i.ID,
+		&i.GoalID,
 		&i.DiscordUser,
-		&i.Description,
-		&i.CheckpointID,
-		&i.Status,
+		&i.Content,
 		&i.CreatedAt,
 	)
 	return i, err
 }
 
-const getGoalsByCheckpoint = `-- name: GetGoalsByCheckpoint :many
-SELECT id, discord_user, description, checkpoint_id, status, created_at FROM goals
-WHERE checkpoint_id = ?
-ORDER BY created_at ASC
+const createGoalVerification = `-- name: CreateGoalVerification :execrows
+INSERT INTO goal_verifications (goal_id, discord_user)
+VALUES (?, ?)
+ON CONFLICT (goal_id, discord_user) DO NOTHING
 `
 
-func (q *Queries) GetGoalsByCheckpoint(ctx context.Context, checkpointID int64) ([]Goal, error) {
-	rows, err := q.db.QueryContext(ctx, getGoalsByCheckpoint, checkpointID)
+type CreateGoalVerificationParams struct {
+	GoalID      int64  `json:"goal_id"`
+	DiscordUser string `json:"discord_user"`
+}
+
+func (q *Queries) CreateGoalVerification(ctx context.Context, arg CreateGoalVerificationParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, createGoalVerification, arg.GoalID, arg.DiscordUser)
 	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []Goal
-	for rows.Next() {
-		var i Goal
-		if err := rows.Scan(
-			&i.ID,
-			&i.DiscordUser,
-			&i.Description,
-			&i.CheckpointID,
-			&i.Status,
-			&i.CreatedAt,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
+		return 0, err
 	}
-	return items, nil
+	return result.RowsAffected()
 }
 
-const getGuild = `-- name: GetGuild :one
-SELECT guild_id, timezone, owner_id, created_at FROM guilds
-WHERE guild_id = ?
+const createGuild = `-- name: CreateGuild :one
+INSERT INTO guilds (guild_id, timezone, owner_id)
+VALUES (?, ?, ?) RETURNING guild_id, timezone, owner_id, created_at
 `
 
-func (q *Queries) GetGuild(ctx context.Context, guildID string) (Guild, error) {
-	row := q.db.QueryRowContext(ctx, getGuild, guildID)
+type CreateGuildParams struct {
+	GuildID  string `json:"guild_id"`
+	Timezone string `json:"timezone"`
+	OwnerID  string `json:"owner_id"`
+}
+
+func (q *Queries) CreateGuild(ctx context.Context, arg CreateGuildParams) (Guild, error) {
+	row := q.db.QueryRowContext(ctx, createGuild, arg.GuildID, arg.Timezone, arg.OwnerID)
 	var i Guild
 	err := row.Scan(
 		&i.GuildID,
@@ -226,75 +443,1697 @@ func (q *Queries) GetGuild(ctx context.Context, guildID string) (Guild, error) {
 	return i, err
 }
 
-const getPastCheckpointsByChannel = `-- name: GetPastCheckpointsByChannel :many
-SELECT id, scheduled_at, channel_id, guild_id, discord_user, created_at FROM checkpoints
-WHERE channel_id = ? AND datetime(scheduled_at) < datetime('now')
-ORDER BY datetime(scheduled_at) DESC
+const createObjective = `-- name: CreateObjective :one
+INSERT INTO objectives (guild_id, discord_user, title, deadline)
+VALUES (?, ?, ?, ?)
+RETURNING id, guild_id, discord_user, title, deadline, status, created_at, updated_at
 `
 
-func (q *Queries) GetPastCheckpointsByChannel(ctx context.Context, channelID string) ([]Checkpoint, error) {
-	rows, err := q.db.QueryContext(ctx, getPastCheckpointsByChannel, channelID)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []Checkpoint
-	for rows.Next() {
-		var i Checkpoint
-		if err := rows.Scan(
-			&i.ID,
-			&i.ScheduledAt,
-			&i.ChannelID,
-			&i.GuildID,
-			&i.DiscordUser,
-			&i.CreatedAt,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
+type CreateObjectiveParams struct {
+	GuildID     string `json:"guild_id"`
+	DiscordUser string `json:"discord_user"`
+	Title       string `json:"title"`
+	Deadline    string `json:"deadline"`
 }
 
-const getUpcomingCheckpointByGuildAndChannel = `-- name: GetUpcomingCheckpointByGuildAndChannel :one
-SELECT id, scheduled_at, channel_id, guild_id, discord_user, created_at FROM checkpoints
-WHERE guild_id = ? AND channel_id = ? AND datetime(scheduled_at) >= datetime('now')
-ORDER BY datetime(scheduled_at) ASC
-LIMIT 1
+func (q *Queries) CreateObjective(ctx context.Context, arg CreateObjectiveParams) (Objective, error) {
+	row := q.db.QueryRowContext(ctx, createObjective,
+		arg.GuildID,
+		arg.DiscordUser,
+		arg.Title,
+		arg.Deadline,
+	)
+	var i Objective
+	err := row.Scan(
+		&i.ID,
+		&i.GuildID,
+		&i.DiscordUser,
+		&i.Title,
+		&i.Deadline,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createRsvp = `-- name: CreateRsvp :one
+INSERT OR IGNORE INTO checkpoint_rsvp (checkpoint_id, discord_user)
+VALUES (?, ?) RETURNING id, checkpoint_id, discord_user, created_at
 `
 
-type GetUpcomingCheckpointByGuildAndChannelParams struct {
-	GuildID   string `json:"guild_id"`
-	ChannelID string `json:"channel_id"`
+type CreateRsvpParams struct {
+	CheckpointID int64  `json:"checkpoint_id"`
+	DiscordUser  string `json:"discord_user"`
 }
 
-func (q *Queries) GetUpcomingCheckpointByGuildAndChannel(ctx context.Context, arg GetUpcomingCheckpointByGuildAndChannelParams) (Checkpoint, error) {
-	row := q.db.QueryRowContext(ctx, getUpcomingCheckpointByGuildAndChannel, arg.GuildID, arg.ChannelID)
-	var i Checkpoint
+func (q *Queries) CreateRsvp(ctx context.Context, arg CreateRsvpParams) (CheckpointRsvp, error) {
+	row := q.db.QueryRowContext(ctx, createRsvp, arg.CheckpointID, arg.DiscordUser)
+	var i CheckpointRsvp
 	err := row.Scan(
 		&i.ID,
-		&i.ScheduledAt,
-		&i.ChannelID,
-		&i.GuildID,
+		&i.CheckpointID,
 		&i.DiscordUser,
 		&i.CreatedAt,
 	)
 	return i, err
 }
 
-const getUpcomingCheckpoints = `-- name: GetUpcomingCheckpoints :many
-SELECT id, scheduled_at, channel_id, guild_id, discord_user, created_at FROM checkpoints
-WHERE datetime(scheduled_at) >= datetime('now')
-ORDER BY datetime(scheduled_at) ASC
+const createWebhook = `-- name: CreateWebhook :one
+INSERT INTO webhooks (guild_id, url, secret, event_types, created_by)
+VALUES (?, ?, ?, ?, ?) RETURNING id, guild_id, url, secret, event_types, created_by, created_at
 `
 
-func (q *Queries) GetUpcomingCheckpoints(ctx context.Context) ([]Checkpoint, error) {
+type CreateWebhookParams struct {
+	GuildID    string `json:"guild_id"`
+	Url        string `json:"url"`
+	Secret     string `json:"secret"`
+	EventTypes string `json:"event_types"`
+	CreatedBy  string `json:"created_by"`
+}
+
+func (q *Queries) CreateWebhook(ctx context.Context, arg CreateWebhookParams) (Webhook, error) {
+	row := q.db.QueryRowContext(ctx, createWebhook,
+		arg.GuildID,
+		arg.Url,
+		arg.Secret,
+		arg.EventTypes,
+		arg.CreatedBy,
+	)
+	var i Webhook
+	err := row.Scan(
+		&i.ID,
+		&i.GuildID,
+		&i.Url,
+		&i.Secret,
+		&i.EventTypes,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createWebhookDelivery = `-- name: CreateWebhookDelivery :one
+INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, payload, next_attempt_at)
+VALUES (?, ?, ?, ?, ?) RETURNING id, webhook_id, event_id, event_type, payload, status, attempts, response_status, last_error, next_attempt_at, created_at, delivered_at
+`
+
+type CreateWebhookDeliveryParams struct {
+	WebhookID     int64  `json:"webhook_id"`
+	EventID       string `json:"event_id"`
+	EventType     string `json:"event_type"`
+	Payload       string `json:"payload"`
+	NextAttemptAt string `json:"next_attempt_at"`
+}
+
+func (q *Queries) CreateWebhookDelivery(ctx context.Context, arg CreateWebhookDeliveryParams) (WebhookDelivery, error) {
+	row := q.db.QueryRowContext(ctx, createWebhookDelivery,
+		arg.WebhookID,
+		arg.EventID,
+		arg.EventType,
+		arg.Payload,
+		arg.NextAttemptAt,
+	)
+	var i WebhookDelivery
+	err := row.Scan(
+		&i.ID,
+		&i.WebhookID,
+		&i.EventID,
+		&i.EventType,
+		&i.Payload,
+		&i.Status,
+		&i.Attempts,
+		&i.ResponseStatus,
+		&i.LastError,
+		&i.NextAttemptAt,
+		&i.CreatedAt,
+		&i.DeliveredAt,
+	)
+	return i, err
+}
+
+const deleteApiToken = `-- name: DeleteApiToken :execrows
+DELETE FROM api_tokens
+WHERE id = ? AND guild_id = ?
+`
+
+type DeleteApiTokenParams struct {
+	ID      int64  `json:"id"`
+	GuildID string `json:"guild_id"`
+}
+
+func (q *Queries) DeleteApiToken(ctx context.Context, arg DeleteApiTokenParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteApiToken, arg.ID, arg.GuildID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteCheckpoint = `-- name: DeleteCheckpoint :one
+DELETE FROM checkpoints
+WHERE id = ?
+RETURNING id, scheduled_at, channel_id, guild_id, discord_user, created_at, sequence, updated_at
+`
+
+func (q *Queries) DeleteCheckpoint(ctx context.Context, id int64) (Checkpoint, error) {
+	row := q.db.QueryRowContext(ctx, deleteCheckpoint, id)
+	var i Checkpoint
+	err := row.Scan(
+		&i.ID,
+		&i.ScheduledAt,
+		&i.ChannelID,
+		&i.GuildID,
+		&i.DiscordUser,
+		&i.CreatedAt,
+		&i.Sequence,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const deleteGoalTags = `-- name: DeleteGoalTags :exec
+DELETE FROM goal_tags
+WHERE goal_id = ?
+`
+
+func (q *Queries) DeleteGoalTags(ctx context.Context, goalID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteGoalTags, goalID)
+	return err
+}
+
+const deleteGoalTemplate = `-- name: DeleteGoalTemplate :execrows
+DELETE FROM goal_templates
+WHERE id = ?
+`
+
+func (q *Queries) DeleteGoalTemplate(ctx context.Context, id int64) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteGoalTemplate, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteGoalVerifications = `-- name: DeleteGoalVerifications :exec
+DELETE FROM goal_verifications
+WHERE goal_id = ?
+`
+
+func (q *Queries) DeleteGoalVerifications(ctx context.Context, goalID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteGoalVerifications, goalID)
+	return err
+}
+
+const deleteGuildSetting = `-- name: DeleteGuildSetting :execrows
+DELETE FROM guild_settings
+WHERE guild_id = ? AND key = ?
+`
+
+type DeleteGuildSettingParams struct {
+	GuildID string `json:"guild_id"`
+	Key     string `json:"key"`
+}
+
+func (q *Queries) DeleteGuildSetting(ctx context.Context, arg DeleteGuildSettingParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteGuildSetting, arg.GuildID, arg.Key)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteObjective = `-- name: DeleteObjective :execrows
+DELETE FROM objectives
+WHERE id = ?
+`
+
+func (q *Queries) DeleteObjective(ctx context.Context, id int64) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteObjective, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deletePartner = `-- name: DeletePartner :execrows
+DELETE FROM partners
+WHERE guild_id = ? AND discord_user = ?
+`
+
+type DeletePartnerParams struct {
+	GuildID     string `json:"guild_id"`
+	DiscordUser string `json:"discord_user"`
+}
+
+func (q *Queries) DeletePartner(ctx context.Context, arg DeletePartnerParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deletePartner, arg.GuildID, arg.DiscordUser)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deletePartnerPairs = `-- name: DeletePartnerPairs :exec
+DELETE FROM partners
+WHERE guild_id = ?1
+  AND (discord_user IN (?2, ?3) OR partner_user IN (?2, ?3))
+`
+
+type DeletePartnerPairsParams struct {
+	GuildID string `json:"guild_id"`
+	UserA   string `json:"user_a"`
+	UserB   string `json:"user_b"`
+}
+
+func (q *Queries) DeletePartnerPairs(ctx context.Context, arg DeletePartnerPairsParams) error {
+	_, err := q.db.ExecContext(ctx, deletePartnerPairs, arg.GuildID, arg.UserA, arg.UserB)
+	return err
+}
+
+const deletePendingGoal = `-- name: DeletePendingGoal :exec
+DELETE FROM pending_goals
+WHERE id = ?
+`
+
+func (q *Queries) DeletePendingGoal(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, deletePendingGoal, id)
+	return err
+}
+
+const deleteUpdatePrompt = `-- name: DeleteUpdatePrompt :execrows
+DELETE FROM update_prompts
+WHERE guild_id = ? AND discord_user = ?
+`
+
+type DeleteUpdatePromptParams struct {
+	GuildID     string `json:"guild_id"`
+	DiscordUser string `json:"discord_user"`
+}
+
+func (q *Queries) DeleteUpdatePrompt(ctx context.Context, arg DeleteUpdatePromptParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteUpdatePrompt, arg.GuildID, arg.DiscordUser)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteWebhook = `-- name: DeleteWebhook :execrows
+DELETE FROM webhooks
+WHERE id = ? AND guild_id = ?
+`
+
+type DeleteWebhookParams struct {
+	ID      int64  `json:"id"`
+	GuildID string `json:"guild_id"`
+}
+
+func (q *Queries) DeleteWebhook(ctx context.Context, arg DeleteWebhookParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteWebhook, arg.ID, arg.GuildID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const failedGoal = `-- name: FailedGoal :exec
+UPDATE goals
+SET status = 'failed'
+WHERE discord_user = ? AND checkpoint_id = ?
+`
+
+type FailedGoalParams struct {
+	DiscordUser  string `json:"discord_user"`
+	CheckpointID int64  `json:"checkpoint_id"`
+}
+
+func (q *Queries) FailedGoal(ctx context.Context, arg FailedGoalParams) error {
+	_, err := q.db.ExecContext(ctx, failedGoal, arg.DiscordUser, arg.CheckpointID)
+	return err
+}
+
+const getApiTokenByHash = `-- name: GetApiTokenByHash :one
+SELECT id, guild_id, name, token_hash, created_by, created_at, last_used_at, scope, discord_user FROM api_tokens
+WHERE token_hash = ?
+`
+
+func (q *Queries) GetApiTokenByHash(ctx context.Context, tokenHash string) (ApiToken, error) {
+	row := q.db.QueryRowContext(ctx, getApiTokenByHash, tokenHash)
+	var i ApiToken
+	err := row.Scan(
+		&i.ID,
+		&i.GuildID,
+		&i.Name,
+		&i.TokenHash,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.LastUsedAt,
+		&i.Scope,
+		&i.DiscordUser,
+	)
+	return i, err
+}
+
+const getAttendanceByCheckpoint = `-- name: GetAttendanceByCheckpoint :many
+SELECT id, discord_user, checkpoint_id, created_at FROM attendance
+WHERE checkpoint_id = ?
+ORDER BY created_at ASC
+`
+
+func (q *Queries) GetAttendanceByCheckpoint(ctx context.Context, checkpointID int64) ([]Attendance, error) {
+	rows, err := q.db.QueryContext(ctx, getAttendanceByCheckpoint, checkpointID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Attendance
+	for rows.Next() {
+		var i Attendance
+		if err := rows.Scan(
+			&i.ID,
+			&i.DiscordUser,
+			&i.CheckpointID,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getCalendarTokenHash = `-- name: GetCalendarTokenHash :one
+SELECT token_hash FROM calendar_tokens
+WHERE guild_id = ?
+`
+
+func (q *Queries) GetCalendarTokenHash(ctx context.Context, guildID string) (string, error) {
+	row := q.db.QueryRowContext(ctx, getCalendarTokenHash, guildID)
+	var token_hash string
+	err := row.Scan(&token_hash)
+	return token_hash, err
+}
+
+const getCheckpoint = `-- name: GetCheckpoint :one
+SELECT id, scheduled_at, channel_id, guild_id, discord_user, created_at, sequence, updated_at FROM checkpoints
+WHERE id = ?
+`
+
+func (q *Queries) GetCheckpoint(ctx context.Context, id int64) (Checkpoint, error) {
+	row := q.db.QueryRowContext(ctx, getCheckpoint, id)
+	var i Checkpoint
+	err := row.Scan(
+		&i.ID,
+		&i.ScheduledAt,
+		&i.ChannelID,
+		&i.GuildID,
+		&i.DiscordUser,
+		&i.CreatedAt,
+		&i.Sequence,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getCheckpointByScheduledAtAndChannel = `-- name: GetCheckpointByScheduledAtAndChannel :one
+SELECT id, scheduled_at, channel_id, guild_id, discord_user, created_at, sequence, updated_at FROM checkpoints
+WHERE scheduled_at = ? AND channel_id = ?
+`
+
+type GetCheckpointByScheduledAtAndChannelParams struct {
+	ScheduledAt string `json:"scheduled_at"`
+	ChannelID   string `json:"channel_id"`
+}
+
+func (q *Queries) GetCheckpointByScheduledAtAndChannel(ctx context.Context, arg GetCheckpointByScheduledAtAndChannelParams) (Checkpoint, error) {
+	row := q.db.QueryRowContext(ctx, getCheckpointByScheduledAtAndChannel, arg.ScheduledAt, arg.ChannelID)
+	var i Checkpoint
+	err := row.Scan(
+		&i.ID,
+		&i.ScheduledAt,
+		&i.ChannelID,
+		&i.GuildID,
+		&i.DiscordUser,
+		&i.CreatedAt,
+		&i.Sequence,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getCheckpointPartner = `-- name: GetCheckpointPartner :one
+SELECT checkpoint_id, discord_user, partner_user FROM checkpoint_partners
+WHERE checkpoint_id = ? AND discord_user = ?
+`
+
+type GetCheckpointPartnerParams struct {
+	CheckpointID int64  `json:"checkpoint_id"`
+	DiscordUser  string `json:"discord_user"`
+}
+
+func (q *Queries) GetCheckpointPartner(ctx context.Context, arg GetCheckpointPartnerParams) (CheckpointPartner, error) {
+	row := q.db.QueryRowContext(ctx, getCheckpointPartner, arg.CheckpointID, arg.DiscordUser)
+	var i CheckpointPartner
+	err := row.Scan(&i.CheckpointID, &i.DiscordUser, &i.PartnerUser)
+	return i, err
+}
+
+const getCheckpointsScheduledBetween = `-- name: GetCheckpointsScheduledBetween :many
+SELECT id, scheduled_at, channel_id, guild_id, discord_user, created_at, sequence, updated_at FROM checkpoints
+WHERE datetime(scheduled_at) > datetime(CAST(?1 AS TEXT))
+  AND datetime(scheduled_at) <= datetime(CAST(?2 AS TEXT))
+ORDER BY datetime(scheduled_at) ASC
+`
+
+type GetCheckpointsScheduledBetweenParams struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+func (q *Queries) GetCheckpointsScheduledBetween(ctx context.Context, arg GetCheckpointsScheduledBetweenParams) ([]Checkpoint, error) {
+	rows, err := q.db.QueryContext(ctx, getCheckpointsScheduledBetween, arg.From, arg.To)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Checkpoint
+	for rows.Next() {
+		var i Checkpoint
+		if err := rows.Scan(
+			&i.ID,
+			&i.ScheduledAt,
+			&i.ChannelID,
+			&i.GuildID,
+			&i.DiscordUser,
+			&i.CreatedAt,
+			&i.Sequence,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDueUpdatePrompts = `-- name: GetDueUpdatePrompts :many
+SELECT guild_id, discord_user, interval_days, next_prompt_at, created_at FROM update_prompts
+WHERE datetime(next_prompt_at) <= datetime(CAST(?1 AS TEXT))
+ORDER BY datetime(next_prompt_at) ASC
+`
+
+func (q *Queries) GetDueUpdatePrompts(ctx context.Context, now string) ([]UpdatePrompt, error) {
+	rows, err := q.db.QueryContext(ctx, getDueUpdatePrompts, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []UpdatePrompt
+	for rows.Next() {
+		var i UpdatePrompt
+		if err := rows.Scan(
+			&i.GuildID,
+			&i.DiscordUser,
+			&i.IntervalDays,
+			&i.NextPromptAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDueWebhookDeliveries = `-- name: GetDueWebhookDeliveries :many
+SELECT webhook_deliveries.id, webhook_deliveries.event_id, webhook_deliveries.event_type, webhook_deliveries.payload,
+    webhook_deliveries.attempts, webhooks.url, webhooks.secret
+FROM webhook_deliveries
+JOIN webhooks ON webhooks.id = webhook_deliveries.webhook_id
+WHERE webhook_deliveries.status = 'pending'
+  AND datetime(webhook_deliveries.next_attempt_at) <= datetime(CAST(?1 AS TEXT))
+ORDER BY datetime(webhook_deliveries.next_attempt_at) ASC
+LIMIT ?2
+`
+
+type GetDueWebhookDeliveriesParams struct {
+	Now           string `json:"now"`
+	MaxDeliveries int64  `json:"max_deliveries"`
+}
+
+type GetDueWebhookDeliveriesRow struct {
+	ID        int64  `json:"id"`
+	EventID   string `json:"event_id"`
+	EventType string `json:"event_type"`
+	Payload   string `json:"payload"`
+	Attempts  int64  `json:"attempts"`
+	Url       string `json:"url"`
+	Secret    string `json:"secret"`
+}
+
+func (q *Queries) GetDueWebhookDeliveries(ctx context.Context, arg GetDueWebhookDeliveriesParams) ([]GetDueWebhookDeliveriesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getDueWebhookDeliveries, arg.Now, arg.MaxDeliveries)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetDueWebhookDeliveriesRow
+	for rows.Next() {
+		var i GetDueWebhookDeliveriesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.EventID,
+			&i.EventType,
+			&i.Payload,
+			&i.Attempts,
+			&i.Url,
+			&i.Secret,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getEditedGoalIDsByCheckpoint = `-- name: GetEditedGoalIDsByCheckpoint :many
+SELECT goal_revisions.goal_id FROM goal_revisions
+JOIN goals ON goals.id = goal_revisions.goal_id
+WHERE goals.checkpoint_id = ?
+GROUP BY goal_revisions.goal_id
+HAVING COUNT(DISTINCT goal_revisions.description) > 1
+`
+
+func (q *Queries) GetEditedGoalIDsByCheckpoint(ctx context.Context, checkpointID int64) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, getEditedGoalIDsByCheckpoint, checkpointID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var goal_id int64
+		if err := rows.Scan(&goal_id); err != nil {
+			return nil, err
+		}
+		items = append(items, goal_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getGoal = `-- name: GetGoal :one
+SELECT id, discord_user, description, checkpoint_id, status, created_at, progress, target, unit, total, verification, verified_by, objective_id, visibility FROM goals
+WHERE id = ?
+`
+
+func (q *Queries) GetGoal(ctx context.Context, id int64) (Goal, error) {
+	row := q.db.QueryRowContext(ctx, getGoal, id)
+	var i Goal
+	err := row.Scan(
+		&i.ID,
+		&i.DiscordUser,
+		&i.Description,
+		&i.CheckpointID,
+		&i.Status,
+		&i.CreatedAt,
+		&i.Progress,
+		&i.Target,
+		&i.Unit,
+		&i.Total,
+		&i.Verification,
+		&i.VerifiedBy,
+		&i.ObjectiveID,
+		&i.Visibility,
+	)
+	return i, err
+}
+
+const getGoalByCheckpointAndUser = `-- name: GetGoalByCheckpointAndUser :one
+SELECT id, discord_user, description, checkpoint_id, status, created_at, progress, target, unit, total, verification, verified_by, objective_id, visibility FROM goals
+WHERE checkpoint_id = ? AND discord_user = ?
+`
+
+type GetGoalByCheckpointAndUserParams struct {
+	CheckpointID int64  `json:"checkpoint_id"`
+	DiscordUser  string `json:"discord_user"`
+}
+
+func (q *Queries) GetGoalByCheckpointAndUser(ctx context.Context, arg GetGoalByCheckpointAndUserParams) (Goal, error) {
+	row := q.db.QueryRowContext(ctx, getGoalByCheckpointAndUser, arg.CheckpointID, arg.DiscordUser)
+	var i Goal
+	err := row.Scan(
+		&i.ID,
+		&i.DiscordUser,
+		&i.Description,
+		&i.CheckpointID,
+		&i.Status,
+		&i.CreatedAt,
+		&i.Progress,
+		&i.Target,
+		&i.Unit,
+		&i.Total,
+		&i.Verification,
+		&i.VerifiedBy,
+		&i.ObjectiveID,
+		&i.Visibility,
+	)
+	return i, err
+}
+
+const getGoalCheckins = `-- name: GetGoalCheckins :many
+SELECT id, goal_id, amount, note, logged_by, created_at FROM goal_checkins
+WHERE goal_id = ?
+ORDER BY id ASC
+`
+
+func (q *Queries) GetGoalCheckins(ctx context.Context, goalID int64) ([]GoalCheckin, error) {
+	rows, err := q.db.QueryContext(ctx, getGoalCheckins, goalID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GoalCheckin
+	for rows.Next() {
+		var i GoalCheckin
+		if err := rows.Scan(
+			&i.ID,
+			&i.GoalID,
+			&i.Amount,
+			&i.Note,
+			&i.LoggedBy,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getGoalEvidence = `-- name: GetGoalEvidence :many
+SELECT id, goal_id, kind, url, filename, added_by, created_at FROM goal_evidence
+WHERE goal_id = ?
+ORDER BY id ASC
+`
+
+func (q *Queries) GetGoalEvidence(ctx context.Context, goalID int64) ([]GoalEvidence, error) {
+	rows, err := q.db.QueryContext(ctx, getGoalEvidence, goalID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GoalEvidence
+	for rows.Next() {
+		var i GoalEvidence
+		if err := rows.Scan(
+			&i.ID,
+			&i.GoalID,
+			&i.Kind,
+			&i.Url,
+			&i.Filename,
+			&i.AddedBy,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getGoalEvidenceByCheckpoint = `-- name: GetGoalEvidenceByCheckpoint :many
+SELECT goal_evidence.id, goal_evidence.goal_id, goal_evidence.kind, goal_evidence.url, goal_evidence.filename, goal_evidence.added_by, goal_evidence.created_at, goals.discord_user FROM goal_evidence
+JOIN goals ON goals.id = goal_evidence.goal_id
+WHERE goals.checkpoint_id = ?
+ORDER BY goal_evidence.id ASC
+`
+
+type GetGoalEvidenceByCheckpointRow struct {
+	ID          int64        `json:"id"`
+	GoalID      int64        `json:"goal_id"`
+	Kind        string       `json:"kind"`
+	Url         string       `json:"url"`
+	Filename    string       `json:"filename"`
+	AddedBy     string       `json:"added_by"`
+	CreatedAt   sql.NullTime `json:"created_at"`
+	DiscordUser string       `json:"discord_user"`
+}
+
+func (q *Queries) GetGoalEvidenceByCheckpoint(ctx context.Context, checkpointID int64) ([]GetGoalEvidenceByCheckpointRow, error) {
+	rows, err := q.db.QueryContext(ctx, getGoalEvidenceByCheckpoint, checkpointID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetGoalEvidenceByCheckpointRow
+	for rows.Next() {
+		var i GetGoalEvidenceByCheckpointRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.GoalID,
+			&i.Kind,
+			&i.Url,
+			&i.Filename,
+			&i.AddedBy,
+			&i.CreatedAt,
+			&i.DiscordUser,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getGoalRevisions = `-- name: GetGoalRevisions :many
+SELECT id, goal_id, description, status, edited_by, created_at, progress FROM goal_revisions
+WHERE goal_id = ?
+ORDER BY id ASC
+`
+
+func (q *Queries) GetGoalRevisions(ctx context.Context, goalID int64) ([]GoalRevision, error) {
+	rows, err := q.db.QueryContext(ctx, getGoalRevisions, goalID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GoalRevision
+	for rows.Next() {
+		var i GoalRevision
+		if err := rows.Scan(
+			&i.ID,
+			&i.GoalID,
+			&i.Description,
+			&i.Status,
+			&i.EditedBy,
+			&i.CreatedAt,
+			&i.Progress,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getGoalTags = `-- name: GetGoalTags :many
+SELECT tag FROM goal_tags
+WHERE goal_id = ?
+ORDER BY tag ASC
+`
+
+func (q *Queries) GetGoalTags(ctx context.Context, goalID int64) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, getGoalTags, goalID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return nil, err
+		}
+		items = append(items, tag)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getGoalTagsByCheckpoint = `-- name: GetGoalTagsByCheckpoint :many
+SELECT goal_tags.goal_id, goal_tags.tag FROM goal_tags
+JOIN goals ON goals.id = goal_tags.goal_id
+WHERE goals.checkpoint_id = ?
+ORDER BY goal_tags.goal_id ASC, goal_tags.tag ASC
+`
+
+func (q *Queries) GetGoalTagsByCheckpoint(ctx context.Context, checkpointID int64) ([]GoalTag, error) {
+	rows, err := q.db.QueryContext(ctx, getGoalTagsByCheckpoint, checkpointID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GoalTag
+	for rows.Next() {
+		var i GoalTag
+		if err := rows.Scan(&i.GoalID, &i.Tag); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getGoalTemplate = `-- name: GetGoalTemplate :one
+SELECT id, guild_id, discord_user, name, description, target, unit, uses, created_by, created_at FROM goal_templates
+WHERE id = ?
+`
+
+func (q *Queries) GetGoalTemplate(ctx context.Context, id int64) (GoalTemplate, error) {
+	row := q.db.QueryRowContext(ctx, getGoalTemplate, id)
+	var i GoalTemplate
+	err := row.Scan(
+		&i.ID,
+		&i.GuildID,
+		&i.DiscordUser,
+		&i.Name,
+		&i.Description,
+		&i.Target,
+		&i.Unit,
+		&i.Uses,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getGoalTemplatesByGuildAndUser = `-- name: GetGoalTemplatesByGuildAndUser :many
+SELECT id, guild_id, discord_user, name, description, target, unit, uses, created_by, created_at FROM goal_templates
+WHERE guild_id = ? AND (discord_user = ?2 OR discord_user = '')
+ORDER BY uses DESC, name ASC
+`
+
+type GetGoalTemplatesByGuildAndUserParams struct {
+	GuildID     string `json:"guild_id"`
+	DiscordUser string `json:"discord_user"`
+}
+
+func (q *Queries) GetGoalTemplatesByGuildAndUser(ctx context.Context, arg GetGoalTemplatesByGuildAndUserParams) ([]GoalTemplate, error) {
+	rows, err := q.db.QueryContext(ctx, getGoalTemplatesByGuildAndUser, arg.GuildID, arg.DiscordUser)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GoalTemplate
+	for rows.Next() {
+		var i GoalTemplate
+		if err := rows.Scan(
+			&i.ID,
+			&i.GuildID,
+			&i.DiscordUser,
+			&i.Name,
+			&i.Description,
+			&i.Target,
+			&i.Unit,
+			&i.Uses,
+			&i.CreatedBy,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getGoalUpdatesByCheckpoint = `-- name: GetGoalUpdatesByCheckpoint :many
+SELECT goal_updates.id, goal_updates.goal_id, goal_updates.discord_user, goal_updates.content, goal_updates.created_at FROM goal_updates
+JOIN goals ON goals.id = goal_updates.goal_id
+WHERE goals.checkpoint_id = ?
+ORDER BY goal_updates.id ASC
+`
+
+func (q *Queries) GetGoalUpdatesByCheckpoint(ctx context.Context, checkpointID int64) ([]GoalUpdate, error) {
+	rows, err := q.db.QueryContext(ctx, getGoalUpdatesByCheckpoint, checkpointID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GoalUpdate
+	for rows.Next() {
+		var i GoalUpdate
+		if err := rows.Scan(
+			&i.ID,
+			&i.GoalID,
+			&i.DiscordUser,
+			&i.Content,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getGoalVerifications = `-- name: GetGoalVerifications :many
+SELECT goal_id, discord_user, created_at FROM goal_verifications
+WHERE goal_id = ?
+ORDER BY created_at ASC, discord_user ASC
+`
+
+func (q *Queries) GetGoalVerifications(ctx context.Context, goalID int64) ([]GoalVerification, error) {
+	rows, err := q.db.QueryContext(ctx, getGoalVerifications, goalID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GoalVerification
+	for rows.Next() {
+		var i GoalVerification
+		if err := rows.Scan(&i.GoalID, &i.DiscordUser, &i.CreatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getGoalsByCheckpoint = `-- name: GetGoalsByCheckpoint :many
+SELECT id, discord_user, description, checkpoint_id, status, created_at, progress, target, unit, total, verification, verified_by, objective_id, visibility FROM goals
+WHERE checkpoint_id = ?
+ORDER BY created_at ASC
+`
+
+func (q *Queries) GetGoalsByCheckpoint(ctx context.Context, checkpointID int64) ([]Goal, error) {
+	rows, err := q.db.QueryContext(ctx, getGoalsByCheckpoint, checkpointID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Goal
+	for rows.Next() {
+		var i Goal
+		if err := rows.Scan(
+			&i.ID,
+			&i.DiscordUser,
+			&i.Description,
+			&i.CheckpointID,
+			&i.Status,
+			&i.CreatedAt,
+			&i.Progress,
+			&i.Target,
+			&i.Unit,
+			&i.Total,
+			&i.Verification,
+			&i.VerifiedBy,
+			&i.ObjectiveID,
+			&i.Visibility,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getGoalsByObjective = `-- name: GetGoalsByObjective :many
+SELECT id, discord_user, description, checkpoint_id, status, created_at, progress, target, unit, total, verification, verified_by, objective_id, visibility FROM goals
+WHERE objective_id = ?
+ORDER BY created_at ASC
+`
+
+func (q *Queries) GetGoalsByObjective(ctx context.Context, objectiveID sql.NullInt64) ([]Goal, error) {
+	rows, err := q.db.QueryContext(ctx, getGoalsByObjective, objectiveID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Goal
+	for rows.Next() {
+		var i Goal
+		if err := rows.Scan(
+			&i.ID,
+			&i.DiscordUser,
+			&i.Description,
+			&i.CheckpointID,
+			&i.Status,
+			&i.CreatedAt,
+			&i.Progress,
+			&i.Target,
+			&i.Unit,
+			&i.Total,
+			&i.Verification,
+			&i.VerifiedBy,
+			&i.ObjectiveID,
+			&i.Visibility,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getGoalsPendingVerification = `-- name: GetGoalsPendingVerification :many
+SELECT goals.id, goals.discord_user, goals.description, goals.checkpoint_id, goals.status, goals.created_at, goals.progress, goals.target, goals.unit, goals.total, goals.verification, goals.verified_by, goals.objective_id, goals.visibility FROM goals
+JOIN checkpoints ON checkpoints.id = goals.checkpoint_id
+WHERE checkpoints.guild_id = ? AND goals.status = 'completed' AND goals.verification = 'pending'
+ORDER BY goals.id ASC
+`
+
+func (q *Queries) GetGoalsPendingVerification(ctx context.Context, guildID string) ([]Goal, error) {
+	rows, err := q.db.QueryContext(ctx, getGoalsPendingVerification, guildID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Goal
+	for rows.Next() {
+		var i Goal
+		if err := rows.Scan(
+			&i.ID,
+			&i.DiscordUser,
+			&i.Description,
+			&i.CheckpointID,
+			&i.Status,
+			&i.CreatedAt,
+			&i.Progress,
+			&i.Target,
+			&i.Unit,
+			&i.Total,
+			&i.Verification,
+			&i.VerifiedBy,
+			&i.ObjectiveID,
+			&i.Visibility,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getGuild = `-- name: GetGuild :one
+SELECT guild_id, timezone, owner_id, created_at FROM guilds
+WHERE guild_id = ?
+`
+
+func (q *Queries) GetGuild(ctx context.Context, guildID string) (Guild, error) {
+	row := q.db.QueryRowContext(ctx, getGuild, guildID)
+	var i Guild
+	err := row.Scan(
+		&i.GuildID,
+		&i.Timezone,
+		&i.OwnerID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getGuildSettings = `-- name: GetGuildSettings :many
+SELECT guild_id, "key", value, updated_by, updated_at FROM guild_settings
+WHERE guild_id = ?
+ORDER BY key ASC
+`
+
+func (q *Queries) GetGuildSettings(ctx context.Context, guildID string) ([]GuildSetting, error) {
+	rows, err := q.db.QueryContext(ctx, getGuildSettings, guildID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GuildSetting
+	for rows.Next() {
+		var i GuildSetting
+		if err := rows.Scan(
+			&i.GuildID,
+			&i.Key,
+			&i.Value,
+			&i.UpdatedBy,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getGuildTotals = `-- name: GetGuildTotals :many
+SELECT
+    guilds.guild_id,
+    (SELECT COUNT(*) FROM checkpoints WHERE checkpoints.guild_id = guilds.guild_id) AS checkpoints,
+    (SELECT COUNT(*) FROM goals JOIN checkpoints ON checkpoints.id = goals.checkpoint_id WHERE checkpoints.guild_id = guilds.guild_id) AS goals
+FROM guilds
+`
+
+type GetGuildTotalsRow struct {
+	GuildID     string `json:"guild_id"`
+	Checkpoints int64  `json:"checkpoints"`
+	Goals       int64  `json:"goals"`
+}
+
+func (q *Queries) GetGuildTotals(ctx context.Context) ([]GetGuildTotalsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getGuildTotals)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetGuildTotalsRow
+	for rows.Next() {
+		var i GetGuildTotalsRow
+		if err := rows.Scan(&i.GuildID, &i.Checkpoints, &i.Goals); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getGuildUserStats = `-- name: GetGuildUserStats :many
+SELECT
+    goals.discord_user,
+    COUNT(*) AS goals,
+    CAST(COALESCE(SUM(goals.status = 'completed' AND goals.verification != 'pending'), 0) AS INTEGER) AS completed,
+    CAST(COALESCE(SUM(goals.status = 'completed' AND goals.verification = 'pending'), 0) AS INTEGER) AS unverified,
+    CAST(COALESCE(SUM(goals.status = 'failed'), 0) AS INTEGER) AS failed,
+    CAST(COALESCE(SUM(goals.status = 'incomplete'), 0) AS INTEGER) AS incomplete,
+    CAST(COALESCE(SUM(goals.status = 'partial'), 0) AS INTEGER) AS partial,
+    CAST(COALESCE(SUM(CASE WHEN goals.status = 'partial' THEN goals.progress ELSE 0 END), 0) AS INTEGER) AS partial_progress
+FROM goals
+JOIN checkpoints ON checkpoints.id = goals.checkpoint_id
+WHERE checkpoints.guild_id = ?
+GROUP BY goals.discord_user
+ORDER BY completed DESC, goals DESC
+`
+
+type GetGuildUserStatsRow struct {
+	DiscordUser     string `json:"discord_user"`
+	Goals           int64  `json:"goals"`
+	Completed       int64  `json:"completed"`
+	Unverified      int64  `json:"unverified"`
+	Failed          int64  `json:"failed"`
+	Incomplete      int64  `json:"incomplete"`
+	Partial         int64  `json:"partial"`
+	PartialProgress int64  `json:"partial_progress"`
+}
+
+func (q *Queries) GetGuildUserStats(ctx context.Context, guildID string) ([]GetGuildUserStatsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getGuildUserStats, guildID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetGuildUserStatsRow
+	for rows.Next() {
+		var i GetGuildUserStatsRow
+		if err := rows.Scan(
+			&i.DiscordUser,
+			&i.Goals,
+			&i.Completed,
+			&i.Unverified,
+			&i.Failed,
+			&i.Incomplete,
+			&i.Partial,
+			&i.PartialProgress,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getObjective = `-- name: GetObjective :one
+SELECT id, guild_id, discord_user, title, deadline, status, created_at, updated_at FROM objectives
+WHERE id = ?
+`
+
+func (q *Queries) GetObjective(ctx context.Context, id int64) (Objective, error) {
+	row := q.db.QueryRowContext(ctx, getObjective, id)
+	var i Objective
+	err := row.Scan(
+		&i.ID,
+		&i.GuildID,
+		&i.DiscordUser,
+		&i.Title,
+		&i.Deadline,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getObjectivesByGuildAndUser = `-- name: GetObjectivesByGuildAndUser :many
+SELECT objectives.id, objectives.guild_id, objectives.discord_user, objectives.title, objectives.deadline, objectives.status, objectives.created_at, objectives.updated_at,
+    COUNT(goals.id) AS goals,
+    COUNT(DISTINCT goals.checkpoint_id) AS checkpoints,
+    CAST(COALESCE(SUM(goals.status = 'completed' AND goals.verification != 'pending'), 0) AS INTEGER) AS completed,
+    CAST(COALESCE(SUM(CASE WHEN goals.status = 'partial' THEN goals.progress ELSE 0 END), 0) AS INTEGER) AS partial_progress
+FROM objectives
+LEFT JOIN goals ON goals.objective_id = objectives.id
+WHERE objectives.guild_id = ? AND objectives.discord_user = ?
+GROUP BY objectives.id
+ORDER BY objectives.status = 'achieved' ASC, objectives.deadline ASC
+`
+
+type GetObjectivesByGuildAndUserParams struct {
+	GuildID     string `json:"guild_id"`
+	DiscordUser string `json:"discord_user"`
+}
+
+type GetObjectivesByGuildAndUserRow struct {
+	Objective       Objective `json:"objective"`
+	Goals           int64     `json:"goals"`
+	Checkpoints     int64     `json:"checkpoints"`
+	Completed       int64     `json:"completed"`
+	PartialProgress int64     `json:"partial_progress"`
+}
+
+func (q *Queries) GetObjectivesByGuildAndUser(ctx context.Context, arg GetObjectivesByGuildAndUserParams) ([]GetObjectivesByGuildAndUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, getObjectivesByGuildAndUser, arg.GuildID, arg.DiscordUser)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetObjectivesByGuildAndUserRow
+	for rows.Next() {
+		var i GetObjectivesByGuildAndUserRow
+		if err := rows.Scan(
+			&i.Objective.ID,
+			&i.Objective.GuildID,
+			&i.Objective.DiscordUser,
+			&i.Objective.Title,
+			&i.Objective.Deadline,
+			&i.Objective.Status,
+			&i.Objective.CreatedAt,
+			&i.Objective.UpdatedAt,
+			&i.Goals,
+			&i.Checkpoints,
+			&i.Completed,
+			&i.PartialProgress,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPartner = `-- name: GetPartner :one
+SELECT guild_id, discord_user, partner_user, created_by, created_at FROM partners
+WHERE guild_id = ? AND discord_user = ?
+`
+
+type GetPartnerParams struct {
+	GuildID     string `json:"guild_id"`
+	DiscordUser string `json:"discord_user"`
+}
+
+func (q *Queries) GetPartner(ctx context.Context, arg GetPartnerParams) (Partner, error) {
+	row := q.db.QueryRowContext(ctx, getPartner, arg.GuildID, arg.DiscordUser)
+	var i Partner
+	err := row.Scan(
+		&i.GuildID,
+		&i.DiscordUser,
+		&i.PartnerUser,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getPastCheckpointsByChannel = `-- name: GetPastCheckpointsByChannel :many
+SELECT id, scheduled_at, channel_id, guild_id, discord_user, created_at, sequence, updated_at FROM checkpoints
+WHERE channel_id = ? AND datetime(scheduled_at) < datetime('now')
+ORDER BY datetime(scheduled_at) DESC
+`
+
+func (q *Queries) GetPastCheckpointsByChannel(ctx context.Context, channelID string) ([]Checkpoint, error) {
+	rows, err := q.db.QueryContext(ctx, getPastCheckpointsByChannel, channelID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Checkpoint
+	for rows.Next() {
+		var i Checkpoint
+		if err := rows.Scan(
+			&i.ID,
+			&i.ScheduledAt,
+			&i.ChannelID,
+			&i.GuildID,
+			&i.DiscordUser,
+			&i.CreatedAt,
+			&i.Sequence,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPastCheckpointsByChannelAndTag = `-- name: GetPastCheckpointsByChannelAndTag :many
+SELECT checkpoints.id, checkpoints.scheduled_at, checkpoints.channel_id, checkpoints.guild_id, checkpoints.discord_user, checkpoints.created_at, checkpoints.sequence, checkpoints.updated_at, COUNT(*) AS tagged_goals,
+    CAST(COALESCE(SUM(goals.status = 'completed' AND goals.verification != 'pending'), 0) AS INTEGER) AS tagged_completed
+FROM checkpoints
+JOIN goals ON goals.checkpoint_id = checkpoints.id
+JOIN goal_tags ON goal_tags.goal_id = goals.id
+WHERE checkpoints.channel_id = ? AND goal_tags.tag = ? AND datetime(checkpoints.scheduled_at) < datetime('now')
+GROUP BY checkpoints.id
+ORDER BY datetime(checkpoints.scheduled_at) DESC
+`
+
+type GetPastCheckpointsByChannelAndTagParams struct {
+	ChannelID string `json:"channel_id"`
+	Tag       string `json:"tag"`
+}
+
+type GetPastCheckpointsByChannelAndTagRow struct {
+	Checkpoint      Checkpoint `json:"checkpoint"`
+	TaggedGoals     int64      `json:"tagged_goals"`
+	TaggedCompleted int64      `json:"tagged_completed"`
+}
+
+func (q *Queries) GetPastCheckpointsByChannelAndTag(ctx context.Context, arg GetPastCheckpointsByChannelAndTagParams) ([]GetPastCheckpointsByChannelAndTagRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPastCheckpointsByChannelAndTag, arg.ChannelID, arg.Tag)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPastCheckpointsByChannelAndTagRow
+	for rows.Next() {
+		var i GetPastCheckpointsByChannelAndTagRow
+		if err := rows.Scan(
+			&i.Checkpoint.ID,
+			&i.Checkpoint.ScheduledAt,
+			&i.Checkpoint.ChannelID,
+			&i.Checkpoint.GuildID,
+			&i.Checkpoint.DiscordUser,
+			&i.Checkpoint.CreatedAt,
+			&i.Checkpoint.Sequence,
+			&i.Checkpoint.UpdatedAt,
+			&i.TaggedGoals,
+			&i.TaggedCompleted,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPendingGoal = `-- name: GetPendingGoal :one
+SELECT id, guild_id, channel_id, discord_user, description, updated_by, created_at, updated_at, target, unit, tags, visibility FROM pending_goals
+WHERE channel_id = ? AND discord_user = ?
+`
+
+type GetPendingGoalParams struct {
+	ChannelID   string `json:"channel_id"`
+	DiscordUser string `json:"discord_user"`
+}
+
+func (q *Queries) GetPendingGoal(ctx context.Context, arg GetPendingGoalParams) (PendingGoal, error) {
+	row := q.db.QueryRowContext(ctx, getPendingGoal, arg.ChannelID, arg.DiscordUser)
+	var i PendingGoal
+	err := row.Scan(
+		&i.ID,
+		&i.GuildID,
+		&i.ChannelID,
+		&i.DiscordUser,
+		&i.Description,
+		&i.UpdatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Target,
+		&i.Unit,
+		&i.Tags,
+		&i.Visibility,
+	)
+	return i, err
+}
+
+const getPendingGoalsByChannel = `-- name: GetPendingGoalsByChannel :many
+SELECT id, guild_id, channel_id, discord_user, description, updated_by, created_at, updated_at, target, unit, tags, visibility FROM pending_goals
+WHERE channel_id = ?
+ORDER BY created_at ASC
+`
+
+func (q *Queries) GetPendingGoalsByChannel(ctx context.Context, channelID string) ([]PendingGoal, error) {
+	rows, err := q.db.QueryContext(ctx, getPendingGoalsByChannel, channelID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PendingGoal
+	for rows.Next() {
+		var i PendingGoal
+		if err := rows.Scan(
+			&i.ID,
+			&i.GuildID,
+			&i.ChannelID,
+			&i.DiscordUser,
+			&i.Description,
+			&i.UpdatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Target,
+			&i.Unit,
+			&i.Tags,
+			&i.Visibility,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPreviousCheckpointByChannel = `-- name: GetPreviousCheckpointByChannel :one
+SELECT id, scheduled_at, channel_id, guild_id, discord_user, created_at, sequence, updated_at FROM checkpoints
+WHERE channel_id = ? AND datetime(scheduled_at) < datetime(CAST(?2 AS TEXT))
+ORDER BY datetime(scheduled_at) DESC
+LIMIT 1
+`
+
+type GetPreviousCheckpointByChannelParams struct {
+	ChannelID string `json:"channel_id"`
+	Before    string `json:"before"`
+}
+
+func (q *Queries) GetPreviousCheckpointByChannel(ctx context.Context, arg GetPreviousCheckpointByChannelParams) (Checkpoint, error) {
+	row := q.db.QueryRowContext(ctx, getPreviousCheckpointByChannel, arg.ChannelID, arg.Before)
+	var i Checkpoint
+	err := row.Scan(
+		&i.ID,
+		&i.ScheduledAt,
+		&i.ChannelID,
+		&i.GuildID,
+		&i.DiscordUser,
+		&i.CreatedAt,
+		&i.Sequence,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getRsvpsByCheckpoint = `-- name: GetRsvpsByCheckpoint :many
+SELECT id, checkpoint_id, discord_user, created_at FROM checkpoint_rsvp
+WHERE checkpoint_id = ?
+ORDER BY created_at ASC
+`
+
+func (q *Queries) GetRsvpsByCheckpoint(ctx context.Context, checkpointID int64) ([]CheckpointRsvp, error) {
+	rows, err := q.db.QueryContext(ctx, getRsvpsByCheckpoint, checkpointID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CheckpointRsvp
+	for rows.Next() {
+		var i CheckpointRsvp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CheckpointID,
+			&i.DiscordUser,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUpcomingCheckpointByGuildAndChannel = `-- name: GetUpcomingCheckpointByGuildAndChannel :one
+SELECT id, scheduled_at, channel_id, guild_id, discord_user, created_at, sequence, updated_at FROM checkpoints
+WHERE guild_id = ? AND channel_id = ? AND datetime(scheduled_at) >= datetime('now')
+ORDER BY datetime(scheduled_at) ASC
+LIMIT 1
+`
+
+type GetUpcomingCheckpointByGuildAndChannelParams struct {
+	GuildID   string `json:"guild_id"`
+	ChannelID string `json:"channel_id"`
+}
+
+func (q *Queries) GetUpcomingCheckpointByGuildAndChannel(ctx context.Context, arg GetUpcomingCheckpointByGuildAndChannelParams) (Checkpoint, error) {
+	row := q.db.QueryRowContext(ctx, getUpcomingCheckpointByGuildAndChannel, arg.GuildID, arg.ChannelID)
+	var i Checkpoint
+	err := row.Scan(
+		&i.ID,
+		&i.ScheduledAt,
+		&i.ChannelID,
+		&i.GuildID,
+		&i.DiscordUser,
+		&i.CreatedAt,
+		&i.Sequence,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getUpcomingCheckpoints = `-- name: GetUpcomingCheckpoints :many
+SELECT id, scheduled_at, channel_id, guild_id, discord_user, created_at, sequence, updated_at FROM checkpoints
+WHERE datetime(scheduled_at) >= datetime('now')
+ORDER BY datetime(scheduled_at) ASC
+`
+
+func (q *Queries) GetUpcomingCheckpoints(ctx context.Context) ([]Checkpoint, error) {
 	rows, err := q.db.QueryContext(ctx, getUpcomingCheckpoints)
 	if err != nil {
 		return nil, err
@@ -310,6 +2149,423 @@ func (q *Queries) GetUpcomingCheckpoints(ctx context.Context) ([]Checkpoint, err
 			&i.GuildID,
 			&i.DiscordUser,
 			&i.CreatedAt,
+			&i.Sequence,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUpcomingCheckpointsByGuildAndChannel = `-- name: GetUpcomingCheckpointsByGuildAndChannel :many
+SELECT id, scheduled_at, channel_id, guild_id, discord_user, created_at, sequence, updated_at FROM checkpoints
+WHERE guild_id = ? AND channel_id = ? AND datetime(scheduled_at) >= datetime('now')
+ORDER BY datetime(scheduled_at) ASC
+`
+
+type GetUpcomingCheckpointsByGuildAndChannelParams struct {
+	GuildID   string `json:"guild_id"`
+	ChannelID string `json:"channel_id"`
+}
+
+func (q *Queries) GetUpcomingCheckpointsByGuildAndChannel(ctx context.Context, arg GetUpcomingCheckpointsByGuildAndChannelParams) ([]Checkpoint, error) {
+	rows, err := q.db.QueryContext(ctx, getUpcomingCheckpointsByGuildAndChannel, arg.GuildID, arg.ChannelID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Checkpoint
+	for rows.Next() {
+		var i Checkpoint
+		if err := rows.Scan(
+			&i.ID,
+			&i.ScheduledAt,
+			&i.ChannelID,
+			&i.GuildID,
+			&i.DiscordUser,
+			&i.CreatedAt,
+			&i.Sequence,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUpcomingGoalsByGuildAndUser = `-- name: GetUpcomingGoalsByGuildAndUser :many
+SELECT goals.id, goals.discord_user, goals.description, goals.checkpoint_id, goals.status, goals.created_at, goals.progress, goals.target, goals.unit, goals.total, goals.verification, goals.verified_by, goals.objective_id, goals.visibility FROM goals
+JOIN checkpoints ON checkpoints.id = goals.checkpoint_id
+WHERE checkpoints.guild_id = ? AND goals.discord_user = ?
+AND datetime(checkpoints.scheduled_at) >= datetime('now')
+ORDER BY datetime(checkpoints.scheduled_at) ASC
+`
+
+type GetUpcomingGoalsByGuildAndUserParams struct {
+	GuildID     string `json:"guild_id"`
+	DiscordUser string `json:"discord_user"`
+}
+
+func (q *Queries) GetUpcomingGoalsByGuildAndUser(ctx context.Context, arg GetUpcomingGoalsByGuildAndUserParams) ([]Goal, error) {
+	rows, err := q.db.QueryContext(ctx, getUpcomingGoalsByGuildAndUser, arg.GuildID, arg.DiscordUser)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Goal
+	for rows.Next() {
+		var i Goal
+		if err := rows.Scan(
+			&i.ID,
+			&i.DiscordUser,
+			&i.Description,
+			&i.CheckpointID,
+			&i.Status,
+			&i.CreatedAt,
+			&i.Progress,
+			&i.Target,
+			&i.Unit,
+			&i.Total,
+			&i.Verification,
+			&i.VerifiedBy,
+			&i.ObjectiveID,
+			&i.Visibility,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserAttendanceCount = `-- name: GetUserAttendanceCount :one
+SELECT COUNT(*) FROM attendance
+JOIN checkpoints ON checkpoints.id = attendance.checkpoint_id
+WHERE checkpoints.guild_id = ? AND attendance.discord_user = ?
+`
+
+type GetUserAttendanceCountParams struct {
+	GuildID     string `json:"guild_id"`
+	DiscordUser string `json:"discord_user"`
+}
+
+func (q *Queries) GetUserAttendanceCount(ctx context.Context, arg GetUserAttendanceCountParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getUserAttendanceCount, arg.GuildID, arg.DiscordUser)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const getUserGoalStats = `-- name: GetUserGoalStats :one
+SELECT
+    COUNT(*) AS goals,
+    CAST(COALESCE(SUM(goals.status = 'completed' AND goals.verification != 'pending'), 0) AS INTEGER) AS completed,
+    CAST(COALESCE(SUM(goals.status = 'completed' AND goals.verification = 'pending'), 0) AS INTEGER) AS unverified,
+    CAST(COALESCE(SUM(goals.status = 'failed'), 0) AS INTEGER) AS failed,
+    CAST(COALESCE(SUM(goals.status = 'incomplete'), 0) AS INTEGER) AS incomplete,
+    CAST(COALESCE(SUM(goals.status = 'partial'), 0) AS INTEGER) AS partial,
+    CAST(COALESCE(SUM(CASE WHEN goals.status = 'partial' THEN goals.progress ELSE 0 END), 0) AS INTEGER) AS partial_progress
+FROM goals
+JOIN checkpoints ON checkpoints.id = goals.checkpoint_id
+WHERE checkpoints.guild_id = ? AND goals.discord_user = ?
+`
+
+type GetUserGoalStatsParams struct {
+	GuildID     string `json:"guild_id"`
+	DiscordUser string `json:"discord_user"`
+}
+
+type GetUserGoalStatsRow struct {
+	Goals           int64 `json:"goals"`
+	Completed       int64 `json:"completed"`
+	Unverified      int64 `json:"unverified"`
+	Failed          int64 `json:"failed"`
+	Incomplete      int64 `json:"incomplete"`
+	Partial         int64 `json:"partial"`
+	PartialProgress int64 `json:"partial_progress"`
+}
+
+func (q *Queries) GetUserGoalStats(ctx context.Context, arg GetUserGoalStatsParams) (GetUserGoalStatsRow, error) {
+	row := q.db.QueryRowContext(ctx, getUserGoalStats, arg.GuildID, arg.DiscordUser)
+	var i GetUserGoalStatsRow
+	err := row.Scan(
+		&i.Goals,
+		&i.Completed,
+		&i.Unverified,
+		&i.Failed,
+		&i.Incomplete,
+		&i.Partial,
+		&i.PartialProgress,
+	)
+	return i, err
+}
+
+const getUserGoalStatsByTag = `-- name: GetUserGoalStatsByTag :one
+SELECT
+    COUNT(*) AS goals,
+    CAST(COALESCE(SUM(goals.status = 'completed' AND goals.verification != 'pending'), 0) AS INTEGER) AS completed,
+    CAST(COALESCE(SUM(goals.status = 'completed' AND goals.verification = 'pending'), 0) AS INTEGER) AS unverified,
+    CAST(COALESCE(SUM(goals.status = 'failed'), 0) AS INTEGER) AS failed,
+    CAST(COALESCE(SUM(goals.status = 'incomplete'), 0) AS INTEGER) AS incomplete,
+    CAST(COALESCE(SUM(goals.status = 'partial'), 0) AS INTEGER) AS partial,
+    CAST(COALESCE(SUM(CASE WHEN goals.status = 'partial' THEN goals.progress ELSE 0 END), 0) AS INTEGER) AS partial_progress
+FROM goals
+JOIN checkpoints ON checkpoints.id = goals.checkpoint_id
+JOIN goal_tags ON goal_tags.goal_id = goals.id
+WHERE checkpoints.guild_id = ? AND goals.discord_user = ? AND goal_tags.tag = ?
+`
+
+type GetUserGoalStatsByTagParams struct {
+	GuildID     string `json:"guild_id"`
+	DiscordUser string `json:"discord_user"`
+	Tag         string `json:"tag"`
+}
+
+type GetUserGoalStatsByTagRow struct {
+	Goals           int64 `json:"goals"`
+	Completed       int64 `json:"completed"`
+	Unverified      int64 `json:"unverified"`
+	Failed          int64 `json:"failed"`
+	Incomplete      int64 `json:"incomplete"`
+	Partial         int64 `json:"partial"`
+	PartialProgress int64 `json:"partial_progress"`
+}
+
+func (q *Queries) GetUserGoalStatsByTag(ctx context.Context, arg GetUserGoalStatsByTagParams) (GetUserGoalStatsByTagRow, error) {
+	row := q.db.QueryRowContext(ctx, getUserGoalStatsByTag, arg.GuildID, arg.DiscordUser, arg.Tag)
+	var i GetUserGoalStatsByTagRow
+	err := row.Scan(
+		&i.Goals,
+		&i.Completed,
+		&i.Unverified,
+		&i.Failed,
+		&i.Incomplete,
+		&i.Partial,
+		&i.PartialProgress,
+	)
+	return i, err
+}
+
+const getUserTagStats = `-- name: GetUserTagStats :many
+SELECT
+    goal_tags.tag,
+    COUNT(*) AS goals,
+    CAST(COALESCE(SUM(goals.status = 'completed' AND goals.verification != 'pending'), 0) AS INTEGER) AS completed,
+    CAST(COALESCE(SUM(CASE WHEN goals.status = 'partial' THEN goals.progress ELSE 0 END), 0) AS INTEGER) AS partial_progress
+FROM goals
+JOIN checkpoints ON checkpoints.id = goals.checkpoint_id
+JOIN goal_tags ON goal_tags.goal_id = goals.id
+WHERE checkpoints.guild_id = ? AND goals.discord_user = ?
+GROUP BY goal_tags.tag
+ORDER BY goals DESC, goal_tags.tag ASC
+`
+
+type GetUserTagStatsParams struct {
+	GuildID     string `json:"guild_id"`
+	DiscordUser string `json:"discord_user"`
+}
+
+type GetUserTagStatsRow struct {
+	Tag             string `json:"tag"`
+	Goals           int64  `json:"goals"`
+	Completed       int64  `json:"completed"`
+	PartialProgress int64  `json:"partial_progress"`
+}
+
+func (q *Queries) GetUserTagStats(ctx context.Context, arg GetUserTagStatsParams) ([]GetUserTagStatsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getUserTagStats, arg.GuildID, arg.DiscordUser)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUserTagStatsRow
+	for rows.Next() {
+		var i GetUserTagStatsRow
+		if err := rows.Scan(
+			&i.Tag,
+			&i.Goals,
+			&i.Completed,
+			&i.PartialProgress,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const incrementGoalTemplateUses = `-- name: IncrementGoalTemplateUses :exec
+UPDATE goal_templates
+SET uses = uses + 1
+WHERE id = ?
+`
+
+func (q *Queries) IncrementGoalTemplateUses(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, incrementGoalTemplateUses, id)
+	return err
+}
+
+const listApiTokensByGuild = `-- name: ListApiTokensByGuild :many
+SELECT id, guild_id, name, token_hash, created_by, created_at, last_used_at, scope, discord_user FROM api_tokens
+WHERE guild_id = ?
+ORDER BY created_at ASC
+`
+
+func (q *Queries) ListApiTokensByGuild(ctx context.Context, guildID string) ([]ApiToken, error) {
+	rows, err := q.db.QueryContext(ctx, listApiTokensByGuild, guildID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ApiToken
+	for rows.Next() {
+		var i ApiToken
+		if err := rows.Scan(
+			&i.ID,
+			&i.GuildID,
+			&i.Name,
+			&i.TokenHash,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.LastUsedAt,
+			&i.Scope,
+			&i.DiscordUser,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAuditEntries = `-- name: ListAuditEntries :many
+SELECT id, guild_id, actor_id, source, "action", target_type, target_id, target_user, before_value, after_value, created_at FROM audit_log
+WHERE guild_id = ?1
+  AND (CAST(?2 AS TEXT) IS NULL OR action = ?2)
+  AND (CAST(?3 AS TEXT) IS NULL OR actor_id = ?3 OR target_user = ?3)
+ORDER BY id DESC
+LIMIT ?4
+`
+
+type ListAuditEntriesParams struct {
+	GuildID    string         `json:"guild_id"`
+	Action     sql.NullString `json:"action"`
+	UserID     sql.NullString `json:"user_id"`
+	MaxEntries int64          `json:"max_entries"`
+}
+
+func (q *Queries) ListAuditEntries(ctx context.Context, arg ListAuditEntriesParams) ([]AuditLog, error) {
+	rows, err := q.db.QueryContext(ctx, listAuditEntries,
+		arg.GuildID,
+		arg.Action,
+		arg.UserID,
+		arg.MaxEntries,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AuditLog
+	for rows.Next() {
+		var i AuditLog
+		if err := rows.Scan(
+			&i.ID,
+			&i.GuildID,
+			&i.ActorID,
+			&i.Source,
+			&i.Action,
+			&i.TargetType,
+			&i.TargetID,
+			&i.TargetUser,
+			&i.BeforeValue,
+			&i.AfterValue,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCheckpoints = `-- name: ListCheckpoints :many
+SELECT id, scheduled_at, channel_id, guild_id, discord_user, created_at, sequence, updated_at FROM checkpoints
+WHERE guild_id = ?1
+  AND (CAST(?2 AS TEXT) IS NULL OR channel_id = ?2)
+  AND (CAST(?3 AS TEXT) IS NULL OR datetime(scheduled_at) >= datetime(?3))
+  AND (CAST(?4 AS TEXT) IS NULL OR datetime(scheduled_at) < datetime(?4))
+ORDER BY datetime(scheduled_at) ASC
+`
+
+type ListCheckpointsParams struct {
+	GuildID       string         `json:"guild_id"`
+	ChannelID     sql.NullString `json:"channel_id"`
+	ScheduledFrom sql.NullString `json:"scheduled_from"`
+	ScheduledTo   sql.NullString `json:"scheduled_to"`
+}
+
+func (q *Queries) ListCheckpoints(ctx context.Context, arg ListCheckpointsParams) ([]Checkpoint, error) {
+	rows, err := q.db.QueryContext(ctx, listCheckpoints,
+		arg.GuildID,
+		arg.ChannelID,
+		arg.ScheduledFrom,
+		arg.ScheduledTo,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Checkpoint
+	for rows.Next() {
+		var i Checkpoint
+		if err := rows.Scan(
+			&i.ID,
+			&i.ScheduledAt,
+			&i.ChannelID,
+			&i.GuildID,
+			&i.DiscordUser,
+			&i.CreatedAt,
+			&i.Sequence,
+			&i.UpdatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -324,32 +2580,78 @@ func (q *Queries) GetUpcomingCheckpoints(ctx context.Context) ([]Checkpoint, err
 	return items, nil
 }
 
-const getUpcomingCheckpointsByGuildAndChannel = `-- name: GetUpcomingCheckpointsByGuildAndChannel :many
-SELECT id, scheduled_at, channel_id, guild_id, discord_user, created_at FROM checkpoints
-WHERE guild_id = ? AND channel_id = ? AND datetime(scheduled_at) >= datetime('now')
-ORDER BY datetime(scheduled_at) ASC
+const listWebhookDeliveries = `-- name: ListWebhookDeliveries :many
+SELECT webhook_deliveries.id, webhook_deliveries.webhook_id, webhook_deliveries.event_id, webhook_deliveries.event_type, webhook_deliveries.payload, webhook_deliveries.status, webhook_deliveries.attempts, webhook_deliveries.response_status, webhook_deliveries.last_error, webhook_deliveries.next_attempt_at, webhook_deliveries.created_at, webhook_deliveries.delivered_at FROM webhook_deliveries
+JOIN webhooks ON webhooks.id = webhook_deliveries.webhook_id
+WHERE webhook_deliveries.webhook_id = ? AND webhooks.guild_id = ?
+ORDER BY webhook_deliveries.id DESC
+LIMIT ?
 `
 
-type GetUpcomingCheckpointsByGuildAndChannelParams struct {
+type ListWebhookDeliveriesParams struct {
+	WebhookID int64  `json:"webhook_id"`
 	GuildID   string `json:"guild_id"`
-	ChannelID string `json:"channel_id"`
+	Limit     int64  `json:"limit"`
 }
 
-func (q *Queries) GetUpcomingCheckpointsByGuildAndChannel(ctx context.Context, arg GetUpcomingCheckpointsByGuildAndChannelParams) ([]Checkpoint, error) {
-	rows, err := q.db.QueryContext(ctx, getUpcomingCheckpointsByGuildAndChannel, arg.GuildID, arg.ChannelID)
+func (q *Queries) ListWebhookDeliveries(ctx context.Context, arg ListWebhookDeliveriesParams) ([]WebhookDelivery, error) {
+	rows, err := q.db.QueryContext(ctx, listWebhookDeliveries, arg.WebhookID, arg.GuildID, arg.Limit)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Checkpoint
+	var items []WebhookDelivery
 	for rows.Next() {
-		var i Checkpoint
+		var i WebhookDelivery
+		if err := rows.Scan(
+			&i.ID,
+			&i.WebhookID,
+			&i.EventID,
+			&i.EventType,
+			&i.Payload,
+			&i.Status,
+			&i.Attempts,
+			&i.ResponseStatus,
+			&i.LastError,
+			&i.NextAttemptAt,
+			&i.CreatedAt,
+			&i.DeliveredAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWebhooksByGuild = `-- name: ListWebhooksByGuild :many
+SELECT id, guild_id, url, secret, event_types, created_by, created_at FROM webhooks
+WHERE guild_id = ?
+ORDER BY id ASC
+`
+
+func (q *Queries) ListWebhooksByGuild(ctx context.Context, guildID string) ([]Webhook, error) {
+	rows, err := q.db.QueryContext(ctx, listWebhooksByGuild, guildID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Webhook
+	for rows.Next() {
+		var i Webhook
 		if err := rows.Scan(
 			&i.ID,
-			&i.ScheduledAt,
-			&i.ChannelID,
 			&i.GuildID,
-			&i.DiscordUser,
+			&i.Url,
+			&i.Secret,
+			&i.EventTypes,
+			&i.CreatedBy,
 			&i.CreatedAt,
 		); err != nil {
 			return nil, err
@@ -380,6 +2682,285 @@ func (q *Queries) MarkAttendance(ctx context.Context, arg MarkAttendanceParams)
 	return err
 }
 
+const rescheduleCheckpoint = `-- name: RescheduleCheckpoint :one
+UPDATE checkpoints
+SET scheduled_at = ?, sequence = sequence + 1, updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+RETURNING id, scheduled_at, channel_id, guild_id, discord_user, created_at, sequence, updated_at
+`
+
+type RescheduleCheckpointParams struct {
+	ScheduledAt string `json:"scheduled_at"`
+	ID          int64  `json:"id"`
+}
+
+func (q *Queries) RescheduleCheckpoint(ctx context.Context, arg RescheduleCheckpointParams) (Checkpoint, error) {
+	row := q.db.QueryRowContext(ctx, rescheduleCheckpoint, arg.ScheduledAt, arg.ID)
+	var i Checkpoint
+	err := row.Scan(
+		&i.ID,
+		&i.ScheduledAt,
+		&i.ChannelID,
+		&i.GuildID,
+		&i.DiscordUser,
+		&i.CreatedAt,
+		&i.Sequence,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const saveGoalTemplate = `-- name: SaveGoalTemplate :one
+INSERT INTO goal_templates (guild_id, discord_user, name, description, target, unit, created_by)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (guild_id, discord_user, name) DO UPDATE
+SET description = excluded.description, target = excluded.target, unit = excluded.unit, created_by = excluded.created_by
+RETURNING id, guild_id, discord_user, name, description, target, unit, uses, created_by, created_at
+`
+
+type SaveGoalTemplateParams struct {
+	GuildID     string  `json:"guild_id"`
+	DiscordUser string  `json:"discord_user"`
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Target      float64 `json:"target"`
+	Unit        string  `json:"unit"`
+	CreatedBy   string  `json:"created_by"`
+}
+
+func (q *Queries) SaveGoalTemplate(ctx context.Context, arg SaveGoalTemplateParams) (GoalTemplate, error) {
+	row := q.db.QueryRowContext(ctx, saveGoalTemplate,
+		arg.GuildID,
+		arg.DiscordUser,
+		arg.Name,
+		arg.Description,
+		arg.Target,
+		arg.Unit,
+		arg.CreatedBy,
+	)
+	var i GoalTemplate
+	err := row.Scan(
+		&i.ID,
+		&i.GuildID,
+		&i.DiscordUser,
+		&i.Name,
+		&i.Description,
+		&i.Target,
+		&i.Unit,
+		&i.Uses,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const savePendingGoal = `-- name: SavePendingGoal :one
+INSERT INTO pending_goals (guild_id, channel_id, discord_user, description, target, unit, tags, visibility, updated_by)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (channel_id, discord_user) DO UPDATE
+SET description = excluded.description, target = excluded.target, unit = excluded.unit, tags = excluded.tags,
+    visibility = CASE WHEN excluded.visibility = '' THEN pending_goals.visibility ELSE excluded.visibility END,
+    updated_by = excluded.updated_by, updated_at = CURRENT_TIMESTAMP
+RETURNING id, guild_id, channel_id, discord_user, description, updated_by, created_at, updated_at, target, unit, tags, visibility
+`
+
+type SavePendingGoalParams struct {
+	GuildID     string  `json:"guild_id"`
+	ChannelID   string  `json:"channel_id"`
+	DiscordUser string  `json:"discord_user"`
+	Description string  `json:"description"`
+	Target      float64 `json:"target"`
+	Unit        string  `json:"unit"`
+	Tags        string  `json:"tags"`
+	Visibility  string  `json:"visibility"`
+	UpdatedBy   string  `json:"updated_by"`
+}
+
+func (q *Queries) SavePendingGoal(ctx context.Context, arg SavePendingGoalParams) (PendingGoal, error) {
+	row := q.db.QueryRowContext(ctx, savePendingGoal,
+		arg.GuildID,
+		arg.ChannelID,
+		arg.DiscordUser,
+		arg.Description,
+		arg.Target,
+		arg.Unit,
+		arg.Tags,
+		arg.Visibility,
+		arg.UpdatedBy,
+	)
+	var i PendingGoal
+	err := row.Scan(
+		&i.ID,
+		&i.GuildID,
+		&i.ChannelID,
+		&i.DiscordUser,
+		&i.Description,
+		&i.UpdatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Target,
+		&i.Unit,
+		&i.Tags,
+		&i.Visibility,
+	)
+	return i, err
+}
+
+const saveUpdatePrompt = `-- name: SaveUpdatePrompt :one
+INSERT INTO update_prompts (guild_id, discord_user, interval_days, next_prompt_at)
+VALUES (?, ?, ?, ?)
+ON CONFLICT (guild_id, discord_user) DO UPDATE
+SET interval_days = excluded.interval_days, next_prompt_at = excluded.next_prompt_at
+RETURNING guild_id, discord_user, interval_days, next_prompt_at, created_at
+`
+
+type SaveUpdatePromptParams struct {
+	GuildID      string `json:"guild_id"`
+	DiscordUser  string `json:"discord_user"`
+	IntervalDays int64  `json:"interval_days"`
+	NextPromptAt string `json:"next_prompt_at"`
+}
+
+func (q *Queries) SaveUpdatePrompt(ctx context.Context, arg SaveUpdatePromptParams) (UpdatePrompt, error) {
+	row := q.db.QueryRowContext(ctx, saveUpdatePrompt,
+		arg.GuildID,
+		arg.DiscordUser,
+		arg.IntervalDays,
+		arg.NextPromptAt,
+	)
+	var i UpdatePrompt
+	err := row.Scan(
+		&i.GuildID,
+		&i.DiscordUser,
+		&i.IntervalDays,
+		&i.NextPromptAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const setCalendarToken = `-- name: SetCalendarToken :exec
+INSERT INTO calendar_tokens (guild_id, token_hash, created_by)
+VALUES (?, ?, ?)
+ON CONFLICT (guild_id) DO UPDATE
+SET token_hash = excluded.token_hash, created_by = excluded.created_by, created_at = CURRENT_TIMESTAMP
+`
+
+type SetCalendarTokenParams struct {
+	GuildID   string `json:"guild_id"`
+	TokenHash string `json:"token_hash"`
+	CreatedBy string `json:"created_by"`
+}
+
+// Replaces the guild's token, the previous link stops working
+func (q *Queries) SetCalendarToken(ctx context.Context, arg SetCalendarTokenParams) error {
+	_, err := q.db.ExecContext(ctx, setCalendarToken, arg.GuildID, arg.TokenHash, arg.CreatedBy)
+	return err
+}
+
+const setCheckpointPartner = `-- name: SetCheckpointPartner :exec
+INSERT INTO checkpoint_partners (checkpoint_id, discord_user, partner_user)
+VALUES (?, ?, ?)
+ON CONFLICT (checkpoint_id, discord_user) DO UPDATE
+SET partner_user = excluded.partner_user
+`
+
+type SetCheckpointPartnerParams struct {
+	CheckpointID int64  `json:"checkpoint_id"`
+	DiscordUser  string `json:"discord_user"`
+	PartnerUser  string `json:"partner_user"`
+}
+
+func (q *Queries) SetCheckpointPartner(ctx context.Context, arg SetCheckpointPartnerParams) error {
+	_, err := q.db.ExecContext(ctx, setCheckpointPartner, arg.CheckpointID, arg.DiscordUser, arg.PartnerUser)
+	return err
+}
+
+const setGuildSetting = `-- name: SetGuildSetting :one
+INSERT INTO guild_settings (guild_id, key, value, updated_by)
+VALUES (?, ?, ?, ?)
+ON CONFLICT (guild_id, key) DO UPDATE
+SET value = excluded.value, updated_by = excluded.updated_by, updated_at = CURRENT_TIMESTAMP
+RETURNING guild_id, "key", value, updated_by, updated_at
+`
+
+type SetGuildSettingParams struct {
+	GuildID   string `json:"guild_id"`
+	Key       string `json:"key"`
+	Value     string `json:"value"`
+	UpdatedBy string `json:"updated_by"`
+}
+
+func (q *Queries) SetGuildSetting(ctx context.Context, arg SetGuildSettingParams) (GuildSetting, error) {
+	row := q.db.QueryRowContext(ctx, setGuildSetting,
+		arg.GuildID,
+		arg.Key,
+		arg.Value,
+		arg.UpdatedBy,
+	)
+	var i GuildSetting
+	err := row.Scan(
+		&i.GuildID,
+		&i.Key,
+		&i.Value,
+		&i.UpdatedBy,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const setPartner = `-- name: SetPartner :exec
+INSERT INTO partners (guild_id, discord_user, partner_user, created_by)
+VALUES (?, ?, ?, ?)
+ON CONFLICT (guild_id, discord_user) DO UPDATE
+SET partner_user = excluded.partner_user, created_by = excluded.created_by, created_at = CURRENT_TIMESTAMP
+`
+
+type SetPartnerParams struct {
+	GuildID     string `json:"guild_id"`
+	DiscordUser string `json:"discord_user"`
+	PartnerUser string `json:"partner_user"`
+	CreatedBy   string `json:"created_by"`
+}
+
+func (q *Queries) SetPartner(ctx context.Context, arg SetPartnerParams) error {
+	_, err := q.db.ExecContext(ctx, setPartner,
+		arg.GuildID,
+		arg.DiscordUser,
+		arg.PartnerUser,
+		arg.CreatedBy,
+	)
+	return err
+}
+
+const setUpdatePromptNext = `-- name: SetUpdatePromptNext :exec
+UPDATE update_prompts
+SET next_prompt_at = ?
+WHERE guild_id = ? AND discord_user = ?
+`
+
+type SetUpdatePromptNextParams struct {
+	NextPromptAt string `json:"next_prompt_at"`
+	GuildID      string `json:"guild_id"`
+	DiscordUser  string `json:"discord_user"`
+}
+
+func (q *Queries) SetUpdatePromptNext(ctx context.Context, arg SetUpdatePromptNextParams) error {
+	_, err := q.db.ExecContext(ctx, setUpdatePromptNext, arg.NextPromptAt, arg.GuildID, arg.DiscordUser)
+	return err
+}
+
+const touchApiToken = `-- name: TouchApiToken :exec
+UPDATE api_tokens
+SET last_used_at = CURRENT_TIMESTAMP
+WHERE id = ?
+`
+
+func (q *Queries) TouchApiToken(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, touchApiToken, id)
+	return err
+}
+
 const updateGoalDescription = `-- name: UpdateGoalDescription :exec
 UPDATE goals
 SET description = ?
@@ -397,6 +2978,39 @@ func (q *Queries) UpdateGoalDescription(ctx context.Context, arg UpdateGoalDescr
 	return err
 }
 
+const updateGoalObjective = `-- name: UpdateGoalObjective :exec
+UPDATE goals
+SET objective_id = ?
+WHERE id = ?
+`
+
+type UpdateGoalObjectiveParams struct {
+	ObjectiveID sql.NullInt64 `json:"objective_id"`
+	ID          int64         `json:"id"`
+}
+
+func (q *Queries) UpdateGoalObjective(ctx context.Context, arg UpdateGoalObjectiveParams) error {
+	_, err := q.db.ExecContext(ctx, updateGoalObjective, arg.ObjectiveID, arg.ID)
+	return err
+}
+
+const updateGoalProgress = `-- name: UpdateGoalProgress :exec
+UPDATE goals
+SET progress = ?
+WHERE checkpoint_id = ? AND discord_user = ?
+`
+
+type UpdateGoalProgressParams struct {
+	Progress     int64  `json:"progress"`
+	CheckpointID int64  `json:"checkpoint_id"`
+	DiscordUser  string `json:"discord_user"`
+}
+
+func (q *Queries) UpdateGoalProgress(ctx context.Context, arg UpdateGoalProgressParams) error {
+	_, err := q.db.ExecContext(ctx, updateGoalProgress, arg.Progress, arg.CheckpointID, arg.DiscordUser)
+	return err
+}
+
 const updateGoalStatus = `-- name: UpdateGoalStatus :exec
 UPDATE goals
 SET status = ?
@@ -413,3 +3027,128 @@ func (q *Queries) UpdateGoalStatus(ctx context.Context, arg UpdateGoalStatusPara
 	_, err := q.db.ExecContext(ctx, updateGoalStatus, arg.Status, arg.CheckpointID, arg.DiscordUser)
 	return err
 }
+
+const updateGoalTarget = `-- name: UpdateGoalTarget :exec
+UPDATE goals
+SET target = ?, unit = ?
+WHERE checkpoint_id = ? AND discord_user = ?
+`
+
+type UpdateGoalTargetParams struct {
+	Target       float64 `json:"target"`
+	Unit         string  `json:"unit"`
+	CheckpointID int64   `json:"checkpoint_id"`
+	DiscordUser  string  `json:"discord_user"`
+}
+
+func (q *Queries) UpdateGoalTarget(ctx context.Context, arg UpdateGoalTargetParams) error {
+	_, err := q.db.ExecContext(ctx, updateGoalTarget,
+		arg.Target,
+		arg.Unit,
+		arg.CheckpointID,
+		arg.DiscordUser,
+	)
+	return err
+}
+
+const updateGoalVerification = `-- name: UpdateGoalVerification :exec
+UPDATE goals
+SET verification = ?, verified_by = ?
+WHERE id = ?
+`
+
+type UpdateGoalVerificationParams struct {
+	Verification string `json:"verification"`
+	VerifiedBy   string `json:"verified_by"`
+	ID           int64  `json:"id"`
+}
+
+func (q *Queries) UpdateGoalVerification(ctx context.Context, arg UpdateGoalVerificationParams) error {
+	_, err := q.db.ExecContext(ctx, updateGoalVerification, arg.Verification, arg.VerifiedBy, arg.ID)
+	return err
+}
+
+const updateGoalVisibility = `-- name: UpdateGoalVisibility :exec
+UPDATE goals
+SET visibility = ?
+WHERE id = ?
+`
+
+type UpdateGoalVisibilityParams struct {
+	Visibility string `json:"visibility"`
+	ID         int64  `json:"id"`
+}
+
+func (q *Queries) UpdateGoalVisibility(ctx context.Context, arg UpdateGoalVisibilityParams) error {
+	_, err := q.db.ExecContext(ctx, updateGoalVisibility, arg.Visibility, arg.ID)
+	return err
+}
+
+const updateGuildTimezone = `-- name: UpdateGuildTimezone :one
+UPDATE guilds
+SET timezone = ?
+WHERE guild_id = ?
+RETURNING guild_id, timezone, owner_id, created_at
+`
+
+type UpdateGuildTimezoneParams struct {
+	Timezone string `json:"timezone"`
+	GuildID  string `json:"guild_id"`
+}
+
+func (q *Queries) UpdateGuildTimezone(ctx context.Context, arg UpdateGuildTimezoneParams) (Guild, error) {
+	row := q.db.QueryRowContext(ctx, updateGuildTimezone, arg.Timezone, arg.GuildID)
+	var i Guild
+	err := row.Scan(
+		&i.GuildID,
+		&i.Timezone,
+		&i.OwnerID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const updateObjectiveStatus = `-- name: UpdateObjectiveStatus :exec
+UPDATE objectives
+SET status = ?, updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+`
+
+type UpdateObjectiveStatusParams struct {
+	Status string `json:"status"`
+	ID     int64  `json:"id"`
+}
+
+func (q *Queries) UpdateObjectiveStatus(ctx context.Context, arg UpdateObjectiveStatusParams) error {
+	_, err := q.db.ExecContext(ctx, updateObjectiveStatus, arg.Status, arg.ID)
+	return err
+}
+
+const updateWebhookDelivery = `-- name: UpdateWebhookDelivery :exec
+UPDATE webhook_deliveries
+SET status = ?, attempts = ?, response_status = ?, last_error = ?, next_attempt_at = ?, delivered_at = ?
+WHERE id = ?
+`
+
+type UpdateWebhookDeliveryParams struct {
+	Status         string         `json:"status"`
+	Attempts       int64          `json:"attempts"`
+	ResponseStatus sql.NullInt64  `json:"response_status"`
+	LastError      sql.NullString `json:"last_error"`
+	NextAttemptAt  string         `json:"next_attempt_at"`
+	DeliveredAt    sql.NullTime   `json:"delivered_at"`
+	ID             int64          `json:"id"`
+}
+
+func (q *Queries) UpdateWebhookDelivery(ctx context.Context, arg UpdateWebhookDeliveryParams) error {
+	_, err := q.db.ExecContext(ctx, updateWebhookDelivery,
+		arg.Status,
+		arg.Attempts,
+		arg.ResponseStatus,
+		arg.LastError,
+		arg.NextAttemptAt,
+		arg.DeliveredAt,
+		arg.ID,
+	)
+	return err
+}
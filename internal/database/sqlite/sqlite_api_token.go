@@ -0,0 +1,48 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/charmbracelet/log"
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+)
+
+func (db *SqliteDatabase) CreateApiToken(ctx context.Context, params queries.CreateApiTokenParams) (*queries.ApiToken, error) {
+	record, err := db.queries.CreateApiToken(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	log.Info("Created API token", "id", record.ID, "guild_id", record.GuildID, "name", record.Name, "created_by", record.CreatedBy, "scope", record.Scope, "discord_user", record.DiscordUser)
+	return &record, nil
+}
+
+func (db *SqliteDatabase) GetApiTokenByHash(ctx context.Context, tokenHash string) (*queries.ApiToken, error) {
+	record, err := db.queries.GetApiTokenByHash(ctx, tokenHash)
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (db *SqliteDatabase) ListApiTokensByGuild(ctx context.Context, guildID string) ([]queries.ApiToken, error) {
+	records, err := db.queries.ListApiTokensByGuild(ctx, guildID)
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+func (db *SqliteDatabase) DeleteApiToken(ctx context.Context, params queries.DeleteApiTokenParams) (int64, error) {
+	deleted, err := db.queries.DeleteApiToken(ctx, params)
+	if err != nil {
+		return 0, err
+	}
+	if deleted > 0 {
+		log.Info("Deleted API token", "id", params.ID, "guild_id", params.GuildID)
+	}
+	return deleted, nil
+}
+
+func (db *SqliteDatabase) TouchApiToken(ctx context.Context, id int64) error {
+	return db.queries.TouchApiToken(ctx, id)
+}
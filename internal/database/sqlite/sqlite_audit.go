@@ -0,0 +1,29 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/charmbracelet/log"
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+	"github.com/metruzanca/checkpoint-bot/internal/events"
+)
+
+func (db *SqliteDatabase) CreateAuditEntry(ctx context.Context, params queries.CreateAuditEntryParams) (*queries.AuditLog, error) {
+	record, err := db.queries.CreateAuditEntry(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info("Recorded audit entry", "id", record.ID, "guild_id", record.GuildID, "actor", record.ActorID, "action", record.Action, "target_type", record.TargetType, "target_id", record.TargetID)
+	db.events.Publish(events.NewAuditEvent(record))
+
+	return &record, nil
+}
+
+func (db *SqliteDatabase) ListAuditEntries(ctx context.Context, params queries.ListAuditEntriesParams) ([]queries.AuditLog, error) {
+	records, err := db.queries.ListAuditEntries(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
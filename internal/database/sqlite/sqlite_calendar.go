@@ -0,0 +1,20 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/charmbracelet/log"
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+)
+
+func (db *SqliteDatabase) SetCalendarToken(ctx context.Context, params queries.SetCalendarTokenParams) error {
+	if err := db.queries.SetCalendarToken(ctx, params); err != nil {
+		return err
+	}
+	log.Info("Set calendar token", "guild_id", params.GuildID, "created_by", params.CreatedBy)
+	return nil
+}
+
+func (db *SqliteDatabase) GetCalendarTokenHash(ctx context.Context, guildID string) (string, error) {
+	return db.queries.GetCalendarTokenHash(ctx, guildID)
+}
@@ -2,9 +2,11 @@ package sqlite
 
 import (
 	"context"
+	"database/sql"
 
 	"github.com/charmbracelet/log"
 	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+	"github.com/metruzanca/checkpoint-bot/internal/events"
 )
 
 // Implementations for CheckpointDatabase interface
@@ -16,6 +18,39 @@ func (db *SqliteDatabase) CreateCheckpoint(ctx context.Context, params queries.C
 	}
 
 	log.Info("Created checkpoint", "id", record.ID, "channel_id", record.ChannelID, "guild_id", record.GuildID, "discord_user", record.DiscordUser, "scheduled_at", record.ScheduledAt)
+	db.events.Publish(events.NewCheckpointEvent(events.CheckpointCreated, record))
+
+	return &record, nil
+}
+
+func (db *SqliteDatabase) GetCheckpoint(ctx context.Context, id int64) (*queries.Checkpoint, error) {
+	record, err := db.queries.GetCheckpoint(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (db *SqliteDatabase) RescheduleCheckpoint(ctx context.Context, params queries.RescheduleCheckpointParams) (*queries.Checkpoint, error) {
+	record, err := db.queries.RescheduleCheckpoint(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info("Rescheduled checkpoint", "id", record.ID, "channel_id", record.ChannelID, "guild_id", record.GuildID, "scheduled_at", record.ScheduledAt, "sequence", record.Sequence)
+	db.events.Publish(events.NewCheckpointEvent(events.CheckpointRescheduled, record))
+
+	return &record, nil
+}
+
+func (db *SqliteDatabase) DeleteCheckpoint(ctx context.Context, id int64) (*queries.Checkpoint, error) {
+	record, err := db.queries.DeleteCheckpoint(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info("Deleted checkpoint", "id", record.ID, "channel_id", record.ChannelID, "guild_id", record.GuildID, "scheduled_at", record.ScheduledAt)
+	db.events.Publish(events.NewCheckpointEvent(events.CheckpointCancelled, record))
 
 	return &record, nil
 }
@@ -29,11 +64,27 @@ func (db *SqliteDatabase) GetUpcomingCheckpoints(ctx context.Context) ([]queries
 }
 
 func (db *SqliteDatabase) MarkAttendance(ctx context.Context, params queries.MarkAttendanceParams) error {
-	err := db.queries.MarkAttendance(ctx, params)
-	if err != nil {
+	// CreateAttendance tells whether a row was inserted, so repeated calls don't emit events
+	_, err := db.queries.CreateAttendance(ctx, queries.CreateAttendanceParams(params))
+	if err == sql.ErrNoRows {
+		return nil
+	} else if err != nil {
 		return err
 	}
 	log.Info("Marked attendance", "discord_user", params.DiscordUser, "checkpoint_id", params.CheckpointID)
+	db.publishMemberEvent(ctx, events.AttendanceRecorded, params.CheckpointID, params.DiscordUser)
+	return nil
+}
+
+func (db *SqliteDatabase) CreateRsvp(ctx context.Context, params queries.CreateRsvpParams) error {
+	_, err := db.queries.CreateRsvp(ctx, params)
+	if err == sql.ErrNoRows {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	log.Info("Created RSVP", "discord_user", params.DiscordUser, "checkpoint_id", params.CheckpointID)
+	db.publishMemberEvent(ctx, events.RsvpCreated, params.CheckpointID, params.DiscordUser)
 	return nil
 }
 
@@ -43,24 +94,33 @@ func (db *SqliteDatabase) CreateGoal(ctx context.Context, params queries.CreateG
 		return nil, err
 	}
 	log.Info("Created goal", "goal_id", record.ID, "discord_user", record.DiscordUser, "checkpoint_id", record.CheckpointID)
+	db.publishGoalEvent(ctx, events.GoalCreated, record.CheckpointID, record.DiscordUser, "")
 	return &record, nil
 }
 
 func (db *SqliteDatabase) CompleteGoal(ctx context.Context, params queries.CompleteGoalParams) error {
+	previousStatus := db.goalStatus(ctx, params.CheckpointID, params.DiscordUser)
 	err := db.queries.CompleteGoal(ctx, params)
 	if err != nil {
 		return err
 	}
 	log.Info("Completed goal", "discord_user", params.DiscordUser, "checkpoint_id", params.CheckpointID)
+	if previousStatus != "completed" {
+		db.publishGoalEvent(ctx, events.GoalStatusChanged, params.CheckpointID, params.DiscordUser, previousStatus)
+	}
 	return nil
 }
 
 func (db *SqliteDatabase) FailedGoal(ctx context.Context, params queries.FailedGoalParams) error {
+	previousStatus := db.goalStatus(ctx, params.CheckpointID, params.DiscordUser)
 	err := db.queries.FailedGoal(ctx, params)
 	if err != nil {
 		return err
 	}
 	log.Info("Failed goal", "discord_user", params.DiscordUser, "checkpoint_id", params.CheckpointID)
+	if previousStatus != "failed" {
+		db.publishGoalEvent(ctx, events.GoalStatusChanged, params.CheckpointID, params.DiscordUser, previousStatus)
+	}
 	return nil
 }
 
@@ -72,6 +132,14 @@ func (db *SqliteDatabase) GetGuild(ctx context.Context, guildID string) (*querie
 	return &record, nil
 }
 
+func (db *SqliteDatabase) GetGuildTotals(ctx context.Context) ([]queries.GetGuildTotalsRow, error) {
+	records, err := db.queries.GetGuildTotals(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
 func (db *SqliteDatabase) CreateGuild(ctx context.Context, params queries.CreateGuildParams) (*queries.Guild, error) {
 	record, err := db.queries.CreateGuild(ctx, params)
 	if err != nil {
@@ -81,6 +149,15 @@ func (db *SqliteDatabase) CreateGuild(ctx context.Context, params queries.Create
 	return &record, nil
 }
 
+func (db *SqliteDatabase) UpdateGuildTimezone(ctx context.Context, params queries.UpdateGuildTimezoneParams) (*queries.Guild, error) {
+	record, err := db.queries.UpdateGuildTimezone(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	log.Info("Updated guild timezone", "guild_id", record.GuildID, "timezone", record.Timezone)
+	return &record, nil
+}
+
 func (db *SqliteDatabase) GetCheckpointByScheduledAtAndChannel(ctx context.Context, params queries.GetCheckpointByScheduledAtAndChannelParams) (*queries.Checkpoint, error) {
 	record, err := db.queries.GetCheckpointByScheduledAtAndChannel(ctx, params)
 	if err != nil {
@@ -97,6 +174,14 @@ func (db *SqliteDatabase) GetPastCheckpointsByChannel(ctx context.Context, chann
 	return records, nil
 }
 
+func (db *SqliteDatabase) GetPastCheckpointsByChannelAndTag(ctx context.Context, params queries.GetPastCheckpointsByChannelAndTagParams) ([]queries.GetPastCheckpointsByChannelAndTagRow, error) {
+	records, err := db.queries.GetPastCheckpointsByChannelAndTag(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
 func (db *SqliteDatabase) GetUpcomingCheckpointByGuildAndChannel(ctx context.Context, params queries.GetUpcomingCheckpointByGuildAndChannelParams) (*queries.Checkpoint, error) {
 	record, err := db.queries.GetUpcomingCheckpointByGuildAndChannel(ctx, params)
 	if err != nil {
@@ -105,6 +190,22 @@ func (db *SqliteDatabase) GetUpcomingCheckpointByGuildAndChannel(ctx context.Con
 	return &record, nil
 }
 
+func (db *SqliteDatabase) GetGoal(ctx context.Context, id int64) (*queries.Goal, error) {
+	record, err := db.queries.GetGoal(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (db *SqliteDatabase) GetUpcomingGoalsByGuildAndUser(ctx context.Context, params queries.GetUpcomingGoalsByGuildAndUserParams) ([]queries.Goal, error) {
+	records, err := db.queries.GetUpcomingGoalsByGuildAndUser(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
 func (db *SqliteDatabase) GetGoalByCheckpointAndUser(ctx context.Context, params queries.GetGoalByCheckpointAndUserParams) (*queries.Goal, error) {
 	record, err := db.queries.GetGoalByCheckpointAndUser(ctx, params)
 	if err != nil {
@@ -119,15 +220,48 @@ func (db *SqliteDatabase) UpdateGoalDescription(ctx context.Context, params quer
 		return err
 	}
 	log.Info("Updated goal description", "checkpoint_id", params.CheckpointID, "discord_user", params.DiscordUser)
+	db.publishGoalUpdated(ctx, params.CheckpointID, params.DiscordUser, "description")
 	return nil
 }
 
 func (db *SqliteDatabase) UpdateGoalStatus(ctx context.Context, params queries.UpdateGoalStatusParams) error {
+	previousStatus := db.goalStatus(ctx, params.CheckpointID, params.DiscordUser)
 	err := db.queries.UpdateGoalStatus(ctx, params)
 	if err != nil {
 		return err
 	}
 	log.Info("Updated goal status", "checkpoint_id", params.CheckpointID, "discord_user", params.DiscordUser, "status", params.Status)
+	if previousStatus != params.Status {
+		db.publishGoalEvent(ctx, events.GoalStatusChanged, params.CheckpointID, params.DiscordUser, previousStatus)
+	}
+	return nil
+}
+
+func (db *SqliteDatabase) UpdateGoalProgress(ctx context.Context, params queries.UpdateGoalProgressParams) error {
+	err := db.queries.UpdateGoalProgress(ctx, params)
+	if err != nil {
+		return err
+	}
+	log.Info("Updated goal progress", "checkpoint_id", params.CheckpointID, "discord_user", params.DiscordUser, "progress", params.Progress)
+	db.publishGoalUpdated(ctx, params.CheckpointID, params.DiscordUser, "progress")
+	return nil
+}
+
+func (db *SqliteDatabase) UpdateGoalTarget(ctx context.Context, params queries.UpdateGoalTargetParams) error {
+	err := db.queries.UpdateGoalTarget(ctx, params)
+	if err != nil {
+		return err
+	}
+	log.Info("Updated goal target", "checkpoint_id", params.CheckpointID, "discord_user", params.DiscordUser, "target", params.Target, "unit", params.Unit)
+	db.publishGoalUpdated(ctx, params.CheckpointID, params.DiscordUser, "target")
+	return nil
+}
+
+func (db *SqliteDatabase) UpdateGoalVisibility(ctx context.Context, params queries.UpdateGoalVisibilityParams) error {
+	if err := db.queries.UpdateGoalVisibility(ctx, params); err != nil {
+		return err
+	}
+	log.Info("Updated goal visibility", "id", params.ID, "visibility", params.Visibility)
 	return nil
 }
 
@@ -146,3 +280,71 @@ func (db *SqliteDatabase) GetGoalsByCheckpoint(ctx context.Context, checkpointID
 	}
 	return records, nil
 }
+
+func (db *SqliteDatabase) ListCheckpoints(ctx context.Context, params queries.ListCheckpointsParams) ([]queries.Checkpoint, error) {
+	records, err := db.queries.ListCheckpoints(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+func (db *SqliteDatabase) GetRsvpsByCheckpoint(ctx context.Context, checkpointID int64) ([]queries.CheckpointRsvp, error) {
+	records, err := db.queries.GetRsvpsByCheckpoint(ctx, checkpointID)
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+func (db *SqliteDatabase) GetAttendanceByCheckpoint(ctx context.Context, checkpointID int64) ([]queries.Attendance, error) {
+	records, err := db.queries.GetAttendanceByCheckpoint(ctx, checkpointID)
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+func (db *SqliteDatabase) GetUserGoalStats(ctx context.Context, params queries.GetUserGoalStatsParams) (*queries.GetUserGoalStatsRow, error) {
+	record, err := db.queries.GetUserGoalStats(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (db *SqliteDatabase) GetUserGoalStatsByTag(ctx context.Context, params queries.GetUserGoalStatsByTagParams) (*queries.GetUserGoalStatsByTagRow, error) {
+	record, err := db.queries.GetUserGoalStatsByTag(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (db *SqliteDatabase) GetUserTagStats(ctx context.Context, params queries.GetUserTagStatsParams) ([]queries.GetUserTagStatsRow, error) {
+	records, err := db.queries.GetUserTagStats(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+func (db *SqliteDatabase) GetUserAttendanceCount(ctx context.Context, params queries.GetUserAttendanceCountParams) (int64, error) {
+	return db.queries.GetUserAttendanceCount(ctx, params)
+}
+
+func (db *SqliteDatabase) GetCheckpointsScheduledBetween(ctx context.Context, params queries.GetCheckpointsScheduledBetweenParams) ([]queries.Checkpoint, error) {
+	records, err := db.queries.GetCheckpointsScheduledBetween(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+func (db *SqliteDatabase) GetGuildUserStats(ctx context.Context, guildID string) ([]queries.GetGuildUserStatsRow, error) {
+	records, err := db.queries.GetGuildUserStats(ctx, guildID)
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
@@ -1,17 +1,21 @@
 package sqlite
 
 import (
+	"context"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
-// setupTestDB creates an in-memory SQLite database for testing
+// setupTestDB creates a temporary SQLite database for testing
 func setupTestDB(t *testing.T) *SqliteDatabase {
 	t.Helper()
 
-	// Use in-memory database
-	db := NewSqliteDatabase(":memory:")
+	// A file rather than :memory:, which would give every pooled connection its own empty database
+	db := NewSqliteDatabase(filepath.Join(t.TempDir(), "checkpoint.db"))
 	require.NotNil(t, db, "Failed to create test database")
 
 	return db
@@ -23,3 +27,21 @@ func TestTemplate(t *testing.T) {
 	defer db.Close()
 	// ctx := context.Background()
 }
+
+// TestPing_Busy tests that the readiness probe waits for a concurrent write instead of failing
+func TestPing_Busy(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	ctx := context.Background()
+
+	tx, err := db.db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	_, err = tx.ExecContext(ctx, "INSERT INTO guilds (guild_id, timezone, owner_id) VALUES ('10', 'UTC', '20')")
+	require.NoError(t, err)
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		tx.Commit()
+	}()
+
+	assert.NoError(t, db.Ping(ctx))
+}
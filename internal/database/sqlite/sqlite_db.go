@@ -11,6 +11,8 @@ import (
 	"github.com/metruzanca/checkpoint-bot/internal/database"
 	"github.com/metruzanca/checkpoint-bot/internal/database/migrations"
 	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+	"github.com/metruzanca/checkpoint-bot/internal/events"
+	"github.com/metruzanca/checkpoint-bot/internal/metrics"
 	sqlite "modernc.org/sqlite"
 )
 
@@ -22,6 +24,8 @@ type SqliteDatabase struct {
 	queries *queries.Queries
 	// connection instance
 	db *sql.DB
+	// events published after successful writes
+	events *events.Bus
 }
 
 func NewSqliteDatabase(dbPath string) *SqliteDatabase {
@@ -29,13 +33,17 @@ func NewSqliteDatabase(dbPath string) *SqliteDatabase {
 		log.Fatal("Error creating database directory", "err", err)
 	}
 
-	// foreign_keys needs to be set on each connection
+	// foreign_keys and busy_timeout need to be set on each connection
 	sqlite.RegisterConnectionHook(func(conn sqlite.ExecQuerierContext, dsn string) error {
 		ctx := context.Background()
 		// Set connection-specific PRAGMA statements
 		if _, err := conn.ExecContext(ctx, "PRAGMA foreign_keys = ON;", []driver.NamedValue{}); err != nil {
 			return err
 		}
+		// Wait for other connections' writes instead of failing with SQLITE_BUSY, Ping takes the write lock too
+		if _, err := conn.ExecContext(ctx, "PRAGMA busy_timeout = 5000;", []driver.NamedValue{}); err != nil {
+			return err
+		}
 
 		return nil
 	})
@@ -62,9 +70,49 @@ func NewSqliteDatabase(dbPath string) *SqliteDatabase {
 	}
 
 	return &SqliteDatabase{
-		queries: queries.New(sqliteDB),
+		queries: queries.New(metrics.InstrumentDB(sqliteDB)),
 		db:      sqliteDB,
+		events:  events.NewBus(),
+	}
+}
+
+func (db *SqliteDatabase) Events() *events.Bus {
+	return db.events
+}
+
+func (db *SqliteDatabase) WithTx(ctx context.Context, fn func(q *queries.Queries) error) error {
+	tx, err := db.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(queries.New(metrics.InstrumentDB(tx))); err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			log.Error("Error rolling back transaction", "err", rollbackErr)
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (db *SqliteDatabase) Ping(ctx context.Context) error {
+	if err := db.db.PingContext(ctx); err != nil {
+		return err
+	}
+
+	// BEGIN IMMEDIATE takes the write lock, so it fails on read-only or stuck databases
+	conn, err := db.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "BEGIN IMMEDIATE;"); err != nil {
+		return err
 	}
+	_, err = conn.ExecContext(ctx, "ROLLBACK;")
+	return err
 }
 
 func (db *SqliteDatabase) Close() error {
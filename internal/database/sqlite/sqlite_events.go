@@ -0,0 +1,68 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/charmbracelet/log"
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+	"github.com/metruzanca/checkpoint-bot/internal/events"
+)
+
+// Helpers loading what events need after a write.
+// The write has already succeeded, so lookup errors are logged and the event is dropped.
+
+// goalStatus returns a goal's current status, or "" if it can't be read
+func (db *SqliteDatabase) goalStatus(ctx context.Context, checkpointID int64, discordUser string) string {
+	goal, err := db.queries.GetGoalByCheckpointAndUser(ctx, queries.GetGoalByCheckpointAndUserParams{
+		CheckpointID: checkpointID,
+		DiscordUser:  discordUser,
+	})
+	if err != nil {
+		return ""
+	}
+	return goal.Status
+}
+
+func (db *SqliteDatabase) publishGoalEvent(ctx context.Context, t events.Type, checkpointID int64, discordUser string, previousStatus string) {
+	if event, ok := db.goalEvent(ctx, t, checkpointID, discordUser, previousStatus); ok {
+		db.events.Publish(event)
+	}
+}
+
+// publishGoalUpdated publishes goal.updated with the field that changed, see events.GoalData.Changed
+func (db *SqliteDatabase) publishGoalUpdated(ctx context.Context, checkpointID int64, discordUser string, changed string) {
+	event, ok := db.goalEvent(ctx, events.GoalUpdated, checkpointID, discordUser, "")
+	if !ok {
+		return
+	}
+	data := event.Data.(events.GoalData)
+	data.Changed = changed
+	event.Data = data
+	db.events.Publish(event)
+}
+
+func (db *SqliteDatabase) goalEvent(ctx context.Context, t events.Type, checkpointID int64, discordUser string, previousStatus string) (events.Event, bool) {
+	checkpoint, err := db.queries.GetCheckpoint(ctx, checkpointID)
+	if err != nil {
+		log.Error("cannot load checkpoint for event", "err", err, "type", t, "checkpoint_id", checkpointID)
+		return events.Event{}, false
+	}
+	goal, err := db.queries.GetGoalByCheckpointAndUser(ctx, queries.GetGoalByCheckpointAndUserParams{
+		CheckpointID: checkpointID,
+		DiscordUser:  discordUser,
+	})
+	if err != nil {
+		log.Error("cannot load goal for event", "err", err, "type", t, "checkpoint_id", checkpointID, "discord_user", discordUser)
+		return events.Event{}, false
+	}
+	return events.NewGoalEvent(t, checkpoint, goal, previousStatus), true
+}
+
+func (db *SqliteDatabase) publishMemberEvent(ctx context.Context, t events.Type, checkpointID int64, discordUser string) {
+	checkpoint, err := db.queries.GetCheckpoint(ctx, checkpointID)
+	if err != nil {
+		log.Error("cannot load checkpoint for event", "err", err, "type", t, "checkpoint_id", checkpointID)
+		return
+	}
+	db.events.Publish(events.NewMemberEvent(t, checkpoint, discordUser))
+}
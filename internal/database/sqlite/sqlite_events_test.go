@@ -0,0 +1,54 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+	"github.com/metruzanca/checkpoint-bot/internal/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestEvents tests that writes publish events, and only when something changed
+func TestEvents(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+	ctx := context.Background()
+
+	var published []events.Event
+	db.Events().Subscribe(func(e events.Event) { published = append(published, e) })
+
+	_, err := db.CreateGuild(ctx, queries.CreateGuildParams{GuildID: "10", Timezone: "UTC", OwnerID: "20"})
+	require.NoError(t, err)
+	checkpoint, err := db.CreateCheckpoint(ctx, queries.CreateCheckpointParams{ScheduledAt: "2025-01-15T19:00:00Z", ChannelID: "30", GuildID: "10", DiscordUser: "20"})
+	require.NoError(t, err)
+	_, err = db.CreateGoal(ctx, queries.CreateGoalParams{DiscordUser: "40", Description: "Ship it", CheckpointID: checkpoint.ID})
+	require.NoError(t, err)
+
+	status := queries.UpdateGoalStatusParams{Status: "completed", CheckpointID: checkpoint.ID, DiscordUser: "40"}
+	require.NoError(t, db.UpdateGoalStatus(ctx, status))
+	require.NoError(t, db.UpdateGoalStatus(ctx, status))
+
+	attendance := queries.MarkAttendanceParams{DiscordUser: "40", CheckpointID: checkpoint.ID}
+	require.NoError(t, db.MarkAttendance(ctx, attendance))
+	require.NoError(t, db.MarkAttendance(ctx, attendance))
+
+	_, err = db.DeleteCheckpoint(ctx, checkpoint.ID)
+	require.NoError(t, err)
+	goals, err := db.GetGoalsByCheckpoint(ctx, checkpoint.ID)
+	require.NoError(t, err)
+	assert.Empty(t, goals)
+
+	types := make([]events.Type, 0, len(published))
+	for _, e := range published {
+		types = append(types, e.Type)
+		assert.Equal(t, "10", e.GuildID)
+	}
+	assert.Equal(t, []events.Type{events.CheckpointCreated, events.GoalCreated, events.GoalStatusChanged, events.AttendanceRecorded, events.CheckpointCancelled}, types)
+
+	goal := published[2].Data.(events.GoalData)
+	assert.Equal(t, "incomplete", goal.PreviousStatus)
+	assert.Equal(t, "completed", goal.Status)
+	assert.Equal(t, "30", goal.ChannelID)
+}
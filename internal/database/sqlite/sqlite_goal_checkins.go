@@ -0,0 +1,36 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/charmbracelet/log"
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+	"github.com/metruzanca/checkpoint-bot/internal/events"
+)
+
+func (db *SqliteDatabase) CreateGoalCheckin(ctx context.Context, params queries.CreateGoalCheckinParams) (*queries.GoalCheckin, error) {
+	record, err := db.queries.CreateGoalCheckin(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	log.Info("Created goal check-in", "id", record.ID, "goal_id", record.GoalID, "amount", record.Amount, "logged_by", record.LoggedBy)
+	return &record, nil
+}
+
+func (db *SqliteDatabase) AddGoalTotal(ctx context.Context, params queries.AddGoalTotalParams) (*queries.Goal, error) {
+	record, err := db.queries.AddGoalTotal(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	log.Info("Added to goal total", "goal_id", record.ID, "amount", params.Amount, "total", record.Total)
+	db.publishGoalEvent(ctx, events.GoalCheckedIn, record.CheckpointID, record.DiscordUser, "")
+	return &record, nil
+}
+
+func (db *SqliteDatabase) GetGoalCheckins(ctx context.Context, goalID int64) ([]queries.GoalCheckin, error) {
+	records, err := db.queries.GetGoalCheckins(ctx, goalID)
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
@@ -0,0 +1,33 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/charmbracelet/log"
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+)
+
+func (db *SqliteDatabase) CreateGoalEvidence(ctx context.Context, params queries.CreateGoalEvidenceParams) (*queries.GoalEvidence, error) {
+	record, err := db.queries.CreateGoalEvidence(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	log.Info("Created goal evidence", "id", record.ID, "goal_id", record.GoalID, "kind", record.Kind, "added_by", record.AddedBy)
+	return &record, nil
+}
+
+func (db *SqliteDatabase) GetGoalEvidence(ctx context.Context, goalID int64) ([]queries.GoalEvidence, error) {
+	records, err := db.queries.GetGoalEvidence(ctx, goalID)
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+func (db *SqliteDatabase) GetGoalEvidenceByCheckpoint(ctx context.Context, checkpointID int64) ([]queries.GetGoalEvidenceByCheckpointRow, error) {
+	records, err := db.queries.GetGoalEvidenceByCheckpoint(ctx, checkpointID)
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
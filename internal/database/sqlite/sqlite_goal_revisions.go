@@ -0,0 +1,33 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/charmbracelet/log"
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+)
+
+func (db *SqliteDatabase) CreateGoalRevision(ctx context.Context, params queries.CreateGoalRevisionParams) (*queries.GoalRevision, error) {
+	record, err := db.queries.CreateGoalRevision(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	log.Info("Created goal revision", "id", record.ID, "goal_id", record.GoalID, "edited_by", record.EditedBy, "status", record.Status)
+	return &record, nil
+}
+
+func (db *SqliteDatabase) GetGoalRevisions(ctx context.Context, goalID int64) ([]queries.GoalRevision, error) {
+	records, err := db.queries.GetGoalRevisions(ctx, goalID)
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+func (db *SqliteDatabase) GetEditedGoalIDsByCheckpoint(ctx context.Context, checkpointID int64) ([]int64, error) {
+	ids, err := db.queries.GetEditedGoalIDsByCheckpoint(ctx, checkpointID)
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
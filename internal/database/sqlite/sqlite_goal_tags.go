@@ -0,0 +1,43 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/charmbracelet/log"
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+)
+
+func (db *SqliteDatabase) SetGoalTags(ctx context.Context, goalID int64, tags []string) error {
+	err := db.WithTx(ctx, func(q *queries.Queries) error {
+		if err := q.DeleteGoalTags(ctx, goalID); err != nil {
+			return err
+		}
+		for _, tag := range tags {
+			if err := q.CreateGoalTag(ctx, queries.CreateGoalTagParams{GoalID: goalID, Tag: tag}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	log.Info("Set goal tags", "goal_id", goalID, "tags", tags)
+	return nil
+}
+
+func (db *SqliteDatabase) GetGoalTags(ctx context.Context, goalID int64) ([]string, error) {
+	tags, err := db.queries.GetGoalTags(ctx, goalID)
+	if err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+func (db *SqliteDatabase) GetGoalTagsByCheckpoint(ctx context.Context, checkpointID int64) ([]queries.GoalTag, error) {
+	records, err := db.queries.GetGoalTagsByCheckpoint(ctx, checkpointID)
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
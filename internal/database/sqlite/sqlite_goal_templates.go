@@ -0,0 +1,48 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/charmbracelet/log"
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+)
+
+func (db *SqliteDatabase) SaveGoalTemplate(ctx context.Context, params queries.SaveGoalTemplateParams) (*queries.GoalTemplate, error) {
+	template, err := db.queries.SaveGoalTemplate(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	log.Info("Saved goal template", "id", template.ID, "guild_id", template.GuildID, "discord_user", template.DiscordUser, "name", template.Name)
+	return &template, nil
+}
+
+func (db *SqliteDatabase) GetGoalTemplate(ctx context.Context, id int64) (*queries.GoalTemplate, error) {
+	template, err := db.queries.GetGoalTemplate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+func (db *SqliteDatabase) GetGoalTemplatesByGuildAndUser(ctx context.Context, params queries.GetGoalTemplatesByGuildAndUserParams) ([]queries.GoalTemplate, error) {
+	templates, err := db.queries.GetGoalTemplatesByGuildAndUser(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+func (db *SqliteDatabase) IncrementGoalTemplateUses(ctx context.Context, id int64) error {
+	return db.queries.IncrementGoalTemplateUses(ctx, id)
+}
+
+func (db *SqliteDatabase) DeleteGoalTemplate(ctx context.Context, id int64) (int64, error) {
+	rows, err := db.queries.DeleteGoalTemplate(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+	if rows > 0 {
+		log.Info("Deleted goal template", "id", id)
+	}
+	return rows, nil
+}
@@ -0,0 +1,57 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/charmbracelet/log"
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+)
+
+func (db *SqliteDatabase) CreateGoalUpdate(ctx context.Context, params queries.CreateGoalUpdateParams) (*queries.GoalUpdate, error) {
+	record, err := db.queries.CreateGoalUpdate(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	log.Info("Created goal update", "id", record.ID, "goal_id", record.GoalID, "discord_user", record.DiscordUser)
+	return &record, nil
+}
+
+func (db *SqliteDatabase) GetGoalUpdatesByCheckpoint(ctx context.Context, checkpointID int64) ([]queries.GoalUpdate, error) {
+	records, err := db.queries.GetGoalUpdatesByCheckpoint(ctx, checkpointID)
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+func (db *SqliteDatabase) SaveUpdatePrompt(ctx context.Context, params queries.SaveUpdatePromptParams) (*queries.UpdatePrompt, error) {
+	record, err := db.queries.SaveUpdatePrompt(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	log.Info("Saved update prompt", "guild_id", record.GuildID, "discord_user", record.DiscordUser, "interval_days", record.IntervalDays, "next_prompt_at", record.NextPromptAt)
+	return &record, nil
+}
+
+func (db *SqliteDatabase) DeleteUpdatePrompt(ctx context.Context, params queries.DeleteUpdatePromptParams) (int64, error) {
+	deleted, err := db.queries.DeleteUpdatePrompt(ctx, params)
+	if err != nil {
+		return 0, err
+	}
+	if deleted > 0 {
+		log.Info("Deleted update prompt", "guild_id", params.GuildID, "discord_user", params.DiscordUser)
+	}
+	return deleted, nil
+}
+
+func (db *SqliteDatabase) GetDueUpdatePrompts(ctx context.Context, now string) ([]queries.UpdatePrompt, error) {
+	records, err := db.queries.GetDueUpdatePrompts(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+func (db *SqliteDatabase) SetUpdatePromptNext(ctx context.Context, params queries.SetUpdatePromptNextParams) error {
+	return db.queries.SetUpdatePromptNext(ctx, params)
+}
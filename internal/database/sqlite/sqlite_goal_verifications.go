@@ -0,0 +1,31 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/charmbracelet/log"
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+)
+
+func (db *SqliteDatabase) CreateGoalVerification(ctx context.Context, params queries.CreateGoalVerificationParams) (int64, error) {
+	created, err := db.queries.CreateGoalVerification(ctx, params)
+	if err != nil {
+		return 0, err
+	}
+	if created > 0 {
+		log.Info("Created goal verification", "goal_id", params.GoalID, "discord_user", params.DiscordUser)
+	}
+	return created, nil
+}
+
+func (db *SqliteDatabase) GetGoalVerifications(ctx context.Context, goalID int64) ([]queries.GoalVerification, error) {
+	records, err := db.queries.GetGoalVerifications(ctx, goalID)
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+func (db *SqliteDatabase) DeleteGoalVerifications(ctx context.Context, goalID int64) error {
+	return db.queries.DeleteGoalVerifications(ctx, goalID)
+}
@@ -0,0 +1,69 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/charmbracelet/log"
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+)
+
+func (db *SqliteDatabase) CreateObjective(ctx context.Context, params queries.CreateObjectiveParams) (*queries.Objective, error) {
+	objective, err := db.queries.CreateObjective(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	log.Info("Created objective", "id", objective.ID, "guild_id", objective.GuildID, "discord_user", objective.DiscordUser, "deadline", objective.Deadline)
+	return &objective, nil
+}
+
+func (db *SqliteDatabase) GetObjective(ctx context.Context, id int64) (*queries.Objective, error) {
+	objective, err := db.queries.GetObjective(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &objective, nil
+}
+
+func (db *SqliteDatabase) GetObjectivesByGuildAndUser(ctx context.Context, params queries.GetObjectivesByGuildAndUserParams) ([]queries.GetObjectivesByGuildAndUserRow, error) {
+	records, err := db.queries.GetObjectivesByGuildAndUser(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+func (db *SqliteDatabase) UpdateObjectiveStatus(ctx context.Context, params queries.UpdateObjectiveStatusParams) error {
+	if err := db.queries.UpdateObjectiveStatus(ctx, params); err != nil {
+		return err
+	}
+	log.Info("Updated objective status", "id", params.ID, "status", params.Status)
+	return nil
+}
+
+func (db *SqliteDatabase) DeleteObjective(ctx context.Context, id int64) (int64, error) {
+	rows, err := db.queries.DeleteObjective(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+	if rows > 0 {
+		log.Info("Deleted objective", "id", id)
+	}
+	return rows, nil
+}
+
+func (db *SqliteDatabase) UpdateGoalObjective(ctx context.Context, params queries.UpdateGoalObjectiveParams) error {
+	if err := db.queries.UpdateGoalObjective(ctx, params); err != nil {
+		return err
+	}
+	log.Info("Updated goal objective", "goal_id", params.ID, "objective_id", params.ObjectiveID.Int64)
+	return nil
+}
+
+func (db *SqliteDatabase) GetGoalsByObjective(ctx context.Context, objectiveID int64) ([]queries.Goal, error) {
+	goals, err := db.queries.GetGoalsByObjective(ctx, sql.NullInt64{Int64: objectiveID, Valid: true})
+	if err != nil {
+		return nil, err
+	}
+	return goals, nil
+}
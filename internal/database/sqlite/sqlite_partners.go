@@ -0,0 +1,108 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/charmbracelet/log"
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+	"github.com/metruzanca/checkpoint-bot/internal/events"
+)
+
+func (db *SqliteDatabase) SetPartner(ctx context.Context, params queries.SetPartnerParams) error {
+	err := db.WithTx(ctx, func(q *queries.Queries) error {
+		err := q.DeletePartnerPairs(ctx, queries.DeletePartnerPairsParams{
+			GuildID: params.GuildID,
+			UserA:   params.DiscordUser,
+			UserB:   params.PartnerUser,
+		})
+		if err != nil {
+			return err
+		}
+		if err := q.SetPartner(ctx, params); err != nil {
+			return err
+		}
+		return q.SetPartner(ctx, queries.SetPartnerParams{
+			GuildID:     params.GuildID,
+			DiscordUser: params.PartnerUser,
+			PartnerUser: params.DiscordUser,
+			CreatedBy:   params.CreatedBy,
+		})
+	})
+	if err != nil {
+		return err
+	}
+	log.Info("Set partner", "guild_id", params.GuildID, "discord_user", params.DiscordUser, "partner_user", params.PartnerUser)
+	return nil
+}
+
+func (db *SqliteDatabase) GetPartner(ctx context.Context, params queries.GetPartnerParams) (*queries.Partner, error) {
+	record, err := db.queries.GetPartner(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (db *SqliteDatabase) DeletePartner(ctx context.Context, params queries.DeletePartnerParams) (int64, error) {
+	deleted, err := db.queries.DeletePartner(ctx, params)
+	if err != nil {
+		return 0, err
+	}
+	if deleted > 0 {
+		log.Info("Deleted partner", "guild_id", params.GuildID, "discord_user", params.DiscordUser)
+	}
+	return deleted, nil
+}
+
+func (db *SqliteDatabase) SetCheckpointPartner(ctx context.Context, params queries.SetCheckpointPartnerParams) error {
+	if err := db.queries.SetCheckpointPartner(ctx, params); err != nil {
+		return err
+	}
+	log.Info("Set checkpoint partner", "checkpoint_id", params.CheckpointID, "discord_user", params.DiscordUser, "partner_user", params.PartnerUser)
+	return nil
+}
+
+func (db *SqliteDatabase) GetCheckpointPartner(ctx context.Context, params queries.GetCheckpointPartnerParams) (*queries.CheckpointPartner, error) {
+	record, err := db.queries.GetCheckpointPartner(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (db *SqliteDatabase) GetPreviousCheckpointByChannel(ctx context.Context, params queries.GetPreviousCheckpointByChannelParams) (*queries.Checkpoint, error) {
+	record, err := db.queries.GetPreviousCheckpointByChannel(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (db *SqliteDatabase) CountCheckpointsByChannelBefore(ctx context.Context, params queries.CountCheckpointsByChannelBeforeParams) (int64, error) {
+	return db.queries.CountCheckpointsByChannelBefore(ctx, params)
+}
+
+func (db *SqliteDatabase) UpdateGoalVerification(ctx context.Context, params queries.UpdateGoalVerificationParams) error {
+	if err := db.queries.UpdateGoalVerification(ctx, params); err != nil {
+		return err
+	}
+	log.Info("Updated goal verification", "id", params.ID, "verification", params.Verification, "verified_by", params.VerifiedBy)
+	if params.Verification != "verified" {
+		return nil
+	}
+	goal, err := db.queries.GetGoal(ctx, params.ID)
+	if err != nil {
+		log.Error("cannot load goal for event", "err", err, "type", events.GoalVerified, "id", params.ID)
+		return nil
+	}
+	db.publishGoalEvent(ctx, events.GoalVerified, goal.CheckpointID, goal.DiscordUser, "")
+	return nil
+}
+
+func (db *SqliteDatabase) GetGoalsPendingVerification(ctx context.Context, guildID string) ([]queries.Goal, error) {
+	records, err := db.queries.GetGoalsPendingVerification(ctx, guildID)
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
@@ -0,0 +1,42 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/charmbracelet/log"
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+)
+
+func (db *SqliteDatabase) SavePendingGoal(ctx context.Context, params queries.SavePendingGoalParams) (*queries.PendingGoal, error) {
+	record, err := db.queries.SavePendingGoal(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	log.Info("Saved pending goal", "id", record.ID, "channel_id", record.ChannelID, "discord_user", record.DiscordUser, "updated_by", record.UpdatedBy)
+	return &record, nil
+}
+
+func (db *SqliteDatabase) GetPendingGoal(ctx context.Context, params queries.GetPendingGoalParams) (*queries.PendingGoal, error) {
+	record, err := db.queries.GetPendingGoal(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (db *SqliteDatabase) GetPendingGoalsByChannel(ctx context.Context, channelID string) ([]queries.PendingGoal, error) {
+	records, err := db.queries.GetPendingGoalsByChannel(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+func (db *SqliteDatabase) DeletePendingGoal(ctx context.Context, id int64) error {
+	err := db.queries.DeletePendingGoal(ctx, id)
+	if err != nil {
+		return err
+	}
+	log.Info("Deleted pending goal", "id", id)
+	return nil
+}
@@ -0,0 +1,36 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/charmbracelet/log"
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+)
+
+func (db *SqliteDatabase) GetGuildSettings(ctx context.Context, guildID string) ([]queries.GuildSetting, error) {
+	records, err := db.queries.GetGuildSettings(ctx, guildID)
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+func (db *SqliteDatabase) SetGuildSetting(ctx context.Context, params queries.SetGuildSettingParams) (*queries.GuildSetting, error) {
+	record, err := db.queries.SetGuildSetting(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	log.Info("Updated guild setting", "guild_id", record.GuildID, "key", record.Key, "value", record.Value, "updated_by", record.UpdatedBy)
+	return &record, nil
+}
+
+func (db *SqliteDatabase) DeleteGuildSetting(ctx context.Context, params queries.DeleteGuildSettingParams) (int64, error) {
+	deleted, err := db.queries.DeleteGuildSetting(ctx, params)
+	if err != nil {
+		return 0, err
+	}
+	if deleted > 0 {
+		log.Info("Reset guild setting", "guild_id", params.GuildID, "key", params.Key)
+	}
+	return deleted, nil
+}
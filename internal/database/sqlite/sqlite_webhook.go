@@ -0,0 +1,64 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/charmbracelet/log"
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+)
+
+func (db *SqliteDatabase) CreateWebhook(ctx context.Context, params queries.CreateWebhookParams) (*queries.Webhook, error) {
+	record, err := db.queries.CreateWebhook(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	log.Info("Created webhook", "id", record.ID, "guild_id", record.GuildID, "event_types", record.EventTypes, "created_by", record.CreatedBy)
+	return &record, nil
+}
+
+func (db *SqliteDatabase) ListWebhooksByGuild(ctx context.Context, guildID string) ([]queries.Webhook, error) {
+	records, err := db.queries.ListWebhooksByGuild(ctx, guildID)
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+func (db *SqliteDatabase) DeleteWebhook(ctx context.Context, params queries.DeleteWebhookParams) (int64, error) {
+	deleted, err := db.queries.DeleteWebhook(ctx, params)
+	if err != nil {
+		return 0, err
+	}
+	if deleted > 0 {
+		log.Info("Deleted webhook", "id", params.ID, "guild_id", params.GuildID)
+	}
+	return deleted, nil
+}
+
+func (db *SqliteDatabase) CreateWebhookDelivery(ctx context.Context, params queries.CreateWebhookDeliveryParams) (*queries.WebhookDelivery, error) {
+	record, err := db.queries.CreateWebhookDelivery(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (db *SqliteDatabase) GetDueWebhookDeliveries(ctx context.Context, params queries.GetDueWebhookDeliveriesParams) ([]queries.GetDueWebhookDeliveriesRow, error) {
+	records, err := db.queries.GetDueWebhookDeliveries(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+func (db *SqliteDatabase) UpdateWebhookDelivery(ctx context.Context, params queries.UpdateWebhookDeliveryParams) error {
+	return db.queries.UpdateWebhookDelivery(ctx, params)
+}
+
+func (db *SqliteDatabase) ListWebhookDeliveries(ctx context.Context, params queries.ListWebhookDeliveriesParams) ([]queries.WebhookDelivery, error) {
+	records, err := db.queries.ListWebhookDeliveries(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
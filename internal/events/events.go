@@ -0,0 +1,201 @@
+// events package publishes typed events about checkpoints and goals to in-process subscribers.
+package events
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+)
+
+// Type identifies what happened, it's also sent to webhooks as X-Checkpoint-Event
+type Type string
+
+const (
+	CheckpointCreated     Type = "checkpoint.created"
+	CheckpointRescheduled Type = "checkpoint.rescheduled"
+	CheckpointCancelled   Type = "checkpoint.cancelled"
+	CheckpointStarted     Type = "checkpoint.started"
+	CheckpointEnded       Type = "checkpoint.ended"
+	GoalCreated           Type = "goal.created"
+	GoalUpdated           Type = "goal.updated"
+	GoalStatusChanged     Type = "goal.status_changed"
+	GoalCheckedIn         Type = "goal.checked_in"
+	GoalVerified          Type = "goal.verified"
+	RsvpCreated           Type = "rsvp.created"
+	AttendanceRecorded    Type = "attendance.recorded"
+	AuditRecorded         Type = "audit.recorded"
+)
+
+// Types lists every event type, in the order they're documented
+var Types = []Type{
+	CheckpointCreated, CheckpointRescheduled, CheckpointCancelled, CheckpointStarted, CheckpointEnded,
+	GoalCreated, GoalUpdated, GoalStatusChanged, GoalCheckedIn, GoalVerified, RsvpCreated, AttendanceRecorded, AuditRecorded,
+}
+
+// Event is something that happened in a guild, Data is one of the *Data types below
+type Event struct {
+	ID         string    `json:"id"`
+	Type       Type      `json:"type"`
+	GuildID    string    `json:"guild_id"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Data       any       `json:"data"`
+}
+
+type CheckpointData struct {
+	ID          int64  `json:"id"`
+	ChannelID   string `json:"channel_id"`
+	ScheduledAt string `json:"scheduled_at"`
+	CreatedBy   string `json:"created_by"`
+	Sequence    int64  `json:"sequence"`
+}
+
+type GoalData struct {
+	ID           int64  `json:"id"`
+	CheckpointID int64  `json:"checkpoint_id"`
+	ChannelID    string `json:"channel_id"`
+	UserID       string `json:"user_id"`
+	// Description is empty unless the goal is public, webhooks send events to third parties
+	Description string `json:"description"`
+	Status      string `json:"status"`
+	// Progress is how much of the goal is done, from 0 to 100
+	Progress int64 `json:"progress"`
+	// Target, Unit and Total are set for goals with a target, Total is the sum of their check-ins
+	Target float64 `json:"target,omitempty"`
+	Unit   string  `json:"unit,omitempty"`
+	Total  float64 `json:"total,omitempty"`
+	// PreviousStatus is only set for goal.status_changed
+	PreviousStatus string `json:"previous_status,omitempty"`
+	// Changed is the field changed by a goal.updated: description, progress or target
+	Changed string `json:"changed,omitempty"`
+	// Verification is pending while a completed goal awaits its partner's confirmation, then verified
+	Verification string `json:"verification,omitempty"`
+	VerifiedBy   string `json:"verified_by,omitempty"`
+	// Visibility is public, members or private, see settings.VisibilityMembers
+	Visibility string `json:"visibility"`
+}
+
+// MemberData is the payload of RSVP and attendance events
+type MemberData struct {
+	CheckpointID int64  `json:"checkpoint_id"`
+	ChannelID    string `json:"channel_id"`
+	UserID       string `json:"user_id"`
+}
+
+// AuditData is the payload of audit.recorded, an entry of the audit log
+type AuditData struct {
+	ID         int64  `json:"id"`
+	ActorID    string `json:"actor_id"`
+	Source     string `json:"source"`
+	Action     string `json:"action"`
+	TargetType string `json:"target_type"`
+	TargetID   string `json:"target_id"`
+	TargetUser string `json:"target_user,omitempty"`
+	Before     string `json:"before"`
+	After      string `json:"after"`
+}
+
+// NewCheckpointEvent creates an event about a checkpoint
+func NewCheckpointEvent(t Type, checkpoint queries.Checkpoint) Event {
+	return newEvent(t, checkpoint.GuildID, CheckpointData{
+		ID:          checkpoint.ID,
+		ChannelID:   checkpoint.ChannelID,
+		ScheduledAt: checkpoint.ScheduledAt,
+		CreatedBy:   checkpoint.DiscordUser,
+		Sequence:    checkpoint.Sequence,
+	})
+}
+
+// publicGoal is settings.VisibilityPublic, which can't be imported here since settings depends on the database
+const publicGoal = "public"
+
+// NewGoalEvent creates an event about a goal, previousStatus is only used for GoalStatusChanged
+func NewGoalEvent(t Type, checkpoint queries.Checkpoint, goal queries.Goal, previousStatus string) Event {
+	description := goal.Description
+	if goal.Visibility != publicGoal {
+		description = ""
+	}
+	return newEvent(t, checkpoint.GuildID, GoalData{
+		ID:             goal.ID,
+		CheckpointID:   checkpoint.ID,
+		ChannelID:      checkpoint.ChannelID,
+		UserID:         goal.DiscordUser,
+		Description:    description,
+		Status:         goal.Status,
+		Progress:       goal.Progress,
+		Target:         goal.Target,
+		Unit:           goal.Unit,
+		Total:          goal.Total,
+		PreviousStatus: previousStatus,
+		Verification:   goal.Verification,
+		VerifiedBy:     goal.VerifiedBy,
+		Visibility:     goal.Visibility,
+	})
+}
+
+// NewMemberEvent creates an RSVP or attendance event
+func NewMemberEvent(t Type, checkpoint queries.Checkpoint, userID string) Event {
+	return newEvent(t, checkpoint.GuildID, MemberData{
+		CheckpointID: checkpoint.ID,
+		ChannelID:    checkpoint.ChannelID,
+		UserID:       userID,
+	})
+}
+
+// NewAuditEvent creates an event for a new audit log entry
+func NewAuditEvent(entry queries.AuditLog) Event {
+	return newEvent(AuditRecorded, entry.GuildID, AuditData{
+		ID:         entry.ID,
+		ActorID:    entry.ActorID,
+		Source:     entry.Source,
+		Action:     entry.Action,
+		TargetType: entry.TargetType,
+		TargetID:   entry.TargetID,
+		TargetUser: entry.TargetUser,
+		Before:     entry.BeforeValue,
+		After:      entry.AfterValue,
+	})
+}
+
+func newEvent(t Type, guildID string, data any) Event {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return Event{
+		ID:         hex.EncodeToString(id),
+		Type:       t,
+		GuildID:    guildID,
+		OccurredAt: time.Now().UTC(),
+		Data:       data,
+	}
+}
+
+// Handler receives published events, it runs on the publisher's goroutine so it must not block
+type Handler func(Event)
+
+// Bus fans out events to every subscriber
+type Bus struct {
+	mu       sync.RWMutex
+	handlers []Handler
+}
+
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Subscribe registers a handler for every event published after this call
+func (b *Bus) Subscribe(handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers = append(b.handlers, handler)
+}
+
+// Publish calls every subscriber with the event
+func (b *Bus) Publish(event Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, handler := range b.handlers {
+		handler(event)
+	}
+}
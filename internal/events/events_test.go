@@ -0,0 +1,31 @@
+package events_test
+
+import (
+	"testing"
+
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+	"github.com/metruzanca/checkpoint-bot/internal/events"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestNewGoalEvent tests that only public goals share their text in events
+func TestNewGoalEvent(t *testing.T) {
+	checkpoint := queries.Checkpoint{ID: 1, GuildID: "10", ChannelID: "30"}
+
+	tests := []struct {
+		visibility  string
+		description string
+	}{
+		{"public", "Ship it"},
+		{"members", ""},
+		{"private", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.visibility, func(t *testing.T) {
+			goal := queries.Goal{ID: 2, CheckpointID: 1, DiscordUser: "40", Description: "Ship it", Visibility: tt.visibility}
+			data := events.NewGoalEvent(events.GoalCreated, checkpoint, goal, "").Data.(events.GoalData)
+			assert.Equal(t, tt.description, data.Description)
+			assert.Equal(t, tt.visibility, data.Visibility)
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package events
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+)
+
+// CheckpointSource is the part of the database the Watcher needs
+type CheckpointSource interface {
+	GetCheckpointsScheduledBetween(ctx context.Context, params queries.GetCheckpointsScheduledBetweenParams) ([]queries.Checkpoint, error)
+}
+
+// Watcher publishes checkpoint.started and checkpoint.ended as checkpoints reach their start and end time.
+// Only transitions while the bot is running are reported, there's no backfill after downtime.
+type Watcher struct {
+	db       CheckpointSource
+	bus      *Bus
+	duration time.Duration
+	interval time.Duration
+
+	last time.Time
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewWatcher creates a watcher for checkpoints lasting duration
+func NewWatcher(db CheckpointSource, bus *Bus, duration time.Duration) *Watcher {
+	return &Watcher{
+		db:       db,
+		bus:      bus,
+		duration: duration,
+		interval: 30 * time.Second,
+		stop:     make(chan struct{}),
+	}
+}
+
+// Start polls in the background until Stop is called
+func (w *Watcher) Start() {
+	w.Check(time.Now())
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-w.stop:
+				return
+			case now := <-ticker.C:
+				w.Check(now)
+			}
+		}
+	}()
+}
+
+func (w *Watcher) Stop() {
+	close(w.stop)
+	w.wg.Wait()
+}
+
+// Check publishes the transitions between the previous check and now, the first check only sets the starting point
+func (w *Watcher) Check(now time.Time) {
+	if w.last.IsZero() {
+		w.last = now
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	w.publishBetween(ctx, CheckpointStarted, w.last, now)
+	// A checkpoint ends duration after it starts
+	w.publishBetween(ctx, CheckpointEnded, w.last.Add(-w.duration), now.Add(-w.duration))
+	w.last = now
+}
+
+func (w *Watcher) publishBetween(ctx context.Context, t Type, from time.Time, to time.Time) {
+	checkpoints, err := w.db.GetCheckpointsScheduledBetween(ctx, queries.GetCheckpointsScheduledBetweenParams{
+		From: from.UTC().Format(time.RFC3339),
+		To:   to.UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		log.Error("cannot get checkpoints for lifecycle events", "err", err, "type", t)
+		return
+	}
+	for _, checkpoint := range checkpoints {
+		w.bus.Publish(NewCheckpointEvent(t, checkpoint))
+	}
+}
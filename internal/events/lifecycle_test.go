@@ -0,0 +1,43 @@
+package events_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+	"github.com/metruzanca/checkpoint-bot/internal/database/sqlite"
+	"github.com/metruzanca/checkpoint-bot/internal/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWatcher tests that checkpoints start and end once, as time passes their start and end
+func TestWatcher(t *testing.T) {
+	db := sqlite.NewSqliteDatabase(filepath.Join(t.TempDir(), "checkpoint.db"))
+	t.Cleanup(func() { db.Close() })
+	ctx := context.Background()
+
+	start := time.Date(2025, 1, 15, 19, 0, 0, 0, time.UTC)
+	_, err := db.CreateGuild(ctx, queries.CreateGuildParams{GuildID: "10", Timezone: "Europe/Rome", OwnerID: "20"})
+	require.NoError(t, err)
+	// Stored in the guild's timezone, like the commands do
+	_, err = db.CreateCheckpoint(ctx, queries.CreateCheckpointParams{ScheduledAt: "2025-01-15T20:00:00+01:00", ChannelID: "30", GuildID: "10", DiscordUser: "20"})
+	require.NoError(t, err)
+
+	bus := events.NewBus()
+	var published []events.Type
+	bus.Subscribe(func(e events.Event) { published = append(published, e.Type) })
+
+	watcher := events.NewWatcher(db, bus, time.Hour)
+	watcher.Check(start.Add(-time.Minute))
+	assert.Empty(t, published)
+
+	watcher.Check(start)
+	watcher.Check(start.Add(30 * time.Second))
+	assert.Equal(t, []events.Type{events.CheckpointStarted}, published)
+
+	watcher.Check(start.Add(time.Hour + time.Minute))
+	assert.Equal(t, []events.Type{events.CheckpointStarted, events.CheckpointEnded}, published)
+}
@@ -0,0 +1,215 @@
+// history package builds full exports of a guild's checkpoint history.
+package history
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/metruzanca/checkpoint-bot/internal/database"
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+	"github.com/metruzanca/checkpoint-bot/internal/util"
+)
+
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+)
+
+// Filter narrows down which checkpoints are exported.
+// Dates are YYYY-MM-DD and interpreted in the guild's timezone, both ends inclusive.
+type Filter struct {
+	GuildID   string
+	ChannelID string
+	FromDate  string
+	ToDate    string
+}
+
+// Export is a complete dump of a guild's history, built from the sqlc models
+type Export struct {
+	Guild       queries.Guild            `json:"guild"`
+	Checkpoints []queries.Checkpoint     `json:"checkpoints"`
+	Goals       []queries.Goal           `json:"goals"`
+	Rsvps       []queries.CheckpointRsvp `json:"rsvps"`
+	Attendance  []queries.Attendance     `json:"attendance"`
+}
+
+// csvHeader is shared by every row of a CSV export, the record_type column tells rows apart
+var csvHeader = []string{
+	"record_type", "id", "guild_id", "channel_id", "checkpoint_id", "scheduled_at",
+	"discord_user", "description", "status", "timezone", "created_at",
+}
+
+// Build collects the guild, its checkpoints and everything attached to them
+func Build(ctx context.Context, db database.CheckpointDatabase, filter Filter) (*Export, error) {
+	guild, err := db.GetGuild(ctx, filter.GuildID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("guild %s not found", filter.GuildID)
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot get guild: %w", err)
+	}
+
+	params, err := ListParams(*guild, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	checkpoints, err := db.ListCheckpoints(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list checkpoints: %w", err)
+	}
+
+	export := &Export{
+		Guild:       *guild,
+		Checkpoints: checkpoints,
+		Goals:       []queries.Goal{},
+		Rsvps:       []queries.CheckpointRsvp{},
+		Attendance:  []queries.Attendance{},
+	}
+	if export.Checkpoints == nil {
+		export.Checkpoints = []queries.Checkpoint{}
+	}
+
+	for _, checkpoint := range checkpoints {
+		goals, err := db.GetGoalsByCheckpoint(ctx, checkpoint.ID)
+		if err != nil {
+			return nil, fmt.Errorf("cannot get goals for checkpoint %d: %w", checkpoint.ID, err)
+		}
+		export.Goals = append(export.Goals, goals...)
+
+		rsvps, err := db.GetRsvpsByCheckpoint(ctx, checkpoint.ID)
+		if err != nil {
+			return nil, fmt.Errorf("cannot get rsvps for checkpoint %d: %w", checkpoint.ID, err)
+		}
+		export.Rsvps = append(export.Rsvps, rsvps...)
+
+		attendance, err := db.GetAttendanceByCheckpoint(ctx, checkpoint.ID)
+		if err != nil {
+			return nil, fmt.Errorf("cannot get attendance for checkpoint %d: %w", checkpoint.ID, err)
+		}
+		export.Attendance = append(export.Attendance, attendance...)
+	}
+
+	log.Info("Built history export", "guild_id", guild.GuildID, "checkpoints", len(export.Checkpoints), "goals", len(export.Goals), "rsvps", len(export.Rsvps), "attendance", len(export.Attendance))
+
+	return export, nil
+}
+
+// ListParams converts a Filter into query params, resolving dates in the guild's timezone
+func ListParams(guild queries.Guild, filter Filter) (queries.ListCheckpointsParams, error) {
+	loc := util.GuildLocation(guild)
+	params := queries.ListCheckpointsParams{GuildID: guild.GuildID}
+
+	if filter.ChannelID != "" {
+		params.ChannelID = sql.NullString{String: filter.ChannelID, Valid: true}
+	}
+	if filter.FromDate != "" {
+		from, err := util.ParseDate(filter.FromDate)
+		if err != nil {
+			return params, fmt.Errorf("from is not a valid date (expected YYYY-MM-DD): %w", err)
+		}
+		from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, loc)
+		params.ScheduledFrom = sql.NullString{String: from.Format(time.RFC3339), Valid: true}
+	}
+	if filter.ToDate != "" {
+		to, err := util.ParseDate(filter.ToDate)
+		if err != nil {
+			return params, fmt.Errorf("to is not a valid date (expected YYYY-MM-DD): %w", err)
+		}
+		// Inclusive end date, so the range ends at midnight of the following day
+		to = time.Date(to.Year(), to.Month(), to.Day()+1, 0, 0, 0, 0, loc)
+		params.ScheduledTo = sql.NullString{String: to.Format(time.RFC3339), Valid: true}
+	}
+
+	return params, nil
+}
+
+// Write encodes the export in the given format
+func Write(w io.Writer, export *Export, format string) error {
+	switch format {
+	case FormatJSON:
+		return WriteJSON(w, export)
+	case FormatCSV:
+		return WriteCSV(w, export)
+	default:
+		return fmt.Errorf("unknown export format %q (expected json or csv)", format)
+	}
+}
+
+// WriteJSON encodes the export as indented JSON
+func WriteJSON(w io.Writer, export *Export) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(export)
+}
+
+// WriteCSV encodes the export as a single spreadsheet friendly table.
+// Goals, RSVPs and attendance rows repeat their checkpoint's channel and date so they can be filtered on their own.
+func WriteCSV(w io.Writer, export *Export) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+
+	guild := export.Guild
+	rows := [][]string{
+		{"guild", "", guild.GuildID, "", "", "", guild.OwnerID, "", "", guild.Timezone, formatNullTime(guild.CreatedAt)},
+	}
+
+	checkpointsByID := make(map[int64]queries.Checkpoint, len(export.Checkpoints))
+	for _, checkpoint := range export.Checkpoints {
+		checkpointsByID[checkpoint.ID] = checkpoint
+		rows = append(rows, []string{
+			"checkpoint", formatID(checkpoint.ID), checkpoint.GuildID, checkpoint.ChannelID, "", checkpoint.ScheduledAt,
+			checkpoint.DiscordUser, "", "", "", formatNullTime(checkpoint.CreatedAt),
+		})
+	}
+	for _, goal := range export.Goals {
+		checkpoint := checkpointsByID[goal.CheckpointID]
+		rows = append(rows, []string{
+			"goal", formatID(goal.ID), guild.GuildID, checkpoint.ChannelID, formatID(goal.CheckpointID), checkpoint.ScheduledAt,
+			goal.DiscordUser, goal.Description, goal.Status, "", formatNullTime(goal.CreatedAt),
+		})
+	}
+	for _, rsvp := range export.Rsvps {
+		checkpoint := checkpointsByID[rsvp.CheckpointID]
+		rows = append(rows, []string{
+			"rsvp", formatID(rsvp.ID), guild.GuildID, checkpoint.ChannelID, formatID(rsvp.CheckpointID), checkpoint.ScheduledAt,
+			rsvp.DiscordUser, "", "", "", formatNullTime(rsvp.CreatedAt),
+		})
+	}
+	for _, attendance := range export.Attendance {
+		checkpoint := checkpointsByID[attendance.CheckpointID]
+		rows = append(rows, []string{
+			"attendance", formatID(attendance.ID), guild.GuildID, checkpoint.ChannelID, formatID(attendance.CheckpointID), checkpoint.ScheduledAt,
+			attendance.DiscordUser, "", "", "", formatNullTime(attendance.CreatedAt),
+		})
+	}
+
+	if err := writer.WriteAll(rows); err != nil {
+		return err
+	}
+	return writer.Error()
+}
+
+// Filename returns a descriptive file name for an export
+func Filename(guildID string, format string) string {
+	return fmt.Sprintf("checkpoint-export-%s-%s.%s", guildID, time.Now().Format("20060102"), format)
+}
+
+func formatID(id int64) string {
+	return strconv.FormatInt(id, 10)
+}
+
+func formatNullTime(t sql.NullTime) string {
+	if !t.Valid {
+		return ""
+	}
+	return t.Time.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,56 @@
+package history
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWriteCSV tests that every record becomes a row tagged with its record type
+func TestWriteCSV(t *testing.T) {
+	export := &Export{
+		Guild: queries.Guild{GuildID: "1", Timezone: "Europe/Rome", OwnerID: "2"},
+		Checkpoints: []queries.Checkpoint{
+			{ID: 10, ScheduledAt: "2025-01-15T19:00:00+01:00", ChannelID: "3", GuildID: "1", DiscordUser: "2"},
+		},
+		Goals: []queries.Goal{
+			{ID: 20, DiscordUser: "4", Description: "Write, then \"ship\"", CheckpointID: 10, Status: "completed"},
+		},
+		Attendance: []queries.Attendance{
+			{ID: 30, DiscordUser: "4", CheckpointID: 10},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteCSV(&buf, export))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 5)
+
+	assert.Equal(t, csvHeader, records[0])
+	assert.Equal(t, "guild", records[1][0])
+	assert.Equal(t, "Europe/Rome", records[1][9])
+	assert.Equal(t, "checkpoint", records[2][0])
+	assert.Equal(t, []string{"goal", "20", "1", "3", "10", "2025-01-15T19:00:00+01:00", "4", "Write, then \"ship\"", "completed", "", ""}, records[3])
+	assert.Equal(t, "attendance", records[4][0])
+}
+
+// TestListParams tests that date filters are resolved in the guild's timezone with an inclusive end date
+func TestListParams(t *testing.T) {
+	guild := queries.Guild{GuildID: "1", Timezone: "America/New_York"}
+
+	params, err := ListParams(guild, Filter{GuildID: "1", ChannelID: "3", FromDate: "2025-01-01", ToDate: "2025-01-31"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "3", params.ChannelID.String)
+	assert.Equal(t, "2025-01-01T00:00:00-05:00", params.ScheduledFrom.String)
+	assert.Equal(t, "2025-02-01T00:00:00-05:00", params.ScheduledTo.String)
+
+	_, err = ListParams(guild, Filter{GuildID: "1", FromDate: "01/01/2025"})
+	assert.Error(t, err)
+}
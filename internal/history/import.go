@@ -0,0 +1,652 @@
+package history
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/log"
+	"github.com/metruzanca/checkpoint-bot/internal/database"
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+	"github.com/metruzanca/checkpoint-bot/internal/events"
+	"github.com/metruzanca/checkpoint-bot/internal/service"
+	"github.com/metruzanca/checkpoint-bot/internal/util"
+)
+
+// snowflakePattern matches Discord IDs (users, channels, guilds)
+var snowflakePattern = regexp.MustCompile(`^\d{15,21}$`)
+
+var validStatuses = map[string]bool{
+	"incomplete": true,
+	"completed":  true,
+	"failed":     true,
+	"partial":    true,
+}
+
+// errDryRun is returned from the import transaction to roll it back after the summary is built
+var errDryRun = errors.New("dry run")
+
+// ImportOptions configures how an import is applied
+type ImportOptions struct {
+	// GuildID is the guild every record is imported into
+	GuildID string
+	// DryRun rolls the transaction back after computing the summary
+	DryRun bool
+	// AllowOtherGuild imports an export of another guild, e.g. when moving to a new server
+	AllowOtherGuild bool
+	// ActorID is recorded as the editor in the revisions of imported goals, the guild's owner by default
+	ActorID string
+}
+
+// SimpleCSVOptions configures how a date,user,goal,status CSV is turned into an Export
+type SimpleCSVOptions struct {
+	// ChannelID is the channel the checkpoints are created in, simple CSVs don't have one
+	ChannelID string
+	// Time is the time of day (HH:MM) used for dates without a time
+	Time string
+	// Location is used to interpret dates without an offset
+	Location *time.Location
+}
+
+// Summary describes what an import changed (or would change on a dry run)
+type Summary struct {
+	CheckpointsCreated  int
+	CheckpointsExisting int
+	GoalsCreated        int
+	GoalsUpdated        int
+	GoalsUnchanged      int
+	RsvpsCreated        int
+	AttendanceCreated   int
+	// Changes is a human readable diff, one line per created or updated record
+	Changes []string
+
+	// events are the goal events published once the import is committed
+	events []goalEvent
+}
+
+// goalEvent is a goal write of the import, see events.GoalData for previousStatus and changed
+type goalEvent struct {
+	t              events.Type
+	checkpointID   int64
+	discordUser    string
+	previousStatus string
+	changed        string
+}
+
+func (s *Summary) String() string {
+	var sb strings.Builder
+	for _, change := range s.Changes {
+		sb.WriteString(change)
+		sb.WriteString("\n")
+	}
+	fmt.Fprintf(&sb, "checkpoints: %d created, %d existing\n", s.CheckpointsCreated, s.CheckpointsExisting)
+	fmt.Fprintf(&sb, "goals: %d created, %d updated, %d unchanged\n", s.GoalsCreated, s.GoalsUpdated, s.GoalsUnchanged)
+	fmt.Fprintf(&sb, "rsvps: %d created\n", s.RsvpsCreated)
+	fmt.Fprintf(&sb, "attendance: %d created\n", s.AttendanceCreated)
+	return sb.String()
+}
+
+// ReadJSON decodes an export written by WriteJSON
+func ReadJSON(r io.Reader) (*Export, error) {
+	var export Export
+	if err := json.NewDecoder(r).Decode(&export); err != nil {
+		return nil, fmt.Errorf("cannot decode JSON export: %w", err)
+	}
+	return &export, nil
+}
+
+// ReadCSV decodes either a CSV written by WriteCSV or a simple date,user,goal,status CSV
+func ReadCSV(r io.Reader, opts SimpleCSVOptions) (*Export, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("cannot read CSV: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, errors.New("CSV is empty")
+	}
+
+	header := make([]string, len(records[0]))
+	for idx, column := range records[0] {
+		header[idx] = strings.ToLower(strings.TrimSpace(column))
+	}
+	if header[0] == csvHeader[0] {
+		return readExportCSV(header, records[1:])
+	}
+	return readSimpleCSV(header, records[1:], opts)
+}
+
+// readExportCSV rebuilds an Export from the rows written by WriteCSV
+func readExportCSV(header []string, rows [][]string) (*Export, error) {
+	if len(header) != len(csvHeader) {
+		return nil, fmt.Errorf("export CSV has %d columns, expected %d", len(header), len(csvHeader))
+	}
+
+	export := &Export{}
+	for idx, row := range rows {
+		line := idx + 2
+		if len(row) != len(csvHeader) {
+			return nil, fmt.Errorf("line %d: expected %d columns, got %d", line, len(csvHeader), len(row))
+		}
+		// Indexes follow csvHeader
+		recordType, guildID, channelID, scheduledAt := row[0], row[2], row[3], row[5]
+		user, description, status, timezone := row[6], row[7], row[8], row[9]
+
+		id, err := parseOptionalID(row[1])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid id: %w", line, err)
+		}
+		checkpointID, err := parseOptionalID(row[4])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid checkpoint_id: %w", line, err)
+		}
+
+		switch recordType {
+		case "guild":
+			export.Guild = queries.Guild{GuildID: guildID, Timezone: timezone, OwnerID: user}
+		case "checkpoint":
+			export.Checkpoints = append(export.Checkpoints, queries.Checkpoint{
+				ID: id, ScheduledAt: scheduledAt, ChannelID: channelID, GuildID: guildID, DiscordUser: user,
+			})
+		case "goal":
+			export.Goals = append(export.Goals, queries.Goal{
+				ID: id, DiscordUser: user, Description: description, CheckpointID: checkpointID, Status: status,
+				Progress: csvProgress(status),
+			})
+		case "rsvp":
+			export.Rsvps = append(export.Rsvps, queries.CheckpointRsvp{ID: id, CheckpointID: checkpointID, DiscordUser: user})
+		case "attendance":
+			export.Attendance = append(export.Attendance, queries.Attendance{ID: id, CheckpointID: checkpointID, DiscordUser: user})
+		default:
+			return nil, fmt.Errorf("line %d: unknown record_type %q", line, recordType)
+		}
+	}
+
+	return export, nil
+}
+
+// csvProgress is the progress of a goal read from a CSV, which only has its status
+func csvProgress(status string) int64 {
+	if status == "completed" {
+		return 100
+	}
+	return 0
+}
+
+// readSimpleCSV builds an Export from date,user,goal[,status] rows, one checkpoint per distinct date
+func readSimpleCSV(header []string, rows [][]string, opts SimpleCSVOptions) (*Export, error) {
+	columns := map[string]int{}
+	for idx, column := range header {
+		columns[column] = idx
+	}
+	for _, required := range []string{"date", "user", "goal"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("CSV header must contain date,user,goal (and optionally status), got %s", strings.Join(header, ","))
+		}
+	}
+	if opts.ChannelID == "" {
+		return nil, errors.New("a channel is required when importing a simple CSV")
+	}
+	loc := opts.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	export := &Export{}
+	checkpointIDs := map[string]int64{}
+	for idx, row := range rows {
+		line := idx + 2
+		value := func(column string) string {
+			pos, ok := columns[column]
+			if !ok || pos >= len(row) {
+				return ""
+			}
+			return strings.TrimSpace(row[pos])
+		}
+
+		scheduledAt, err := parseSimpleDate(value("date"), opts.Time, loc)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		key := scheduledAt.Format(time.RFC3339)
+
+		checkpointID, ok := checkpointIDs[key]
+		if !ok {
+			// Synthetic IDs only link goals to checkpoints within this import
+			checkpointID = int64(len(checkpointIDs) + 1)
+			checkpointIDs[key] = checkpointID
+			export.Checkpoints = append(export.Checkpoints, queries.Checkpoint{
+				ID: checkpointID, ScheduledAt: key, ChannelID: opts.ChannelID,
+			})
+		}
+
+		status := strings.ToLower(value("status"))
+		if status == "" {
+			status = "incomplete"
+		}
+		export.Goals = append(export.Goals, queries.Goal{
+			DiscordUser:  normalizeUser(value("user")),
+			Description:  value("goal"),
+			CheckpointID: checkpointID,
+			Status:       status,
+			Progress:     csvProgress(status),
+		})
+	}
+
+	return export, nil
+}
+
+// parseSimpleDate accepts RFC3339, "YYYY-MM-DD HH:MM" or "YYYY-MM-DD" (using defaultTime) in loc
+func parseSimpleDate(value string, defaultTime string, loc *time.Location) (time.Time, error) {
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return parsed, nil
+	}
+
+	datePart, timePart, hasTime := strings.Cut(value, " ")
+	if !hasTime {
+		timePart = defaultTime
+	}
+	date, err := util.ParseDate(datePart)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("date %q is not a valid date (expected YYYY-MM-DD [HH:MM])", value)
+	}
+	hour, minute := 0, 0
+	if timePart != "" {
+		hour, minute, err = util.ParseTime(timePart)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("time %q is not a valid time (expected HH:MM or H:MM AM/PM)", timePart)
+		}
+	}
+	return time.Date(date.Year(), date.Month(), date.Day(), hour, minute, 0, 0, loc), nil
+}
+
+// normalizeUser strips mention syntax so both <@123> and 123 are accepted
+func normalizeUser(user string) string {
+	return strings.TrimRight(strings.TrimLeft(user, "<@!"), ">")
+}
+
+func parseOptionalID(value string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.ParseInt(value, 10, 64)
+}
+
+// Validate checks every record before anything is written, returning all problems at once
+func Validate(export *Export) error {
+	var problems []string
+	checkpointIDs := map[int64]bool{}
+
+	for _, checkpoint := range export.Checkpoints {
+		checkpointIDs[checkpoint.ID] = true
+		if !snowflakePattern.MatchString(checkpoint.ChannelID) {
+			problems = append(problems, fmt.Sprintf("checkpoint %d: invalid channel %q", checkpoint.ID, checkpoint.ChannelID))
+		}
+		if checkpoint.DiscordUser != "" && !snowflakePattern.MatchString(checkpoint.DiscordUser) {
+			problems = append(problems, fmt.Sprintf("checkpoint %d: invalid creator %q", checkpoint.ID, checkpoint.DiscordUser))
+		}
+		if _, err := time.Parse(time.RFC3339, checkpoint.ScheduledAt); err != nil {
+			problems = append(problems, fmt.Sprintf("checkpoint %d: invalid scheduled_at %q", checkpoint.ID, checkpoint.ScheduledAt))
+		}
+	}
+
+	for _, goal := range export.Goals {
+		if !checkpointIDs[goal.CheckpointID] {
+			problems = append(problems, fmt.Sprintf("goal %d: unknown checkpoint %d", goal.ID, goal.CheckpointID))
+		}
+		if !snowflakePattern.MatchString(goal.DiscordUser) {
+			problems = append(problems, fmt.Sprintf("goal %d: invalid user %q", goal.ID, goal.DiscordUser))
+		}
+		if goal.Description == "" || utf8.RuneCountInString(goal.Description) > service.GoalMaxLength {
+			problems = append(problems, fmt.Sprintf("goal %d: description must be between 1 and %d characters", goal.ID, service.GoalMaxLength))
+		}
+		if !validStatuses[goal.Status] {
+			problems = append(problems, fmt.Sprintf("goal %d: invalid status %q", goal.ID, goal.Status))
+		}
+		if goal.Visibility != "" && !service.ValidVisibility(goal.Visibility) {
+			problems = append(problems, fmt.Sprintf("goal %d: invalid visibility %q", goal.ID, goal.Visibility))
+		}
+		if goal.Progress < 0 || goal.Progress > 100 {
+			problems = append(problems, fmt.Sprintf("goal %d: progress must be between 0 and 100, got %d", goal.ID, goal.Progress))
+		}
+		if goal.Target < 0 || goal.Total < 0 {
+			problems = append(problems, fmt.Sprintf("goal %d: target and total cannot be negative", goal.ID))
+		}
+	}
+
+	for _, rsvp := range export.Rsvps {
+		if !checkpointIDs[rsvp.CheckpointID] {
+			problems = append(problems, fmt.Sprintf("rsvp %d: unknown checkpoint %d", rsvp.ID, rsvp.CheckpointID))
+		}
+		if !snowflakePattern.MatchString(rsvp.DiscordUser) {
+			problems = append(problems, fmt.Sprintf("rsvp %d: invalid user %q", rsvp.ID, rsvp.DiscordUser))
+		}
+	}
+
+	for _, attendance := range export.Attendance {
+		if !checkpointIDs[attendance.CheckpointID] {
+			problems = append(problems, fmt.Sprintf("attendance %d: unknown checkpoint %d", attendance.ID, attendance.CheckpointID))
+		}
+		if !snowflakePattern.MatchString(attendance.DiscordUser) {
+			problems = append(problems, fmt.Sprintf("attendance %d: invalid user %q", attendance.ID, attendance.DiscordUser))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid import:\n  %s", strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+// Import validates the export and writes it into the guild inside a single transaction.
+// Existing checkpoints (same channel and time) are reused and existing goals are updated in place.
+// Created and updated goals get a revision, their goal events are published once the import is committed.
+func Import(ctx context.Context, db database.CheckpointDatabase, export *Export, opts ImportOptions) (*Summary, error) {
+	if err := Validate(export); err != nil {
+		return nil, err
+	}
+	if export.Guild.GuildID != "" && export.Guild.GuildID != opts.GuildID {
+		if !opts.AllowOtherGuild {
+			return nil, fmt.Errorf("export is from guild %s, not %s, allow importing it into another guild explicitly", export.Guild.GuildID, opts.GuildID)
+		}
+		log.Warn("Importing export from a different guild", "export_guild_id", export.Guild.GuildID, "guild_id", opts.GuildID)
+	}
+
+	summary := &Summary{}
+	err := db.WithTx(ctx, func(q *queries.Queries) error {
+		guild, err := q.GetGuild(ctx, opts.GuildID)
+		if err == sql.ErrNoRows {
+			if export.Guild.Timezone == "" {
+				return fmt.Errorf("guild %s not found, create a checkpoint in it first or import a full export", opts.GuildID)
+			}
+			guild, err = q.CreateGuild(ctx, queries.CreateGuildParams{
+				GuildID:  opts.GuildID,
+				Timezone: export.Guild.Timezone,
+				OwnerID:  export.Guild.OwnerID,
+			})
+			if err != nil {
+				return fmt.Errorf("cannot create guild: %w", err)
+			}
+			summary.Changes = append(summary.Changes, fmt.Sprintf("+ guild %s (%s)", guild.GuildID, guild.Timezone))
+		} else if err != nil {
+			return fmt.Errorf("cannot get guild: %w", err)
+		}
+		loc := util.GuildLocation(guild)
+		actorID := opts.ActorID
+		if actorID == "" {
+			actorID = guild.OwnerID
+		}
+
+		// Maps IDs in the export to IDs in the database
+		checkpointIDs := make(map[int64]int64, len(export.Checkpoints))
+		for _, checkpoint := range export.Checkpoints {
+			id, err := importCheckpoint(ctx, q, guild, loc, checkpoint, summary)
+			if err != nil {
+				return err
+			}
+			checkpointIDs[checkpoint.ID] = id
+		}
+
+		for _, goal := range export.Goals {
+			if err := importGoal(ctx, q, checkpointIDs[goal.CheckpointID], goal, actorID, summary); err != nil {
+				return err
+			}
+		}
+
+		for _, rsvp := range export.Rsvps {
+			_, err := q.CreateRsvp(ctx, queries.CreateRsvpParams{CheckpointID: checkpointIDs[rsvp.CheckpointID], DiscordUser: rsvp.DiscordUser})
+			if err == nil {
+				summary.RsvpsCreated++
+			} else if err != sql.ErrNoRows {
+				return fmt.Errorf("cannot create rsvp: %w", err)
+			}
+		}
+
+		for _, attendance := range export.Attendance {
+			_, err := q.CreateAttendance(ctx, queries.CreateAttendanceParams{CheckpointID: checkpointIDs[attendance.CheckpointID], DiscordUser: attendance.DiscordUser})
+			if err == nil {
+				summary.AttendanceCreated++
+			} else if err != sql.ErrNoRows {
+				return fmt.Errorf("cannot create attendance: %w", err)
+			}
+		}
+
+		if opts.DryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && err != errDryRun {
+		return nil, err
+	}
+	if !opts.DryRun {
+		publishGoalEvents(ctx, db, summary.events)
+	}
+
+	log.Info("Imported history", "guild_id", opts.GuildID, "dry_run", opts.DryRun, "checkpoints_created", summary.CheckpointsCreated, "goals_created", summary.GoalsCreated, "goals_updated", summary.GoalsUpdated)
+
+	return summary, nil
+}
+
+// importCheckpoint finds or creates the checkpoint, returning its database ID
+func importCheckpoint(ctx context.Context, q *queries.Queries, guild queries.Guild, loc *time.Location, checkpoint queries.Checkpoint, summary *Summary) (int64, error) {
+	scheduledAt, err := time.Parse(time.RFC3339, checkpoint.ScheduledAt)
+	if err != nil {
+		return 0, err
+	}
+	// Stored in the guild's timezone, like checkpoints created with /checkpoint
+	scheduledAtStr := scheduledAt.In(loc).Format(time.RFC3339)
+
+	existing, err := q.GetCheckpointByScheduledAtAndChannel(ctx, queries.GetCheckpointByScheduledAtAndChannelParams{
+		ScheduledAt: scheduledAtStr,
+		ChannelID:   checkpoint.ChannelID,
+	})
+	if err == nil {
+		// Channel IDs are unique across guilds, so this is a mistyped channel or a mixed up export
+		if existing.GuildID != guild.GuildID {
+			return 0, fmt.Errorf("checkpoint %d: channel %s belongs to guild %s, not %s", checkpoint.ID, checkpoint.ChannelID, existing.GuildID, guild.GuildID)
+		}
+		summary.CheckpointsExisting++
+		return existing.ID, nil
+	} else if err != sql.ErrNoRows {
+		return 0, fmt.Errorf("cannot check for existing checkpoint: %w", err)
+	}
+
+	creator := checkpoint.DiscordUser
+	if creator == "" {
+		creator = guild.OwnerID
+	}
+	created, err := q.CreateCheckpoint(ctx, queries.CreateCheckpointParams{
+		ScheduledAt: scheduledAtStr,
+		ChannelID:   checkpoint.ChannelID,
+		GuildID:     guild.GuildID,
+		DiscordUser: creator,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("cannot create checkpoint: %w", err)
+	}
+	summary.CheckpointsCreated++
+	summary.Changes = append(summary.Changes, fmt.Sprintf("+ checkpoint %s in channel %s", scheduledAtStr, checkpoint.ChannelID))
+	return created.ID, nil
+}
+
+// importGoal creates the goal or updates its description, status, progress, target and visibility if they differ.
+// Goals of older exports and CSVs have no visibility, new ones are public and existing ones keep theirs.
+func importGoal(ctx context.Context, q *queries.Queries, checkpointID int64, goal queries.Goal, actorID string, summary *Summary) error {
+	existing, err := q.GetGoalByCheckpointAndUser(ctx, queries.GetGoalByCheckpointAndUserParams{
+		CheckpointID: checkpointID,
+		DiscordUser:  goal.DiscordUser,
+	})
+	if err == sql.ErrNoRows {
+		created, err := q.CreateGoal(ctx, queries.CreateGoalParams{
+			DiscordUser:  goal.DiscordUser,
+			Description:  goal.Description,
+			CheckpointID: checkpointID,
+			Visibility:   goal.Visibility,
+		})
+		if err != nil {
+			return fmt.Errorf("cannot create goal: %w", err)
+		}
+		if goal.Status != created.Status {
+			if err := q.UpdateGoalStatus(ctx, queries.UpdateGoalStatusParams{Status: goal.Status, CheckpointID: checkpointID, DiscordUser: goal.DiscordUser}); err != nil {
+				return fmt.Errorf("cannot set goal status: %w", err)
+			}
+		}
+		if goal.Progress != created.Progress {
+			if err := q.UpdateGoalProgress(ctx, queries.UpdateGoalProgressParams{Progress: goal.Progress, CheckpointID: checkpointID, DiscordUser: goal.DiscordUser}); err != nil {
+				return fmt.Errorf("cannot set goal progress: %w", err)
+			}
+		}
+		if _, err := importGoalTarget(ctx, q, checkpointID, created, goal); err != nil {
+			return err
+		}
+		if err := importGoalRevision(ctx, q, checkpointID, goal.DiscordUser, actorID); err != nil {
+			return err
+		}
+		summary.events = append(summary.events, goalEvent{t: events.GoalCreated, checkpointID: checkpointID, discordUser: goal.DiscordUser})
+		summary.GoalsCreated++
+		summary.Changes = append(summary.Changes, fmt.Sprintf("+ goal for user %s on checkpoint %d (%s): %s", goal.DiscordUser, checkpointID, goal.Status, truncate(goal.Description)))
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("cannot check for existing goal: %w", err)
+	}
+
+	changed := false
+	updated := func(field string) {
+		summary.events = append(summary.events, goalEvent{t: events.GoalUpdated, checkpointID: checkpointID, discordUser: goal.DiscordUser, changed: field})
+		changed = true
+	}
+	if existing.Description != goal.Description {
+		if err := q.UpdateGoalDescription(ctx, queries.UpdateGoalDescriptionParams{Description: goal.Description, CheckpointID: checkpointID, DiscordUser: goal.DiscordUser}); err != nil {
+			return fmt.Errorf("cannot update goal description: %w", err)
+		}
+		summary.Changes = append(summary.Changes, fmt.Sprintf("~ goal for user %s on checkpoint %d: %q -> %q", goal.DiscordUser, checkpointID, truncate(existing.Description), truncate(goal.Description)))
+		updated("description")
+	}
+	if existing.Status != goal.Status {
+		if err := q.UpdateGoalStatus(ctx, queries.UpdateGoalStatusParams{Status: goal.Status, CheckpointID: checkpointID, DiscordUser: goal.DiscordUser}); err != nil {
+			return fmt.Errorf("cannot update goal status: %w", err)
+		}
+		summary.Changes = append(summary.Changes, fmt.Sprintf("~ goal for user %s on checkpoint %d: status %s -> %s", goal.DiscordUser, checkpointID, existing.Status, goal.Status))
+		summary.events = append(summary.events, goalEvent{t: events.GoalStatusChanged, checkpointID: checkpointID, discordUser: goal.DiscordUser, previousStatus: existing.Status})
+		changed = true
+	}
+	if existing.Progress != goal.Progress {
+		if err := q.UpdateGoalProgress(ctx, queries.UpdateGoalProgressParams{Progress: goal.Progress, CheckpointID: checkpointID, DiscordUser: goal.DiscordUser}); err != nil {
+			return fmt.Errorf("cannot update goal progress: %w", err)
+		}
+		summary.Changes = append(summary.Changes, fmt.Sprintf("~ goal for user %s on checkpoint %d: progress %d%% -> %d%%", goal.DiscordUser, checkpointID, existing.Progress, goal.Progress))
+		updated("progress")
+	}
+	if goal.Visibility != "" && existing.Visibility != goal.Visibility {
+		if err := q.UpdateGoalVisibility(ctx, queries.UpdateGoalVisibilityParams{Visibility: goal.Visibility, ID: existing.ID}); err != nil {
+			return fmt.Errorf("cannot update goal visibility: %w", err)
+		}
+		summary.Changes = append(summary.Changes, fmt.Sprintf("~ goal for user %s on checkpoint %d: visibility %s -> %s", goal.DiscordUser, checkpointID, existing.Visibility, goal.Visibility))
+		changed = true
+	}
+	targetChanged, err := importGoalTarget(ctx, q, checkpointID, existing, goal)
+	if err != nil {
+		return err
+	}
+	if targetChanged {
+		summary.Changes = append(summary.Changes, fmt.Sprintf("~ goal for user %s on checkpoint %d: target %g/%g %s -> %g/%g %s",
+			goal.DiscordUser, checkpointID, existing.Total, existing.Target, existing.Unit, goal.Total, goal.Target, goal.Unit))
+		updated("target")
+	}
+
+	if changed {
+		if err := importGoalRevision(ctx, q, checkpointID, goal.DiscordUser, actorID); err != nil {
+			return err
+		}
+		summary.GoalsUpdated++
+	} else {
+		summary.GoalsUnchanged++
+	}
+	return nil
+}
+
+// importGoalTarget sets the goal's target, unit and total when they differ from existing
+func importGoalTarget(ctx context.Context, q *queries.Queries, checkpointID int64, existing queries.Goal, goal queries.Goal) (bool, error) {
+	changed := false
+	if existing.Target != goal.Target || existing.Unit != goal.Unit {
+		if err := q.UpdateGoalTarget(ctx, queries.UpdateGoalTargetParams{Target: goal.Target, Unit: goal.Unit, CheckpointID: checkpointID, DiscordUser: goal.DiscordUser}); err != nil {
+			return false, fmt.Errorf("cannot set goal target: %w", err)
+		}
+		changed = true
+	}
+	if existing.Total != goal.Total {
+		if _, err := q.AddGoalTotal(ctx, queries.AddGoalTotalParams{Amount: goal.Total - existing.Total, ID: existing.ID}); err != nil {
+			return false, fmt.Errorf("cannot set goal total: %w", err)
+		}
+		changed = true
+	}
+	return changed, nil
+}
+
+// importGoalRevision snapshots the goal after the import wrote it, like edits made in Discord
+func importGoalRevision(ctx context.Context, q *queries.Queries, checkpointID int64, discordUser string, actorID string) error {
+	goal, err := q.GetGoalByCheckpointAndUser(ctx, queries.GetGoalByCheckpointAndUserParams{CheckpointID: checkpointID, DiscordUser: discordUser})
+	if err != nil {
+		return fmt.Errorf("cannot get imported goal: %w", err)
+	}
+	_, err = q.CreateGoalRevision(ctx, queries.CreateGoalRevisionParams{
+		GoalID:      goal.ID,
+		Description: goal.Description,
+		Status:      goal.Status,
+		Progress:    goal.Progress,
+		EditedBy:    actorID,
+	})
+	if err != nil {
+		return fmt.Errorf("cannot record goal revision: %w", err)
+	}
+	return nil
+}
+
+// publishGoalEvents publishes the goal events of a committed import.
+// The import has already succeeded, so lookup errors are logged and the event is dropped.
+func publishGoalEvents(ctx context.Context, db database.CheckpointDatabase, goalEvents []goalEvent) {
+	checkpoints := make(map[int64]*queries.Checkpoint)
+	for _, e := range goalEvents {
+		checkpoint, ok := checkpoints[e.checkpointID]
+		if !ok {
+			var err error
+			if checkpoint, err = db.GetCheckpoint(ctx, e.checkpointID); err != nil {
+				log.Error("cannot load checkpoint for event", "err", err, "type", e.t, "checkpoint_id", e.checkpointID)
+				continue
+			}
+			checkpoints[e.checkpointID] = checkpoint
+		}
+		goal, err := db.GetGoalByCheckpointAndUser(ctx, queries.GetGoalByCheckpointAndUserParams{CheckpointID: e.checkpointID, DiscordUser: e.discordUser})
+		if err != nil {
+			log.Error("cannot load goal for event", "err", err, "type", e.t, "checkpoint_id", e.checkpointID, "discord_user", e.discordUser)
+			continue
+		}
+		event := events.NewGoalEvent(e.t, *checkpoint, *goal, e.previousStatus)
+		if e.changed != "" {
+			data := event.Data.(events.GoalData)
+			data.Changed = e.changed
+			event.Data = data
+		}
+		db.Events().Publish(event)
+	}
+}
+
+// truncate shortens descriptions so the diff stays readable
+func truncate(s string) string {
+	runes := []rune(strings.ReplaceAll(s, "\n", " "))
+	if len(runes) > 60 {
+		return string(runes[:57]) + "..."
+	}
+	return string(runes)
+}
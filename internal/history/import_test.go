@@ -0,0 +1,208 @@
+package history
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+	"github.com/metruzanca/checkpoint-bot/internal/database/sqlite"
+	"github.com/metruzanca/checkpoint-bot/internal/events"
+	"github.com/metruzanca/checkpoint-bot/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testGuild   = "100000000000000001"
+	testChannel = "100000000000000002"
+	testOwner   = "100000000000000003"
+	testUser    = "100000000000000004"
+)
+
+// setupTestDB creates a file backed SQLite database, in-memory databases aren't shared between pooled connections
+func setupTestDB(t *testing.T) *sqlite.SqliteDatabase {
+	t.Helper()
+
+	db := sqlite.NewSqliteDatabase(filepath.Join(t.TempDir(), "checkpoint.db"))
+	require.NotNil(t, db, "Failed to create test database")
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+// TestImport tests dry runs, imports and re-imports of a JSON export
+func TestImport(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	export := &Export{
+		Guild: queries.Guild{GuildID: testGuild, Timezone: "America/New_York", OwnerID: testOwner},
+		Checkpoints: []queries.Checkpoint{
+			{ID: 7, ScheduledAt: "2025-01-15T19:00:00Z", ChannelID: testChannel, GuildID: testGuild, DiscordUser: testOwner},
+		},
+		Goals: []queries.Goal{
+			{ID: 1, DiscordUser: testUser, Description: "Run 5km", CheckpointID: 7, Status: "completed", Visibility: "private"},
+		},
+		Attendance: []queries.Attendance{
+			{ID: 1, DiscordUser: testUser, CheckpointID: 7},
+		},
+	}
+
+	var published []events.Type
+	db.Events().Subscribe(func(e events.Event) {
+		published = append(published, e.Type)
+	})
+
+	summary, err := Import(ctx, db, export, ImportOptions{GuildID: testGuild, DryRun: true})
+	require.NoError(t, err)
+	assert.Equal(t, 1, summary.CheckpointsCreated)
+	assert.Equal(t, 1, summary.GoalsCreated)
+	_, err = db.GetGuild(ctx, testGuild)
+	assert.Error(t, err, "dry run should not write anything")
+	assert.Empty(t, published, "dry run should not publish events")
+
+	summary, err = Import(ctx, db, export, ImportOptions{GuildID: testGuild})
+	require.NoError(t, err)
+	assert.Equal(t, 1, summary.AttendanceCreated)
+	assert.Equal(t, []events.Type{events.GoalCreated}, published)
+
+	// Timestamps are stored in the guild's timezone
+	checkpoints, err := db.ListCheckpoints(ctx, queries.ListCheckpointsParams{GuildID: testGuild})
+	require.NoError(t, err)
+	require.Len(t, checkpoints, 1)
+	assert.Equal(t, "2025-01-15T14:00:00-05:00", checkpoints[0].ScheduledAt)
+
+	// Visibility survives an export and import
+	exported, err := Build(ctx, db, Filter{GuildID: testGuild})
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, WriteJSON(&buf, exported))
+	exported, err = ReadJSON(&buf)
+	require.NoError(t, err)
+	require.Len(t, exported.Goals, 1)
+	assert.Equal(t, "private", exported.Goals[0].Visibility)
+
+	// Re-importing reuses the checkpoint and only updates what changed
+	export.Goals[0].Status = "failed"
+	export.Goals[0].Visibility = "members"
+	export.Goals[0].Target, export.Goals[0].Unit, export.Goals[0].Total = 5, "km", 3.5
+	summary, err = Import(ctx, db, export, ImportOptions{GuildID: testGuild})
+	require.NoError(t, err)
+	assert.Equal(t, 0, summary.CheckpointsCreated)
+	assert.Equal(t, 1, summary.CheckpointsExisting)
+	assert.Equal(t, 1, summary.GoalsUpdated)
+	assert.Equal(t, 0, summary.AttendanceCreated)
+	assert.Equal(t, []events.Type{events.GoalCreated, events.GoalStatusChanged, events.GoalUpdated}, published)
+
+	goal, err := db.GetGoalByCheckpointAndUser(ctx, queries.GetGoalByCheckpointAndUserParams{CheckpointID: checkpoints[0].ID, DiscordUser: testUser})
+	require.NoError(t, err)
+	assert.Equal(t, "failed", goal.Status)
+	assert.Equal(t, "members", goal.Visibility)
+	assert.Equal(t, "km", goal.Unit)
+	assert.Equal(t, 3.5, goal.Total)
+
+	// Both writes are in the goal's history, edited by the guild's owner
+	revisions, err := db.GetGoalRevisions(ctx, goal.ID)
+	require.NoError(t, err)
+	require.Len(t, revisions, 2)
+	assert.Equal(t, "completed", revisions[0].Status)
+	assert.Equal(t, "failed", revisions[1].Status)
+	assert.Equal(t, testOwner, revisions[1].EditedBy)
+}
+
+// TestImport_Invalid tests that validation errors abort the import before writing
+func TestImport_Invalid(t *testing.T) {
+	db := setupTestDB(t)
+
+	export := &Export{
+		Guild: queries.Guild{GuildID: testGuild, Timezone: "UTC", OwnerID: testOwner},
+		Checkpoints: []queries.Checkpoint{
+			{ID: 1, ScheduledAt: "2025-01-15T19:00:00Z", ChannelID: "general"},
+		},
+		Goals: []queries.Goal{
+			{ID: 1, DiscordUser: testUser, Description: "Run", CheckpointID: 1, Status: "done"},
+			{ID: 2, DiscordUser: testUser, Description: "Swim", CheckpointID: 1, Status: "partial", Progress: 150},
+			{ID: 3, DiscordUser: testUser, Description: "Read", CheckpointID: 1, Status: "completed", Visibility: "secret"},
+		},
+	}
+
+	_, err := Import(context.Background(), db, export, ImportOptions{GuildID: testGuild})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid channel "general"`)
+	assert.Contains(t, err.Error(), `invalid status "done"`)
+	assert.Contains(t, err.Error(), "goal 2: progress must be between 0 and 100")
+	assert.Contains(t, err.Error(), `goal 3: invalid visibility "secret"`)
+}
+
+// TestValidate_DescriptionLength tests that descriptions are limited in characters like the goal modal, not bytes
+func TestValidate_DescriptionLength(t *testing.T) {
+	export := &Export{
+		Guild: queries.Guild{GuildID: testGuild, Timezone: "UTC", OwnerID: testOwner},
+		Checkpoints: []queries.Checkpoint{
+			{ID: 1, ScheduledAt: "2025-01-15T19:00:00Z", ChannelID: testChannel},
+		},
+		Goals: []queries.Goal{
+			{ID: 1, DiscordUser: testUser, Description: strings.Repeat("é", service.GoalMaxLength), CheckpointID: 1, Status: "completed"},
+		},
+	}
+	assert.NoError(t, Validate(export))
+
+	export.Goals[0].Description += "🏃"
+	err := Validate(export)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "goal 1: description must be between 1 and")
+}
+
+// TestReadCSV_Simple tests that a simple spreadsheet CSV groups goals by date in the given timezone
+func TestReadCSV_Simple(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Rome")
+	require.NoError(t, err)
+
+	input := "Date,User,Goal,Status\n" +
+		"2025-01-15,<@" + testUser + ">,Run 5km,completed\n" +
+		"2025-01-15,100000000000000005,Read a book,\n" +
+		"2025-01-22 18:30,100000000000000005,Finish the book,failed\n"
+
+	export, err := ReadCSV(strings.NewReader(input), SimpleCSVOptions{ChannelID: testChannel, Time: "19:00", Location: loc})
+	require.NoError(t, err)
+
+	require.Len(t, export.Checkpoints, 2)
+	assert.Equal(t, "2025-01-15T19:00:00+01:00", export.Checkpoints[0].ScheduledAt)
+	assert.Equal(t, "2025-01-22T18:30:00+01:00", export.Checkpoints[1].ScheduledAt)
+
+	require.Len(t, export.Goals, 3)
+	assert.Equal(t, testUser, export.Goals[0].DiscordUser)
+	assert.Equal(t, "incomplete", export.Goals[1].Status)
+	assert.Equal(t, export.Checkpoints[1].ID, export.Goals[2].CheckpointID)
+	assert.NoError(t, Validate(export))
+}
+
+// TestImport_OtherGuild tests that exports of another guild need AllowOtherGuild and never reuse its checkpoints
+func TestImport_OtherGuild(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	const otherGuild = "100000000000000009"
+	export := &Export{
+		Guild: queries.Guild{GuildID: otherGuild, Timezone: "UTC", OwnerID: testOwner},
+		Checkpoints: []queries.Checkpoint{
+			{ID: 1, ScheduledAt: "2025-01-15T19:00:00Z", ChannelID: testChannel, GuildID: otherGuild, DiscordUser: testOwner},
+		},
+	}
+	_, err := Import(ctx, db, export, ImportOptions{GuildID: testGuild})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "export is from guild "+otherGuild)
+
+	_, err = Import(ctx, db, export, ImportOptions{GuildID: testGuild, AllowOtherGuild: true})
+	require.NoError(t, err)
+
+	// The checkpoint now belongs to testGuild, importing the same channel into another guild fails
+	export.Guild.GuildID = ""
+	_, err = Import(ctx, db, export, ImportOptions{GuildID: otherGuild})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "belongs to guild "+testGuild)
+}
@@ -0,0 +1,261 @@
+package httpserver
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/metruzanca/checkpoint-bot/internal/apitoken"
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+	"github.com/metruzanca/checkpoint-bot/internal/history"
+	"github.com/metruzanca/checkpoint-bot/internal/service"
+	"github.com/metruzanca/checkpoint-bot/internal/stats"
+)
+
+// apiCheckpoint is the public representation of a checkpoint
+type apiCheckpoint struct {
+	ID          int64      `json:"id"`
+	GuildID     string     `json:"guild_id"`
+	ChannelID   string     `json:"channel_id"`
+	ScheduledAt string     `json:"scheduled_at"`
+	CreatedBy   string     `json:"created_by"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+}
+
+// apiGoal is the public representation of a goal
+type apiGoal struct {
+	ID           int64  `json:"id"`
+	CheckpointID int64  `json:"checkpoint_id"`
+	UserID       string `json:"user_id"`
+	Description  string `json:"description"`
+	// Visibility is public, members or private, the description of goals the token's user can't see is replaced
+	Visibility string `json:"visibility"`
+	Status     string `json:"status"`
+	Progress   int64  `json:"progress"`
+	// Target, Unit and Total are only set for goals with a target, Total is the sum of their check-ins
+	Target float64 `json:"target,omitempty"`
+	Unit   string  `json:"unit,omitempty"`
+	Total  float64 `json:"total,omitempty"`
+	// Verification is pending while a completed goal awaits its partner's confirmation, then verified
+	Verification string     `json:"verification,omitempty"`
+	CreatedAt    *time.Time `json:"created_at,omitempty"`
+}
+
+type apiError struct {
+	Error string `json:"error"`
+}
+
+type tokenContextKey struct{}
+
+// requestToken returns the API token that authenticated the request
+func requestToken(r *http.Request) *queries.ApiToken {
+	token, _ := r.Context().Value(tokenContextKey{}).(*queries.ApiToken)
+	return token
+}
+
+// apiRoutes registers the versioned REST API, every route is scoped to a guild and requires a token for it
+func (s *Server) apiRoutes() {
+	s.mux.Handle("GET /api/v1/guilds/{guildID}/checkpoints", s.requireToken(s.handleAPICheckpoints))
+	s.mux.Handle("GET /api/v1/guilds/{guildID}/checkpoints/{checkpointID}/goals", s.requireToken(s.handleAPIGoals))
+	s.mux.Handle("GET /api/v1/guilds/{guildID}/users/{userID}/stats", s.requireToken(s.handleAPIUserStats))
+
+	s.mux.Handle("POST /api/v1/guilds/{guildID}/checkpoints", s.requireToken(s.requireWrite(s.handleAPICreateCheckpoint)))
+	s.mux.Handle("POST /api/v1/guilds/{guildID}/checkpoints/{checkpointID}/goals", s.requireToken(s.requireWrite(s.handleAPISaveGoal)))
+	s.mux.Handle("PATCH /api/v1/guilds/{guildID}/checkpoints/{checkpointID}/goals/{userID}", s.requireToken(s.requireWrite(s.handleAPIUpdateGoal)))
+}
+
+// requireToken authenticates the bearer token and checks it was issued for the guild in the path
+func (s *Server) requireToken(next http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := dbContext(r)
+		defer cancel()
+
+		raw, ok := apitoken.FromHeader(r.Header.Get("Authorization"))
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="checkpoint-bot"`)
+			writeJSON(w, http.StatusUnauthorized, apiError{Error: "missing or malformed bearer token"})
+			return
+		}
+
+		token, err := s.Database.GetApiTokenByHash(ctx, apitoken.Hash(raw))
+		if err == sql.ErrNoRows {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="checkpoint-bot", error="invalid_token"`)
+			writeJSON(w, http.StatusUnauthorized, apiError{Error: "invalid token"})
+			return
+		} else if err != nil {
+			log.Error("cannot get api token", "err", err)
+			writeJSON(w, http.StatusInternalServerError, apiError{Error: "error checking token"})
+			return
+		}
+
+		if token.GuildID != r.PathValue("guildID") {
+			writeJSON(w, http.StatusForbidden, apiError{Error: "token is not valid for this guild"})
+			return
+		}
+
+		if err := s.Database.TouchApiToken(ctx, token.ID); err != nil {
+			// Not fatal, the request is already authenticated
+			log.Warn("cannot update api token last use", "err", err, "token_id", token.ID)
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), tokenContextKey{}, token)))
+	})
+}
+
+// requireWrite rejects tokens that are only allowed to read, it must run after requireToken
+func (s *Server) requireWrite(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !apitoken.CanWrite(requestToken(r).Scope) {
+			writeJSON(w, http.StatusForbidden, apiError{Error: "token is read-only"})
+			return
+		}
+		next(w, r)
+	}
+}
+
+// handleAPICheckpoints lists a guild's checkpoints, filtered by ?channel=, ?from= and ?to= (YYYY-MM-DD)
+func (s *Server) handleAPICheckpoints(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := dbContext(r)
+	defer cancel()
+
+	guildID := r.PathValue("guildID")
+	guild, err := s.Database.GetGuild(ctx, guildID)
+	if err == sql.ErrNoRows {
+		writeJSON(w, http.StatusNotFound, apiError{Error: "guild not found"})
+		return
+	} else if err != nil {
+		log.Error("cannot get guild", "err", err, "guild", guildID)
+		writeJSON(w, http.StatusInternalServerError, apiError{Error: "error loading guild"})
+		return
+	}
+
+	query := r.URL.Query()
+	params, err := history.ListParams(*guild, history.Filter{
+		GuildID:   guildID,
+		ChannelID: query.Get("channel"),
+		FromDate:  query.Get("from"),
+		ToDate:    query.Get("to"),
+	})
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, apiError{Error: err.Error()})
+		return
+	}
+
+	checkpoints, err := s.Database.ListCheckpoints(ctx, params)
+	if err != nil {
+		log.Error("cannot list checkpoints", "err", err, "guild", guildID)
+		writeJSON(w, http.StatusInternalServerError, apiError{Error: "error loading checkpoints"})
+		return
+	}
+
+	response := make([]apiCheckpoint, 0, len(checkpoints))
+	for _, checkpoint := range checkpoints {
+		response = append(response, apiCheckpoint{
+			ID:          checkpoint.ID,
+			GuildID:     checkpoint.GuildID,
+			ChannelID:   checkpoint.ChannelID,
+			ScheduledAt: checkpoint.ScheduledAt,
+			CreatedBy:   checkpoint.DiscordUser,
+			CreatedAt:   nullTime(checkpoint.CreatedAt),
+		})
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// handleAPIGoals lists the goals of a checkpoint belonging to the guild
+func (s *Server) handleAPIGoals(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := dbContext(r)
+	defer cancel()
+
+	guildID := r.PathValue("guildID")
+	checkpointID, err := strconv.ParseInt(r.PathValue("checkpointID"), 10, 64)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, apiError{Error: "checkpoint id must be a number"})
+		return
+	}
+
+	checkpoint, err := s.Database.GetCheckpoint(ctx, checkpointID)
+	// Checkpoints of other guilds are reported as missing so ids can't be probed
+	if err == sql.ErrNoRows || (err == nil && checkpoint.GuildID != guildID) {
+		writeJSON(w, http.StatusNotFound, apiError{Error: "checkpoint not found"})
+		return
+	} else if err != nil {
+		log.Error("cannot get checkpoint", "err", err, "checkpoint", checkpointID)
+		writeJSON(w, http.StatusInternalServerError, apiError{Error: "error loading checkpoint"})
+		return
+	}
+
+	goals, err := s.Database.GetGoalsByCheckpoint(ctx, checkpoint.ID)
+	if err != nil {
+		log.Error("cannot get goals", "err", err, "checkpoint", checkpointID)
+		writeJSON(w, http.StatusInternalServerError, apiError{Error: "error loading goals"})
+		return
+	}
+
+	// Tokens see goals like their user does in Discord, admin tokens like a goal moderator
+	token := requestToken(r)
+	response := apiGoals(goals)
+	for n, goal := range goals {
+		visible, err := service.GoalVisibleTo(ctx, s.Database, goal, token.DiscordUser, token.Scope == apitoken.ScopeAdmin)
+		if err != nil {
+			log.Error("cannot check goal visibility", "err", err, "goal_id", goal.ID)
+			writeJSON(w, http.StatusInternalServerError, apiError{Error: "error loading goals"})
+			return
+		}
+		if !visible {
+			response[n].Description = service.HiddenGoalText(goal.Visibility)
+		}
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// handleAPIUserStats returns a user's goal and attendance totals in the guild
+func (s *Server) handleAPIUserStats(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := dbContext(r)
+	defer cancel()
+
+	guildID := r.PathValue("guildID")
+	userID := r.PathValue("userID")
+
+	userStats, err := stats.ForUser(ctx, s.Database, guildID, userID)
+	if err != nil {
+		log.Error("cannot get user stats", "err", err, "guild", guildID, "user", userID)
+		writeJSON(w, http.StatusInternalServerError, apiError{Error: "error loading stats"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, userStats)
+}
+
+func apiGoals(goals []queries.Goal) []apiGoal {
+	response := make([]apiGoal, 0, len(goals))
+	for _, goal := range goals {
+		response = append(response, apiGoal{
+			ID:           goal.ID,
+			CheckpointID: goal.CheckpointID,
+			UserID:       goal.DiscordUser,
+			Description:  goal.Description,
+			Visibility:   goal.Visibility,
+			Status:       goal.Status,
+			Progress:     goal.Progress,
+			Target:       goal.Target,
+			Unit:         goal.Unit,
+			Total:        goal.Total,
+			Verification: goal.Verification,
+			CreatedAt:    nullTime(goal.CreatedAt),
+		})
+	}
+	return response
+}
+
+func nullTime(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	utc := t.Time.UTC()
+	return &utc
+}
@@ -0,0 +1,226 @@
+package httpserver
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/metruzanca/checkpoint-bot/internal/apitoken"
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+	"github.com/metruzanca/checkpoint-bot/internal/settings"
+	"github.com/metruzanca/checkpoint-bot/internal/stats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// apiRequest builds a GET request carrying the given bearer token
+func apiRequest(path string, token string) *http.Request {
+	return apiRequestWithBody(http.MethodGet, path, token, "")
+}
+
+// apiRequestWithBody builds a request with a JSON body carrying the given bearer token
+func apiRequestWithBody(method string, path string, token string, body string) *http.Request {
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return req
+}
+
+// createToken stores a new API token for the guild and returns it
+func createToken(t *testing.T, s *Server, guildID string, scope string, userID string) string {
+	t.Helper()
+
+	token, hash, err := apitoken.Generate()
+	require.NoError(t, err)
+	_, err = s.Database.CreateApiToken(context.Background(), queries.CreateApiTokenParams{
+		GuildID: guildID, Name: "test", TokenHash: hash, CreatedBy: "20", Scope: scope, DiscordUser: userID,
+	})
+	require.NoError(t, err)
+	return token
+}
+
+// TestAPI tests token authentication and the read endpoints
+func TestAPI(t *testing.T) {
+	s := setupTestServer(t)
+	ctx := context.Background()
+
+	for _, guildID := range []string{"10", "11"} {
+		_, err := s.Database.CreateGuild(ctx, queries.CreateGuildParams{GuildID: guildID, Timezone: "UTC", OwnerID: "20"})
+		require.NoError(t, err)
+	}
+	checkpoint, err := s.Database.CreateCheckpoint(ctx, queries.CreateCheckpointParams{ScheduledAt: "2025-01-15T19:00:00Z", ChannelID: "30", GuildID: "10", DiscordUser: "20"})
+	require.NoError(t, err)
+	other, err := s.Database.CreateCheckpoint(ctx, queries.CreateCheckpointParams{ScheduledAt: "2025-01-15T19:00:00Z", ChannelID: "31", GuildID: "11", DiscordUser: "20"})
+	require.NoError(t, err)
+	_, err = s.Database.CreateGoal(ctx, queries.CreateGoalParams{DiscordUser: "40", Description: "Ship it", CheckpointID: checkpoint.ID})
+	require.NoError(t, err)
+	require.NoError(t, s.Database.UpdateGoalStatus(ctx, queries.UpdateGoalStatusParams{Status: "completed", CheckpointID: checkpoint.ID, DiscordUser: "40"}))
+
+	_, err = s.Database.CreateGoal(ctx, queries.CreateGoalParams{DiscordUser: "41", Description: "Secret plan", CheckpointID: checkpoint.ID, Visibility: "private"})
+	require.NoError(t, err)
+
+	token := createToken(t, s, "10", apitoken.ScopeRead, "20")
+
+	t.Run("missing token", func(t *testing.T) {
+		rec := serve(s, apiRequest("/api/v1/guilds/10/checkpoints", ""))
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		rec := serve(s, apiRequest("/api/v1/guilds/10/checkpoints", apitoken.Prefix+"nope"))
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("other guild", func(t *testing.T) {
+		rec := serve(s, apiRequest("/api/v1/guilds/11/checkpoints", token))
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("checkpoints", func(t *testing.T) {
+		rec := serve(s, apiRequest("/api/v1/guilds/10/checkpoints?from=2025-01-01&to=2025-01-31", token))
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var body []apiCheckpoint
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		require.Len(t, body, 1)
+		assert.Equal(t, checkpoint.ID, body[0].ID)
+
+		rec = serve(s, apiRequest("/api/v1/guilds/10/checkpoints?from=bad", token))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("goals", func(t *testing.T) {
+		rec := serve(s, apiRequest("/api/v1/guilds/10/checkpoints/"+strconv.FormatInt(checkpoint.ID, 10)+"/goals", token))
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var body []apiGoal
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		require.Len(t, body, 2)
+		assert.Equal(t, "Ship it", body[0].Description)
+		assert.Equal(t, "public", body[0].Visibility)
+		assert.Equal(t, "🔒 private goal", body[1].Description)
+		assert.Equal(t, "private", body[1].Visibility)
+
+		// The goal's owner and admin tokens can still read private goals
+		for _, visibleToken := range []string{createToken(t, s, "10", apitoken.ScopeRead, "41"), createToken(t, s, "10", apitoken.ScopeAdmin, "20")} {
+			rec = serve(s, apiRequest("/api/v1/guilds/10/checkpoints/"+strconv.FormatInt(checkpoint.ID, 10)+"/goals", visibleToken))
+			require.Equal(t, http.StatusOK, rec.Code)
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+			require.Len(t, body, 2)
+			assert.Equal(t, "Secret plan", body[1].Description)
+		}
+
+		rec = serve(s, apiRequest("/api/v1/guilds/10/checkpoints/"+strconv.FormatInt(other.ID, 10)+"/goals", token))
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("stats", func(t *testing.T) {
+		rec := serve(s, apiRequest("/api/v1/guilds/10/users/40/stats", token))
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var body stats.UserStats
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Equal(t, int64(1), body.Goals)
+		assert.Equal(t, int64(1), body.Completed)
+		assert.Equal(t, 1.0, body.CompletionRate)
+	})
+
+	record, err := s.Database.GetApiTokenByHash(ctx, apitoken.Hash(token))
+	require.NoError(t, err)
+	assert.True(t, record.LastUsedAt.Valid)
+}
+
+// TestAPIWrite tests that writes follow the same rules as the slash commands
+func TestAPIWrite(t *testing.T) {
+	s := setupTestServer(t)
+	ctx := context.Background()
+
+	_, err := s.Database.CreateGuild(ctx, queries.CreateGuildParams{GuildID: "10", Timezone: "UTC", OwnerID: "20"})
+	require.NoError(t, err)
+
+	readToken := createToken(t, s, "10", apitoken.ScopeRead, "40")
+	writeToken := createToken(t, s, "10", apitoken.ScopeWrite, "40")
+	adminToken := createToken(t, s, "10", apitoken.ScopeAdmin, "20")
+	tomorrow := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+	checkpointBody := `{"channel_id": "30", "date": "` + tomorrow + `", "time": "19:00"}`
+
+	rec := serve(s, apiRequestWithBody(http.MethodPost, "/api/v1/guilds/10/checkpoints", readToken, checkpointBody))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	rec = serve(s, apiRequestWithBody(http.MethodPost, "/api/v1/guilds/10/checkpoints", writeToken, `{"channel_id": "30", "date": "2020-01-01", "time": "19:00"}`))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = serve(s, apiRequestWithBody(http.MethodPost, "/api/v1/guilds/10/checkpoints", writeToken, checkpointBody))
+	require.Equal(t, http.StatusCreated, rec.Code)
+	var checkpoint apiCheckpoint
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &checkpoint))
+	assert.Equal(t, "40", checkpoint.CreatedBy)
+
+	rec = serve(s, apiRequestWithBody(http.MethodPost, "/api/v1/guilds/10/checkpoints", writeToken, checkpointBody))
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	goalsPath := "/api/v1/guilds/10/checkpoints/" + strconv.FormatInt(checkpoint.ID, 10) + "/goals"
+
+	rec = serve(s, apiRequestWithBody(http.MethodPatch, goalsPath+"/40", writeToken, `{"status": "completed"}`))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = serve(s, apiRequestWithBody(http.MethodPost, goalsPath, writeToken, `{"description": "Ship it"}`))
+	require.Equal(t, http.StatusCreated, rec.Code)
+
+	rec = serve(s, apiRequestWithBody(http.MethodPost, goalsPath, writeToken, `{"user_id": "50", "description": "Not mine"}`))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	rec = serve(s, apiRequestWithBody(http.MethodPatch, goalsPath+"/40", writeToken, `{"status": "done"}`))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = serve(s, apiRequestWithBody(http.MethodPatch, goalsPath+"/40", adminToken, `{"status": "completed"}`))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var goal apiGoal
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &goal))
+	assert.Equal(t, "Ship it", goal.Description)
+	assert.Equal(t, "completed", goal.Status)
+}
+
+// TestAPICreateCheckpointPermissions tests that checkpoint_channels and checkpoint_creators apply to write tokens
+func TestAPICreateCheckpointPermissions(t *testing.T) {
+	s := setupTestServer(t)
+	ctx := context.Background()
+
+	_, err := s.Database.CreateGuild(ctx, queries.CreateGuildParams{GuildID: "10", Timezone: "UTC", OwnerID: "20"})
+	require.NoError(t, err)
+	writeToken := createToken(t, s, "10", apitoken.ScopeWrite, "40")
+	adminToken := createToken(t, s, "10", apitoken.ScopeAdmin, "20")
+	tomorrow := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+	checkpointBody := func(channelID string) string {
+		return `{"channel_id": "` + channelID + `", "date": "` + tomorrow + `", "time": "19:00"}`
+	}
+
+	_, err = settings.Set(ctx, s.Database, "10", settings.CheckpointChannels, "31,32", "20")
+	require.NoError(t, err)
+	rec := serve(s, apiRequestWithBody(http.MethodPost, "/api/v1/guilds/10/checkpoints", writeToken, checkpointBody("30")))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Body.String(), "checkpoints can only be created in")
+	rec = serve(s, apiRequestWithBody(http.MethodPost, "/api/v1/guilds/10/checkpoints", writeToken, checkpointBody("31")))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	_, err = settings.Set(ctx, s.Database, "10", settings.CheckpointCreators, settings.CreatorsManagers, "20")
+	require.NoError(t, err)
+	rec = serve(s, apiRequestWithBody(http.MethodPost, "/api/v1/guilds/10/checkpoints", writeToken, checkpointBody("32")))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Body.String(), "only checkpoint managers")
+
+	// Admin tokens act as administrators
+	rec = serve(s, apiRequestWithBody(http.MethodPost, "/api/v1/guilds/10/checkpoints", adminToken, checkpointBody("32")))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+}
@@ -0,0 +1,301 @@
+package httpserver
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/charmbracelet/log"
+	"github.com/metruzanca/checkpoint-bot/internal/apitoken"
+	"github.com/metruzanca/checkpoint-bot/internal/audit"
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+	"github.com/metruzanca/checkpoint-bot/internal/permissions"
+	"github.com/metruzanca/checkpoint-bot/internal/service"
+	"github.com/metruzanca/checkpoint-bot/internal/settings"
+)
+
+// maxBodySize limits request bodies, a goal is at most a couple of KB
+const maxBodySize = 64 << 10
+
+type createCheckpointRequest struct {
+	ChannelID string `json:"channel_id"`
+	// Date (YYYY-MM-DD) and time (HH:MM or H:MM AM/PM) in the guild's timezone
+	Date string `json:"date"`
+	Time string `json:"time"`
+}
+
+type saveGoalRequest struct {
+	// UserID defaults to the token's user, editing other users' goals needs an admin token
+	UserID      string `json:"user_id"`
+	Description string `json:"description"`
+	Status      string `json:"status"`
+	Progress    *int64 `json:"progress"`
+	// Target and Unit make the goal quantitative (e.g. 20 km), a target of 0 removes it
+	Target *float64 `json:"target"`
+	Unit   string   `json:"unit"`
+}
+
+type updateGoalRequest struct {
+	Description string   `json:"description"`
+	Status      string   `json:"status"`
+	Progress    *int64   `json:"progress"`
+	Target      *float64 `json:"target"`
+	Unit        string   `json:"unit"`
+}
+
+// handleAPICreateCheckpoint schedules a checkpoint with the same rules as /checkpoint
+func (s *Server) handleAPICreateCheckpoint(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := dbContext(r)
+	defer cancel()
+
+	guildID := r.PathValue("guildID")
+	token := requestToken(r)
+
+	var body createCheckpointRequest
+	if !decodeJSON(w, r, &body) {
+		return
+	}
+	if body.ChannelID == "" {
+		writeJSON(w, http.StatusBadRequest, apiError{Error: "channel_id is required"})
+		return
+	}
+	if err := s.checkChannel(guildID, body.ChannelID); err != nil {
+		writeJSON(w, http.StatusBadRequest, apiError{Error: err.Error()})
+		return
+	}
+
+	guildSettings, err := settings.Load(ctx, s.Database, guildID)
+	if err != nil {
+		log.Error("cannot load guild settings", "err", err, "guild", guildID)
+		writeJSON(w, http.StatusInternalServerError, apiError{Error: "error loading settings"})
+		return
+	}
+	member := s.tokenMember(guildID, token)
+	if err := permissions.Check(guildSettings, member, permissions.CreateCheckpoint, permissions.Target{ChannelID: body.ChannelID}); err != nil {
+		log.Warn("permission denied", "action", permissions.CreateCheckpoint, "user", member.UserID, "channel", body.ChannelID, "guild", guildID, "token_id", token.ID)
+		writeJSON(w, http.StatusForbidden, apiError{Error: err.Error()})
+		return
+	}
+
+	guild, err := s.Database.GetGuild(ctx, guildID)
+	if err != nil {
+		log.Error("cannot get guild", "err", err, "guild", guildID)
+		writeJSON(w, http.StatusInternalServerError, apiError{Error: "error loading guild"})
+		return
+	}
+
+	checkpoint, err := service.CreateCheckpoint(ctx, s.Database, *guild, service.NewCheckpoint{
+		ChannelID: body.ChannelID,
+		UserID:    token.DiscordUser,
+		Date:      body.Date,
+		Time:      body.Time,
+	})
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	log.Info("checkpoint created via API", "checkpoint_id", checkpoint.ID, "guild", guildID, "channel", checkpoint.ChannelID, "token_id", token.ID)
+	s.notify(checkpoint.ChannelID, &discordgo.MessageEmbed{
+		Title:       "Checkpoint created",
+		Description: fmt.Sprintf("<@%s> scheduled checkpoint #%d for %s", token.DiscordUser, checkpoint.ID, discordTimestamp(checkpoint.ScheduledAt)),
+		Color:       0x0099ff,
+		Footer:      &discordgo.MessageEmbedFooter{Text: "via API"},
+	})
+
+	writeJSON(w, http.StatusCreated, apiCheckpoint{
+		ID:          checkpoint.ID,
+		GuildID:     checkpoint.GuildID,
+		ChannelID:   checkpoint.ChannelID,
+		ScheduledAt: checkpoint.ScheduledAt,
+		CreatedBy:   checkpoint.DiscordUser,
+		CreatedAt:   nullTime(checkpoint.CreatedAt),
+	})
+}
+
+// handleAPISaveGoal creates or updates a goal for an upcoming checkpoint with the same rules as /goal
+func (s *Server) handleAPISaveGoal(w http.ResponseWriter, r *http.Request) {
+	var body saveGoalRequest
+	if !decodeJSON(w, r, &body) {
+		return
+	}
+	if body.UserID == "" {
+		body.UserID = requestToken(r).DiscordUser
+	}
+	s.saveGoal(w, r, service.GoalChange{UserID: body.UserID, Description: body.Description, Status: body.Status, Progress: body.Progress, Target: body.Target, Unit: body.Unit}, false)
+}
+
+// handleAPIUpdateGoal updates the description, status, progress and/or target of an existing goal
+func (s *Server) handleAPIUpdateGoal(w http.ResponseWriter, r *http.Request) {
+	var body updateGoalRequest
+	if !decodeJSON(w, r, &body) {
+		return
+	}
+	if body.Description == "" && body.Status == "" && body.Progress == nil && body.Target == nil {
+		writeJSON(w, http.StatusBadRequest, apiError{Error: "description, status, progress or target is required"})
+		return
+	}
+	s.saveGoal(w, r, service.GoalChange{UserID: r.PathValue("userID"), Description: body.Description, Status: body.Status, Progress: body.Progress, Target: body.Target, Unit: body.Unit}, true)
+}
+
+// saveGoal applies a goal change as the token's user, mustExist rejects changes to missing goals
+func (s *Server) saveGoal(w http.ResponseWriter, r *http.Request, change service.GoalChange, mustExist bool) {
+	ctx, cancel := dbContext(r)
+	defer cancel()
+
+	guildID := r.PathValue("guildID")
+	token := requestToken(r)
+	change.ActorID = token.DiscordUser
+	change.Moderator = token.Scope == apitoken.ScopeAdmin
+	change.OverrideLock = token.Scope == apitoken.ScopeAdmin
+	change.Source = audit.SourceAPI
+
+	checkpointID, err := strconv.ParseInt(r.PathValue("checkpointID"), 10, 64)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, apiError{Error: "checkpoint id must be a number"})
+		return
+	}
+
+	checkpoint, err := service.GoalCheckpoint(ctx, s.Database, guildID, checkpointID)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	if mustExist {
+		_, err := s.Database.GetGoalByCheckpointAndUser(ctx, queries.GetGoalByCheckpointAndUserParams{
+			CheckpointID: checkpoint.ID,
+			DiscordUser:  change.UserID,
+		})
+		if err != nil {
+			writeServiceError(w, goalLookupError(err))
+			return
+		}
+	}
+
+	result, err := service.SaveGoal(ctx, s.Database, checkpoint.ID, change)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	log.Info("goal saved via API", "checkpoint_id", checkpoint.ID, "user", change.UserID, "created", result.Created, "status", result.Goal.Status, "token_id", token.ID)
+	action := "updated"
+	if result.Created {
+		action = "set"
+	}
+	description := result.Goal.Description
+	if result.Goal.Visibility != settings.VisibilityPublic {
+		description = service.HiddenGoalText(result.Goal.Visibility)
+	}
+	s.notify(checkpoint.ChannelID, &discordgo.MessageEmbed{
+		Title:       fmt.Sprintf("Goal %s", action),
+		Description: fmt.Sprintf("<@%s>'s goal for checkpoint #%d (%s):\n%s", change.UserID, checkpoint.ID, result.Goal.Status, description),
+		Color:       0x0099ff,
+		Footer:      &discordgo.MessageEmbedFooter{Text: "via API"},
+	})
+
+	status := http.StatusOK
+	if result.Created {
+		status = http.StatusCreated
+	}
+	writeJSON(w, status, apiGoals([]queries.Goal{result.Goal})[0])
+}
+
+// goalLookupError maps a missing goal to the service error so it's reported as 404
+func goalLookupError(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return service.ErrGoalNotFound
+	}
+	return err
+}
+
+// writeServiceError maps the service's rule violations to HTTP statuses, anything else is a 500
+func writeServiceError(w http.ResponseWriter, err error) {
+	var existsErr *service.CheckpointExistsError
+	switch {
+	case errors.As(err, &existsErr):
+		writeJSON(w, http.StatusConflict, apiError{Error: fmt.Sprintf("%s (checkpoint %d)", err, existsErr.Checkpoint.ID)})
+	case errors.Is(err, service.ErrNotAllowed):
+		writeJSON(w, http.StatusForbidden, apiError{Error: err.Error()})
+	case errors.Is(err, service.ErrCheckpointNotFound), errors.Is(err, service.ErrGoalNotFound), errors.Is(err, service.ErrNoUpcomingCheckpoint):
+		writeJSON(w, http.StatusNotFound, apiError{Error: err.Error()})
+	case errors.Is(err, service.ErrCheckpointNotUpcoming), errors.Is(err, service.ErrGoalsLocked):
+		writeJSON(w, http.StatusConflict, apiError{Error: err.Error()})
+	case service.IsUserError(err):
+		writeJSON(w, http.StatusBadRequest, apiError{Error: err.Error()})
+	default:
+		log.Error("cannot handle API request", "err", err)
+		writeJSON(w, http.StatusInternalServerError, apiError{Error: "internal error"})
+	}
+}
+
+// decodeJSON reads the request body into v, writing a 400 and returning false if it's invalid
+func decodeJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		writeJSON(w, http.StatusBadRequest, apiError{Error: fmt.Sprintf("invalid JSON body: %s", err)})
+		return false
+	}
+	return true
+}
+
+// checkChannel verifies the channel belongs to the guild using the gateway's cache.
+// The check is skipped while the gateway isn't connected, since the cache is empty.
+func (s *Server) checkChannel(guildID string, channelID string) error {
+	if s.discordReady() != nil || s.Discord.State == nil {
+		return nil
+	}
+	channel, err := s.Discord.State.Channel(channelID)
+	if err != nil || channel.GuildID != guildID {
+		return errors.New("channel_id is not a channel of this guild")
+	}
+	return nil
+}
+
+// tokenMember is the token's user for permission checks. Admin tokens act as administrators,
+// other tokens have their user's roles, or none while the gateway isn't connected.
+func (s *Server) tokenMember(guildID string, token *queries.ApiToken) permissions.Member {
+	member := permissions.Member{UserID: token.DiscordUser, IsAdmin: token.Scope == apitoken.ScopeAdmin}
+	if member.IsAdmin || s.discordReady() != nil || s.Discord.State == nil {
+		return member
+	}
+	discordMember, err := s.Discord.State.Member(guildID, token.DiscordUser)
+	if err != nil {
+		// Not cached, members are only in the state when the gateway sent them
+		discordMember, err = s.Discord.GuildMember(guildID, token.DiscordUser)
+	}
+	if err != nil {
+		log.Warn("cannot get token user's roles", "err", err, "user", token.DiscordUser, "guild", guildID)
+		return member
+	}
+	member.RoleIDs = discordMember.Roles
+	return member
+}
+
+// notify posts an embed about a change made through the API to the channel it affects.
+// Failures are only logged, the change itself has already been saved.
+func (s *Server) notify(channelID string, embed *discordgo.MessageEmbed) {
+	if err := s.discordReady(); err != nil {
+		log.Warn("cannot notify channel of API change", "err", err, "channel", channelID)
+		return
+	}
+	if _, err := s.Discord.ChannelMessageSendEmbed(channelID, embed); err != nil {
+		log.Warn("cannot notify channel of API change", "err", err, "channel", channelID)
+	}
+}
+
+// discordTimestamp renders an RFC3339 time as a Discord timestamp, shown in each reader's timezone
+func discordTimestamp(scheduledAt string) string {
+	t, err := time.Parse(time.RFC3339, scheduledAt)
+	if err != nil {
+		return scheduledAt
+	}
+	return fmt.Sprintf("<t:%d:F>", t.Unix())
+}
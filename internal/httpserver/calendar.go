@@ -0,0 +1,53 @@
+package httpserver
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	"github.com/charmbracelet/log"
+	"github.com/metruzanca/checkpoint-bot/internal/calendar"
+)
+
+// handleCalendar serves an .ics feed of a guild's checkpoints, ?channel= limits it to one channel.
+// The URL carries the guild's feed token from /calendar, a wrong token is reported as a missing guild.
+func (s *Server) handleCalendar(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := dbContext(r)
+	defer cancel()
+
+	guildID := r.PathValue("guildID")
+	channelID := r.URL.Query().Get("channel")
+
+	valid, err := calendar.ValidFeedToken(ctx, s.Database, guildID, r.PathValue("token"))
+	if err != nil {
+		log.Error("cannot check calendar token", "err", err, "guild", guildID)
+		http.Error(w, "error loading calendar", http.StatusInternalServerError)
+		return
+	} else if !valid {
+		http.Error(w, "guild not found", http.StatusNotFound)
+		return
+	}
+
+	cal, err := calendar.ForGuild(ctx, s.Database, guildID, channelID)
+	if err == sql.ErrNoRows {
+		http.Error(w, "guild not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		log.Error("cannot load calendar", "err", err, "guild", guildID, "channel", channelID)
+		http.Error(w, "error loading calendar", http.StatusInternalServerError)
+		return
+	}
+
+	// Render before writing headers so errors can still be reported
+	var buf bytes.Buffer
+	if err := calendar.Write(&buf, cal); err != nil {
+		log.Error("cannot write calendar", "err", err, "guild", guildID, "channel", channelID)
+		http.Error(w, "error writing calendar", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", calendar.Filename(guildID)))
+	w.Write(buf.Bytes())
+}
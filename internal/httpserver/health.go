@@ -0,0 +1,81 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/metruzanca/checkpoint-bot/internal/version"
+)
+
+// healthResponse is returned by /healthz and /readyz
+type healthResponse struct {
+	Status string            `json:"status"`
+	Uptime string            `json:"uptime,omitempty"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// handleHealthz reports that the process is up, it never checks dependencies
+func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, healthResponse{
+		Status: "ok",
+		Uptime: time.Since(s.startedAt).Round(time.Second).String(),
+	})
+}
+
+// handleReadyz reports whether the Discord gateway is connected and the database accepts writes
+func (s *Server) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := dbContext(r)
+	defer cancel()
+
+	checks := map[string]string{
+		"discord":  "ok",
+		"database": "ok",
+	}
+	ready := true
+
+	if err := s.discordReady(); err != nil {
+		checks["discord"] = err.Error()
+		ready = false
+	}
+	if err := s.Database.Ping(ctx); err != nil {
+		log.Warn("readiness database check failed", "err", err)
+		checks["database"] = err.Error()
+		ready = false
+	}
+
+	if !ready {
+		writeJSON(w, http.StatusServiceUnavailable, healthResponse{Status: "not ready", Checks: checks})
+		return
+	}
+	writeJSON(w, http.StatusOK, healthResponse{Status: "ready", Checks: checks})
+}
+
+// handleVersion reports build information
+func (s *Server) handleVersion(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, version.Get())
+}
+
+// discordReady checks the gateway session has received READY and is heartbeating
+func (s *Server) discordReady() error {
+	if s.Discord == nil {
+		return errors.New("no discord session")
+	}
+	s.Discord.RLock()
+	defer s.Discord.RUnlock()
+	if !s.Discord.DataReady {
+		return errors.New("gateway not connected")
+	}
+	return nil
+}
+
+// writeJSON writes v as a JSON response with the given status code
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Error("cannot encode JSON response", "err", err)
+	}
+}
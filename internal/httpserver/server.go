@@ -0,0 +1,113 @@
+// httpserver package serves the bot's optional HTTP endpoints.
+package httpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/charmbracelet/log"
+	"github.com/metruzanca/checkpoint-bot/internal/dashboard"
+	"github.com/metruzanca/checkpoint-bot/internal/database"
+	"github.com/metruzanca/checkpoint-bot/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Server is an HTTP server backed by the same database and Discord session as the bot
+type Server struct {
+	Database database.CheckpointDatabase
+	Discord  *discordgo.Session
+
+	server    *http.Server
+	mux       *http.ServeMux
+	startedAt time.Time
+}
+
+// New creates a server listening on addr (e.g. ":8080"), call Start to begin serving
+func New(addr string, db database.CheckpointDatabase, discord *discordgo.Session) *Server {
+	s := &Server{
+		Database:  db,
+		Discord:   discord,
+		mux:       http.NewServeMux(),
+		startedAt: time.Now(),
+	}
+	s.routes()
+	s.server = &http.Server{
+		Addr:              addr,
+		Handler:           s.logRequests(s.mux),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return s
+}
+
+// routes registers every endpoint on the server's mux
+func (s *Server) routes() {
+	s.mux.HandleFunc("GET /healthz", s.handleHealthz)
+	s.mux.HandleFunc("GET /readyz", s.handleReadyz)
+	s.mux.HandleFunc("GET /version", s.handleVersion)
+	s.mux.Handle("GET /metrics", s.metricsHandler())
+	s.mux.HandleFunc("GET /calendar/{guildID}/{token}", s.handleCalendar)
+	s.apiRoutes()
+}
+
+// metricsHandler serves the process wide metrics plus the guild totals read from this server's database
+func (s *Server) metricsHandler() http.Handler {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(metrics.NewGuildCollector(s.Database))
+	gatherers := prometheus.Gatherers{prometheus.DefaultGatherer, registry}
+	return promhttp.HandlerFor(gatherers, promhttp.HandlerOpts{})
+}
+
+// Start binds the listener and serves in the background.
+// Binding errors (e.g. port already in use) are returned instead of only being logged.
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", s.server.Addr)
+	if err != nil {
+		return fmt.Errorf("cannot listen on %s: %w", s.server.Addr, err)
+	}
+
+	go func() {
+		if err := s.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error("HTTP server stopped", "err", err)
+		}
+	}()
+
+	log.Info("HTTP server listening", "addr", listener.Addr().String())
+	return nil
+}
+
+// Shutdown stops accepting connections and waits for in-flight requests until ctx is done
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.server.Shutdown(ctx)
+}
+
+// logRequests logs every request at debug level
+func (s *Server) logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		next.ServeHTTP(w, r)
+		log.Debug("HTTP request", "method", r.Method, "path", r.URL.Path, "duration", time.Since(start))
+	})
+}
+
+// dbContext creates a context with timeout for database operations tied to the request
+func dbContext(r *http.Request) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(r.Context(), 10*time.Second)
+}
+
+// EnableDashboard serves the admin web dashboard under /dashboard/
+func (s *Server) EnableDashboard(cfg dashboard.Config) error {
+	d, err := dashboard.New(s.Database, cfg)
+	if err != nil {
+		return err
+	}
+	d.Register(s.mux)
+	log.Info("Dashboard enabled", "url", strings.TrimSuffix(cfg.PublicURL, "/")+"/dashboard/")
+	return nil
+}
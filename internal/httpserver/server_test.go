@@ -0,0 +1,117 @@
+package httpserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/metruzanca/checkpoint-bot/internal/calendar"
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+	"github.com/metruzanca/checkpoint-bot/internal/database/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupTestServer creates a server backed by a file SQLite database and a disconnected Discord session
+func setupTestServer(t *testing.T) *Server {
+	t.Helper()
+
+	db := sqlite.NewSqliteDatabase(filepath.Join(t.TempDir(), "checkpoint.db"))
+	t.Cleanup(func() { db.Close() })
+
+	return New(":0", db, &discordgo.Session{})
+}
+
+// serve runs a request through the server's handler and returns the recorded response
+func serve(s *Server, req *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	s.server.Handler.ServeHTTP(rec, req)
+	return rec
+}
+
+// TestHealthz tests that liveness doesn't depend on Discord
+func TestHealthz(t *testing.T) {
+	s := setupTestServer(t)
+
+	rec := serve(s, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+// TestReadyz tests that readiness follows the Discord session state
+func TestReadyz(t *testing.T) {
+	s := setupTestServer(t)
+
+	rec := serve(s, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	var body healthResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, "gateway not connected", body.Checks["discord"])
+	assert.Equal(t, "ok", body.Checks["database"])
+
+	s.Discord.DataReady = true
+	rec = serve(s, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+// TestMetrics tests that query metrics and per-guild totals are exposed
+func TestMetrics(t *testing.T) {
+	s := setupTestServer(t)
+	ctx := context.Background()
+
+	_, err := s.Database.CreateGuild(ctx, queries.CreateGuildParams{GuildID: "10", Timezone: "UTC", OwnerID: "20"})
+	require.NoError(t, err)
+	_, err = s.Database.CreateCheckpoint(ctx, queries.CreateCheckpointParams{ScheduledAt: "2025-01-15T19:00:00Z", ChannelID: "30", GuildID: "10", DiscordUser: "20"})
+	require.NoError(t, err)
+
+	rec := serve(s, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	body := rec.Body.String()
+	assert.Contains(t, body, `checkpoint_guild_checkpoints{guild_id="10"} 1`)
+	assert.Contains(t, body, `checkpoint_guild_goals{guild_id="10"} 0`)
+	assert.Contains(t, body, `checkpoint_db_query_duration_seconds_count{query="CreateCheckpoint"}`)
+}
+
+// TestCalendar tests that calendar feeds are only served with the guild's feed token
+func TestCalendar(t *testing.T) {
+	s := setupTestServer(t)
+	ctx := context.Background()
+
+	_, err := s.Database.CreateGuild(ctx, queries.CreateGuildParams{GuildID: "10", Timezone: "UTC", OwnerID: "20"})
+	require.NoError(t, err)
+	_, err = s.Database.CreateCheckpoint(ctx, queries.CreateCheckpointParams{ScheduledAt: "2025-01-15T19:00:00Z", ChannelID: "30", GuildID: "10", DiscordUser: "20"})
+	require.NoError(t, err)
+
+	// No token has been handed out yet
+	rec := serve(s, httptest.NewRequest(http.MethodGet, "/calendar/10", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	rec = serve(s, httptest.NewRequest(http.MethodGet, "/calendar/10/guess", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	previous, err := calendar.NewFeedToken(ctx, s.Database, "10", "20")
+	require.NoError(t, err)
+	token, err := calendar.NewFeedToken(ctx, s.Database, "10", "20")
+	require.NoError(t, err)
+
+	// Only the hash is stored and a new token replaces the previous one
+	stored, err := s.Database.GetCalendarTokenHash(ctx, "10")
+	require.NoError(t, err)
+	assert.NotContains(t, stored, token)
+	rec = serve(s, httptest.NewRequest(http.MethodGet, "/calendar/10/"+previous, nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = serve(s, httptest.NewRequest(http.MethodGet, "/calendar/10/"+token, nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/calendar; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Body.String(), "BEGIN:VEVENT")
+
+	rec = serve(s, httptest.NewRequest(http.MethodGet, "/calendar/10/"+token+"x", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	rec = serve(s, httptest.NewRequest(http.MethodGet, "/calendar/11/"+token, nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
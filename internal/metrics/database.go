@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+)
+
+// instrumentedDB records latency and errors for every query the CheckpointDatabase runs.
+// It wraps the connection handed to sqlc, so new queries are covered without touching the metrics code.
+type instrumentedDB struct {
+	db queries.DBTX
+}
+
+// InstrumentDB wraps a connection or transaction used by the sqlc queries
+func InstrumentDB(db queries.DBTX) queries.DBTX {
+	return &instrumentedDB{db: db}
+}
+
+func (i *instrumentedDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	start := time.Now()
+	result, err := i.db.ExecContext(ctx, query, args...)
+	observe(query, start, err)
+	return result, err
+}
+
+func (i *instrumentedDB) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	start := time.Now()
+	stmt, err := i.db.PrepareContext(ctx, query)
+	observe(query, start, err)
+	return stmt, err
+}
+
+func (i *instrumentedDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	start := time.Now()
+	rows, err := i.db.QueryContext(ctx, query, args...)
+	observe(query, start, err)
+	return rows, err
+}
+
+func (i *instrumentedDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	start := time.Now()
+	row := i.db.QueryRowContext(ctx, query, args...)
+	// Err doesn't include sql.ErrNoRows, which is reported on Scan and isn't a failure anyway
+	observe(query, start, row.Err())
+	return row
+}
+
+func observe(query string, start time.Time, err error) {
+	name := queryName(query)
+	DBQueryDuration.WithLabelValues(name).Observe(time.Since(start).Seconds())
+	if err != nil {
+		DBQueryErrors.WithLabelValues(name).Inc()
+	}
+}
+
+// queryName extracts the name from the "-- name: CreateGoal :one" header sqlc puts on every query
+func queryName(query string) string {
+	header, _, _ := strings.Cut(query, "\n")
+	fields := strings.Fields(header)
+	if len(fields) >= 3 && fields[0] == "--" && fields[1] == "name:" {
+		return fields[2]
+	}
+	return "other"
+}
@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/metruzanca/checkpoint-bot/internal/database"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	guildCheckpointsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "guild", "checkpoints"),
+		"Number of checkpoints created in a guild.",
+		[]string{"guild_id"}, nil,
+	)
+	guildGoalsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "guild", "goals"),
+		"Number of goals set in a guild.",
+		[]string{"guild_id"}, nil,
+	)
+)
+
+// guildCollector reads per-guild totals from the database on every scrape
+type guildCollector struct {
+	db database.CheckpointDatabase
+}
+
+// NewGuildCollector creates a collector exposing checkpoint and goal totals per guild
+func NewGuildCollector(db database.CheckpointDatabase) prometheus.Collector {
+	return &guildCollector{db: db}
+}
+
+func (c *guildCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- guildCheckpointsDesc
+	ch <- guildGoalsDesc
+}
+
+func (c *guildCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	totals, err := c.db.GetGuildTotals(ctx)
+	if err != nil {
+		log.Error("cannot collect guild totals", "err", err)
+		return
+	}
+
+	for _, total := range totals {
+		ch <- prometheus.MustNewConstMetric(guildCheckpointsDesc, prometheus.GaugeValue, float64(total.Checkpoints), total.GuildID)
+		ch <- prometheus.MustNewConstMetric(guildGoalsDesc, prometheus.GaugeValue, float64(total.Goals), total.GuildID)
+	}
+}
@@ -0,0 +1,62 @@
+// metrics package defines the Prometheus metrics exposed on the HTTP server's /metrics endpoint.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "checkpoint"
+
+var (
+	// CommandInvocations counts slash command executions by command name
+	CommandInvocations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "command_invocations_total",
+		Help:      "Number of slash commands executed.",
+	}, []string{"command"})
+
+	// CommandDuration tracks how long slash command handlers take
+	CommandDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "command_duration_seconds",
+		Help:      "Time spent handling slash commands.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"command"})
+
+	// RateLimitRejections counts commands rejected by the per-user rate limiter
+	RateLimitRejections = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limit_rejections_total",
+		Help:      "Number of slash commands rejected by the rate limiter.",
+	}, []string{"command"})
+
+	// DBQueryDuration tracks database query latency by sqlc query name
+	DBQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Time spent executing database queries.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+	}, []string{"query"})
+
+	// DBQueryErrors counts failed database queries by sqlc query name
+	DBQueryErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "db_query_errors_total",
+		Help:      "Number of database queries that returned an error.",
+	}, []string{"query"})
+
+	// GatewayDisconnects counts Discord gateway disconnections
+	GatewayDisconnects = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "gateway_disconnects_total",
+		Help:      "Number of times the Discord gateway connection was lost.",
+	})
+
+	// GatewayReconnects counts Discord gateway reconnections (new sessions and resumes)
+	GatewayReconnects = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "gateway_reconnects_total",
+		Help:      "Number of times the Discord gateway reconnected.",
+	}, []string{"type"})
+)
@@ -0,0 +1,111 @@
+// permissions package decides which members can manage checkpoints, goals and the bot, based on the guild's settings.
+package permissions
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/metruzanca/checkpoint-bot/internal/settings"
+)
+
+// Action is something only some members may do
+type Action string
+
+const (
+	// CreateCheckpoint schedules a checkpoint in a channel
+	CreateCheckpoint Action = "create_checkpoint"
+	// ManageCheckpoint reschedules or cancels a checkpoint, Target.OwnerID is its creator
+	ManageCheckpoint Action = "manage_checkpoint"
+	// ModerateGoals edits another member's goals, Target.OwnerID is the goal's owner
+	ModerateGoals Action = "moderate_goals"
+	// OverrideGoalLock sets or edits goals after the checkpoint's goal_lock
+	OverrideGoalLock Action = "override_goal_lock"
+	// ManageGuild changes settings, API tokens and webhooks or exports the guild's data
+	ManageGuild Action = "manage_guild"
+)
+
+// Member is who is asking to do something
+type Member struct {
+	UserID  string
+	RoleIDs []string
+	// IsAdmin members (Administrator permission) can do everything
+	IsAdmin bool
+}
+
+// FromDiscord builds a Member from the member invoking an interaction
+func FromDiscord(member *discordgo.Member) Member {
+	if member == nil || member.User == nil {
+		return Member{}
+	}
+	return Member{
+		UserID:  member.User.ID,
+		RoleIDs: member.Roles,
+		IsAdmin: member.Permissions&discordgo.PermissionAdministrator != 0,
+	}
+}
+
+// HasRole reports whether the member has roleID, an empty roleID is never held
+func (m Member) HasRole(roleID string) bool {
+	return roleID != "" && slices.Contains(m.RoleIDs, roleID)
+}
+
+// Target is what the action applies to, fields that don't matter for an action are left empty
+type Target struct {
+	ChannelID string
+	OwnerID   string
+}
+
+// ForbiddenError explains why an action isn't allowed, its message can be shown to the user
+type ForbiddenError struct {
+	Action Action
+	Reason string
+}
+
+func (e *ForbiddenError) Error() string {
+	return e.Reason
+}
+
+// Check returns a *ForbiddenError unless member may perform action on target
+func Check(guildSettings settings.Settings, member Member, action Action, target Target) error {
+	if member.IsAdmin {
+		return nil
+	}
+	isManager := member.HasRole(guildSettings.ManagerRoleID)
+
+	switch action {
+	case CreateCheckpoint:
+		if len(guildSettings.CheckpointChannelIDs) > 0 && !slices.Contains(guildSettings.CheckpointChannelIDs, target.ChannelID) {
+			return &ForbiddenError{Action: action, Reason: "checkpoints can only be created in " + channelList(guildSettings.CheckpointChannelIDs)}
+		}
+		if guildSettings.CheckpointCreators == settings.CreatorsManagers && !isManager {
+			return &ForbiddenError{Action: action, Reason: "only checkpoint managers and administrators can create checkpoints"}
+		}
+		return nil
+	case ManageCheckpoint:
+		if target.OwnerID == member.UserID || isManager {
+			return nil
+		}
+		return &ForbiddenError{Action: action, Reason: "only the checkpoint's creator, checkpoint managers and administrators can change it"}
+	case ModerateGoals:
+		if target.OwnerID == member.UserID || member.HasRole(guildSettings.GoalModeratorRoleID) {
+			return nil
+		}
+		return &ForbiddenError{Action: action, Reason: "you don't have permission to edit other users' goals"}
+	case OverrideGoalLock:
+		return &ForbiddenError{Action: action, Reason: "goals are locked for this checkpoint, only administrators can change them now"}
+	case ManageGuild:
+		return &ForbiddenError{Action: action, Reason: "only administrators can do this"}
+	}
+	return &ForbiddenError{Action: action, Reason: fmt.Sprintf("unknown action %s", action)}
+}
+
+// channelList formats channel IDs as mentions
+func channelList(channelIDs []string) string {
+	mentions := make([]string, len(channelIDs))
+	for i, id := range channelIDs {
+		mentions[i] = "<#" + id + ">"
+	}
+	return strings.Join(mentions, ", ")
+}
@@ -0,0 +1,68 @@
+package permissions
+
+import (
+	"testing"
+
+	"github.com/metruzanca/checkpoint-bot/internal/settings"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCheck tests each action against the roles and restrictions configured in settings
+func TestCheck(t *testing.T) {
+	defaults := settings.Settings{CheckpointCreators: settings.CreatorsEveryone}
+	restricted := settings.Settings{
+		ManagerRoleID:        "100",
+		GoalModeratorRoleID:  "200",
+		CheckpointCreators:   settings.CreatorsManagers,
+		CheckpointChannelIDs: []string{"10", "11"},
+	}
+
+	admin := Member{UserID: "1", IsAdmin: true}
+	member := Member{UserID: "2"}
+	manager := Member{UserID: "3", RoleIDs: []string{"100"}}
+	moderator := Member{UserID: "4", RoleIDs: []string{"200"}}
+
+	tests := []struct {
+		name     string
+		settings settings.Settings
+		member   Member
+		action   Action
+		target   Target
+		allowed  bool
+	}{
+		{"anyone creates by default", defaults, member, CreateCheckpoint, Target{ChannelID: "99"}, true},
+		{"member can't create when restricted to managers", restricted, member, CreateCheckpoint, Target{ChannelID: "10"}, false},
+		{"manager creates in allowed channel", restricted, manager, CreateCheckpoint, Target{ChannelID: "10"}, true},
+		{"manager can't create in other channels", restricted, manager, CreateCheckpoint, Target{ChannelID: "99"}, false},
+		{"admin creates anywhere", restricted, admin, CreateCheckpoint, Target{ChannelID: "99"}, true},
+
+		{"creator manages own checkpoint", defaults, member, ManageCheckpoint, Target{OwnerID: "2"}, true},
+		{"member can't manage others' checkpoints", defaults, member, ManageCheckpoint, Target{OwnerID: "9"}, false},
+		{"manager manages others' checkpoints", restricted, manager, ManageCheckpoint, Target{OwnerID: "9"}, true},
+		{"manager role unset", defaults, manager, ManageCheckpoint, Target{OwnerID: "9"}, false},
+
+		{"member edits own goal", restricted, member, ModerateGoals, Target{OwnerID: "2"}, true},
+		{"member can't edit others' goals", restricted, member, ModerateGoals, Target{OwnerID: "9"}, false},
+		{"moderator edits others' goals", restricted, moderator, ModerateGoals, Target{OwnerID: "9"}, true},
+		{"manager isn't a goal moderator", restricted, manager, ModerateGoals, Target{OwnerID: "9"}, false},
+		{"admin edits others' goals", defaults, admin, ModerateGoals, Target{OwnerID: "9"}, true},
+
+		{"moderator can't override the goal lock", restricted, moderator, OverrideGoalLock, Target{OwnerID: "9"}, false},
+		{"admin overrides the goal lock", defaults, admin, OverrideGoalLock, Target{OwnerID: "9"}, true},
+
+		{"only admins manage the guild", restricted, manager, ManageGuild, Target{}, false},
+		{"admin manages the guild", defaults, admin, ManageGuild, Target{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Check(tt.settings, tt.member, tt.action, tt.target)
+			if tt.allowed {
+				assert.NoError(t, err)
+			} else {
+				var forbidden *ForbiddenError
+				assert.ErrorAs(t, err, &forbidden)
+			}
+		})
+	}
+}
@@ -1,14 +1,23 @@
 package server
 
 import (
+	"context"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/charmbracelet/log"
+	"github.com/metruzanca/checkpoint-bot/internal/audit"
+	"github.com/metruzanca/checkpoint-bot/internal/calendar"
+	"github.com/metruzanca/checkpoint-bot/internal/dashboard"
 	"github.com/metruzanca/checkpoint-bot/internal/database"
 	"github.com/metruzanca/checkpoint-bot/internal/database/sqlite"
+	"github.com/metruzanca/checkpoint-bot/internal/events"
+	"github.com/metruzanca/checkpoint-bot/internal/httpserver"
+	"github.com/metruzanca/checkpoint-bot/internal/metrics"
 	"github.com/metruzanca/checkpoint-bot/internal/server/commands"
 	"github.com/metruzanca/checkpoint-bot/internal/util"
+	"github.com/metruzanca/checkpoint-bot/internal/webhook"
 	"github.com/spf13/viper"
 
 	"github.com/bwmarrin/discordgo"
@@ -18,6 +27,18 @@ type Bot struct {
 	DiscordClient  *discordgo.Session
 	Database       database.CheckpointDatabase
 	CommandHandler *commands.CommandHandler
+	// HTTPServer is only set when HTTP_ADDR is configured
+	HTTPServer *httpserver.Server
+	Webhooks   *webhook.Dispatcher
+	Lifecycle  *events.Watcher
+	// AuditMirror posts audit log entries to each guild's mod_log_channel
+	AuditMirror *audit.Mirror
+	// Prompter sends goal update prompts and checkpoint recaps
+	Prompter *commands.Prompter
+	// PartnerNotifier DMs accountability partners about each other's goals
+	PartnerNotifier *commands.PartnerNotifier
+	// Reminder posts reminders before checkpoints at each guild's reminder_offsets
+	Reminder *commands.Reminder
 }
 
 func NewBot(token string, dbPath string) *Bot {
@@ -37,6 +58,45 @@ func init() {
 	startTime = time.Now()
 }
 
+// StartServices starts the HTTP server and the background services, call it before Start
+// so /readyz can report the gateway as not ready yet
+func (b *Bot) StartServices() error {
+	// Optional HTTP server (health checks, metrics, calendar feeds)
+	if addr := viper.GetString("HTTP_ADDR"); addr != "" {
+		b.HTTPServer = httpserver.New(addr, b.Database, b.DiscordClient)
+		if clientID := viper.GetString("OAUTH_CLIENT_ID"); clientID != "" {
+			err := b.HTTPServer.EnableDashboard(dashboard.Config{
+				ClientID:     clientID,
+				ClientSecret: viper.GetString("OAUTH_CLIENT_SECRET"),
+				PublicURL:    viper.GetString("PUBLIC_URL"),
+			})
+			if err != nil {
+				return fmt.Errorf("Error enabling dashboard: %w", err)
+			}
+		}
+		if err := b.HTTPServer.Start(); err != nil {
+			return fmt.Errorf("Error starting HTTP server: %w", err)
+		}
+	}
+
+	// Webhooks subscribe to database events, checkpoint start/end events come from the lifecycle watcher
+	b.Webhooks = webhook.NewDispatcher(b.Database, nil)
+	b.Webhooks.Start()
+	b.Lifecycle = events.NewWatcher(b.Database, b.Database.Events(), calendar.EventDuration)
+	b.Lifecycle.Start()
+	b.AuditMirror = audit.NewMirror(b.Database, b.DiscordClient)
+	b.AuditMirror.Start()
+	b.Prompter = commands.NewPrompter(b.Database, b.DiscordClient)
+	b.Prompter.Start()
+	b.PartnerNotifier = commands.NewPartnerNotifier(b.Database, b.DiscordClient)
+	b.PartnerNotifier.Start()
+	b.Reminder = commands.NewReminder(b.Database, b.DiscordClient)
+	b.Reminder.Start()
+
+	return nil
+}
+
+// Start connects to Discord and registers the commands
 func (b *Bot) Start() error {
 	// Startup logging
 	b.DiscordClient.AddHandler(func(s *discordgo.Session, r *discordgo.Ready) {
@@ -72,9 +132,26 @@ func (b *Bot) Start() error {
 		}
 	})
 
-	err := b.DiscordClient.Open()
-	if err != nil {
-		return fmt.Errorf("Error opening Discord session: %w", err)
+	// Gateway connection metrics, the first Connect is the initial login rather than a reconnect
+	// Handlers run in their own goroutines
+	var connected atomic.Bool
+	b.DiscordClient.AddHandler(func(s *discordgo.Session, c *discordgo.Connect) {
+		if connected.Swap(true) {
+			metrics.GatewayReconnects.WithLabelValues("connect").Inc()
+			log.Info("Reconnected to Discord gateway")
+		}
+	})
+	b.DiscordClient.AddHandler(func(s *discordgo.Session, r *discordgo.Resumed) {
+		metrics.GatewayReconnects.WithLabelValues("resume").Inc()
+		log.Info("Resumed Discord gateway session")
+	})
+	b.DiscordClient.AddHandler(func(s *discordgo.Session, d *discordgo.Disconnect) {
+		metrics.GatewayDisconnects.Inc()
+		log.Warn("Disconnected from Discord gateway")
+	})
+
+	if err := b.Open(); err != nil {
+		return err
 	}
 
 	b.CommandHandler = commands.NewCommandHandler(b.DiscordClient, b.Database)
@@ -86,6 +163,14 @@ func (b *Bot) Start() error {
 	return nil
 }
 
+// Open connects the session to Discord without registering commands
+func (b *Bot) Open() error {
+	if err := b.DiscordClient.Open(); err != nil {
+		return fmt.Errorf("Error opening Discord session: %w", err)
+	}
+	return nil
+}
+
 func (b *Bot) onGuildJoined(s *discordgo.Session, g *discordgo.GuildCreate) {
 	log.Info("Bot added to new guild", "guild_id", g.ID, "guild_name", g.Name, "member_count", g.MemberCount)
 	b.CommandHandler.RegisterCommandsForGuild(g.ID)
@@ -110,6 +195,31 @@ func (b *Bot) UnregisterCommands() {
 }
 
 func (b *Bot) Stop() {
+	if b.HTTPServer != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := b.HTTPServer.Shutdown(ctx); err != nil {
+			log.Error("Error shutting down HTTP server", "err", err)
+		}
+	}
+	if b.Lifecycle != nil {
+		b.Lifecycle.Stop()
+	}
+	if b.Webhooks != nil {
+		b.Webhooks.Stop()
+	}
+	if b.AuditMirror != nil {
+		b.AuditMirror.Stop()
+	}
+	if b.Prompter != nil {
+		b.Prompter.Stop()
+	}
+	if b.PartnerNotifier != nil {
+		b.PartnerNotifier.Stop()
+	}
+	if b.Reminder != nil {
+		b.Reminder.Stop()
+	}
 	b.DiscordClient.Close()
 	b.Database.Close()
 
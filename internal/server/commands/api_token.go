@@ -0,0 +1,189 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/charmbracelet/log"
+	"github.com/metruzanca/checkpoint-bot/internal/apitoken"
+	"github.com/metruzanca/checkpoint-bot/internal/database"
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+	"github.com/metruzanca/checkpoint-bot/internal/permissions"
+)
+
+// ApiTokenCmd manages the tokens used to authenticate against the REST API (admin only)
+var ApiTokenCmd = &Command{
+	ApplicationCommand: discordgo.ApplicationCommand{
+		Name:        "api-token",
+		Description: "Manage REST API tokens for this server (admin only)",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "create",
+				Description: "Create a new API token",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "name",
+						Description: "What the token is used for (e.g. dashboard)",
+						Required:    true,
+						MaxLength:   100,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "scope",
+						Description: "What the token can do (default: read)",
+						Required:    false,
+						Choices: []*discordgo.ApplicationCommandOptionChoice{
+							{
+								Name:  "read",
+								Value: apitoken.ScopeRead,
+							},
+							{
+								Name:  "write (edit the user's own goals)",
+								Value: apitoken.ScopeWrite,
+							},
+							{
+								Name:  "admin (edit everyone's goals)",
+								Value: apitoken.ScopeAdmin,
+							},
+						},
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionUser,
+						Name:        "user",
+						Description: "Member the token acts as when making changes (default: you)",
+						Required:    false,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "list",
+				Description: "List this server's API tokens",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "revoke",
+				Description: "Revoke an API token",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "id",
+						Description: "ID of the token, as shown by /api-token list",
+						Required:    true,
+					},
+				},
+			},
+		},
+	},
+	Handler: func(db database.CheckpointDatabase, s *discordgo.Session, i *discordgo.InteractionCreate) {
+		ctx, cancel := dbContext()
+		defer cancel()
+		if !authorize(ctx, db, s, i, permissions.ManageGuild, permissions.Target{}) {
+			return
+		}
+
+		sub := i.ApplicationCommandData().Options[0]
+		switch sub.Name {
+		case "create":
+			params := queries.CreateApiTokenParams{
+				GuildID:     i.GuildID,
+				CreatedBy:   i.Member.User.ID,
+				Scope:       apitoken.ScopeRead,
+				DiscordUser: i.Member.User.ID,
+			}
+			for _, opt := range sub.Options {
+				switch opt.Name {
+				case "name":
+					params.Name = opt.StringValue()
+				case "scope":
+					params.Scope = opt.StringValue()
+				case "user":
+					params.DiscordUser = opt.UserValue(s).ID
+				}
+			}
+			createApiToken(db, s, i, params)
+		case "list":
+			listApiTokens(db, s, i)
+		case "revoke":
+			revokeApiToken(db, s, i, sub.Options[0].IntValue())
+		}
+	},
+}
+
+func createApiToken(db database.CheckpointDatabase, s *discordgo.Session, i *discordgo.InteractionCreate, params queries.CreateApiTokenParams) {
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	token, hash, err := apitoken.Generate()
+	if err != nil {
+		log.Error("cannot generate api token", "err", err)
+		respondEphemeral(s, i, "Error generating token")
+		return
+	}
+
+	params.TokenHash = hash
+	record, err := db.CreateApiToken(ctx, params)
+	if err != nil {
+		log.Error("cannot create api token", "err", err, "guild", i.GuildID)
+		respondEphemeral(s, i, "Error saving token")
+		return
+	}
+
+	respondEphemeral(s, i, fmt.Sprintf(
+		"Created %s API token **%s** (id %d) acting as <@%s>. Copy it now, it won't be shown again:\n```\n%s\n```\nSend it as `Authorization: Bearer <token>`.",
+		record.Scope, record.Name, record.ID, record.DiscordUser, token,
+	))
+}
+
+func listApiTokens(db database.CheckpointDatabase, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	tokens, err := db.ListApiTokensByGuild(ctx, i.GuildID)
+	if err != nil {
+		log.Error("cannot list api tokens", "err", err, "guild", i.GuildID)
+		respondEphemeral(s, i, "Error loading tokens")
+		return
+	}
+
+	if len(tokens) == 0 {
+		respondEphemeral(s, i, "This server has no API tokens. Create one with `/api-token create`.")
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString("**API tokens**\n")
+	for _, token := range tokens {
+		lastUsed := "never used"
+		if token.LastUsedAt.Valid {
+			lastUsed = fmt.Sprintf("last used <t:%d:R>", token.LastUsedAt.Time.Unix())
+		}
+		sb.WriteString(fmt.Sprintf("`%d` **%s** (%s, acts as <@%s>) by <@%s>, %s\n", token.ID, token.Name, token.Scope, token.DiscordUser, token.CreatedBy, lastUsed))
+	}
+	respondEphemeral(s, i, sb.String())
+}
+
+func revokeApiToken(db database.CheckpointDatabase, s *discordgo.Session, i *discordgo.InteractionCreate, id int64) {
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	deleted, err := db.DeleteApiToken(ctx, queries.DeleteApiTokenParams{ID: id, GuildID: i.GuildID})
+	if err != nil {
+		log.Error("cannot delete api token", "err", err, "guild", i.GuildID, "id", id)
+		respondEphemeral(s, i, "Error revoking token")
+		return
+	}
+	if deleted == 0 {
+		respondEphemeral(s, i, fmt.Sprintf("No API token with id %d in this server", id))
+		return
+	}
+
+	respondEphemeral(s, i, fmt.Sprintf("Revoked API token %d", id))
+}
+
+func init() {
+	registerCommand(ApiTokenCmd)
+}
@@ -0,0 +1,124 @@
+package commands
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/charmbracelet/log"
+	"github.com/metruzanca/checkpoint-bot/internal/audit"
+	"github.com/metruzanca/checkpoint-bot/internal/database"
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+	"github.com/metruzanca/checkpoint-bot/internal/permissions"
+)
+
+// auditValueMaxLength keeps before/after values short enough to list several entries in one message
+const auditValueMaxLength = 80
+
+// AuditCmd lists the server's latest administrative actions (admin only)
+var AuditCmd = &Command{
+	ApplicationCommand: discordgo.ApplicationCommand{
+		Name:        "audit",
+		Description: "Show the latest administrative actions in this server (admin only)",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "action",
+				Description: "Only show this kind of action",
+				Required:    false,
+				Choices:     auditActionChoices(),
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionUser,
+				Name:        "user",
+				Description: "Only show actions made by or affecting this user",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Name:        "limit",
+				Description: "How many entries to show (default: 10)",
+				Required:    false,
+				MinValue:    &[]float64{1}[0],
+				MaxValue:    25,
+			},
+		},
+	},
+	Handler: func(db database.CheckpointDatabase, s *discordgo.Session, i *discordgo.InteractionCreate) {
+		ctx, cancel := dbContext()
+		defer cancel()
+
+		if !authorize(ctx, db, s, i, permissions.ManageGuild, permissions.Target{}) {
+			return
+		}
+
+		params := queries.ListAuditEntriesParams{GuildID: i.GuildID, MaxEntries: 10}
+		for _, opt := range i.ApplicationCommandData().Options {
+			switch opt.Name {
+			case "action":
+				params.Action = sql.NullString{String: opt.StringValue(), Valid: true}
+			case "user":
+				params.UserID = sql.NullString{String: opt.UserValue(s).ID, Valid: true}
+			case "limit":
+				params.MaxEntries = opt.IntValue()
+			}
+		}
+
+		entries, err := db.ListAuditEntries(ctx, params)
+		if err != nil {
+			log.Error("cannot list audit entries", "err", err, "guild", i.GuildID)
+			respondEphemeral(s, i, "Error loading the audit log")
+			return
+		}
+		if len(entries) == 0 {
+			respondEphemeral(s, i, "No matching audit log entries")
+			return
+		}
+
+		var sb strings.Builder
+		sb.WriteString("**Audit log**\n")
+		for _, entry := range entries {
+			line := auditLine(entry)
+			if sb.Len()+len(line) > DiscordMessageMaxLength {
+				break
+			}
+			sb.WriteString(line)
+		}
+		respondEphemeral(s, i, sb.String())
+	},
+}
+
+// auditActionChoices lists every audit action as a command choice
+func auditActionChoices() []*discordgo.ApplicationCommandOptionChoice {
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, 0, len(audit.Actions))
+	for _, action := range audit.Actions {
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: action, Value: action})
+	}
+	return choices
+}
+
+// auditLine formats an audit entry as one or two lines of a message
+func auditLine(entry queries.AuditLog) string {
+	line := fmt.Sprintf("`#%d` **%s** %s", entry.ID, entry.Action, audit.Summary(entry.ActorID, entry.Source, entry.TargetType, entry.TargetID, entry.TargetUser))
+	if entry.CreatedAt.Valid {
+		line += fmt.Sprintf(" <t:%d:R>", entry.CreatedAt.Time.Unix())
+	}
+	if entry.BeforeValue != "" || entry.AfterValue != "" {
+		line += fmt.Sprintf("\n-# %s → %s", auditValue(entry.BeforeValue), auditValue(entry.AfterValue))
+	}
+	return line + "\n"
+}
+
+// auditValue formats a before/after value on a single line
+func auditValue(value string) string {
+	if value == "" {
+		return "_none_"
+	}
+	value = strings.Join(strings.Fields(value), " ")
+	return "`" + strings.ReplaceAll(audit.Truncate(value, auditValueMaxLength), "`", "'") + "`"
+}
+
+func init() {
+	registerCommand(AuditCmd)
+}
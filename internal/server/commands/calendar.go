@@ -0,0 +1,156 @@
+package commands
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/charmbracelet/log"
+	"github.com/metruzanca/checkpoint-bot/internal/calendar"
+	"github.com/metruzanca/checkpoint-bot/internal/database"
+	"github.com/metruzanca/checkpoint-bot/internal/permissions"
+	"github.com/spf13/viper"
+)
+
+// CalendarCmd attaches an .ics file with the checkpoints of this channel (or the whole server)
+var CalendarCmd = &Command{
+	ApplicationCommand: discordgo.ApplicationCommand{
+		Name:        "calendar",
+		Description: "Download the checkpoints as a calendar file",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionBoolean,
+				Name:        "all-channels",
+				Description: "Include checkpoints from every channel in this server",
+				Required:    false,
+			},
+		},
+	},
+	Handler: func(db database.CheckpointDatabase, s *discordgo.Session, i *discordgo.InteractionCreate) {
+		ctx, cancel := dbContext()
+		defer cancel()
+
+		channelID := i.ChannelID
+		for _, opt := range i.ApplicationCommandData().Options {
+			if opt.Name == "all-channels" && opt.BoolValue() {
+				channelID = ""
+			}
+		}
+
+		cal, err := calendar.ForGuild(ctx, db, i.GuildID, channelID)
+		if err == sql.ErrNoRows {
+			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+				Type: discordgo.InteractionResponseChannelMessageWithSource,
+				Data: &discordgo.InteractionResponseData{
+					Content: "No checkpoints have been created in this server yet",
+					Flags:   discordgo.MessageFlagsEphemeral,
+				},
+			})
+			return
+		} else if err != nil {
+			log.Error("cannot load calendar", "err", err, "guild", i.GuildID, "channel", channelID)
+			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+				Type: discordgo.InteractionResponseChannelMessageWithSource,
+				Data: &discordgo.InteractionResponseData{
+					Content: "Error loading checkpoints",
+					Flags:   discordgo.MessageFlagsEphemeral,
+				},
+			})
+			return
+		}
+
+		var buf bytes.Buffer
+		if err := calendar.Write(&buf, cal); err != nil {
+			log.Error("cannot write calendar", "err", err, "guild", i.GuildID, "channel", channelID)
+			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+				Type: discordgo.InteractionResponseChannelMessageWithSource,
+				Data: &discordgo.InteractionResponseData{
+					Content: "Error writing calendar",
+					Flags:   discordgo.MessageFlagsEphemeral,
+				},
+			})
+			return
+		}
+
+		content := fmt.Sprintf("%d checkpoints. Import the file into your calendar app.", len(cal.Checkpoints))
+		// Subscribing keeps the calendar up to date, only possible when the HTTP server is public
+		if viper.GetString("PUBLIC_URL") != "" {
+			content += "\nTo stay up to date, ask an admin for the server's subscription link (`/calendar-link`)."
+		}
+
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: content,
+				Flags:   discordgo.MessageFlagsEphemeral,
+				Files: []*discordgo.File{
+					{
+						Name:        calendar.Filename(i.GuildID),
+						ContentType: "text/calendar",
+						Reader:      &buf,
+					},
+				},
+			},
+		})
+	},
+}
+
+// CalendarLinkCmd creates the private link to subscribe to the server's calendar, replacing the previous one (admin only)
+var CalendarLinkCmd = &Command{
+	ApplicationCommand: discordgo.ApplicationCommand{
+		Name:        "calendar-link",
+		Description: "Create a new calendar subscription link, the previous one stops working (admin only)",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:         discordgo.ApplicationCommandOptionChannel,
+				Name:         "channel",
+				Description:  "Only include this channel's checkpoints (default: every channel)",
+				Required:     false,
+				ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildText},
+			},
+		},
+	},
+	Handler: func(db database.CheckpointDatabase, s *discordgo.Session, i *discordgo.InteractionCreate) {
+		ctx, cancel := dbContext()
+		defer cancel()
+		if !authorize(ctx, db, s, i, permissions.ManageGuild, permissions.Target{}) {
+			return
+		}
+
+		baseURL := viper.GetString("PUBLIC_URL")
+		if baseURL == "" {
+			respondEphemeral(s, i, "Calendar links need the bot's `PUBLIC_URL` to be set")
+			return
+		}
+		// Calendar tokens reference the guild, which only exists once the bot has been used in it
+		if _, err := ensureGuild(ctx, db, s, i.GuildID); err != nil {
+			log.Error("cannot ensure guild", "err", err, "guild", i.GuildID)
+			respondEphemeral(s, i, "Error checking guild")
+			return
+		}
+
+		token, err := calendar.NewFeedToken(ctx, db, i.GuildID, i.Member.User.ID)
+		if err != nil {
+			log.Error("cannot create calendar token", "err", err, "guild", i.GuildID)
+			respondEphemeral(s, i, "Error creating calendar link")
+			return
+		}
+		feedURL := fmt.Sprintf("%s/calendar/%s/%s", strings.TrimRight(baseURL, "/"), i.GuildID, token)
+		for _, opt := range i.ApplicationCommandData().Options {
+			if opt.Name == "channel" {
+				feedURL += "?channel=" + opt.ChannelValue(s).ID
+			}
+		}
+		respondEphemeral(s, i, fmt.Sprintf(
+			"Created a new calendar link, the previous one stopped working. Copy it now, it won't be shown again:\n```\n%s\n```\nShare it with members to subscribe in their calendar app, the same link works with `?channel=<id>` for a single channel.",
+			feedURL,
+		))
+	},
+}
+
+func init() {
+	registerCommand(CalendarCmd)
+	registerCommand(CalendarLinkCmd)
+}
@@ -3,13 +3,21 @@ package commands
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/charmbracelet/log"
+	"github.com/metruzanca/checkpoint-bot/internal/audit"
 	"github.com/metruzanca/checkpoint-bot/internal/database"
 	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+	"github.com/metruzanca/checkpoint-bot/internal/permissions"
+	"github.com/metruzanca/checkpoint-bot/internal/service"
+	"github.com/metruzanca/checkpoint-bot/internal/settings"
 	"github.com/metruzanca/checkpoint-bot/internal/util"
 )
 
@@ -18,6 +26,8 @@ const (
 	DiscordEmbedFieldMaxLength = 1024
 	// DiscordTextInputMaxLength is the maximum length for Discord text input fields
 	DiscordTextInputMaxLength = 2000
+	// DiscordMessageMaxLength is the maximum length of a message's content
+	DiscordMessageMaxLength = 2000
 )
 
 // CreateCheckpointCmd creates a new checkpoint for a specified date and time
@@ -44,7 +54,6 @@ var CreateCheckpointCmd = &Command{
 		ctx, cancel := dbContext()
 		defer cancel()
 
-		var scheduledAt time.Time
 		options := i.ApplicationCommandData().Options
 
 		// Find date and time options
@@ -57,69 +66,14 @@ var CreateCheckpointCmd = &Command{
 			}
 		}
 
-		// Parse date (YYYY-MM-DD)
-		parsedDate, err := util.ParseDate(dateStr)
-		if err != nil {
-			log.Error("cannot parse date", "channel", i.ChannelID, "date", dateStr, "time", timeStr)
-			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-				Type: discordgo.InteractionResponseChannelMessageWithSource,
-				Data: &discordgo.InteractionResponseData{
-					Content: "date is not a valid date (expected YYYY-MM-DD)",
-				},
-			})
-			return
-		}
-
-		// Parse time
-		hour, minute, err := util.ParseTime(timeStr)
-		if err != nil {
-			log.Error("cannot parse time", "err", err, "time", timeStr, "channel", i.ChannelID)
-			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-				Type: discordgo.InteractionResponseChannelMessageWithSource,
-				Data: &discordgo.InteractionResponseData{
-					Content: "time is not a valid time (expected HH:MM or H:MM AM/PM)",
-				},
-			})
+		if !authorize(ctx, db, s, i, permissions.CreateCheckpoint, permissions.Target{ChannelID: i.ChannelID}) {
 			return
 		}
 
 		// Ensure guild exists in database (needed for timezone)
-		guild, err := db.GetGuild(ctx, i.GuildID)
-		if err == sql.ErrNoRows {
-			// Guild doesn't exist, create it
-			// Get guild info from Discord to get owner ID
-			discordGuild, err := s.Guild(i.GuildID)
-			if err != nil {
-				log.Error("cannot get guild from Discord", "err", err, "guild", i.GuildID)
-				s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-					Type: discordgo.InteractionResponseChannelMessageWithSource,
-					Data: &discordgo.InteractionResponseData{
-						Content: "error getting guild information",
-					},
-				})
-				return
-			}
-
-			// Create guild with default timezone (UTC for now)
-			createdGuild, err := db.CreateGuild(ctx, queries.CreateGuildParams{
-				GuildID:  i.GuildID,
-				Timezone: "UTC",
-				OwnerID:  discordGuild.OwnerID,
-			})
-			if err != nil {
-				log.Error("cannot create guild", "err", err, "guild", i.GuildID)
-				s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-					Type: discordgo.InteractionResponseChannelMessageWithSource,
-					Data: &discordgo.InteractionResponseData{
-						Content: "error creating guild",
-					},
-				})
-				return
-			}
-			guild = createdGuild
-		} else if err != nil {
-			// Some other error occurred
-			log.Error("cannot get guild", "err", err, "guild", i.GuildID)
+		guild, err := ensureGuild(ctx, db, s, i.GuildID)
+		if err != nil {
+			log.Error("cannot ensure guild", "err", err, "guild", i.GuildID)
 			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 				Type: discordgo.InteractionResponseChannelMessageWithSource,
 				Data: &discordgo.InteractionResponseData{
@@ -129,116 +83,37 @@ var CreateCheckpointCmd = &Command{
 			return
 		}
 
-		// Determine timezone location
-		loc := time.UTC // Default to UTC
-		if guild != nil && guild.Timezone != "" {
-			timezoneLoc, err := time.LoadLocation(guild.Timezone)
-			if err == nil {
-				loc = timezoneLoc
-			} else {
-				log.Warn("cannot load guild timezone, using UTC", "timezone", guild.Timezone, "err", err, "guild", i.GuildID)
-			}
-		}
-
-		// Combine date and time (seconds always 0) in the guild's timezone
-		scheduledAt = time.Date(
-			parsedDate.Year(),
-			parsedDate.Month(),
-			parsedDate.Day(),
-			hour,
-			minute,
-			0,
-			0,
-			loc,
-		)
-
-		// Validate that checkpoint is in the future
-		now := time.Now().In(loc)
-		if scheduledAt.Before(now) {
-			log.Warn("attempted to create checkpoint in the past", "scheduled_at", scheduledAt, "now", now, "channel", i.ChannelID, "guild", i.GuildID, "user", i.Member.User.ID)
-			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-				Type: discordgo.InteractionResponseChannelMessageWithSource,
-				Data: &discordgo.InteractionResponseData{
-					Content: "Cannot create checkpoint in the past. Please schedule it for a future date and time.",
-				},
-			})
-			return
-		}
-
-		scheduledAtStr := scheduledAt.Format(time.RFC3339)
-
-		// Check if there's already an upcoming checkpoint for this guild+channel
-		existingUpcomingCheckpoint, err := db.GetUpcomingCheckpointByGuildAndChannel(ctx, queries.GetUpcomingCheckpointByGuildAndChannelParams{
-			GuildID:   i.GuildID,
+		checkpoint, err := service.CreateCheckpoint(ctx, db, *guild, service.NewCheckpoint{
 			ChannelID: i.ChannelID,
+			UserID:    i.Member.User.ID,
+			Date:      dateStr,
+			Time:      timeStr,
 		})
-		if err == nil {
-			// An upcoming checkpoint already exists for this guild+channel
-			log.Info("upcoming checkpoint already exists for guild+channel", "channel", i.ChannelID, "guild", i.GuildID, "user", i.Member.User.ID, "existing_checkpoint_id", existingUpcomingCheckpoint.ID)
-
-			// Create embed using the same format as get-checkpoints
-			embed := createCheckpointEmbed(*existingUpcomingCheckpoint)
-
-			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-				Type: discordgo.InteractionResponseChannelMessageWithSource,
-				Data: &discordgo.InteractionResponseData{
-					Content: "An upcoming checkpoint already exists for this channel:",
-					Embeds:  []*discordgo.MessageEmbed{embed},
-				},
-			})
-			return
-		} else if err != sql.ErrNoRows {
-			// Some other error occurred
-			log.Error("cannot check for existing upcoming checkpoint", "err", err, "channel", i.ChannelID, "guild", i.GuildID)
-			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-				Type: discordgo.InteractionResponseChannelMessageWithSource,
-				Data: &discordgo.InteractionResponseData{
-					Content: "error checking for existing checkpoint",
-				},
-			})
-			return
-		}
-
-		// Check for exact duplicate (same scheduled_at and channel) before attempting insert
-		// This is more efficient than relying on database constraint errors
-		existingCheckpoint, err := db.GetCheckpointByScheduledAtAndChannel(ctx, queries.GetCheckpointByScheduledAtAndChannelParams{
-			ScheduledAt: scheduledAtStr,
-			ChannelID:   i.ChannelID,
-		})
-		if err == nil {
-			// Exact duplicate exists
-			log.Info("duplicate checkpoint attempted", "channel", i.ChannelID, "guild", i.GuildID, "user", i.Member.User.ID, "existing_checkpoint_id", existingCheckpoint.ID)
-			embed := createCheckpointEmbed(*existingCheckpoint)
+		var existsErr *service.CheckpointExistsError
+		if errors.As(err, &existsErr) {
+			log.Info("checkpoint already exists", "channel", i.ChannelID, "guild", i.GuildID, "user", i.Member.User.ID, "existing_checkpoint_id", existsErr.Checkpoint.ID, "duplicate", existsErr.Duplicate)
+			content := "An upcoming checkpoint already exists for this channel:"
+			if existsErr.Duplicate {
+				content = "A checkpoint already exists for this time:"
+			}
 			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 				Type: discordgo.InteractionResponseChannelMessageWithSource,
 				Data: &discordgo.InteractionResponseData{
-					Content: "A checkpoint already exists for this time:",
-					Embeds:  []*discordgo.MessageEmbed{embed},
+					Content: content,
+					Embeds:  []*discordgo.MessageEmbed{createCheckpointEmbed(existsErr.Checkpoint)},
 				},
 			})
 			return
-		} else if err != sql.ErrNoRows {
-			// Error checking for duplicate
-			log.Error("cannot check for duplicate checkpoint", "err", err, "channel", i.ChannelID, "scheduled_at", scheduledAtStr)
+		} else if service.IsUserError(err) {
+			log.Warn("invalid checkpoint", "err", err, "channel", i.ChannelID, "guild", i.GuildID, "date", dateStr, "time", timeStr)
 			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 				Type: discordgo.InteractionResponseChannelMessageWithSource,
 				Data: &discordgo.InteractionResponseData{
-					Content: "error checking for duplicate checkpoint",
+					Content: userMessage(err),
 				},
 			})
 			return
-		}
-
-		// No duplicate exists, proceed with creation
-		checkpoint, err := db.CreateCheckpoint(ctx, queries.CreateCheckpointParams{
-			ScheduledAt: scheduledAtStr,
-			ChannelID:   i.ChannelID,
-			GuildID:     i.GuildID,
-			DiscordUser: i.Member.User.ID,
-		})
-
-		if err != nil {
-
+		} else if err != nil {
 			log.Error("cannot create checkpoint", "err", err, "channel", i.ChannelID, "guild", i.GuildID, "user", i.Member.User.ID)
 			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 				Type: discordgo.InteractionResponseChannelMessageWithSource,
@@ -249,6 +124,7 @@ var CreateCheckpointCmd = &Command{
 			return
 		}
 
+		scheduledAt, _ := time.Parse(time.RFC3339, checkpoint.ScheduledAt)
 		formattedDate := util.FormatCheckpointDate(scheduledAt)
 		countdown := util.FormatCountdown(scheduledAt)
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
@@ -330,11 +206,27 @@ var PastCheckpointsCmd = &Command{
 	ApplicationCommand: discordgo.ApplicationCommand{
 		Name:        "past-checkpoints",
 		Description: "List past checkpoints in this channel",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:         discordgo.ApplicationCommandOptionString,
+				Name:         "tag",
+				Description:  "Only list checkpoints with goals with this tag or category",
+				Required:     false,
+				Autocomplete: true,
+			},
+		},
 	},
 	Handler: func(db database.CheckpointDatabase, s *discordgo.Session, i *discordgo.InteractionCreate) {
 		ctx, cancel := dbContext()
 		defer cancel()
 
+		for _, opt := range i.ApplicationCommandData().Options {
+			if opt.Name == "tag" {
+				listTaggedCheckpoints(db, s, i, opt.StringValue())
+				return
+			}
+		}
+
 		checkpoints, err := db.GetPastCheckpointsByChannel(ctx, i.ChannelID)
 		if err != nil {
 			log.Error("cannot get past checkpoints", "err", err, "channel", i.ChannelID)
@@ -373,6 +265,256 @@ var PastCheckpointsCmd = &Command{
 			},
 		})
 	},
+	Autocomplete: autocompleteTag,
+}
+
+// listTaggedCheckpoints lists the channel's past checkpoints with goals tagged tag, with how many were completed
+func listTaggedCheckpoints(db database.CheckpointDatabase, s *discordgo.Session, i *discordgo.InteractionCreate, value string) {
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	tag := tagOption(value)
+	if tag == "" {
+		respondEphemeral(s, i, fmt.Sprintf("%q is not a valid tag", value))
+		return
+	}
+	checkpoints, err := db.GetPastCheckpointsByChannelAndTag(ctx, queries.GetPastCheckpointsByChannelAndTagParams{ChannelID: i.ChannelID, Tag: tag})
+	if err != nil {
+		log.Error("cannot get past checkpoints by tag", "err", err, "channel", i.ChannelID, "tag", tag)
+		respondEphemeral(s, i, "Error getting past checkpoints")
+		return
+	}
+	log.Info("past-checkpoints command executed", "channel", i.ChannelID, "guild", i.GuildID, "user", i.Member.User.ID, "tag", tag, "count", len(checkpoints))
+	if len(checkpoints) == 0 {
+		respondEphemeral(s, i, fmt.Sprintf("No past checkpoints in this channel have goals tagged `#%s`.", tag))
+		return
+	}
+
+	embeds := make([]*discordgo.MessageEmbed, 0, len(checkpoints))
+	for _, checkpoint := range checkpoints {
+		embed := createCheckpointEmbed(checkpoint.Checkpoint)
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  "#" + tag,
+			Value: fmt.Sprintf("%d/%d goals completed", checkpoint.TaggedCompleted, checkpoint.TaggedGoals),
+		})
+		embeds = append(embeds, embed)
+	}
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: embeds,
+		},
+	})
+}
+
+// RescheduleCheckpointCmd moves the upcoming checkpoint of the current channel to a new date and time
+// Only the checkpoint's creator or an administrator can reschedule it
+var RescheduleCheckpointCmd = &Command{
+	ApplicationCommand: discordgo.ApplicationCommand{
+		Name:        "reschedule",
+		Description: "Move the upcoming checkpoint to a new date and time",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "date",
+				Description: "The new date of the checkpoint (YYYY-MM-DD)",
+				Required:    true,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "time",
+				Description: "The new time of the checkpoint (HH:MM or H:MM AM/PM)",
+				Required:    true,
+			},
+		},
+	},
+	Handler: func(db database.CheckpointDatabase, s *discordgo.Session, i *discordgo.InteractionCreate) {
+		ctx, cancel := dbContext()
+		defer cancel()
+
+		var dateStr, timeStr string
+		for _, opt := range i.ApplicationCommandData().Options {
+			if opt.Name == "date" {
+				dateStr = opt.StringValue()
+			} else if opt.Name == "time" {
+				timeStr = opt.StringValue()
+			}
+		}
+
+		checkpoint, err := db.GetUpcomingCheckpointByGuildAndChannel(ctx, queries.GetUpcomingCheckpointByGuildAndChannelParams{
+			GuildID:   i.GuildID,
+			ChannelID: i.ChannelID,
+		})
+		if err == sql.ErrNoRows {
+			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+				Type: discordgo.InteractionResponseChannelMessageWithSource,
+				Data: &discordgo.InteractionResponseData{
+					Content: "No upcoming checkpoint found for this channel",
+				},
+			})
+			return
+		} else if err != nil {
+			log.Error("cannot get upcoming checkpoint", "err", err, "channel", i.ChannelID, "guild", i.GuildID)
+			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+				Type: discordgo.InteractionResponseChannelMessageWithSource,
+				Data: &discordgo.InteractionResponseData{
+					Content: "Error getting upcoming checkpoint",
+				},
+			})
+			return
+		}
+
+		if !authorize(ctx, db, s, i, permissions.ManageCheckpoint, permissions.Target{ChannelID: i.ChannelID, OwnerID: checkpoint.DiscordUser}) {
+			return
+		}
+
+		guild, err := db.GetGuild(ctx, i.GuildID)
+		if err != nil {
+			log.Error("cannot get guild", "err", err, "guild", i.GuildID)
+			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+				Type: discordgo.InteractionResponseChannelMessageWithSource,
+				Data: &discordgo.InteractionResponseData{
+					Content: "error checking guild",
+				},
+			})
+			return
+		}
+
+		scheduledAt, err := service.ScheduleTime(*guild, dateStr, timeStr)
+		if err != nil {
+			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+				Type: discordgo.InteractionResponseChannelMessageWithSource,
+				Data: &discordgo.InteractionResponseData{
+					Content: userMessage(err),
+				},
+			})
+			return
+		}
+		scheduledAtStr := scheduledAt.Format(time.RFC3339)
+
+		existingCheckpoint, err := db.GetCheckpointByScheduledAtAndChannel(ctx, queries.GetCheckpointByScheduledAtAndChannelParams{
+			ScheduledAt: scheduledAtStr,
+			ChannelID:   i.ChannelID,
+		})
+		if err == nil {
+			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+				Type: discordgo.InteractionResponseChannelMessageWithSource,
+				Data: &discordgo.InteractionResponseData{
+					Content: "A checkpoint already exists for this time:",
+					Embeds:  []*discordgo.MessageEmbed{createCheckpointEmbed(*existingCheckpoint)},
+				},
+			})
+			return
+		} else if err != sql.ErrNoRows {
+			log.Error("cannot check for duplicate checkpoint", "err", err, "channel", i.ChannelID, "scheduled_at", scheduledAtStr)
+			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+				Type: discordgo.InteractionResponseChannelMessageWithSource,
+				Data: &discordgo.InteractionResponseData{
+					Content: "error checking for duplicate checkpoint",
+				},
+			})
+			return
+		}
+
+		rescheduled, err := db.RescheduleCheckpoint(ctx, queries.RescheduleCheckpointParams{
+			ScheduledAt: scheduledAtStr,
+			ID:          checkpoint.ID,
+		})
+		if err != nil {
+			log.Error("cannot reschedule checkpoint", "err", err, "checkpoint_id", checkpoint.ID, "scheduled_at", scheduledAtStr)
+			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+				Type: discordgo.InteractionResponseChannelMessageWithSource,
+				Data: &discordgo.InteractionResponseData{
+					Content: "error rescheduling checkpoint",
+				},
+			})
+			return
+		}
+		audit.Record(ctx, db, audit.Entry{
+			GuildID:    i.GuildID,
+			ActorID:    i.Member.User.ID,
+			Action:     audit.CheckpointRescheduled,
+			TargetType: audit.TargetCheckpoint,
+			TargetID:   strconv.FormatInt(checkpoint.ID, 10),
+			TargetUser: checkpoint.DiscordUser,
+			Before:     checkpoint.ScheduledAt,
+			After:      rescheduled.ScheduledAt,
+		})
+
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Embeds: []*discordgo.MessageEmbed{
+					{
+						Title:       "Checkpoint rescheduled",
+						Description: fmt.Sprintf("Checkpoint #%d moved to __%s__ %s", rescheduled.ID, util.FormatCheckpointDate(scheduledAt), util.FormatCountdown(scheduledAt)),
+						Color:       0x0099ff,
+					},
+				},
+			},
+		})
+	},
+}
+
+// CancelCheckpointCmd cancels the channel's upcoming checkpoint, deleting its goals and RSVPs
+var CancelCheckpointCmd = &Command{
+	ApplicationCommand: discordgo.ApplicationCommand{
+		Name:        "cancel",
+		Description: "Cancel this channel's upcoming checkpoint",
+	},
+	Handler: func(db database.CheckpointDatabase, s *discordgo.Session, i *discordgo.InteractionCreate) {
+		ctx, cancel := dbContext()
+		defer cancel()
+
+		checkpoint, err := service.UpcomingCheckpoint(ctx, db, i.GuildID, i.ChannelID)
+		if err == service.ErrNoUpcomingCheckpoint {
+			respondEphemeral(s, i, "No upcoming checkpoint found for this channel")
+			return
+		} else if err != nil {
+			log.Error("cannot get upcoming checkpoint", "err", err, "channel", i.ChannelID, "guild", i.GuildID)
+			respondEphemeral(s, i, "Error getting upcoming checkpoint")
+			return
+		}
+
+		if !authorize(ctx, db, s, i, permissions.ManageCheckpoint, permissions.Target{ChannelID: i.ChannelID, OwnerID: checkpoint.DiscordUser}) {
+			return
+		}
+
+		if _, err := db.DeleteCheckpoint(ctx, checkpoint.ID); err != nil {
+			log.Error("cannot delete checkpoint", "err", err, "checkpoint_id", checkpoint.ID)
+			respondEphemeral(s, i, "Error cancelling checkpoint")
+			return
+		}
+		audit.Record(ctx, db, audit.Entry{
+			GuildID:    i.GuildID,
+			ActorID:    i.Member.User.ID,
+			Action:     audit.CheckpointCancelled,
+			TargetType: audit.TargetCheckpoint,
+			TargetID:   strconv.FormatInt(checkpoint.ID, 10),
+			TargetUser: checkpoint.DiscordUser,
+			Before:     checkpoint.ScheduledAt,
+		})
+
+		// The checkpoint is already deleted, so a bad time only falls back to the stored value
+		date := checkpoint.ScheduledAt
+		if scheduledAt, err := time.Parse(time.RFC3339, checkpoint.ScheduledAt); err != nil {
+			log.Error("cannot parse checkpoint scheduled_at", "err", err, "checkpoint_id", checkpoint.ID)
+		} else {
+			date = util.FormatCheckpointDate(scheduledAt)
+		}
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Embeds: []*discordgo.MessageEmbed{
+					{
+						Title:       "Checkpoint cancelled",
+						Description: fmt.Sprintf("Checkpoint #%d on __%s__ was cancelled by <@%s>", checkpoint.ID, date, i.Member.User.ID),
+						Color:       0x0099ff,
+					},
+				},
+			},
+		})
+	},
 }
 
 // createCheckpointEmbed creates a Discord embed for a checkpoint with formatted date and countdown
@@ -418,8 +560,22 @@ func createCheckpointEmbed(checkpoint queries.Checkpoint) *discordgo.MessageEmbe
 // createCheckpointEmbedWithGoals creates a Discord embed for a checkpoint including associated goals
 // Goals are truncated if they exceed Discord's embed field length limit
 func createCheckpointEmbedWithGoals(ctx context.Context, db database.CheckpointDatabase, checkpoint queries.Checkpoint) (*discordgo.MessageEmbed, error) {
+	return checkpointEmbedFor(ctx, db, checkpoint, goalViewer{})
+}
+
+// checkpointEmbedFor is the checkpoint's embed with the text of the goals the viewer can see,
+// the others only show their status (see service.CanViewGoal)
+func checkpointEmbedFor(ctx context.Context, db database.CheckpointDatabase, checkpoint queries.Checkpoint, viewer goalViewer) (*discordgo.MessageEmbed, error) {
 	embed := createCheckpointEmbed(checkpoint)
 
+	guildSettings, err := settings.Load(ctx, db, checkpoint.GuildID)
+	if err != nil {
+		return embed, err
+	}
+	if field := goalLockField(checkpoint, guildSettings); field != nil {
+		embed.Fields = append(embed.Fields, field)
+	}
+
 	// Get goals for this checkpoint
 	goals, err := db.GetGoalsByCheckpoint(ctx, checkpoint.ID)
 	if err != nil {
@@ -427,11 +583,22 @@ func createCheckpointEmbedWithGoals(ctx context.Context, db database.CheckpointD
 	}
 
 	if len(goals) > 0 {
+		edited, err := editedGoals(ctx, db, checkpoint.ID)
+		if err != nil {
+			return embed, err
+		}
+		tags, err := goalTags(ctx, db, checkpoint.ID)
+		if err != nil {
+			return embed, err
+		}
+
 		// Build goals text with user mentions and status
+		member := slices.ContainsFunc(goals, func(goal queries.Goal) bool { return goal.DiscordUser == viewer.UserID })
+		visible := make(map[int64]bool, len(goals))
 		goalsText := ""
 		for _, goal := range goals {
-			statusEmoji := getStatusEmoji(goal.Status)
-			goalsText += fmt.Sprintf("%s <@%s>:\n%s\n\n", statusEmoji, goal.DiscordUser, goal.Description)
+			visible[goal.ID] = service.CanViewGoal(goal, viewer.UserID, viewer.Moderator, member)
+			goalsText += goalEntry(goal, edited[goal.ID], tags[goal.ID], visible[goal.ID])
 		}
 
 		// Truncate if total length exceeds Discord limit
@@ -439,13 +606,12 @@ func createCheckpointEmbedWithGoals(ctx context.Context, db database.CheckpointD
 			// Try to fit as many complete goals as possible
 			truncated := ""
 			for _, goal := range goals {
-				statusEmoji := getStatusEmoji(goal.Status)
-				goalEntry := fmt.Sprintf("%s <@%s>:\n%s\n\n", statusEmoji, goal.DiscordUser, goal.Description)
-				if len(truncated)+len(goalEntry) > DiscordEmbedFieldMaxLength-4 {
+				entry := goalEntry(goal, edited[goal.ID], tags[goal.ID], visible[goal.ID])
+				if len(truncated)+len(entry) > DiscordEmbedFieldMaxLength-4 {
 					truncated += "..."
 					break
 				}
-				truncated += goalEntry
+				truncated += entry
 			}
 			goalsText = truncated
 		}
@@ -460,12 +626,103 @@ func createCheckpointEmbedWithGoals(ctx context.Context, db database.CheckpointD
 	return embed, nil
 }
 
+// goalLockField shows when goals lock, it's omitted while they can be changed until the checkpoint starts
+func goalLockField(checkpoint queries.Checkpoint, guildSettings settings.Settings) *discordgo.MessageEmbedField {
+	lockTime, err := service.GoalLockTime(checkpoint, guildSettings)
+	if err != nil {
+		return nil
+	}
+	field := &discordgo.MessageEmbedField{Name: "Goals", Inline: true}
+	switch {
+	case !time.Now().Before(lockTime):
+		field.Value = "🔒 Locked"
+	case guildSettings.GoalLock > 0:
+		field.Value = fmt.Sprintf("🔓 Lock <t:%d:R>", lockTime.Unix())
+	default:
+		return nil
+	}
+	return field
+}
+
+// goalEntry formats a goal for the checkpoint embed, edited goals are marked so changes can be checked with /goal-history.
+// Goals with a target always show their running total, the text of goals the viewer can't see is hidden (see service.HiddenGoalText).
+func goalEntry(goal queries.Goal, edited bool, tags []string, visible bool) string {
+	// Private goals only show their status, members-only goals a summary without their text
+	description := goal.Description
+	if !visible {
+		description = service.HiddenGoalText(goal.Visibility)
+		if goal.Visibility == settings.VisibilityPrivate {
+			return fmt.Sprintf("%s <@%s>: %s\n\n", getStatusEmoji(goal.Status, goal.Verification), goal.DiscordUser, description)
+		}
+	}
+	marker := ""
+	if edited {
+		marker = " *(edited)*"
+	}
+	if len(tags) > 0 {
+		marker += " " + tagList(tags)
+	}
+	progress := ""
+	if goal.Target > 0 {
+		progress = "\n" + progressBar(goal.Progress) + " · " + service.FormatTarget(goal.Total, goal.Target, goal.Unit)
+	} else if goal.Progress > 0 && goal.Progress < 100 {
+		progress = "\n" + progressBar(goal.Progress)
+	}
+	return fmt.Sprintf("%s <@%s>%s:\n%s%s\n\n", getStatusEmoji(goal.Status, goal.Verification), goal.DiscordUser, marker, description, progress)
+}
+
+// progressBar renders a goal's progress as ten blocks, e.g. ▰▰▰▱▱▱▱▱▱▱ 30%
+func progressBar(progress int64) string {
+	filled := int(progress / 10)
+	return strings.Repeat("▰", filled) + strings.Repeat("▱", 10-filled) + fmt.Sprintf(" %d%%", progress)
+}
+
+// statusLabel formats a goal's status with its progress when it's partial
+func statusLabel(status string, progress int64) string {
+	if status == service.StatusPartial {
+		return fmt.Sprintf("%s (%d%%)", status, progress)
+	}
+	return status
+}
+
+// goalTags returns the tags of the checkpoint's goals by goal ID
+func goalTags(ctx context.Context, db database.CheckpointDatabase, checkpointID int64) (map[int64][]string, error) {
+	rows, err := db.GetGoalTagsByCheckpoint(ctx, checkpointID)
+	if err != nil {
+		return nil, err
+	}
+	tags := make(map[int64][]string)
+	for _, row := range rows {
+		tags[row.GoalID] = append(tags[row.GoalID], row.Tag)
+	}
+	return tags, nil
+}
+
+// editedGoals returns the IDs of the checkpoint's goals whose description changed after they were set
+func editedGoals(ctx context.Context, db database.CheckpointDatabase, checkpointID int64) (map[int64]bool, error) {
+	ids, err := db.GetEditedGoalIDsByCheckpoint(ctx, checkpointID)
+	if err != nil {
+		return nil, err
+	}
+	edited := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		edited[id] = true
+	}
+	return edited, nil
+}
+
 // getStatusEmoji returns an emoji representation of a goal's status
-// Returns ✅ for completed, ❌ for failed, ⏳ for incomplete or unknown
-func getStatusEmoji(status string) string {
+// Returns ✅ for completed, 🟡 for partial, ❌ for failed, ⏳ for incomplete or unknown,
+// and 🔍 for completed goals pending verification (see service.VerificationPending)
+func getStatusEmoji(status string, verification string) string {
+	if verification == service.VerificationPending {
+		return "🔍"
+	}
 	switch status {
 	case "completed":
 		return "✅"
+	case "partial":
+		return "🟡"
 	case "failed":
 		return "❌"
 	case "incomplete":
@@ -479,4 +736,6 @@ func init() {
 	registerCommand(CreateCheckpointCmd)
 	registerCommand(ListCheckpointsCmd)
 	registerCommand(PastCheckpointsCmd)
+	registerCommand(RescheduleCheckpointCmd)
+	registerCommand(CancelCheckpointCmd)
 }
@@ -2,15 +2,28 @@ package commands
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/charmbracelet/log"
 	"github.com/metruzanca/checkpoint-bot/internal/database"
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+	"github.com/metruzanca/checkpoint-bot/internal/metrics"
+	"github.com/metruzanca/checkpoint-bot/internal/permissions"
+	"github.com/metruzanca/checkpoint-bot/internal/service"
+	"github.com/metruzanca/checkpoint-bot/internal/settings"
 )
 
-// Global mutable map, only modified in init() functions
-var commands = make(map[string]*Command)
+// Global mutable maps, only modified in init() functions
+var (
+	commands = make(map[string]*Command)
+	// components and modals are keyed by the prefix of the custom IDs they handle
+	components = make(map[string]InteractionHandler)
+	modals     = make(map[string]InteractionHandler)
+)
 
 // CommandHandler manages Discord slash command registration and execution
 type CommandHandler struct {
@@ -27,10 +40,15 @@ func NewCommandHandler(discordClient *discordgo.Session, database database.Check
 	}
 }
 
+// InteractionHandler handles an interaction, e.g. a command, a button click or a modal submission
+type InteractionHandler func(db database.CheckpointDatabase, s *discordgo.Session, i *discordgo.InteractionCreate)
+
 // Command represents a Discord slash command with its handler function
 type Command struct {
 	discordgo.ApplicationCommand
-	Handler func(db database.CheckpointDatabase, s *discordgo.Session, i *discordgo.InteractionCreate)
+	Handler InteractionHandler
+	// Autocomplete suggests values for the command's options with Autocomplete set, it's optional
+	Autocomplete InteractionHandler
 }
 
 // RegisterCommands registers all commands for all guilds and sets up interaction handlers
@@ -39,29 +57,45 @@ func (h *CommandHandler) RegisterCommands() {
 		h.RegisterCommandsForGuild(guild.ID)
 	}
 	h.DiscordClient.AddHandler(func(s *discordgo.Session, i *discordgo.InteractionCreate) {
-		// Handle modal submissions
+		// Handle modal submissions and message components such as buttons, which can also come from DMs
 		if i.Type == discordgo.InteractionModalSubmit {
 			data := i.ModalSubmitData()
-			log.Info("modal submitted", "custom_id", data.CustomID)
-			if len(data.CustomID) > 0 && data.CustomID[:10] == "goal_modal_" {
-				HandleGoalModalSubmission(h.Database, s, i)
-				return
+			log.Info("modal submitted", "custom_id", data.CustomID, "user", interactionUserID(i))
+			if handler := findHandler(modals, data.CustomID); handler != nil {
+				handler(h.Database, s, i)
+			} else {
+				log.Warn("unknown modal submitted", "custom_id", data.CustomID)
 			}
+			return
+		}
+		if i.Type == discordgo.InteractionMessageComponent {
+			data := i.MessageComponentData()
+			log.Info("component used", "custom_id", data.CustomID, "user", interactionUserID(i))
+			if handler := findHandler(components, data.CustomID); handler != nil {
+				handler(h.Database, s, i)
+			} else {
+				log.Warn("unknown component used", "custom_id", data.CustomID)
+			}
+			return
+		}
+
+		// Handle option autocompletion, it isn't rate limited since Discord sends one per keystroke
+		if i.Type == discordgo.InteractionApplicationCommandAutocomplete {
+			if cmd, ok := commands[i.ApplicationCommandData().Name]; ok && cmd.Autocomplete != nil {
+				cmd.Autocomplete(h.Database, s, i)
+			}
+			return
 		}
 
 		// Handle application commands
 		if i.Type == discordgo.InteractionApplicationCommand {
 			commandName := i.ApplicationCommandData().Name
-			userID := ""
-			if i.Member != nil {
-				userID = i.Member.User.ID
-			} else if i.User != nil {
-				userID = i.User.ID
-			}
+			userID := interactionUserID(i)
 
 			// Rate limiting: check if user has exceeded rate limit
 			if !commandRateLimiter.allow(userID) {
 				log.Warn("rate limit exceeded", "command", commandName, "user", userID, "channel", i.ChannelID, "guild", i.GuildID)
+				metrics.RateLimitRejections.WithLabelValues(commandName).Inc()
 				s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 					Type: discordgo.InteractionResponseChannelMessageWithSource,
 					Data: &discordgo.InteractionResponseData{
@@ -74,7 +108,10 @@ func (h *CommandHandler) RegisterCommands() {
 
 			if cmd, ok := commands[commandName]; ok {
 				log.Info("command executed", "command", commandName, "channel", i.ChannelID, "guild", i.GuildID, "user", userID)
+				start := time.Now()
 				cmd.Handler(h.Database, s, i)
+				metrics.CommandInvocations.WithLabelValues(commandName).Inc()
+				metrics.CommandDuration.WithLabelValues(commandName).Observe(time.Since(start).Seconds())
 			} else {
 				log.Warn("unknown command received", "command", commandName, "channel", i.ChannelID, "guild", i.GuildID)
 			}
@@ -100,6 +137,39 @@ func registerCommand(cmd *Command) {
 	commands[cmd.ApplicationCommand.Name] = cmd
 }
 
+// registerComponent registers the handler of message components whose custom ID starts with prefix
+func registerComponent(prefix string, handler InteractionHandler) {
+	components[prefix] = handler
+}
+
+// registerModal registers the handler of modals whose custom ID starts with prefix
+func registerModal(prefix string, handler InteractionHandler) {
+	modals[prefix] = handler
+}
+
+// findHandler returns the handler with the longest prefix of customID, or nil
+func findHandler(handlers map[string]InteractionHandler, customID string) InteractionHandler {
+	var found InteractionHandler
+	longest := -1
+	for prefix, handler := range handlers {
+		if strings.HasPrefix(customID, prefix) && len(prefix) > longest {
+			found, longest = handler, len(prefix)
+		}
+	}
+	return found
+}
+
+// interactionUserID returns the user invoking the interaction, in a guild or in DMs
+func interactionUserID(i *discordgo.InteractionCreate) string {
+	if i.Member != nil && i.Member.User != nil {
+		return i.Member.User.ID
+	}
+	if i.User != nil {
+		return i.User.ID
+	}
+	return ""
+}
+
 // GetAvailableCommands returns a list of all registered command names and descriptions
 func GetAvailableCommands() []struct {
 	Name        string
@@ -144,6 +214,76 @@ func clearUnregisteredCommands(discordClient *discordgo.Session) {
 	}
 }
 
+// authorize checks that the member invoking the interaction may perform action on target,
+// replying with the reason when they can't
+func authorize(ctx context.Context, db database.CheckpointDatabase, s *discordgo.Session, i *discordgo.InteractionCreate, action permissions.Action, target permissions.Target) bool {
+	guildSettings, err := settings.Load(ctx, db, i.GuildID)
+	if err != nil {
+		log.Error("cannot load guild settings", "err", err, "guild", i.GuildID)
+		respondEphemeral(s, i, "Error checking permissions")
+		return false
+	}
+
+	member := permissions.FromDiscord(i.Member)
+	if err := permissions.Check(guildSettings, member, action, target); err != nil {
+		log.Warn("permission denied", "action", action, "user", member.UserID, "channel", i.ChannelID, "guild", i.GuildID)
+		respondEphemeral(s, i, userMessage(err))
+		return false
+	}
+	return true
+}
+
+// goalActor describes the member invoking the interaction as the author of a goal change,
+// with their goal moderation and lock override permissions
+func goalActor(ctx context.Context, db database.CheckpointDatabase, i *discordgo.InteractionCreate) (service.GoalChange, error) {
+	guildSettings, err := settings.Load(ctx, db, i.GuildID)
+	if err != nil {
+		return service.GoalChange{}, err
+	}
+	member := permissions.FromDiscord(i.Member)
+	return service.GoalChange{
+		ActorID:      member.UserID,
+		Moderator:    permissions.Check(guildSettings, member, permissions.ModerateGoals, permissions.Target{}) == nil,
+		OverrideLock: permissions.Check(guildSettings, member, permissions.OverrideGoalLock, permissions.Target{}) == nil,
+	}, nil
+}
+
+// respondEphemeral replies to an interaction with a message only the invoking user can see
+func respondEphemeral(s *discordgo.Session, i *discordgo.InteractionCreate, content string) {
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: content,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+// userMessage turns an error meant for the user (see service.IsUserError) into a reply
+func userMessage(err error) string {
+	msg := err.Error()
+	return strings.ToUpper(msg[:1]) + msg[1:]
+}
+
+// ensureGuild returns the guild, creating it with the default timezone (UTC) on first use
+func ensureGuild(ctx context.Context, db database.CheckpointDatabase, s *discordgo.Session, guildID string) (*queries.Guild, error) {
+	guild, err := db.GetGuild(ctx, guildID)
+	if err != sql.ErrNoRows {
+		return guild, err
+	}
+
+	// Get guild info from Discord to get owner ID
+	discordGuild, err := s.Guild(guildID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get guild from Discord: %w", err)
+	}
+	return db.CreateGuild(ctx, queries.CreateGuildParams{
+		GuildID:  guildID,
+		Timezone: "UTC",
+		OwnerID:  discordGuild.OwnerID,
+	})
+}
+
 // dbContext creates a context with timeout for database operations.
 // Returns a context that will be cancelled after the timeout duration.
 // The caller should defer cancel() to ensure proper cleanup.
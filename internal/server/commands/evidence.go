@@ -0,0 +1,191 @@
+package commands
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/charmbracelet/log"
+	"github.com/metruzanca/checkpoint-bot/internal/database"
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+	"github.com/metruzanca/checkpoint-bot/internal/service"
+	"github.com/metruzanca/checkpoint-bot/internal/settings"
+)
+
+// goalProofMaxLength leaves room for the revisions in /goal-history
+const goalProofMaxLength = 800
+
+// GoalProofCmd attaches proof to the member's goal, such as a screenshot or a link
+var GoalProofCmd = &Command{
+	ApplicationCommand: discordgo.ApplicationCommand{
+		Name:        "goal-proof",
+		Description: "Attach proof to your goal, such as a screenshot or a link",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionAttachment,
+				Name:        "attachment",
+				Description: "A screenshot or file showing your progress",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "url",
+				Description: "A link showing your progress",
+				Required:    false,
+				MaxLength:   service.EvidenceURLMaxLength,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionBoolean,
+				Name:        "complete",
+				Description: "Also mark the goal completed",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Name:        "checkpoint",
+				Description: "Checkpoint ID (default: the channel's upcoming or latest checkpoint)",
+				Required:    false,
+			},
+		},
+	},
+	Handler: func(db database.CheckpointDatabase, s *discordgo.Session, i *discordgo.InteractionCreate) {
+		ctx, cancel := dbContext()
+		defer cancel()
+
+		data := i.ApplicationCommandData()
+		userID := i.Member.User.ID
+		var evidence []service.Evidence
+		var checkpoint *queries.Checkpoint
+		complete := false
+		for _, opt := range data.Options {
+			switch opt.Name {
+			case "attachment":
+				if attachment, ok := data.Resolved.Attachments[opt.Value.(string)]; ok {
+					evidence = append(evidence, service.Evidence{Kind: service.EvidenceAttachment, URL: attachment.URL, Filename: attachment.Filename})
+				}
+			case "url":
+				evidence = append(evidence, service.Evidence{Kind: service.EvidenceLink, URL: strings.TrimSpace(opt.StringValue())})
+			case "complete":
+				complete = opt.BoolValue()
+			case "checkpoint":
+				found, err := db.GetCheckpoint(ctx, opt.IntValue())
+				if err == sql.ErrNoRows || (err == nil && found.GuildID != i.GuildID) {
+					respondEphemeral(s, i, userMessage(service.ErrCheckpointNotFound))
+					return
+				} else if err != nil {
+					log.Error("cannot get checkpoint", "err", err, "checkpoint_id", opt.IntValue())
+					respondEphemeral(s, i, "Error getting checkpoint")
+					return
+				}
+				checkpoint = found
+			}
+		}
+
+		if checkpoint == nil {
+			found, err := latestChannelCheckpoint(db, i)
+			if err != nil {
+				log.Error("cannot get channel checkpoint", "err", err, "channel", i.ChannelID, "guild", i.GuildID)
+				respondEphemeral(s, i, "Error getting checkpoint")
+				return
+			}
+			if found == nil {
+				respondEphemeral(s, i, "No checkpoint found for this channel")
+				return
+			}
+			checkpoint = found
+		}
+
+		added, err := service.AddGoalEvidence(ctx, db, checkpoint.ID, userID, evidence)
+		if service.IsUserError(err) {
+			respondEphemeral(s, i, userMessage(err))
+			return
+		} else if err != nil {
+			log.Error("cannot add goal evidence", "err", err, "checkpoint_id", checkpoint.ID, "user", userID)
+			respondEphemeral(s, i, "Error saving proof")
+			return
+		}
+
+		var sb strings.Builder
+		sb.WriteString(fmt.Sprintf("📎 <@%s> added proof to their goal for checkpoint #%d:\n", userID, checkpoint.ID))
+		for _, item := range added {
+			sb.WriteString(evidenceLine(item.Kind, item.Url, item.Filename) + "\n")
+		}
+
+		// The proof is saved either way, completing the goal follows /goal's rules
+		if complete {
+			actor, err := goalActor(ctx, db, i)
+			if err == nil {
+				actor.UserID = userID
+				actor.Status = service.StatusCompleted
+				var result *service.GoalResult
+				result, err = service.SaveGoal(ctx, db, checkpoint.ID, actor)
+				if err == nil {
+					sb.WriteString(fmt.Sprintf("Goal marked %s %s", service.StatusCompleted, getStatusEmoji(result.Goal.Status, result.Goal.Verification)))
+				}
+			}
+			if service.IsUserError(err) {
+				sb.WriteString(fmt.Sprintf("-# The goal wasn't marked completed: %s", err))
+			} else if err != nil {
+				log.Error("cannot complete goal", "err", err, "checkpoint_id", checkpoint.ID, "user", userID)
+				sb.WriteString("-# Error marking the goal completed")
+			}
+		}
+
+		// Proof of goals that aren't public isn't shared with the channel
+		var flags discordgo.MessageFlags
+		goal, err := db.GetGoalByCheckpointAndUser(ctx, queries.GetGoalByCheckpointAndUserParams{CheckpointID: checkpoint.ID, DiscordUser: userID})
+		if err != nil || goal.Visibility != settings.VisibilityPublic {
+			flags = discordgo.MessageFlagsEphemeral
+		}
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: sb.String(),
+				Flags:   flags,
+			},
+		})
+	},
+}
+
+// evidenceLine formats a proof as a link, files show their name and links aren't previewed
+func evidenceLine(kind string, url string, filename string) string {
+	if kind == service.EvidenceAttachment {
+		name := strings.NewReplacer("[", "(", "]", ")").Replace(filename)
+		return fmt.Sprintf("📎 [%s](%s)", name, url)
+	}
+	return fmt.Sprintf("🔗 <%s>", url)
+}
+
+// evidenceList joins lines of proof, dropping the last ones that don't fit in maxLength
+func evidenceList(lines []string, maxLength int) string {
+	var sb strings.Builder
+	for n, line := range lines {
+		// Leave room for the note about hidden proof
+		if sb.Len()+len(line)+1 > maxLength-32 {
+			sb.WriteString(fmt.Sprintf("-# …and %d more", len(lines)-n))
+			break
+		}
+		sb.WriteString(line + "\n")
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// goalEvidenceSection lists a goal's proof for /goal-history, empty without any
+func goalEvidenceSection(evidence []queries.GoalEvidence) string {
+	if len(evidence) == 0 {
+		return ""
+	}
+	lines := make([]string, len(evidence))
+	for n, item := range evidence {
+		lines[n] = evidenceLine(item.Kind, item.Url, item.Filename)
+		if item.CreatedAt.Valid {
+			lines[n] += fmt.Sprintf(" <t:%d:R>", item.CreatedAt.Time.Unix())
+		}
+	}
+	return fmt.Sprintf("**Proof (%d)**\n%s\n", len(evidence), evidenceList(lines, goalProofMaxLength))
+}
+
+func init() {
+	registerCommand(GoalProofCmd)
+}
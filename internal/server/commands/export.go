@@ -0,0 +1,135 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/charmbracelet/log"
+	"github.com/metruzanca/checkpoint-bot/internal/database"
+	"github.com/metruzanca/checkpoint-bot/internal/history"
+	"github.com/metruzanca/checkpoint-bot/internal/permissions"
+)
+
+// ExportCmd attaches a JSON or CSV dump of the guild's history (admin only)
+var ExportCmd = &Command{
+	ApplicationCommand: discordgo.ApplicationCommand{
+		Name:        "export",
+		Description: "Export this server's checkpoint history (admin only)",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "format",
+				Description: "File format (default: json)",
+				Required:    false,
+				Choices: []*discordgo.ApplicationCommandOptionChoice{
+					{
+						Name:  "json",
+						Value: history.FormatJSON,
+					},
+					{
+						Name:  "csv",
+						Value: history.FormatCSV,
+					},
+				},
+			},
+			{
+				Type:         discordgo.ApplicationCommandOptionChannel,
+				Name:         "channel",
+				Description:  "Only export checkpoints from this channel",
+				Required:     false,
+				ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildText},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "from",
+				Description: "Only export checkpoints on or after this date (YYYY-MM-DD)",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "to",
+				Description: "Only export checkpoints on or before this date (YYYY-MM-DD)",
+				Required:    false,
+			},
+		},
+	},
+	Handler: func(db database.CheckpointDatabase, s *discordgo.Session, i *discordgo.InteractionCreate) {
+		ctx, cancel := dbContext()
+		defer cancel()
+
+		if !authorize(ctx, db, s, i, permissions.ManageGuild, permissions.Target{}) {
+			return
+		}
+
+		format := history.FormatJSON
+		filter := history.Filter{GuildID: i.GuildID}
+		for _, opt := range i.ApplicationCommandData().Options {
+			switch opt.Name {
+			case "format":
+				format = opt.StringValue()
+			case "channel":
+				filter.ChannelID = opt.ChannelValue(s).ID
+			case "from":
+				filter.FromDate = opt.StringValue()
+			case "to":
+				filter.ToDate = opt.StringValue()
+			}
+		}
+
+		export, err := history.Build(ctx, db, filter)
+		if err != nil {
+			log.Error("cannot build export", "err", err, "guild", i.GuildID)
+			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+				Type: discordgo.InteractionResponseChannelMessageWithSource,
+				Data: &discordgo.InteractionResponseData{
+					Content: fmt.Sprintf("Error building export: %s", err),
+					Flags:   discordgo.MessageFlagsEphemeral,
+				},
+			})
+			return
+		}
+
+		var buf bytes.Buffer
+		if err := history.Write(&buf, export, format); err != nil {
+			log.Error("cannot write export", "err", err, "guild", i.GuildID, "format", format)
+			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+				Type: discordgo.InteractionResponseChannelMessageWithSource,
+				Data: &discordgo.InteractionResponseData{
+					Content: "Error writing export",
+					Flags:   discordgo.MessageFlagsEphemeral,
+				},
+			})
+			return
+		}
+
+		log.Info("export command executed", "guild", i.GuildID, "user", i.Member.User.ID, "format", format, "checkpoints", len(export.Checkpoints))
+
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: fmt.Sprintf("Exported %d checkpoints and %d goals.", len(export.Checkpoints), len(export.Goals)),
+				Flags:   discordgo.MessageFlagsEphemeral,
+				Files: []*discordgo.File{
+					{
+						Name:        history.Filename(i.GuildID, format),
+						ContentType: contentType(format),
+						Reader:      &buf,
+					},
+				},
+			},
+		})
+	},
+}
+
+// contentType returns the MIME type used for attachments of the given export format
+func contentType(format string) string {
+	if format == history.FormatCSV {
+		return "text/csv"
+	}
+	return "application/json"
+}
+
+func init() {
+	registerCommand(ExportCmd)
+}
@@ -0,0 +1,233 @@
+package commands
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/charmbracelet/log"
+	"github.com/metruzanca/checkpoint-bot/internal/audit"
+	"github.com/metruzanca/checkpoint-bot/internal/database"
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+	"github.com/metruzanca/checkpoint-bot/internal/service"
+)
+
+// goalRevisionMaxLength keeps a single revision short enough to show several in one message
+const goalRevisionMaxLength = 600
+
+// markdownEscaper escapes the characters Discord uses for formatting in goal text
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "~", `\~`, "`", "\\`", "|", `\|`, ">", `\>`)
+
+// GoalHistoryCmd shows every revision of a goal with the changes between them
+var GoalHistoryCmd = &Command{
+	ApplicationCommand: discordgo.ApplicationCommand{
+		Name:        "goal-history",
+		Description: "Show how a goal changed over time",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionUser,
+				Name:        "user",
+				Description: "Whose goal to show (default: yours)",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Name:        "checkpoint",
+				Description: "Checkpoint ID (default: the channel's upcoming or latest checkpoint)",
+				Required:    false,
+			},
+		},
+	},
+	Handler: func(db database.CheckpointDatabase, s *discordgo.Session, i *discordgo.InteractionCreate) {
+		ctx, cancel := dbContext()
+		defer cancel()
+
+		userID := i.Member.User.ID
+		var checkpoint *queries.Checkpoint
+		for _, opt := range i.ApplicationCommandData().Options {
+			switch opt.Name {
+			case "user":
+				userID = opt.UserValue(s).ID
+			case "checkpoint":
+				found, err := db.GetCheckpoint(ctx, opt.IntValue())
+				if err == sql.ErrNoRows || (err == nil && found.GuildID != i.GuildID) {
+					respondEphemeral(s, i, userMessage(service.ErrCheckpointNotFound))
+					return
+				} else if err != nil {
+					log.Error("cannot get checkpoint", "err", err, "checkpoint_id", opt.IntValue())
+					respondEphemeral(s, i, "Error getting checkpoint")
+					return
+				}
+				checkpoint = found
+			}
+		}
+
+		if checkpoint == nil {
+			found, err := latestChannelCheckpoint(db, i)
+			if err != nil {
+				log.Error("cannot get channel checkpoint", "err", err, "channel", i.ChannelID, "guild", i.GuildID)
+				respondEphemeral(s, i, "Error getting checkpoint")
+				return
+			}
+			if found == nil {
+				respondEphemeral(s, i, "No checkpoint found for this channel")
+				return
+			}
+			checkpoint = found
+		}
+
+		goal, err := db.GetGoalByCheckpointAndUser(ctx, queries.GetGoalByCheckpointAndUserParams{CheckpointID: checkpoint.ID, DiscordUser: userID})
+		if err == sql.ErrNoRows {
+			respondEphemeral(s, i, fmt.Sprintf("<@%s> has no goal for checkpoint `%d`", userID, checkpoint.ID))
+			return
+		} else if err != nil {
+			log.Error("cannot get goal", "err", err, "checkpoint_id", checkpoint.ID, "user", userID)
+			respondEphemeral(s, i, "Error getting goal")
+			return
+		}
+
+		actor, err := goalActor(ctx, db, i)
+		if err != nil {
+			log.Error("cannot check goal permissions", "err", err, "user", i.Member.User.ID, "guild", i.GuildID)
+			respondEphemeral(s, i, "Error checking permissions")
+			return
+		}
+		visible, err := service.GoalVisibleTo(ctx, db, *goal, actor.ActorID, actor.Moderator)
+		if err != nil {
+			log.Error("cannot check goal visibility", "err", err, "goal_id", goal.ID, "user", actor.ActorID)
+			respondEphemeral(s, i, "Error checking goal visibility")
+			return
+		}
+		if !visible {
+			respondEphemeral(s, i, fmt.Sprintf("You can't see <@%s>'s goal for checkpoint `%d`, it's a %s", userID, checkpoint.ID, service.HiddenGoalText(goal.Visibility)))
+			return
+		}
+
+		revisions, err := db.GetGoalRevisions(ctx, goal.ID)
+		if err != nil {
+			log.Error("cannot get goal revisions", "err", err, "goal_id", goal.ID)
+			respondEphemeral(s, i, "Error getting goal history")
+			return
+		}
+		if len(revisions) == 0 {
+			respondEphemeral(s, i, fmt.Sprintf("No history recorded for <@%s>'s goal on checkpoint `%d`", userID, checkpoint.ID))
+			return
+		}
+
+		evidence, err := db.GetGoalEvidence(ctx, goal.ID)
+		if err != nil {
+			log.Error("cannot get goal evidence", "err", err, "goal_id", goal.ID)
+			respondEphemeral(s, i, "Error getting goal proof")
+			return
+		}
+
+		log.Info("goal-history command executed", "goal_id", goal.ID, "revisions", len(revisions), "user", i.Member.User.ID, "guild", i.GuildID)
+		respondEphemeral(s, i, formatGoalHistory(*checkpoint, userID, revisions, evidence))
+	},
+}
+
+// latestChannelCheckpoint returns the channel's upcoming checkpoint, or its latest past one, nil if there is none
+func latestChannelCheckpoint(db database.CheckpointDatabase, i *discordgo.InteractionCreate) (*queries.Checkpoint, error) {
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	checkpoint, err := service.UpcomingCheckpoint(ctx, db, i.GuildID, i.ChannelID)
+	if err == nil {
+		return checkpoint, nil
+	} else if err != service.ErrNoUpcomingCheckpoint {
+		return nil, err
+	}
+
+	past, err := db.GetPastCheckpointsByChannel(ctx, i.ChannelID)
+	if err != nil {
+		return nil, err
+	}
+	if len(past) == 0 {
+		return nil, nil
+	}
+	return &past[0], nil
+}
+
+// formatGoalHistory lists the revisions oldest first followed by the goal's proof,
+// dropping the oldest revisions if the message gets too long
+func formatGoalHistory(checkpoint queries.Checkpoint, userID string, revisions []queries.GoalRevision, evidence []queries.GoalEvidence) string {
+	entries := make([]string, len(revisions))
+	for n, revision := range revisions {
+		entry := fmt.Sprintf("**v%d** by <@%s>", n+1, revision.EditedBy)
+		if revision.CreatedAt.Valid {
+			entry += fmt.Sprintf(" <t:%d:R>", revision.CreatedAt.Time.Unix())
+		}
+
+		if n == 0 {
+			entry += " " + revisionStatus(revision)
+			entry += "\n> " + audit.Truncate(markdownEscaper.Replace(strings.Join(strings.Fields(revision.Description), " ")), goalRevisionMaxLength)
+		} else {
+			previous := revisions[n-1]
+			if previous.Status != revision.Status || previous.Progress != revision.Progress {
+				entry += fmt.Sprintf(" %s → %s", revisionStatus(previous), revisionStatus(revision))
+			}
+			if previous.Description != revision.Description {
+				entry += "\n> " + formatDiff(service.DiffWords(previous.Description, revision.Description), goalRevisionMaxLength)
+			}
+		}
+		entries[n] = entry + "\n"
+	}
+
+	header := fmt.Sprintf("**Goal history** for <@%s> on checkpoint `%d`\n", userID, checkpoint.ID)
+	proof := goalEvidenceSection(evidence)
+	// Keep the newest revisions, leaving room for the proof and the note about hidden ones
+	length := len(header) + len(proof) + 64
+	first := len(entries)
+	for first > 0 && length+len(entries[first-1]) <= DiscordMessageMaxLength {
+		first--
+		length += len(entries[first])
+	}
+
+	var sb strings.Builder
+	sb.WriteString(header)
+	if first > 0 {
+		sb.WriteString(fmt.Sprintf("-# %d older revisions not shown\n", first))
+	}
+	for _, entry := range entries[first:] {
+		sb.WriteString(entry)
+	}
+	sb.WriteString(proof)
+	return sb.String()
+}
+
+// revisionStatus formats a revision's status as an emoji, followed by its progress when it's partial
+func revisionStatus(revision queries.GoalRevision) string {
+	if revision.Status == service.StatusPartial {
+		return fmt.Sprintf("%s %d%%", getStatusEmoji(revision.Status, ""), revision.Progress)
+	}
+	return getStatusEmoji(revision.Status, "")
+}
+
+// formatDiff renders a word diff with removed words struck through and added words in bold,
+// stopping with an ellipsis after max characters
+func formatDiff(parts []service.DiffPart, max int) string {
+	var sb strings.Builder
+	for n, part := range parts {
+		text := markdownEscaper.Replace(part.Text)
+		switch part.Op {
+		case service.DiffRemoved:
+			text = "~~" + text + "~~"
+		case service.DiffAdded:
+			text = "**" + text + "**"
+		}
+		if n > 0 {
+			text = " " + text
+		}
+		if sb.Len()+len(text) > max {
+			sb.WriteString(" …")
+			break
+		}
+		sb.WriteString(text)
+	}
+	return sb.String()
+}
+
+func init() {
+	registerCommand(GoalHistoryCmd)
+}
@@ -12,10 +12,12 @@ import (
 	"github.com/charmbracelet/log"
 	"github.com/metruzanca/checkpoint-bot/internal/database"
 	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+	"github.com/metruzanca/checkpoint-bot/internal/service"
+	"github.com/metruzanca/checkpoint-bot/internal/settings"
 )
 
 // GoalCmd allows users to set or edit their goals for the upcoming checkpoint
-// Supports admin override to edit other users' goals
+// Supports a moderator override to edit other users' goals
 var GoalCmd = &Command{
 	ApplicationCommand: discordgo.ApplicationCommand{
 		Name:        "goal",
@@ -24,7 +26,7 @@ var GoalCmd = &Command{
 			{
 				Type:        discordgo.ApplicationCommandOptionUser,
 				Name:        "user",
-				Description: "User whose goals to edit (admin only)",
+				Description: "User whose goals to edit (goal moderators only)",
 				Required:    false,
 			},
 			{
@@ -37,6 +39,10 @@ var GoalCmd = &Command{
 						Name:  "incomplete",
 						Value: "incomplete",
 					},
+					{
+						Name:  "partial",
+						Value: "partial",
+					},
 					{
 						Name:  "failed",
 						Value: "failed",
@@ -47,34 +53,56 @@ var GoalCmd = &Command{
 					},
 				},
 			},
+			{
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Name:        "progress",
+				Description: "How much of your goal is done, in percent (sets the status unless one is given)",
+				Required:    false,
+				MinValue:    &[]float64{0}[0],
+				MaxValue:    100,
+			},
+			{
+				Type:         discordgo.ApplicationCommandOptionString,
+				Name:         "checkpoint",
+				Description:  "Checkpoint to set goals for (default: the channel's upcoming one, or the next one scheduled)",
+				Required:     false,
+				Autocomplete: true,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "visibility",
+				Description: "Who can see your goal's text (default: the server's goal_visibility setting)",
+				Required:    false,
+				Choices:     visibilityChoices,
+			},
 		},
 	},
 	Handler: func(db database.CheckpointDatabase, s *discordgo.Session, i *discordgo.InteractionCreate) {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
-		// Determine target user (self or admin override)
+		actor, err := goalActor(ctx, db, i)
+		if err != nil {
+			log.Error("cannot check goal permissions", "err", err, "user", i.Member.User.ID, "guild", i.GuildID)
+			respondEphemeral(s, i, "Error checking permissions")
+			return
+		}
+
+		// Determine target user (self or moderator override)
 		targetUserID := i.Member.User.ID
 		isAdminOverride := false
-		var statusValue string
+		var statusValue, checkpointValue, visibilityValue string
+		var progress *int64
 
 		options := i.ApplicationCommandData().Options
 		for _, opt := range options {
 			if opt.Name == "user" {
-				// Check if user has admin permissions
-				hasPermission := false
-				if i.Member != nil {
-					// Check for administrator permission
-					permissions := i.Member.Permissions
-					hasPermission = (permissions & discordgo.PermissionAdministrator) != 0
-				}
-
-				if !hasPermission {
-					log.Warn("user attempted admin override without permission", "user", i.Member.User.ID, "guild", i.GuildID)
+				if err := service.CanEditGoal(i.Member.User.ID, opt.UserValue(s).ID, actor.Moderator); err != nil {
+					log.Warn("user attempted goal override without permission", "user", i.Member.User.ID, "guild", i.GuildID)
 					s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 						Type: discordgo.InteractionResponseChannelMessageWithSource,
 						Data: &discordgo.InteractionResponseData{
-							Content: "You don't have permission to edit other users' goals",
+							Content: userMessage(err),
 						},
 					})
 					return
@@ -82,28 +110,25 @@ var GoalCmd = &Command{
 
 				targetUserID = opt.UserValue(s).ID
 				isAdminOverride = true
-				log.Info("admin editing user goals", "admin", i.Member.User.ID, "target_user", targetUserID, "guild", i.GuildID)
+				log.Info("moderator editing user goals", "moderator", i.Member.User.ID, "target_user", targetUserID, "guild", i.GuildID)
 			} else if opt.Name == "status" {
 				statusValue = opt.StringValue()
+			} else if opt.Name == "checkpoint" {
+				checkpointValue = opt.StringValue()
+			} else if opt.Name == "progress" {
+				value := opt.IntValue()
+				progress = &value
+			} else if opt.Name == "visibility" {
+				visibilityValue = opt.StringValue()
 			}
 		}
 
-		// Get upcoming checkpoint for this guild+channel
-		checkpoint, err := db.GetUpcomingCheckpointByGuildAndChannel(ctx, queries.GetUpcomingCheckpointByGuildAndChannelParams{
-			GuildID:   i.GuildID,
-			ChannelID: i.ChannelID,
-		})
-		if err == sql.ErrNoRows {
-			log.Info("no upcoming checkpoint found", "channel", i.ChannelID, "guild", i.GuildID)
-			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-				Type: discordgo.InteractionResponseChannelMessageWithSource,
-				Data: &discordgo.InteractionResponseData{
-					Content: "No upcoming checkpoint found for this channel",
-				},
-			})
+		checkpoint, err := goalCheckpoint(ctx, db, i, checkpointValue)
+		if service.IsUserError(err) {
+			respondEphemeral(s, i, userMessage(err))
 			return
 		} else if err != nil {
-			log.Error("cannot get upcoming checkpoint", "err", err, "channel", i.ChannelID, "guild", i.GuildID)
+			log.Error("cannot get goal checkpoint", "err", err, "checkpoint", checkpointValue, "channel", i.ChannelID, "guild", i.GuildID)
 			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 				Type: discordgo.InteractionResponseChannelMessageWithSource,
 				Data: &discordgo.InteractionResponseData{
@@ -113,20 +138,50 @@ var GoalCmd = &Command{
 			return
 		}
 
-		// Check if goal already exists
-		existingGoal, err := db.GetGoalByCheckpointAndUser(ctx, queries.GetGoalByCheckpointAndUserParams{
-			CheckpointID: checkpoint.ID,
-			DiscordUser:  targetUserID,
-		})
+		// Without a checkpoint the goal is kept for the next one scheduled in the channel
+		if checkpoint == nil {
+			openPendingGoalModal(ctx, db, s, i, targetUserID, statusValue != "" || progress != nil, visibilityValue)
+			return
+		}
 
-		// If status is provided and goal exists, update status immediately
-		if statusValue != "" && err == nil {
-			err = db.UpdateGoalStatus(ctx, queries.UpdateGoalStatusParams{
-				Status:       statusValue,
-				CheckpointID: checkpoint.ID,
-				DiscordUser:  targetUserID,
-			})
-			if err != nil {
+		// Changing only the visibility of an existing goal doesn't need the editor, even after the goal lock
+		if visibilityValue != "" && statusValue == "" && progress == nil {
+			change := actor
+			change.UserID = targetUserID
+			change.Visibility = visibilityValue
+			_, err := service.SaveGoal(ctx, db, checkpoint.ID, change)
+			if err == nil {
+				respondEphemeral(s, i, fmt.Sprintf("Goal visibility set to %s.", visibilityLabel(visibilityValue)))
+				return
+			} else if err != service.ErrGoalNotFound {
+				if service.IsUserError(err) {
+					respondEphemeral(s, i, userMessage(err))
+					return
+				}
+				log.Error("cannot update goal visibility", "err", err, "checkpoint_id", checkpoint.ID, "user", targetUserID, "visibility", visibilityValue)
+				respondEphemeral(s, i, "Error updating goal visibility")
+				return
+			}
+			// Without a goal yet, the editor sets one with this visibility
+		}
+
+		// If only the status or progress is provided, update it immediately
+		if statusValue != "" || progress != nil {
+			change := actor
+			change.UserID = targetUserID
+			change.Status = statusValue
+			change.Progress = progress
+			change.Visibility = visibilityValue
+			result, err := service.SaveGoal(ctx, db, checkpoint.ID, change)
+			if service.IsUserError(err) {
+				s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+					Type: discordgo.InteractionResponseChannelMessageWithSource,
+					Data: &discordgo.InteractionResponseData{
+						Content: userMessage(err),
+					},
+				})
+				return
+			} else if err != nil {
 				log.Error("cannot update goal status", "err", err, "checkpoint_id", checkpoint.ID, "user", targetUserID, "status", statusValue)
 				s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 					Type: discordgo.InteractionResponseChannelMessageWithSource,
@@ -136,28 +191,36 @@ var GoalCmd = &Command{
 				})
 				return
 			}
-			log.Info("goal status updated", "checkpoint_id", checkpoint.ID, "user", targetUserID, "status", statusValue)
+			content := fmt.Sprintf("Goal status updated to %s!", statusLabel(result.Goal.Status, result.Goal.Progress))
+			if result.Goal.Verification == service.VerificationPending {
+				content += " " + getStatusEmoji(result.Goal.Status, result.Goal.Verification) + " It counts in stats once it's verified."
+			}
 			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 				Type: discordgo.InteractionResponseChannelMessageWithSource,
 				Data: &discordgo.InteractionResponseData{
-					Content: fmt.Sprintf("Goal status updated to %s!", statusValue),
+					Content: content,
 					Flags:   discordgo.MessageFlagsEphemeral,
 				},
 			})
 			return
 		}
 
-		// If status is provided but goal doesn't exist, inform user they need to create goal first
-		if statusValue != "" && err == sql.ErrNoRows {
-			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-				Type: discordgo.InteractionResponseChannelMessageWithSource,
-				Data: &discordgo.InteractionResponseData{
-					Content: "You must create a goal first before setting its status. Use /goal without the status parameter to create one.",
-				},
-			})
+		// Don't open the editor once goals are locked, the submission would be rejected anyway
+		if err := service.CheckGoalLock(ctx, db, *checkpoint, actor.OverrideLock); err == service.ErrGoalsLocked {
+			respondEphemeral(s, i, userMessage(err))
+			return
+		} else if err != nil {
+			log.Error("cannot check goal lock", "err", err, "checkpoint_id", checkpoint.ID)
+			respondEphemeral(s, i, "Error checking goal lock")
 			return
 		}
 
+		// Check if goal already exists
+		existingGoal, err := db.GetGoalByCheckpointAndUser(ctx, queries.GetGoalByCheckpointAndUserParams{
+			CheckpointID: checkpoint.ID,
+			DiscordUser:  targetUserID,
+		})
+
 		if err != nil && err != sql.ErrNoRows {
 			log.Error("cannot get existing goal", "err", err, "checkpoint_id", checkpoint.ID, "user", targetUserID)
 			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
@@ -169,10 +232,20 @@ var GoalCmd = &Command{
 			return
 		}
 
-		var goalText string
+		var goalText, targetText, tagsText string
 		if err == nil {
 			// Goal exists, pre-fill with existing text
 			goalText = existingGoal.Description
+			targetText = targetInput(existingGoal.Target, existingGoal.Unit)
+			tags, err := db.GetGoalTags(ctx, existingGoal.ID)
+			if err != nil {
+				log.Error("cannot get goal tags", "err", err, "goal_id", existingGoal.ID)
+			}
+			tagsText = service.FormatTags(tags)
+		} else if visibilityValue == "" && respondTemplateSelect(ctx, db, s, i, strconv.FormatInt(checkpoint.ID, 10), targetUserID) {
+			// New goals can start from a template, picking one opens the editor (which doesn't carry a visibility)
+			log.Info("goal template menu opened", "checkpoint_id", checkpoint.ID, "user", targetUserID)
+			return
 		}
 
 		// Create modal
@@ -181,36 +254,9 @@ var GoalCmd = &Command{
 			modalTitle = fmt.Sprintf("Edit Goals for User")
 		}
 
-		// Include status in custom ID if provided (for setting status after goal creation)
-		customID := fmt.Sprintf("goal_modal_%d_%s", checkpoint.ID, targetUserID)
-		if statusValue != "" {
-			customID = fmt.Sprintf("goal_modal_%d_%s_%s", checkpoint.ID, targetUserID, statusValue)
-		}
-
-		err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseModal,
-			Data: &discordgo.InteractionResponseData{
-				CustomID: customID,
-				Title:    modalTitle,
-				Components: []discordgo.MessageComponent{
-					discordgo.ActionsRow{
-						Components: []discordgo.MessageComponent{
-							discordgo.TextInput{
-								CustomID:    "goal_text",
-								Label:       "Goals",
-								Style:       discordgo.TextInputParagraph,
-								Placeholder: "Enter your goals for this checkpoint...",
-								Value:       goalText,
-								Required:    true,
-								MaxLength:   DiscordTextInputMaxLength,
-								MinLength:   1,
-							},
-						},
-					},
-				},
-			},
-		})
+		customID := goalModalID(strconv.FormatInt(checkpoint.ID, 10), targetUserID, visibilityValue)
 
+		err = respondGoalModal(s, i, customID, modalTitle, goalText, targetText, tagsText)
 		if err != nil {
 			log.Error("cannot respond with modal", "err", err, "channel", i.ChannelID, "guild", i.GuildID)
 			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
@@ -222,8 +268,196 @@ var GoalCmd = &Command{
 			return
 		}
 
-		log.Info("goal modal opened", "checkpoint_id", checkpoint.ID, "user", targetUserID, "is_admin_override", isAdminOverride, "has_existing_goal", existingGoal != nil, "status", statusValue)
+		log.Info("goal modal opened", "checkpoint_id", checkpoint.ID, "user", targetUserID, "is_admin_override", isAdminOverride, "has_existing_goal", existingGoal != nil)
 	},
+	Autocomplete: autocompleteGoalCheckpoint,
+}
+
+// nextCheckpointValue is the checkpoint option's value for the next checkpoint scheduled in the channel
+const nextCheckpointValue = "next"
+
+// goalCheckpoint resolves /goal's checkpoint option, nil means the next checkpoint scheduled in the channel.
+// By default that's the channel's upcoming checkpoint, or the next one if there is none.
+func goalCheckpoint(ctx context.Context, db database.CheckpointDatabase, i *discordgo.InteractionCreate, value string) (*queries.Checkpoint, error) {
+	switch value {
+	case "":
+		checkpoint, err := service.UpcomingCheckpoint(ctx, db, i.GuildID, i.ChannelID)
+		if err == service.ErrNoUpcomingCheckpoint {
+			return nil, nil
+		}
+		return checkpoint, err
+	case nextCheckpointValue:
+		return nil, nil
+	}
+
+	checkpointID, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil, service.ErrCheckpointNotFound
+	}
+	return service.GoalCheckpoint(ctx, db, i.GuildID, checkpointID)
+}
+
+// autocompleteGoalCheckpoint suggests the channel's upcoming checkpoints and the next one to be scheduled
+func autocompleteGoalCheckpoint(db database.CheckpointDatabase, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	var typed string
+	for _, opt := range i.ApplicationCommandData().Options {
+		if opt.Name == "checkpoint" && opt.Focused {
+			typed = strings.ToLower(opt.StringValue())
+		}
+	}
+
+	choices := []*discordgo.ApplicationCommandOptionChoice{}
+	checkpoints, err := db.GetUpcomingCheckpointsByGuildAndChannel(ctx, queries.GetUpcomingCheckpointsByGuildAndChannelParams{
+		GuildID:   i.GuildID,
+		ChannelID: i.ChannelID,
+	})
+	if err != nil {
+		log.Error("cannot get upcoming checkpoints", "err", err, "channel", i.ChannelID, "guild", i.GuildID)
+	}
+	location := time.UTC
+	if guild, err := db.GetGuild(ctx, i.GuildID); err == nil {
+		if loc, err := time.LoadLocation(guild.Timezone); err == nil {
+			location = loc
+		}
+	}
+	for _, checkpoint := range checkpoints {
+		name := fmt.Sprintf("#%d", checkpoint.ID)
+		if scheduledAt, err := time.Parse(time.RFC3339, checkpoint.ScheduledAt); err == nil {
+			name += " - " + scheduledAt.In(location).Format("Mon Jan 2, 15:04 MST")
+		}
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: name, Value: strconv.FormatInt(checkpoint.ID, 10)})
+	}
+	choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: "Next checkpoint scheduled in this channel", Value: nextCheckpointValue})
+
+	filtered := make([]*discordgo.ApplicationCommandOptionChoice, 0, len(choices))
+	for _, choice := range choices {
+		if len(filtered) < 25 && strings.Contains(strings.ToLower(choice.Name), typed) {
+			filtered = append(filtered, choice)
+		}
+	}
+
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{Choices: filtered},
+	})
+	if err != nil {
+		log.Error("cannot respond to autocomplete", "err", err, "channel", i.ChannelID, "guild", i.GuildID)
+	}
+}
+
+// openPendingGoalModal opens the goal editor for the next checkpoint scheduled in the channel
+func openPendingGoalModal(ctx context.Context, db database.CheckpointDatabase, s *discordgo.Session, i *discordgo.InteractionCreate, targetUserID string, hasStatus bool, visibility string) {
+	if hasStatus {
+		respondEphemeral(s, i, userMessage(service.ErrPendingGoalStatus))
+		return
+	}
+
+	var goalText, targetText, tagsText string
+	pending, err := db.GetPendingGoal(ctx, queries.GetPendingGoalParams{ChannelID: i.ChannelID, DiscordUser: targetUserID})
+	if err == nil {
+		goalText = pending.Description
+		targetText = targetInput(pending.Target, pending.Unit)
+		if pending.Tags != "" {
+			tagsText = service.FormatTags(strings.Split(pending.Tags, ","))
+		}
+	} else if err != sql.ErrNoRows {
+		log.Error("cannot get pending goal", "err", err, "channel", i.ChannelID, "user", targetUserID)
+		respondEphemeral(s, i, "Error checking for existing goal")
+		return
+	}
+	if pending == nil && visibility == "" && respondTemplateSelect(ctx, db, s, i, nextCheckpointValue, targetUserID) {
+		log.Info("goal template menu opened", "channel", i.ChannelID, "user", targetUserID)
+		return
+	}
+
+	customID := goalModalID(nextCheckpointValue, targetUserID, visibility)
+	if err := respondGoalModal(s, i, customID, "Set Goals for the Next Checkpoint", goalText, targetText, tagsText); err != nil {
+		log.Error("cannot respond with modal", "err", err, "channel", i.ChannelID, "guild", i.GuildID)
+		respondEphemeral(s, i, "Error opening goal editor")
+		return
+	}
+	log.Info("pending goal modal opened", "channel", i.ChannelID, "user", targetUserID, "has_existing_goal", pending != nil)
+}
+
+const (
+	// targetMaxLength is the length limit of the goal modal's target input, e.g. "5000 words"
+	targetMaxLength = 40
+	// tagsMaxLength fits service.MaxGoalTags tags separated by commas
+	tagsMaxLength = service.MaxGoalTags * (settings.TagMaxLength + 2)
+)
+
+// targetInput formats a goal's target for the goal modal, e.g. "20 km"
+func targetInput(target float64, unit string) string {
+	if target <= 0 {
+		return ""
+	}
+	return strings.TrimSpace(service.FormatAmount(target) + " " + unit)
+}
+
+// goalModalID is the goal editor's custom ID: goal_modal_{checkpoint_id|next}_{user_id},
+// followed by _{status}_{visibility} when a visibility is chosen (the status is left empty)
+func goalModalID(checkpointValue string, userID string, visibility string) string {
+	customID := fmt.Sprintf("goal_modal_%s_%s", checkpointValue, userID)
+	if visibility != "" {
+		customID += "__" + visibility
+	}
+	return customID
+}
+
+// respondGoalModal opens the goal editor, pre-filled with goalText, targetText and tagsText
+func respondGoalModal(s *discordgo.Session, i *discordgo.InteractionCreate, customID string, title string, goalText string, targetText string, tagsText string) error {
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseModal,
+		Data: &discordgo.InteractionResponseData{
+			CustomID: customID,
+			Title:    title,
+			Components: []discordgo.MessageComponent{
+				discordgo.ActionsRow{
+					Components: []discordgo.MessageComponent{
+						discordgo.TextInput{
+							CustomID:    "goal_text",
+							Label:       "Goals",
+							Style:       discordgo.TextInputParagraph,
+							Placeholder: "Enter your goals for this checkpoint...",
+							Value:       goalText,
+							Required:    true,
+							MaxLength:   DiscordTextInputMaxLength,
+							MinLength:   1,
+						},
+					},
+				},
+				discordgo.ActionsRow{
+					Components: []discordgo.MessageComponent{
+						discordgo.TextInput{
+							CustomID:    "goal_target",
+							Label:       "Target (optional, track it with /log)",
+							Style:       discordgo.TextInputShort,
+							Placeholder: "e.g. 20 km or 5000 words",
+							Value:       targetText,
+							Required:    false,
+							MaxLength:   targetMaxLength,
+						},
+					},
+				},
+				discordgo.ActionsRow{
+					Components: []discordgo.MessageComponent{
+						discordgo.TextInput{
+							CustomID:    "goal_tags",
+							Label:       "Tags (optional, separated by commas)",
+							Style:       discordgo.TextInputShort,
+							Placeholder: "e.g. fitness, running",
+							Value:       tagsText,
+							Required:    false,
+							MaxLength:   tagsMaxLength,
+						},
+					},
+				},
+			},
+		},
+	})
 }
 
 // HandleGoalModalSubmission handles modal submissions for goal creation and editing
@@ -234,9 +468,9 @@ func HandleGoalModalSubmission(db database.CheckpointDatabase, s *discordgo.Sess
 
 	data := i.ModalSubmitData()
 
-	// Parse custom ID: goal_modal_{checkpoint_id}_{user_id} or goal_modal_{checkpoint_id}_{user_id}_{status}
+	// Parse custom ID: goal_modal_{checkpoint_id}_{user_id}, optionally followed by _{status} and _{visibility}
 	parts := strings.Split(data.CustomID, "_")
-	if len(parts) < 4 || len(parts) > 5 || parts[0] != "goal" || parts[1] != "modal" {
+	if len(parts) < 4 || len(parts) > 6 || parts[0] != "goal" || parts[1] != "modal" {
 		log.Error("invalid goal modal custom ID format", "custom_id", data.CustomID)
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
@@ -248,8 +482,10 @@ func HandleGoalModalSubmission(db database.CheckpointDatabase, s *discordgo.Sess
 		return
 	}
 
+	// goal_modal_next_{user_id} is a goal for the next checkpoint scheduled in the channel
+	pending := parts[2] == nextCheckpointValue
 	checkpointID, err := strconv.ParseInt(parts[2], 10, 64)
-	if err != nil {
+	if err != nil && !pending {
 		log.Error("cannot parse checkpoint ID from modal custom ID", "err", err, "custom_id", data.CustomID)
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
@@ -261,22 +497,25 @@ func HandleGoalModalSubmission(db database.CheckpointDatabase, s *discordgo.Sess
 		return
 	}
 	targetUserID := parts[3]
-	var statusValue string
-	if len(parts) == 5 {
+	var statusValue, visibilityValue string
+	if len(parts) >= 5 {
 		statusValue = parts[4]
 	}
+	if len(parts) == 6 {
+		visibilityValue = parts[5]
+	}
 
-	// Get goal text from modal
-	goalText := ""
-	for _, component := range data.Components {
-		if actionRow, ok := component.(*discordgo.ActionsRow); ok {
-			for _, comp := range actionRow.Components {
-				if textInput, ok := comp.(*discordgo.TextInput); ok && textInput.CustomID == "goal_text" {
-					goalText = textInput.Value
-					break
-				}
-			}
-		}
+	// Get goal text and target from modal
+	goalText := modalValue(data, "goal_text")
+	target, unit, err := service.ParseTarget(modalValue(data, "goal_target"))
+	if err != nil {
+		respondEphemeral(s, i, userMessage(err))
+		return
+	}
+	tags, err := service.ParseTags(modalValue(data, "goal_tags"))
+	if err != nil {
+		respondEphemeral(s, i, userMessage(err))
+		return
 	}
 
 	if goalText == "" {
@@ -291,111 +530,113 @@ func HandleGoalModalSubmission(db database.CheckpointDatabase, s *discordgo.Sess
 		return
 	}
 
-	// Check if goal exists
-	existingGoal, err := db.GetGoalByCheckpointAndUser(ctx, queries.GetGoalByCheckpointAndUserParams{
-		CheckpointID: checkpointID,
-		DiscordUser:  targetUserID,
-	})
+	change, err := goalActor(ctx, db, i)
+	if err != nil {
+		log.Error("cannot check goal permissions", "err", err, "user", i.Member.User.ID, "guild", i.GuildID)
+		respondEphemeral(s, i, "Error checking permissions")
+		return
+	}
+	change.UserID = targetUserID
+	change.Description = goalText
+	change.Status = statusValue
+	change.Target = &target
+	change.Unit = unit
+	change.Tags = tags
+	change.Visibility = visibilityValue
 
-	if err == sql.ErrNoRows {
-		// Create new goal
-		_, err = db.CreateGoal(ctx, queries.CreateGoalParams{
-			DiscordUser:  targetUserID,
-			Description:  goalText,
-			CheckpointID: checkpointID,
+	if pending {
+		savePendingGoal(ctx, db, s, i, change)
+		return
+	}
+
+	result, err := service.SaveGoal(ctx, db, checkpointID, change)
+	if service.IsUserError(err) {
+		log.Warn("invalid goal submission", "err", err, "checkpoint_id", checkpointID, "user", targetUserID)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: userMessage(err),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
 		})
-		if err != nil {
-			log.Error("cannot create goal", "err", err, "checkpoint_id", checkpointID, "user", targetUserID)
-			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-				Type: discordgo.InteractionResponseChannelMessageWithSource,
-				Data: &discordgo.InteractionResponseData{
-					Content: "Error saving goal",
-					Flags:   discordgo.MessageFlagsEphemeral,
-				},
-			})
-			return
-		}
-		log.Info("goal created", "checkpoint_id", checkpointID, "user", targetUserID)
-
-		// If status was provided, update it now
-		if statusValue != "" {
-			err = db.UpdateGoalStatus(ctx, queries.UpdateGoalStatusParams{
-				Status:       statusValue,
-				CheckpointID: checkpointID,
-				DiscordUser:  targetUserID,
-			})
-			if err != nil {
-				log.Error("cannot update goal status after creation", "err", err, "checkpoint_id", checkpointID, "user", targetUserID, "status", statusValue)
-				// Don't fail the whole operation, just log the error
-			} else {
-				log.Info("goal status set after creation", "checkpoint_id", checkpointID, "user", targetUserID, "status", statusValue)
-			}
-		}
+		return
 	} else if err != nil {
-		log.Error("cannot check for existing goal", "err", err, "checkpoint_id", checkpointID, "user", targetUserID)
+		log.Error("cannot save goal", "err", err, "checkpoint_id", checkpointID, "user", targetUserID)
 		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseChannelMessageWithSource,
 			Data: &discordgo.InteractionResponseData{
-				Content: "Error checking for existing goal",
+				Content: "Error saving goal",
 				Flags:   discordgo.MessageFlagsEphemeral,
 			},
 		})
 		return
-	} else {
-		// Update existing goal
-		err = db.UpdateGoalDescription(ctx, queries.UpdateGoalDescriptionParams{
-			Description:  goalText,
-			CheckpointID: checkpointID,
-			DiscordUser:  targetUserID,
-		})
-		if err != nil {
-			log.Error("cannot update goal", "err", err, "checkpoint_id", checkpointID, "user", targetUserID)
-			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-				Type: discordgo.InteractionResponseChannelMessageWithSource,
-				Data: &discordgo.InteractionResponseData{
-					Content: "Error updating goal",
-					Flags:   discordgo.MessageFlagsEphemeral,
-				},
-			})
-			return
-		}
-		log.Info("goal updated", "checkpoint_id", checkpointID, "user", targetUserID)
-
-		// If status was provided, update it now
-		if statusValue != "" {
-			err = db.UpdateGoalStatus(ctx, queries.UpdateGoalStatusParams{
-				Status:       statusValue,
-				CheckpointID: checkpointID,
-				DiscordUser:  targetUserID,
-			})
-			if err != nil {
-				log.Error("cannot update goal status after update", "err", err, "checkpoint_id", checkpointID, "user", targetUserID, "status", statusValue)
-				// Don't fail the whole operation, just log the error
-			} else {
-				log.Info("goal status set after update", "checkpoint_id", checkpointID, "user", targetUserID, "status", statusValue)
-			}
-		}
 	}
 
 	// Success response
-	action := "created"
-	if existingGoal != nil {
-		action = "updated"
+	action := "updated"
+	if result.Created {
+		action = "created"
 	}
 	statusMsg := ""
 	if statusValue != "" {
 		statusMsg = fmt.Sprintf(" Status set to %s.", statusValue)
 	}
 
+	content := fmt.Sprintf("Goal %s successfully!%s", action, statusMsg)
+	if visibilityValue != "" {
+		content += fmt.Sprintf(" Visibility set to %s.", visibilityLabel(visibilityValue))
+	}
+
+	// With goal categories, the reply lets the member pick the goal's categories
+	var components []discordgo.MessageComponent
+	guildSettings, err := settings.Load(ctx, db, i.GuildID)
+	if err != nil {
+		log.Error("cannot load guild settings", "err", err, "guild", i.GuildID)
+	} else if len(guildSettings.GoalCategories) > 0 {
+		components = categorySelect(result.Goal.ID, guildSettings.GoalCategories, tags)
+		content += " Pick its categories below."
+	}
+
 	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseChannelMessageWithSource,
 		Data: &discordgo.InteractionResponseData{
-			Content: fmt.Sprintf("Goal %s successfully!%s", action, statusMsg),
-			Flags:   discordgo.MessageFlagsEphemeral,
+			Content:    content,
+			Components: components,
+			Flags:      discordgo.MessageFlagsEphemeral,
 		},
 	})
 }
 
+// modalValue returns the value of a modal's text input
+func modalValue(data discordgo.ModalSubmitInteractionData, customID string) string {
+	for _, component := range data.Components {
+		if actionRow, ok := component.(*discordgo.ActionsRow); ok {
+			for _, comp := range actionRow.Components {
+				if textInput, ok := comp.(*discordgo.TextInput); ok && textInput.CustomID == customID {
+					return textInput.Value
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// savePendingGoal saves a goal submitted for the next checkpoint scheduled in the channel
+func savePendingGoal(ctx context.Context, db database.CheckpointDatabase, s *discordgo.Session, i *discordgo.InteractionCreate, change service.GoalChange) {
+	_, err := service.SavePendingGoal(ctx, db, i.GuildID, i.ChannelID, change)
+	if service.IsUserError(err) {
+		log.Warn("invalid pending goal submission", "err", err, "channel", i.ChannelID, "user", change.UserID)
+		respondEphemeral(s, i, userMessage(err))
+		return
+	} else if err != nil {
+		log.Error("cannot save pending goal", "err", err, "channel", i.ChannelID, "user", change.UserID)
+		respondEphemeral(s, i, "Error saving goal")
+		return
+	}
+	respondEphemeral(s, i, "Goal saved! It will be added to the next checkpoint scheduled in this channel.")
+}
+
 func init() {
 	registerCommand(GoalCmd)
+	registerModal("goal_modal_", HandleGoalModalSubmission)
 }
@@ -0,0 +1,129 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/charmbracelet/log"
+	"github.com/metruzanca/checkpoint-bot/internal/database"
+	"github.com/metruzanca/checkpoint-bot/internal/service"
+	"github.com/metruzanca/checkpoint-bot/internal/settings"
+)
+
+// LogCmd logs an amount towards a goal's target between checkpoints, e.g. 5 km of a 20 km goal
+var LogCmd = &Command{
+	ApplicationCommand: discordgo.ApplicationCommand{
+		Name:        "log",
+		Description: "Log progress towards your goal's target for the upcoming checkpoint",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionNumber,
+				Name:        "amount",
+				Description: "How much to add to your total, negative amounts correct earlier check-ins",
+				Required:    true,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "note",
+				Description: "What you did",
+				Required:    false,
+				MaxLength:   service.NoteMaxLength,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionUser,
+				Name:        "user",
+				Description: "User to log progress for (goal moderators only)",
+				Required:    false,
+			},
+		},
+	},
+	Handler: func(db database.CheckpointDatabase, s *discordgo.Session, i *discordgo.InteractionCreate) {
+		ctx, cancel := dbContext()
+		defer cancel()
+
+		actor, err := goalActor(ctx, db, i)
+		if err != nil {
+			log.Error("cannot check goal permissions", "err", err, "user", i.Member.User.ID, "guild", i.GuildID)
+			respondEphemeral(s, i, "Error checking permissions")
+			return
+		}
+
+		checkin := service.Checkin{
+			ActorID:   actor.ActorID,
+			Moderator: actor.Moderator,
+			UserID:    actor.ActorID,
+		}
+		for _, opt := range i.ApplicationCommandData().Options {
+			switch opt.Name {
+			case "amount":
+				checkin.Amount = opt.FloatValue()
+			case "note":
+				checkin.Note = opt.StringValue()
+			case "user":
+				checkin.UserID = opt.UserValue(s).ID
+			}
+		}
+
+		checkpoint, err := service.UpcomingCheckpoint(ctx, db, i.GuildID, i.ChannelID)
+		if service.IsUserError(err) {
+			respondEphemeral(s, i, userMessage(err))
+			return
+		} else if err != nil {
+			log.Error("cannot get upcoming checkpoint", "err", err, "channel", i.ChannelID, "guild", i.GuildID)
+			respondEphemeral(s, i, "Error getting upcoming checkpoint")
+			return
+		}
+
+		result, err := service.LogGoal(ctx, db, checkpoint.ID, checkin)
+		if service.IsUserError(err) {
+			respondEphemeral(s, i, userMessage(err))
+			return
+		} else if err != nil {
+			log.Error("cannot log goal progress", "err", err, "checkpoint_id", checkpoint.ID, "user", checkin.UserID)
+			respondEphemeral(s, i, "Error logging progress")
+			return
+		}
+
+		// Notes about goals that aren't public aren't shared with the channel
+		var flags discordgo.MessageFlags
+		if result.Goal.Visibility != settings.VisibilityPublic {
+			flags = discordgo.MessageFlagsEphemeral
+		}
+		err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: checkinMessage(checkin, result),
+				Flags:   flags,
+			},
+		})
+		if err != nil {
+			log.Error("cannot respond to log command", "err", err, "channel", i.ChannelID, "guild", i.GuildID)
+		}
+	},
+}
+
+// checkinMessage announces a check-in with the goal's running total
+func checkinMessage(checkin service.Checkin, result *service.CheckinResult) string {
+	goal := result.Goal
+	amount := service.FormatAmount(checkin.Amount)
+	if checkin.Amount > 0 {
+		amount = "+" + amount
+	}
+	if goal.Unit != "" {
+		amount += " " + goal.Unit
+	}
+
+	msg := fmt.Sprintf("📈 <@%s> logged **%s**", checkin.UserID, amount)
+	if checkin.Note != "" {
+		msg += fmt.Sprintf(": %s", checkin.Note)
+	}
+	msg += fmt.Sprintf("\n%s · %s", progressBar(goal.Progress), service.FormatTarget(goal.Total, goal.Target, goal.Unit))
+	if result.Completed {
+		msg += "\n🎉 Goal completed!"
+	}
+	return msg
+}
+
+func init() {
+	registerCommand(LogCmd)
+}
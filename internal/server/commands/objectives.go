@@ -0,0 +1,347 @@
+package commands
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/charmbracelet/log"
+	"github.com/metruzanca/checkpoint-bot/internal/database"
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+	"github.com/metruzanca/checkpoint-bot/internal/service"
+)
+
+// objectiveOption is the option picking one of the member's objectives, with autocomplete
+var objectiveOption = &discordgo.ApplicationCommandOption{
+	Type:         discordgo.ApplicationCommandOptionString,
+	Name:         "objective",
+	Description:  "One of your objectives",
+	Required:     true,
+	Autocomplete: true,
+}
+
+// ObjectivesCmd manages long-term objectives, which the goals of several checkpoints work towards
+var ObjectivesCmd = &Command{
+	ApplicationCommand: discordgo.ApplicationCommand{
+		Name:        "objectives",
+		Description: "Long-term objectives that your checkpoint goals work towards",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "list",
+				Description: "Show objectives and their progress from linked goals",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionUser,
+						Name:        "user",
+						Description: "Whose objectives to show (default: yours)",
+						Required:    false,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "add",
+				Description: "Add an objective, e.g. ship side project by March",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "title",
+						Description: "What you want to achieve",
+						Required:    true,
+						MaxLength:   service.ObjectiveTitleMaxLength,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "deadline",
+						Description: "Deadline in YYYY-MM-DD format",
+						Required:    true,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "link",
+				Description: "Link your goal in this channel's upcoming or latest checkpoint to an objective",
+				Options:     []*discordgo.ApplicationCommandOption{objectiveOption},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "unlink",
+				Description: "Unlink your goal in this channel's upcoming or latest checkpoint from its objective",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "complete",
+				Description: "Mark an objective as achieved",
+				Options:     []*discordgo.ApplicationCommandOption{objectiveOption},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "delete",
+				Description: "Delete an objective, its goals are kept",
+				Options:     []*discordgo.ApplicationCommandOption{objectiveOption},
+			},
+		},
+	},
+	Handler: func(db database.CheckpointDatabase, s *discordgo.Session, i *discordgo.InteractionCreate) {
+		sub := i.ApplicationCommandData().Options[0]
+		switch sub.Name {
+		case "list":
+			userID := i.Member.User.ID
+			if len(sub.Options) > 0 {
+				userID = sub.Options[0].UserValue(s).ID
+			}
+			handleObjectivesList(db, s, i, userID)
+		case "add":
+			handleObjectiveAdd(db, s, i, sub)
+		case "link":
+			handleObjectiveLink(db, s, i, sub.Options[0].StringValue())
+		case "unlink":
+			handleObjectiveLink(db, s, i, "")
+		case "complete", "delete":
+			handleObjectiveUpdate(db, s, i, sub.Name, sub.Options[0].StringValue())
+		}
+	},
+	Autocomplete: autocompleteObjective,
+}
+
+// handleObjectivesList shows the member's objectives with the progress of their linked goals
+func handleObjectivesList(db database.CheckpointDatabase, s *discordgo.Session, i *discordgo.InteractionCreate, userID string) {
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	objectives, err := service.Objectives(ctx, db, i.GuildID, userID)
+	if err != nil {
+		log.Error("cannot get objectives", "err", err, "user", userID, "guild", i.GuildID)
+		respondEphemeral(s, i, "Error loading objectives")
+		return
+	}
+	if len(objectives) == 0 {
+		respondEphemeral(s, i, fmt.Sprintf("<@%s> has no objectives yet, add one with `/objectives add`", userID))
+		return
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "🏔️ Objectives",
+		Color:       0x0099ff,
+		Description: fmt.Sprintf("<@%s>", userID),
+		Footer:      &discordgo.MessageEmbedFooter{Text: "Link goals with /objectives link"},
+	}
+	now := time.Now()
+	for _, objective := range objectives {
+		// An embed has up to 25 fields, achieved objectives come last
+		if len(embed.Fields) == 25 {
+			break
+		}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  objectiveTitle(objective, now),
+			Value: objectiveSummary(objective),
+		})
+	}
+
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{embed},
+		},
+	})
+}
+
+// objectiveTitle formats an objective's title with an emoji for its status
+func objectiveTitle(objective service.ObjectiveProgress, now time.Time) string {
+	switch {
+	case objective.Objective.Status == service.ObjectiveAchieved:
+		return "🏆 " + objective.Objective.Title
+	case objective.Overdue(now):
+		return "⏰ " + objective.Objective.Title + " (overdue)"
+	default:
+		return "🎯 " + objective.Objective.Title
+	}
+}
+
+// objectiveSummary formats an objective's progress, e.g. ▰▰▰▱▱▱▱▱▱▱ 30% · 3/10 goals completed over 8 checkpoints
+func objectiveSummary(objective service.ObjectiveProgress) string {
+	deadline := fmt.Sprintf("Due <t:%d:D> (<t:%d:R>)", objective.Deadline.Unix(), objective.Deadline.Unix())
+	if objective.Goals == 0 {
+		return deadline + "\nNo linked goals yet"
+	}
+	return fmt.Sprintf("%s\n%s · %d/%d goals completed over %d checkpoints",
+		deadline, progressBar(int64(math.Round(objective.Progress*100))), objective.Completed, objective.Goals, objective.Checkpoints)
+}
+
+// handleObjectiveAdd adds an objective for the member
+func handleObjectiveAdd(db database.CheckpointDatabase, s *discordgo.Session, i *discordgo.InteractionCreate, sub *discordgo.ApplicationCommandInteractionDataOption) {
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	var title, deadline string
+	for _, opt := range sub.Options {
+		switch opt.Name {
+		case "title":
+			title = opt.StringValue()
+		case "deadline":
+			deadline = opt.StringValue()
+		}
+	}
+
+	// Objectives reference the guild and their deadline is in its timezone
+	guild, err := ensureGuild(ctx, db, s, i.GuildID)
+	if err != nil {
+		log.Error("cannot ensure guild", "err", err, "guild", i.GuildID)
+		respondEphemeral(s, i, "Error checking guild")
+		return
+	}
+	userID := i.Member.User.ID
+	objective, err := service.CreateObjective(ctx, db, *guild, userID, title, deadline)
+	if service.IsUserError(err) {
+		respondEphemeral(s, i, userMessage(err))
+		return
+	} else if err != nil {
+		log.Error("cannot create objective", "err", err, "user", userID, "guild", i.GuildID)
+		respondEphemeral(s, i, "Error creating objective")
+		return
+	}
+	respondEphemeral(s, i, fmt.Sprintf("🎯 Added the objective **%s**, due %s.\nLink your checkpoint goals to it with `/objectives link`.", objective.Title, strings.TrimSpace(deadline)))
+}
+
+// handleObjectiveLink links the member's goal in the channel to an objective, or unlinks it when value is empty
+func handleObjectiveLink(db database.CheckpointDatabase, s *discordgo.Session, i *discordgo.InteractionCreate, value string) {
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	userID := i.Member.User.ID
+	var objective *queries.Objective
+	if value != "" {
+		var err error
+		objective, err = service.FindObjective(ctx, db, i.GuildID, userID, value)
+		if service.IsUserError(err) {
+			respondEphemeral(s, i, userMessage(err))
+			return
+		} else if err != nil {
+			log.Error("cannot find objective", "err", err, "objective", value, "user", userID, "guild", i.GuildID)
+			respondEphemeral(s, i, "Error loading objective")
+			return
+		}
+	}
+
+	checkpoint, err := latestChannelCheckpoint(db, i)
+	if err != nil {
+		log.Error("cannot get channel checkpoint", "err", err, "channel", i.ChannelID, "guild", i.GuildID)
+		respondEphemeral(s, i, "Error getting checkpoint")
+		return
+	}
+	if checkpoint == nil {
+		respondEphemeral(s, i, "There are no checkpoints in this channel")
+		return
+	}
+
+	_, err = service.LinkGoal(ctx, db, checkpoint.ID, userID, objective)
+	if service.IsUserError(err) {
+		respondEphemeral(s, i, userMessage(err))
+		return
+	} else if err != nil {
+		log.Error("cannot link goal to objective", "err", err, "checkpoint_id", checkpoint.ID, "objective", value, "user", userID)
+		respondEphemeral(s, i, "Error linking goal")
+		return
+	}
+
+	if objective == nil {
+		respondEphemeral(s, i, "Your goal is no longer linked to an objective")
+		return
+	}
+	respondEphemeral(s, i, fmt.Sprintf("🔗 Your goal now counts towards **%s**", objective.Title))
+}
+
+// handleObjectiveUpdate marks one of the member's objectives as achieved or deletes it
+func handleObjectiveUpdate(db database.CheckpointDatabase, s *discordgo.Session, i *discordgo.InteractionCreate, action string, value string) {
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	userID := i.Member.User.ID
+	objective, err := service.FindObjective(ctx, db, i.GuildID, userID, value)
+	if service.IsUserError(err) {
+		respondEphemeral(s, i, userMessage(err))
+		return
+	} else if err != nil {
+		log.Error("cannot find objective", "err", err, "objective", value, "user", userID, "guild", i.GuildID)
+		respondEphemeral(s, i, "Error loading objective")
+		return
+	}
+
+	if action == "delete" {
+		err = service.DeleteObjective(ctx, db, *objective)
+	} else {
+		err = service.CompleteObjective(ctx, db, *objective)
+	}
+	if service.IsUserError(err) {
+		respondEphemeral(s, i, userMessage(err))
+		return
+	} else if err != nil {
+		log.Error("cannot update objective", "err", err, "action", action, "objective_id", objective.ID, "user", userID)
+		respondEphemeral(s, i, "Error updating objective")
+		return
+	}
+
+	if action == "delete" {
+		respondEphemeral(s, i, fmt.Sprintf("🗑️ Deleted the objective **%s**, its goals are kept", objective.Title))
+		return
+	}
+	// Achieving an objective is worth sharing with the channel
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content:         fmt.Sprintf("🏆 <@%s> achieved their objective **%s**!", userID, objective.Title),
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		},
+	})
+}
+
+// autocompleteObjective suggests the member's objectives, active ones first
+func autocompleteObjective(db database.CheckpointDatabase, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	sub := i.ApplicationCommandData().Options[0]
+	var typed string
+	for _, opt := range sub.Options {
+		if opt.Name == "objective" && opt.Focused {
+			typed = strings.ToLower(opt.StringValue())
+		}
+	}
+
+	userID := i.Member.User.ID
+	objectives, err := service.Objectives(ctx, db, i.GuildID, userID)
+	if err != nil {
+		log.Error("cannot get objectives", "err", err, "user", userID, "guild", i.GuildID)
+	}
+	choices := []*discordgo.ApplicationCommandOptionChoice{}
+	for _, objective := range objectives {
+		if len(choices) == 25 || !strings.Contains(strings.ToLower(objective.Objective.Title), typed) {
+			continue
+		}
+		// Only active objectives can have goals linked
+		if sub.Name == "link" && objective.Objective.Status == service.ObjectiveAchieved {
+			continue
+		}
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+			Name:  objective.Objective.Title,
+			Value: strconv.FormatInt(objective.Objective.ID, 10),
+		})
+	}
+
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{Choices: choices},
+	})
+	if err != nil {
+		log.Error("cannot respond to autocomplete", "err", err, "channel", i.ChannelID, "guild", i.GuildID)
+	}
+}
+
+func init() {
+	registerCommand(ObjectivesCmd)
+}
@@ -0,0 +1,183 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/charmbracelet/log"
+	"github.com/metruzanca/checkpoint-bot/internal/database"
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+	"github.com/metruzanca/checkpoint-bot/internal/events"
+	"github.com/metruzanca/checkpoint-bot/internal/service"
+	"github.com/metruzanca/checkpoint-bot/internal/settings"
+)
+
+// PartnerNotifier DMs accountability partners when a goal is set, changed, failed or completed,
+// and tells members when their goal is verified. With peer verification on, it also posts completed
+// goals in their checkpoint's channel for other members to confirm.
+type PartnerNotifier struct {
+	db      database.CheckpointDatabase
+	discord *discordgo.Session
+
+	queue chan events.Event
+	stop  chan struct{}
+	wg    sync.WaitGroup
+}
+
+func NewPartnerNotifier(db database.CheckpointDatabase, discord *discordgo.Session) *PartnerNotifier {
+	return &PartnerNotifier{
+		db:      db,
+		discord: discord,
+		queue:   make(chan events.Event, 64),
+		stop:    make(chan struct{}),
+	}
+}
+
+// Start subscribes to goal events and sends notifications until Stop is called
+func (n *PartnerNotifier) Start() {
+	n.db.Events().Subscribe(n.handle)
+	n.wg.Add(1)
+	go n.loop()
+}
+
+func (n *PartnerNotifier) Stop() {
+	close(n.stop)
+	n.wg.Wait()
+}
+
+// handle runs on the publisher's goroutine, so it only hands the event over
+func (n *PartnerNotifier) handle(event events.Event) {
+	if _, ok := event.Data.(events.GoalData); !ok {
+		return
+	}
+	select {
+	case n.queue <- event:
+	default:
+		log.Warn("partner notifier queue full, dropping event", "event_id", event.ID, "type", event.Type, "guild_id", event.GuildID)
+	}
+}
+
+func (n *PartnerNotifier) loop() {
+	defer n.wg.Done()
+	for {
+		select {
+		case <-n.stop:
+			return
+		case event := <-n.queue:
+			n.notify(event)
+		}
+	}
+}
+
+// notify DMs the partner of the goal's owner, or the owner once their goal is verified
+func (n *PartnerNotifier) notify(event events.Event) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	goal := event.Data.(events.GoalData)
+	if event.Type == events.GoalVerified {
+		n.send(goal.UserID, &discordgo.MessageSend{
+			Content: fmt.Sprintf("✅ Your goal for checkpoint #%d was verified by <@%s>, it now counts in your stats", goal.CheckpointID, goal.VerifiedBy),
+		})
+		return
+	}
+
+	message := partnerNotification(event.Type, goal)
+	if message == nil {
+		return
+	}
+	checkpoint, err := n.db.GetCheckpoint(ctx, goal.CheckpointID)
+	if err != nil {
+		log.Error("cannot get checkpoint for partner notification", "err", err, "checkpoint_id", goal.CheckpointID)
+		return
+	}
+	if event.Type == events.GoalStatusChanged && goal.Verification == service.VerificationPending {
+		n.requestConfirmations(ctx, *checkpoint, goal.ID)
+	}
+	partner, err := service.PartnerFor(ctx, n.db, *checkpoint, goal.UserID)
+	if err != nil {
+		log.Error("cannot get partner for notification", "err", err, "checkpoint_id", goal.CheckpointID, "user", goal.UserID)
+		return
+	}
+	if partner == "" || partner == goal.UserID {
+		return
+	}
+	n.send(partner, message)
+}
+
+func (n *PartnerNotifier) send(userID string, message *discordgo.MessageSend) {
+	channel, err := n.discord.UserChannelCreate(userID)
+	if err != nil {
+		log.Warn("cannot open DM for partner notification", "err", err, "user", userID)
+		return
+	}
+	if _, err := n.discord.ChannelMessageSendComplex(channel.ID, message); err != nil {
+		log.Warn("cannot send partner notification", "err", err, "user", userID)
+		return
+	}
+	log.Info("partner notification sent", "user", userID)
+}
+
+// requestConfirmations posts a completed goal in its checkpoint's channel when the guild requires peer confirmations
+func (n *PartnerNotifier) requestConfirmations(ctx context.Context, checkpoint queries.Checkpoint, goalID int64) {
+	guildSettings, err := settings.Load(ctx, n.db, checkpoint.GuildID)
+	if err != nil {
+		log.Error("cannot load guild settings", "err", err, "guild_id", checkpoint.GuildID)
+		return
+	}
+	if guildSettings.VerificationConfirmations == 0 {
+		return
+	}
+	goal, err := n.db.GetGoal(ctx, goalID)
+	if err != nil {
+		log.Error("cannot get goal for verification request", "err", err, "goal_id", goalID)
+		return
+	}
+	confirmation, err := service.GoalConfirmation(ctx, n.db, *goal)
+	if err != nil {
+		log.Error("cannot get goal confirmations", "err", err, "goal_id", goalID)
+		return
+	}
+	content, components := verificationRequest(*confirmation)
+	_, err = n.discord.ChannelMessageSendComplex(checkpoint.ChannelID, &discordgo.MessageSend{
+		Content:    content,
+		Components: components,
+		// Mention nobody, the goal's owner already knows
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
+	})
+	if err != nil {
+		log.Error("cannot post verification request", "err", err, "goal_id", goalID, "channel", checkpoint.ChannelID)
+		return
+	}
+	log.Info("verification request posted", "goal_id", goalID, "channel", checkpoint.ChannelID)
+}
+
+// partnerNotification is the DM sent to the partner of the goal's owner, or nil for changes partners don't hear about
+func partnerNotification(t events.Type, goal events.GoalData) *discordgo.MessageSend {
+	description := sharedGoalText(goal.Description, goal.Visibility, 300)
+	switch {
+	case t == events.GoalCreated:
+		return &discordgo.MessageSend{
+			Content: fmt.Sprintf("🎯 Your partner <@%s> set a goal for checkpoint #%d:\n> %s", goal.UserID, goal.CheckpointID, description),
+		}
+	case t == events.GoalUpdated && goal.Changed == "description":
+		return &discordgo.MessageSend{
+			Content: fmt.Sprintf("✏️ Your partner <@%s> changed their goal for checkpoint #%d:\n> %s", goal.UserID, goal.CheckpointID, description),
+		}
+	case t == events.GoalStatusChanged && goal.Status == service.StatusFailed:
+		return &discordgo.MessageSend{
+			Content: fmt.Sprintf("❌ Your partner <@%s> failed their goal for checkpoint #%d:\n> %s\nMaybe check in with them?", goal.UserID, goal.CheckpointID, description),
+		}
+	case t == events.GoalStatusChanged && goal.Status == service.StatusCompleted && goal.Verification == service.VerificationPending:
+		return &discordgo.MessageSend{
+			Content: fmt.Sprintf("🏁 Your partner <@%s> completed their goal for checkpoint #%d:\n> %s\nVerify it so it counts in their stats.", goal.UserID, goal.CheckpointID, description),
+			Components: []discordgo.MessageComponent{
+				discordgo.ActionsRow{Components: []discordgo.MessageComponent{verifyButton(queries.Goal{ID: goal.ID}, "Verify")}},
+			},
+		}
+	}
+	return nil
+}
@@ -0,0 +1,265 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/charmbracelet/log"
+	"github.com/metruzanca/checkpoint-bot/internal/database"
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+	"github.com/metruzanca/checkpoint-bot/internal/service"
+)
+
+// partnerVerifyButtonPrefix is followed by the goal ID in the buttons verifying a partner's goal
+const partnerVerifyButtonPrefix = "partner_verify_"
+
+// partnerAcceptButtonPrefix and partnerDeclineButtonPrefix are followed by the requesting and the requested
+// member's IDs in the buttons answering a /partner set request
+const (
+	partnerAcceptButtonPrefix  = "partner_accept_"
+	partnerDeclineButtonPrefix = "partner_decline_"
+)
+
+// PartnerCmd manages accountability partners, who are notified about each other's goals and verify their completion
+var PartnerCmd = &Command{
+	ApplicationCommand: discordgo.ApplicationCommand{
+		Name:        "partner",
+		Description: "Manage your accountability partner",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "set",
+				Description: "Ask a member to pair up, you'll follow and verify each other's goals",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionUser,
+						Name:        "user",
+						Description: "Your accountability partner",
+						Required:    true,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "remove",
+				Description: "Stop being accountability partners",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "view",
+				Description: "View your partner and the goals waiting for your verification",
+			},
+		},
+	},
+	Handler: func(db database.CheckpointDatabase, s *discordgo.Session, i *discordgo.InteractionCreate) {
+		ctx, cancel := dbContext()
+		defer cancel()
+
+		userID := i.Member.User.ID
+		sub := i.ApplicationCommandData().Options[0]
+		switch sub.Name {
+		case "set":
+			partner := sub.Options[0].UserValue(s)
+			if partner.Bot {
+				respondEphemeral(s, i, "Bots can't be accountability partners")
+				return
+			}
+			// Partners reference the guild, which only exists once the bot has been used in it
+			if _, err := ensureGuild(ctx, db, s, i.GuildID); err != nil {
+				log.Error("cannot ensure guild", "err", err, "guild", i.GuildID)
+				respondEphemeral(s, i, "Error checking guild")
+				return
+			}
+			if partner.ID == userID {
+				respondEphemeral(s, i, userMessage(service.ErrSelfPartner))
+				return
+			}
+			// The pair is only set once the other member accepts, it breaks up their current pair
+			ids := userID + "_" + partner.ID
+			s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+				Type: discordgo.InteractionResponseChannelMessageWithSource,
+				Data: &discordgo.InteractionResponseData{
+					Content: fmt.Sprintf("🤝 <@%s>, <@%s> wants to be your accountability partner! You'd get a DM when your partner sets, changes or fails a goal, and completed goals count once your partner verifies them.", partner.ID, userID),
+					Components: []discordgo.MessageComponent{discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+						discordgo.Button{Label: "Accept", Style: discordgo.SuccessButton, CustomID: partnerAcceptButtonPrefix + ids},
+						discordgo.Button{Label: "Decline", Style: discordgo.SecondaryButton, CustomID: partnerDeclineButtonPrefix + ids},
+					}}},
+					AllowedMentions: &discordgo.MessageAllowedMentions{Users: []string{partner.ID}},
+				},
+			})
+		case "remove":
+			former, err := service.RemovePartner(ctx, db, i.GuildID, userID)
+			if service.IsUserError(err) {
+				respondEphemeral(s, i, userMessage(err))
+				return
+			} else if err != nil {
+				log.Error("cannot remove partner", "err", err, "user", userID, "guild", i.GuildID)
+				respondEphemeral(s, i, "Error removing partner")
+				return
+			}
+			respondEphemeral(s, i, fmt.Sprintf("You and <@%s> are no longer accountability partners", former))
+		case "view":
+			handlePartnerView(db, s, i)
+		}
+	},
+}
+
+// handlePartnerView shows the member's partners and the goals they can verify, with a button per goal
+func handlePartnerView(db database.CheckpointDatabase, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	userID := i.Member.User.ID
+	partner, err := service.GuildPartner(ctx, db, i.GuildID, userID)
+	if err != nil {
+		log.Error("cannot get partner", "err", err, "user", userID, "guild", i.GuildID)
+		respondEphemeral(s, i, "Error loading your partner")
+		return
+	}
+
+	var sb strings.Builder
+	if partner != "" {
+		sb.WriteString(fmt.Sprintf("🤝 Your accountability partner is <@%s>\n", partner))
+	} else {
+		sb.WriteString("You don't have an accountability partner, pick one with `/partner set`\n")
+	}
+	// Partners assigned by rotation only apply to their checkpoint
+	checkpoint, err := service.UpcomingCheckpoint(ctx, db, i.GuildID, i.ChannelID)
+	if err == nil {
+		assigned, err := service.PartnerFor(ctx, db, *checkpoint, userID)
+		if err != nil {
+			log.Error("cannot get checkpoint partner", "err", err, "user", userID, "checkpoint_id", checkpoint.ID)
+		} else if assigned != partner && assigned != "" {
+			sb.WriteString(fmt.Sprintf("🔄 For checkpoint #%d your partner is <@%s>\n", checkpoint.ID, assigned))
+		}
+	} else if !service.IsUserError(err) {
+		log.Error("cannot get upcoming checkpoint", "err", err, "channel", i.ChannelID, "guild", i.GuildID)
+	}
+
+	goals, err := service.GoalsToVerify(ctx, db, i.GuildID, userID)
+	if err != nil {
+		log.Error("cannot get goals to verify", "err", err, "user", userID, "guild", i.GuildID)
+		respondEphemeral(s, i, "Error loading goals to verify")
+		return
+	}
+	if len(goals) == 0 {
+		sb.WriteString("\nNo goals are waiting for your verification")
+		respondEphemeral(s, i, sb.String())
+		return
+	}
+
+	// A message can have up to 5 buttons in a row, the oldest goals come first
+	sb.WriteString(fmt.Sprintf("\n**Waiting for your verification (%d)**\n", len(goals)))
+	if len(goals) > 5 {
+		goals = goals[:5]
+	}
+	buttons := make([]discordgo.MessageComponent, 0, len(goals))
+	for n, goal := range goals {
+		sb.WriteString(fmt.Sprintf("%d. <@%s> · checkpoint #%d: %s\n", n+1, goal.DiscordUser, goal.CheckpointID, sharedGoalText(goal.Description, goal.Visibility, 150)))
+		buttons = append(buttons, verifyButton(goal, fmt.Sprintf("Verify %d", n+1)))
+	}
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content:    sb.String(),
+			Components: []discordgo.MessageComponent{discordgo.ActionsRow{Components: buttons}},
+			Flags:      discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+// verifyButton confirms the goal's completion as the member's partner
+func verifyButton(goal queries.Goal, label string) discordgo.Button {
+	return discordgo.Button{
+		Label:    label,
+		Style:    discordgo.SuccessButton,
+		Emoji:    &discordgo.ComponentEmoji{Name: "✅"},
+		CustomID: partnerVerifyButtonPrefix + strconv.FormatInt(goal.ID, 10),
+	}
+}
+
+// handlePartnerVerifyButton verifies the goal of the clicked button, from /partner view or a DM.
+// With peer verification on it counts as one of the goal's confirmations.
+func handlePartnerVerifyButton(db database.CheckpointDatabase, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	data := i.MessageComponentData()
+	userID := interactionUserID(i)
+	goalID, err := strconv.ParseInt(strings.TrimPrefix(data.CustomID, partnerVerifyButtonPrefix), 10, 64)
+	if err != nil {
+		log.Error("cannot parse goal ID from verify button custom ID", "err", err, "custom_id", data.CustomID)
+		respondEphemeral(s, i, "Error verifying goal")
+		return
+	}
+
+	confirmation, err := service.ConfirmGoal(ctx, db, goalID, userID)
+	if service.IsUserError(err) {
+		respondEphemeral(s, i, userMessage(err))
+		return
+	} else if err != nil {
+		log.Error("cannot verify goal", "err", err, "goal_id", goalID, "user", userID)
+		respondEphemeral(s, i, "Error verifying goal")
+		return
+	}
+	goal := confirmation.Goal
+	if !confirmation.Verified() {
+		respondEphemeral(s, i, fmt.Sprintf("👍 Confirmed <@%s>'s goal for checkpoint #%d (%d/%d confirmations)", goal.DiscordUser, goal.CheckpointID, len(confirmation.ConfirmedBy), confirmation.Required))
+		return
+	}
+	respondEphemeral(s, i, fmt.Sprintf("✅ Verified <@%s>'s goal for checkpoint #%d, it now counts in their stats", goal.DiscordUser, goal.CheckpointID))
+}
+
+// handlePartnerRequestButton answers a /partner set request, only the requested member can accept or decline it
+func handlePartnerRequestButton(db database.CheckpointDatabase, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	data := i.MessageComponentData()
+	userID := interactionUserID(i)
+	accept := strings.HasPrefix(data.CustomID, partnerAcceptButtonPrefix)
+	ids := strings.TrimPrefix(strings.TrimPrefix(data.CustomID, partnerAcceptButtonPrefix), partnerDeclineButtonPrefix)
+	requesterID, partnerID, ok := strings.Cut(ids, "_")
+	if !ok {
+		log.Error("cannot parse member IDs from partner request custom ID", "custom_id", data.CustomID)
+		respondEphemeral(s, i, "Error answering partner request")
+		return
+	}
+	if userID != partnerID {
+		respondEphemeral(s, i, fmt.Sprintf("Only <@%s> can answer this request", partnerID))
+		return
+	}
+
+	content := fmt.Sprintf("<@%s> declined <@%s>'s accountability partner request", partnerID, requesterID)
+	if accept {
+		err := service.SetPartner(ctx, db, i.GuildID, requesterID, partnerID, userID)
+		if service.IsUserError(err) {
+			respondEphemeral(s, i, userMessage(err))
+			return
+		} else if err != nil {
+			log.Error("cannot set partner", "err", err, "user", requesterID, "partner", partnerID, "guild", i.GuildID)
+			respondEphemeral(s, i, "Error setting partner")
+			return
+		}
+		content = fmt.Sprintf("🤝 <@%s> and <@%s> are now accountability partners! You'll get a DM when your partner sets, changes or fails a goal, and completed goals count once your partner verifies them.", requesterID, partnerID)
+	}
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Content:    content,
+			Components: []discordgo.MessageComponent{},
+		},
+	})
+	if err != nil {
+		log.Error("cannot update partner request", "err", err, "user", requesterID, "partner", partnerID)
+	}
+}
+
+func init() {
+	registerCommand(PartnerCmd)
+	registerComponent(partnerVerifyButtonPrefix, handlePartnerVerifyButton)
+	registerComponent(partnerAcceptButtonPrefix, handlePartnerRequestButton)
+	registerComponent(partnerDeclineButtonPrefix, handlePartnerRequestButton)
+}
@@ -0,0 +1,144 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/charmbracelet/log"
+	"github.com/metruzanca/checkpoint-bot/internal/database"
+	"github.com/metruzanca/checkpoint-bot/internal/events"
+	"github.com/metruzanca/checkpoint-bot/internal/service"
+	"github.com/metruzanca/checkpoint-bot/internal/settings"
+)
+
+// Prompter DMs members who opted in with /update-prompts for an update on their goals,
+// and posts a recap with the goals and their updates when a checkpoint starts
+type Prompter struct {
+	db       database.CheckpointDatabase
+	discord  *discordgo.Session
+	interval time.Duration
+
+	queue chan events.Event
+	stop  chan struct{}
+	wg    sync.WaitGroup
+}
+
+func NewPrompter(db database.CheckpointDatabase, discord *discordgo.Session) *Prompter {
+	return &Prompter{
+		db:       db,
+		discord:  discord,
+		interval: time.Minute,
+		queue:    make(chan events.Event, 64),
+		stop:     make(chan struct{}),
+	}
+}
+
+// Start subscribes to checkpoint.started events and sends due prompts until Stop is called
+func (p *Prompter) Start() {
+	p.db.Events().Subscribe(p.handle)
+	p.wg.Add(1)
+	go p.loop()
+}
+
+func (p *Prompter) Stop() {
+	close(p.stop)
+	p.wg.Wait()
+}
+
+// handle runs on the publisher's goroutine, so it only hands the event over
+func (p *Prompter) handle(event events.Event) {
+	if event.Type != events.CheckpointStarted {
+		return
+	}
+	select {
+	case p.queue <- event:
+	default:
+		log.Warn("prompter queue full, dropping checkpoint recap", "event_id", event.ID, "guild_id", event.GuildID)
+	}
+}
+
+func (p *Prompter) loop() {
+	defer p.wg.Done()
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stop:
+			return
+		case now := <-ticker.C:
+			p.sendPrompts(now)
+		case event := <-p.queue:
+			if checkpoint, ok := event.Data.(events.CheckpointData); ok {
+				p.postRecap(checkpoint.ID)
+			}
+		}
+	}
+}
+
+// sendPrompts DMs every member whose prompt is due, a failed DM is skipped until the next prompt
+func (p *Prompter) sendPrompts(now time.Time) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	due, err := service.ClaimDueUpdatePrompts(ctx, p.db, now)
+	if err != nil {
+		log.Error("cannot claim due update prompts", "err", err)
+		return
+	}
+	for _, prompt := range due {
+		channel, err := p.discord.UserChannelCreate(prompt.Prompt.DiscordUser)
+		if err != nil {
+			log.Warn("cannot open DM for update prompt", "err", err, "user", prompt.Prompt.DiscordUser, "guild_id", prompt.Prompt.GuildID)
+			continue
+		}
+		if _, err := p.discord.ChannelMessageSendComplex(channel.ID, updatePromptMessage(prompt.Goals)); err != nil {
+			log.Warn("cannot send update prompt", "err", err, "user", prompt.Prompt.DiscordUser, "guild_id", prompt.Prompt.GuildID)
+			continue
+		}
+		log.Info("update prompt sent", "user", prompt.Prompt.DiscordUser, "guild_id", prompt.Prompt.GuildID, "goals", len(prompt.Goals))
+	}
+}
+
+// postRecap posts the checkpoint's goals and updates to the guild's announcement channel, or the checkpoint's channel
+func (p *Prompter) postRecap(checkpointID int64) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	checkpoint, err := p.db.GetCheckpoint(ctx, checkpointID)
+	if err != nil {
+		log.Error("cannot get checkpoint for recap", "err", err, "checkpoint_id", checkpointID)
+		return
+	}
+	goals, err := p.db.GetGoalsByCheckpoint(ctx, checkpoint.ID)
+	if err != nil {
+		log.Error("cannot get goals for recap", "err", err, "checkpoint_id", checkpoint.ID)
+		return
+	}
+	if len(goals) == 0 {
+		return
+	}
+
+	channelID := checkpoint.ChannelID
+	if guildSettings, err := settings.Load(ctx, p.db, checkpoint.GuildID); err != nil {
+		log.Error("cannot load guild settings", "err", err, "guild_id", checkpoint.GuildID)
+	} else if guildSettings.AnnouncementChannelID != "" {
+		channelID = guildSettings.AnnouncementChannelID
+	}
+
+	embed, err := checkpointRecapEmbed(ctx, p.db, *checkpoint)
+	if err != nil {
+		log.Error("cannot create checkpoint recap", "err", err, "checkpoint_id", checkpoint.ID)
+	}
+	_, err = p.discord.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{
+		Content: fmt.Sprintf("⏰ Checkpoint #%d is starting! Here's how everyone's goals went:", checkpoint.ID),
+		Embeds:  []*discordgo.MessageEmbed{embed},
+	})
+	if err != nil {
+		log.Error("cannot post checkpoint recap", "err", err, "checkpoint_id", checkpoint.ID, "channel", channelID)
+		return
+	}
+	log.Info("checkpoint recap posted", "checkpoint_id", checkpoint.ID, "channel", channelID)
+}
@@ -0,0 +1,117 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/charmbracelet/log"
+	"github.com/metruzanca/checkpoint-bot/internal/database"
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+	"github.com/metruzanca/checkpoint-bot/internal/settings"
+)
+
+// Reminder posts a reminder before each checkpoint at the guild's reminder_offsets.
+// Like the lifecycle watcher, reminders due while the bot was offline aren't sent.
+type Reminder struct {
+	db       database.CheckpointDatabase
+	discord  *discordgo.Session
+	interval time.Duration
+
+	last time.Time
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func NewReminder(db database.CheckpointDatabase, discord *discordgo.Session) *Reminder {
+	return &Reminder{
+		db:       db,
+		discord:  discord,
+		interval: 30 * time.Second,
+		stop:     make(chan struct{}),
+	}
+}
+
+// Start sends due reminders in the background until Stop is called
+func (r *Reminder) Start() {
+	r.check(time.Now())
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-r.stop:
+				return
+			case now := <-ticker.C:
+				r.check(now)
+			}
+		}
+	}()
+}
+
+func (r *Reminder) Stop() {
+	close(r.stop)
+	r.wg.Wait()
+}
+
+// check sends the reminders due between the previous check and now, the first check only sets the starting point
+func (r *Reminder) check(now time.Time) {
+	if r.last.IsZero() {
+		r.last = now
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// Every checkpoint that can still have a reminder due, the earliest is MaxReminderOffset before it
+	checkpoints, err := r.db.GetCheckpointsScheduledBetween(ctx, queries.GetCheckpointsScheduledBetweenParams{
+		From: r.last.UTC().Format(time.RFC3339),
+		To:   now.Add(settings.MaxReminderOffset).UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		log.Error("cannot get checkpoints for reminders", "err", err)
+		return
+	}
+
+	guildSettings := map[string]settings.Settings{}
+	for _, checkpoint := range checkpoints {
+		scheduledAt, err := time.Parse(time.RFC3339, checkpoint.ScheduledAt)
+		if err != nil {
+			log.Error("cannot parse checkpoint scheduled_at", "err", err, "checkpoint_id", checkpoint.ID)
+			continue
+		}
+		current, ok := guildSettings[checkpoint.GuildID]
+		if !ok {
+			if current, err = settings.Load(ctx, r.db, checkpoint.GuildID); err != nil {
+				log.Error("cannot load guild settings", "err", err, "guild_id", checkpoint.GuildID)
+				continue
+			}
+			guildSettings[checkpoint.GuildID] = current
+		}
+		for _, offset := range current.ReminderOffsets {
+			if remindAt := scheduledAt.Add(-offset); remindAt.After(r.last) && !remindAt.After(now) {
+				r.send(checkpoint, scheduledAt, current)
+			}
+		}
+	}
+	r.last = now
+}
+
+// send posts the reminder to the guild's announcement channel, or the checkpoint's channel
+func (r *Reminder) send(checkpoint queries.Checkpoint, scheduledAt time.Time, guildSettings settings.Settings) {
+	channelID := checkpoint.ChannelID
+	if guildSettings.AnnouncementChannelID != "" {
+		channelID = guildSettings.AnnouncementChannelID
+	}
+	content := fmt.Sprintf("🔔 Checkpoint #%d starts <t:%d:R>, set your goal with `/goal` if you haven't yet!", checkpoint.ID, scheduledAt.Unix())
+	if _, err := r.discord.ChannelMessageSend(channelID, content); err != nil {
+		log.Error("cannot send checkpoint reminder", "err", err, "checkpoint_id", checkpoint.ID, "channel", channelID)
+		return
+	}
+	log.Info("checkpoint reminder sent", "checkpoint_id", checkpoint.ID, "channel", channelID)
+}
@@ -0,0 +1,202 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/charmbracelet/log"
+	"github.com/metruzanca/checkpoint-bot/internal/audit"
+	"github.com/metruzanca/checkpoint-bot/internal/database"
+	"github.com/metruzanca/checkpoint-bot/internal/permissions"
+	"github.com/metruzanca/checkpoint-bot/internal/settings"
+)
+
+// SettingsCmd shows and changes the server's settings (admin only)
+var SettingsCmd = &Command{
+	ApplicationCommand: discordgo.ApplicationCommand{
+		Name:        "settings",
+		Description: "View or change this server's settings (admin only)",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "view",
+				Description: "Show every setting and its value",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "set",
+				Description: "Change a setting",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "key",
+						Description: "The setting to change",
+						Required:    true,
+						Choices:     settingChoices(),
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "value",
+						Description: "The new value, see /settings view for the expected format",
+						Required:    true,
+						MaxLength:   200,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "reset",
+				Description: "Restore a setting's default value",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "key",
+						Description: "The setting to reset",
+						Required:    true,
+						Choices:     settingChoices(),
+					},
+				},
+			},
+		},
+	},
+	Handler: func(db database.CheckpointDatabase, s *discordgo.Session, i *discordgo.InteractionCreate) {
+		ctx, cancel := dbContext()
+		defer cancel()
+		if !authorize(ctx, db, s, i, permissions.ManageGuild, permissions.Target{}) {
+			return
+		}
+
+		sub := i.ApplicationCommandData().Options[0]
+		switch sub.Name {
+		case "view":
+			viewSettings(db, s, i)
+		case "set":
+			var key, value string
+			for _, opt := range sub.Options {
+				switch opt.Name {
+				case "key":
+					key = opt.StringValue()
+				case "value":
+					value = opt.StringValue()
+				}
+			}
+			setSetting(db, s, i, key, value)
+		case "reset":
+			resetSetting(db, s, i, sub.Options[0].StringValue())
+		}
+	},
+}
+
+// settingChoices lists every setting key as a command choice
+func settingChoices() []*discordgo.ApplicationCommandOptionChoice {
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, 0, len(settings.Definitions))
+	for _, def := range settings.Definitions {
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: def.Key, Value: def.Key})
+	}
+	return choices
+}
+
+func viewSettings(db database.CheckpointDatabase, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	values, err := settings.Values(ctx, db, i.GuildID)
+	if err != nil {
+		log.Error("cannot get guild settings", "err", err, "guild", i.GuildID)
+		respondEphemeral(s, i, "Error loading settings")
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString("**Settings**\n")
+	for _, def := range settings.Definitions {
+		value := settingLabel(def.Key, values[def.Key])
+		if values[def.Key] == def.Default {
+			value += " (default)"
+		}
+		sb.WriteString(fmt.Sprintf("`%s`: %s\n-# %s\n", def.Key, value, def.Description))
+	}
+	respondEphemeral(s, i, sb.String())
+}
+
+func setSetting(db database.CheckpointDatabase, s *discordgo.Session, i *discordgo.InteractionCreate, key string, value string) {
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	// Settings reference the guild, which only exists once the bot has been used in it
+	if _, err := ensureGuild(ctx, db, s, i.GuildID); err != nil {
+		log.Error("cannot ensure guild", "err", err, "guild", i.GuildID)
+		respondEphemeral(s, i, "Error checking guild")
+		return
+	}
+
+	change, err := settings.Set(ctx, db, i.GuildID, key, value, i.Member.User.ID)
+	var validationErr *settings.ValidationError
+	if errors.As(err, &validationErr) || errors.Is(err, settings.ErrUnknownKey) {
+		respondEphemeral(s, i, userMessage(err))
+		return
+	} else if err != nil {
+		log.Error("cannot set guild setting", "err", err, "guild", i.GuildID, "key", key)
+		respondEphemeral(s, i, "Error saving setting")
+		return
+	}
+
+	audit.Record(ctx, db, audit.Entry{
+		GuildID:    i.GuildID,
+		ActorID:    i.Member.User.ID,
+		Action:     audit.SettingChanged,
+		TargetType: audit.TargetSetting,
+		TargetID:   key,
+		Before:     change.Before,
+		After:      change.After,
+	})
+	respondEphemeral(s, i, fmt.Sprintf("`%s` set to %s", key, settingLabel(key, change.After)))
+}
+
+func resetSetting(db database.CheckpointDatabase, s *discordgo.Session, i *discordgo.InteractionCreate, key string) {
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	change, err := settings.Reset(ctx, db, i.GuildID, key)
+	if errors.Is(err, settings.ErrUnknownKey) {
+		respondEphemeral(s, i, userMessage(err))
+		return
+	} else if err != nil {
+		log.Error("cannot reset guild setting", "err", err, "guild", i.GuildID, "key", key)
+		respondEphemeral(s, i, "Error resetting setting")
+		return
+	}
+
+	audit.Record(ctx, db, audit.Entry{
+		GuildID:    i.GuildID,
+		ActorID:    i.Member.User.ID,
+		Action:     audit.SettingReset,
+		TargetType: audit.TargetSetting,
+		TargetID:   key,
+		Before:     change.Before,
+		After:      change.After,
+	})
+	respondEphemeral(s, i, fmt.Sprintf("`%s` reset to %s", key, settingLabel(key, change.After)))
+}
+
+// settingLabel formats a stored setting value for Discord, mentioning roles and channels
+func settingLabel(key string, value string) string {
+	if value == "" {
+		return "not set"
+	}
+	switch key {
+	case settings.ManagerRole, settings.GoalModeratorRole:
+		return "<@&" + value + ">"
+	case settings.AnnouncementChannel, settings.ModLogChannel:
+		return "<#" + value + ">"
+	case settings.CheckpointChannels:
+		return "<#" + strings.ReplaceAll(value, ",", ">, <#") + ">"
+	}
+	return "`" + value + "`"
+}
+
+func init() {
+	registerCommand(SettingsCmd)
+}
@@ -0,0 +1,161 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/charmbracelet/log"
+	"github.com/metruzanca/checkpoint-bot/internal/database"
+	"github.com/metruzanca/checkpoint-bot/internal/settings"
+	"github.com/metruzanca/checkpoint-bot/internal/stats"
+)
+
+// maxTagStats limits how many free tags /stats lists after the categories
+const maxTagStats = 10
+
+// StatsCmd shows a member's goal statistics, overall or for a tag, with their completion rate per category
+var StatsCmd = &Command{
+	ApplicationCommand: discordgo.ApplicationCommand{
+		Name:        "stats",
+		Description: "Show goal statistics and completion rates by category",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionUser,
+				Name:        "user",
+				Description: "Whose statistics to show (default: yours)",
+				Required:    false,
+			},
+			{
+				Type:         discordgo.ApplicationCommandOptionString,
+				Name:         "tag",
+				Description:  "Only count goals with this tag or category",
+				Required:     false,
+				Autocomplete: true,
+			},
+		},
+	},
+	Handler: func(db database.CheckpointDatabase, s *discordgo.Session, i *discordgo.InteractionCreate) {
+		ctx, cancel := dbContext()
+		defer cancel()
+
+		userID := i.Member.User.ID
+		var tag string
+		for _, opt := range i.ApplicationCommandData().Options {
+			switch opt.Name {
+			case "user":
+				userID = opt.UserValue(s).ID
+			case "tag":
+				tag = tagOption(opt.StringValue())
+				if tag == "" {
+					respondEphemeral(s, i, fmt.Sprintf("%q is not a valid tag", opt.StringValue()))
+					return
+				}
+			}
+		}
+
+		var userStats *stats.UserStats
+		var err error
+		if tag != "" {
+			userStats, err = stats.ForUserTag(ctx, db, i.GuildID, userID, tag)
+		} else {
+			userStats, err = stats.ForUser(ctx, db, i.GuildID, userID)
+		}
+		if err != nil {
+			log.Error("cannot compute user stats", "err", err, "user", userID, "tag", tag, "guild", i.GuildID)
+			respondEphemeral(s, i, "Error computing stats")
+			return
+		}
+
+		embed := &discordgo.MessageEmbed{
+			Title:       "📊 Goal stats",
+			Color:       0x0099ff,
+			Description: fmt.Sprintf("<@%s>", userID),
+			Fields: []*discordgo.MessageEmbedField{
+				{Name: "Goals", Value: fmt.Sprint(userStats.Goals), Inline: true},
+				{Name: "Completed", Value: fmt.Sprint(userStats.Completed), Inline: true},
+				{Name: "Completion rate", Value: formatRate(userStats.CompletionRate), Inline: true},
+				{Name: "Partial", Value: fmt.Sprint(userStats.Partial), Inline: true},
+				{Name: "Failed", Value: fmt.Sprint(userStats.Failed), Inline: true},
+				{Name: "Checkpoints attended", Value: fmt.Sprint(userStats.Attended), Inline: true},
+			},
+		}
+		if tag != "" {
+			embed.Title += " · #" + tag
+		}
+		if userStats.Unverified > 0 {
+			embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Awaiting verification", Value: fmt.Sprint(userStats.Unverified), Inline: true})
+		}
+
+		// Rates by tag only make sense for all of the member's goals
+		if tag == "" {
+			fields, err := tagStatsFields(ctx, db, i.GuildID, userID)
+			if err != nil {
+				log.Error("cannot compute tag stats", "err", err, "user", userID, "guild", i.GuildID)
+			}
+			embed.Fields = append(embed.Fields, fields...)
+		}
+
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Embeds: []*discordgo.MessageEmbed{embed},
+			},
+		})
+	},
+	Autocomplete: autocompleteTag,
+}
+
+// tagStatsFields lists the completion rate of each of the guild's categories, then the member's most used other tags
+func tagStatsFields(ctx context.Context, db database.CheckpointDatabase, guildID string, userID string) ([]*discordgo.MessageEmbedField, error) {
+	guildSettings, err := settings.Load(ctx, db, guildID)
+	if err != nil {
+		return nil, err
+	}
+	byTag, err := stats.ByTag(ctx, db, guildID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var categories, others strings.Builder
+	listed := 0
+	for _, category := range guildSettings.GoalCategories {
+		index := slices.IndexFunc(byTag, func(t stats.TagStats) bool { return t.Tag == category })
+		if index < 0 {
+			categories.WriteString(fmt.Sprintf("`#%s` no goals yet\n", category))
+			continue
+		}
+		categories.WriteString(tagStatsLine(byTag[index]))
+	}
+	for _, tagStats := range byTag {
+		if slices.Contains(guildSettings.GoalCategories, tagStats.Tag) || listed == maxTagStats {
+			continue
+		}
+		others.WriteString(tagStatsLine(tagStats))
+		listed++
+	}
+
+	var fields []*discordgo.MessageEmbedField
+	if categories.Len() > 0 {
+		fields = append(fields, &discordgo.MessageEmbedField{Name: "By category", Value: categories.String()})
+	}
+	if others.Len() > 0 {
+		fields = append(fields, &discordgo.MessageEmbedField{Name: "By tag", Value: others.String()})
+	}
+	return fields, nil
+}
+
+func tagStatsLine(tagStats stats.TagStats) string {
+	return fmt.Sprintf("`#%s` %s · %d/%d completed\n", tagStats.Tag, formatRate(tagStats.CompletionRate), tagStats.Completed, tagStats.Goals)
+}
+
+// formatRate formats a completion rate between 0 and 1 as a percentage
+func formatRate(rate float64) string {
+	return fmt.Sprintf("%.0f%%", rate*100)
+}
+
+func init() {
+	registerCommand(StatsCmd)
+}
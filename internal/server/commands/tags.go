@@ -0,0 +1,149 @@
+package commands
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/charmbracelet/log"
+	"github.com/metruzanca/checkpoint-bot/internal/database"
+	"github.com/metruzanca/checkpoint-bot/internal/service"
+	"github.com/metruzanca/checkpoint-bot/internal/settings"
+	"github.com/metruzanca/checkpoint-bot/internal/stats"
+)
+
+// categorySelectPrefix is followed by the goal ID in the menu picking a goal's categories
+const categorySelectPrefix = "goal_categories_"
+
+// categorySelect is a menu of the guild's goal categories, those in tags are selected
+func categorySelect(goalID int64, categories []string, tags []string) []discordgo.MessageComponent {
+	options := make([]discordgo.SelectMenuOption, len(categories))
+	for n, category := range categories {
+		options[n] = discordgo.SelectMenuOption{
+			Label:   category,
+			Value:   category,
+			Default: slices.Contains(tags, category),
+		}
+	}
+	minValues := 0
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.SelectMenu{
+				MenuType:    discordgo.StringSelectMenu,
+				CustomID:    categorySelectPrefix + strconv.FormatInt(goalID, 10),
+				Placeholder: "Goal categories",
+				MinValues:   &minValues,
+				MaxValues:   min(len(categories), service.MaxGoalTags),
+				Options:     options,
+			},
+		}},
+	}
+}
+
+// handleCategorySelect sets the goal's categories picked in the menu shown after saving a goal
+func handleCategorySelect(db database.CheckpointDatabase, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	data := i.MessageComponentData()
+	goalID, err := strconv.ParseInt(strings.TrimPrefix(data.CustomID, categorySelectPrefix), 10, 64)
+	if err != nil {
+		log.Error("cannot parse goal ID from category menu custom ID", "err", err, "custom_id", data.CustomID)
+		respondEphemeral(s, i, "Error setting categories")
+		return
+	}
+	actor, err := goalActor(ctx, db, i)
+	if err != nil {
+		log.Error("cannot check goal permissions", "err", err, "user", interactionUserID(i), "guild", i.GuildID)
+		respondEphemeral(s, i, "Error checking permissions")
+		return
+	}
+
+	tags, err := service.SetGoalCategories(ctx, db, goalID, actor.ActorID, actor.Moderator, data.Values)
+	if service.IsUserError(err) {
+		respondEphemeral(s, i, userMessage(err))
+		return
+	} else if err != nil {
+		log.Error("cannot set goal categories", "err", err, "goal_id", goalID, "user", actor.ActorID)
+		respondEphemeral(s, i, "Error setting categories")
+		return
+	}
+
+	content := "Goal saved without categories."
+	if len(data.Values) > 0 {
+		content = "Goal saved in " + tagList(data.Values) + "."
+	}
+	if len(tags) > len(data.Values) {
+		content += " Its tags are " + tagList(tags) + "."
+	}
+	s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Content:    content,
+			Components: []discordgo.MessageComponent{},
+		},
+	})
+}
+
+// tagList formats tags as hashtags, e.g. `#fitness` `#running`
+func tagList(tags []string) string {
+	formatted := make([]string, len(tags))
+	for n, tag := range tags {
+		formatted[n] = "`#" + tag + "`"
+	}
+	return strings.Join(formatted, " ")
+}
+
+// autocompleteTag suggests the guild's goal categories and the member's own tags for a tag option
+func autocompleteTag(db database.CheckpointDatabase, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	var typed string
+	for _, opt := range i.ApplicationCommandData().Options {
+		if opt.Name == "tag" && opt.Focused {
+			typed = strings.ToLower(strings.TrimPrefix(opt.StringValue(), "#"))
+		}
+	}
+
+	var tags []string
+	guildSettings, err := settings.Load(ctx, db, i.GuildID)
+	if err != nil {
+		log.Error("cannot load guild settings", "err", err, "guild", i.GuildID)
+	}
+	tags = append(tags, guildSettings.GoalCategories...)
+	byTag, err := stats.ByTag(ctx, db, i.GuildID, i.Member.User.ID)
+	if err != nil {
+		log.Error("cannot get tag stats", "err", err, "user", i.Member.User.ID, "guild", i.GuildID)
+	}
+	for _, tagStats := range byTag {
+		if !slices.Contains(tags, tagStats.Tag) {
+			tags = append(tags, tagStats.Tag)
+		}
+	}
+
+	choices := []*discordgo.ApplicationCommandOptionChoice{}
+	for _, tag := range tags {
+		if len(choices) < 25 && strings.Contains(tag, typed) {
+			choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: "#" + tag, Value: tag})
+		}
+	}
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{Choices: choices},
+	})
+	if err != nil {
+		log.Error("cannot respond to autocomplete", "err", err, "channel", i.ChannelID, "guild", i.GuildID)
+	}
+}
+
+// tagOption reads a tag option, "" when it's not a valid tag
+func tagOption(value string) string {
+	tag, _ := settings.NormalizeTag(value)
+	return tag
+}
+
+func init() {
+	registerComponent(categorySelectPrefix, handleCategorySelect)
+}
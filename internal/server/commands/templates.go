@@ -0,0 +1,488 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/charmbracelet/log"
+	"github.com/metruzanca/checkpoint-bot/internal/audit"
+	"github.com/metruzanca/checkpoint-bot/internal/database"
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+	"github.com/metruzanca/checkpoint-bot/internal/service"
+)
+
+const (
+	// templateSelectPrefix is followed by {checkpoint_id|next}_{user_id} in the template menu shown by /goal
+	templateSelectPrefix = "goal_template_"
+	// blankTemplateValue is the template menu's option for a goal without a template
+	blankTemplateValue = "blank"
+)
+
+// TemplateCmd manages reusable goals, personal or shared with the whole server
+var TemplateCmd = &Command{
+	ApplicationCommand: discordgo.ApplicationCommand{
+		Name:        "template",
+		Description: "Save and reuse goals you set often",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "save",
+				Description: "Save a goal as a template, by default your goal in this channel",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "name",
+						Description: "Template name, saving over an existing name replaces it",
+						Required:    true,
+						MaxLength:   service.TemplateNameMaxLength,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "goal",
+						Description: "Goal text (default: your goal for this channel's upcoming or latest checkpoint)",
+						Required:    false,
+						MaxLength:   DiscordTextInputMaxLength,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "target",
+						Description: "Target to track with /log, e.g. 20 km",
+						Required:    false,
+						MaxLength:   targetMaxLength,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionBoolean,
+						Name:        "server",
+						Description: "Share the template with the whole server (goal moderators only)",
+						Required:    false,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "list",
+				Description: "List your templates and the server's, the most used first",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "use",
+				Description: "Set your goal for this channel's upcoming checkpoint from a template",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:         discordgo.ApplicationCommandOptionString,
+						Name:         "name",
+						Description:  "Template to use",
+						Required:     true,
+						Autocomplete: true,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "delete",
+				Description: "Delete a template",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:         discordgo.ApplicationCommandOptionString,
+						Name:         "name",
+						Description:  "Template to delete",
+						Required:     true,
+						Autocomplete: true,
+					},
+				},
+			},
+		},
+	},
+	Handler: func(db database.CheckpointDatabase, s *discordgo.Session, i *discordgo.InteractionCreate) {
+		sub := i.ApplicationCommandData().Options[0]
+		switch sub.Name {
+		case "save":
+			handleTemplateSave(db, s, i, sub)
+		case "list":
+			handleTemplateList(db, s, i)
+		case "use":
+			handleTemplateUse(db, s, i, sub.Options[0].StringValue())
+		case "delete":
+			handleTemplateDelete(db, s, i, sub.Options[0].StringValue())
+		}
+	},
+	Autocomplete: autocompleteTemplate,
+}
+
+// handleTemplateSave saves the given goal, or the member's goal in the channel, as a template
+func handleTemplateSave(db database.CheckpointDatabase, s *discordgo.Session, i *discordgo.InteractionCreate, sub *discordgo.ApplicationCommandInteractionDataOption) {
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	actor, err := goalActor(ctx, db, i)
+	if err != nil {
+		log.Error("cannot check goal permissions", "err", err, "user", i.Member.User.ID, "guild", i.GuildID)
+		respondEphemeral(s, i, "Error checking permissions")
+		return
+	}
+	template := service.NewTemplate{GuildID: i.GuildID, ActorID: actor.ActorID, Moderator: actor.Moderator}
+	for _, opt := range sub.Options {
+		switch opt.Name {
+		case "name":
+			template.Name = opt.StringValue()
+		case "goal":
+			template.Description = opt.StringValue()
+		case "target":
+			template.Target = opt.StringValue()
+		case "server":
+			template.Guild = opt.BoolValue()
+		}
+	}
+
+	if strings.TrimSpace(template.Description) == "" {
+		checkpoint, err := latestChannelCheckpoint(db, i)
+		if err != nil {
+			log.Error("cannot get channel checkpoint", "err", err, "channel", i.ChannelID, "guild", i.GuildID)
+			respondEphemeral(s, i, "Error getting checkpoint")
+			return
+		}
+		var goal *queries.Goal
+		if checkpoint != nil {
+			goal, err = db.GetGoalByCheckpointAndUser(ctx, queries.GetGoalByCheckpointAndUserParams{CheckpointID: checkpoint.ID, DiscordUser: actor.ActorID})
+			if err != nil && err != sql.ErrNoRows {
+				log.Error("cannot get goal", "err", err, "checkpoint_id", checkpoint.ID, "user", actor.ActorID)
+				respondEphemeral(s, i, "Error getting your goal")
+				return
+			}
+		}
+		if goal == nil {
+			respondEphemeral(s, i, "You have no goal in this channel to save, give the goal text instead")
+			return
+		}
+		template.Description = goal.Description
+		if template.Target == "" {
+			template.Target = targetInput(goal.Target, goal.Unit)
+		}
+	}
+
+	// Templates reference the guild, which only exists once the bot has been used in it
+	if _, err := ensureGuild(ctx, db, s, i.GuildID); err != nil {
+		log.Error("cannot ensure guild", "err", err, "guild", i.GuildID)
+		respondEphemeral(s, i, "Error checking guild")
+		return
+	}
+	saved, err := service.SaveTemplate(ctx, db, template)
+	if service.IsUserError(err) {
+		respondEphemeral(s, i, userMessage(err))
+		return
+	} else if err != nil {
+		log.Error("cannot save goal template", "err", err, "user", actor.ActorID, "guild", i.GuildID)
+		respondEphemeral(s, i, "Error saving template")
+		return
+	}
+
+	scope := "your templates"
+	if saved.DiscordUser == "" {
+		scope = "the server's templates"
+	}
+	respondEphemeral(s, i, fmt.Sprintf("📋 Saved **%s** to %s:\n%s\nPick it when setting a goal with `/goal`, or use it with `/template use`.", saved.Name, scope, templateSummary(*saved)))
+}
+
+// handleTemplateList lists the member's templates and the server's
+func handleTemplateList(db database.CheckpointDatabase, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	userID := i.Member.User.ID
+	templates, err := service.Templates(ctx, db, i.GuildID, userID)
+	if err != nil {
+		log.Error("cannot get goal templates", "err", err, "user", userID, "guild", i.GuildID)
+		respondEphemeral(s, i, "Error loading templates")
+		return
+	}
+	if len(templates) == 0 {
+		respondEphemeral(s, i, "There are no templates yet, save one with `/template save`")
+		return
+	}
+
+	var personal, shared strings.Builder
+	for n, template := range templates {
+		line := fmt.Sprintf("- **%s**: %s\n", template.Name, templateSummary(template))
+		// Templates are sorted by uses, so the first one is the most used
+		if n == 0 && template.Uses > 0 {
+			line = fmt.Sprintf("- ⭐ **%s**: %s\n", template.Name, templateSummary(template))
+		}
+		if template.DiscordUser == "" {
+			shared.WriteString(line)
+		} else {
+			personal.WriteString(line)
+		}
+	}
+	var sb strings.Builder
+	if personal.Len() > 0 {
+		sb.WriteString("**Your templates**\n" + personal.String())
+	}
+	if shared.Len() > 0 {
+		sb.WriteString("**Server templates**\n" + shared.String())
+	}
+	sb.WriteString("-# Pick one when setting a goal with `/goal`, or use it with `/template use`")
+	respondEphemeral(s, i, sb.String())
+}
+
+// handleTemplateUse opens the goal editor for the channel's upcoming checkpoint, pre-filled from the template
+func handleTemplateUse(db database.CheckpointDatabase, s *discordgo.Session, i *discordgo.InteractionCreate, value string) {
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	userID := i.Member.User.ID
+	template, err := service.FindTemplate(ctx, db, i.GuildID, userID, value)
+	if service.IsUserError(err) {
+		respondEphemeral(s, i, userMessage(err))
+		return
+	} else if err != nil {
+		log.Error("cannot find goal template", "err", err, "template", value, "user", userID, "guild", i.GuildID)
+		respondEphemeral(s, i, "Error loading template")
+		return
+	}
+	checkpoint, err := goalCheckpoint(ctx, db, i, "")
+	if err != nil {
+		log.Error("cannot get goal checkpoint", "err", err, "channel", i.ChannelID, "guild", i.GuildID)
+		respondEphemeral(s, i, "Error getting upcoming checkpoint")
+		return
+	}
+	openTemplateGoalModal(ctx, db, s, i, checkpoint, userID, template)
+}
+
+// handleTemplateDelete deletes one of the member's templates, or a server template for goal moderators
+func handleTemplateDelete(db database.CheckpointDatabase, s *discordgo.Session, i *discordgo.InteractionCreate, value string) {
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	actor, err := goalActor(ctx, db, i)
+	if err != nil {
+		log.Error("cannot check goal permissions", "err", err, "user", i.Member.User.ID, "guild", i.GuildID)
+		respondEphemeral(s, i, "Error checking permissions")
+		return
+	}
+	deleted, err := service.DeleteTemplate(ctx, db, i.GuildID, actor.ActorID, value, actor.Moderator)
+	if service.IsUserError(err) {
+		respondEphemeral(s, i, userMessage(err))
+		return
+	} else if err != nil {
+		log.Error("cannot delete goal template", "err", err, "template", value, "user", actor.ActorID, "guild", i.GuildID)
+		respondEphemeral(s, i, "Error deleting template")
+		return
+	}
+	respondEphemeral(s, i, fmt.Sprintf("🗑️ Deleted the template **%s**", deleted.Name))
+}
+
+// autocompleteTemplate suggests the member's templates and the server's, the most used first
+func autocompleteTemplate(db database.CheckpointDatabase, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	var typed string
+	for _, opt := range i.ApplicationCommandData().Options[0].Options {
+		if opt.Name == "name" && opt.Focused {
+			typed = strings.ToLower(opt.StringValue())
+		}
+	}
+
+	userID := i.Member.User.ID
+	templates, err := service.Templates(ctx, db, i.GuildID, userID)
+	if err != nil {
+		log.Error("cannot get goal templates", "err", err, "user", userID, "guild", i.GuildID)
+	}
+	choices := []*discordgo.ApplicationCommandOptionChoice{}
+	for _, template := range templates {
+		if len(choices) == 25 || !strings.Contains(strings.ToLower(template.Name), typed) {
+			continue
+		}
+		name := template.Name
+		if template.DiscordUser == "" {
+			name += " (server)"
+		}
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: name, Value: strconv.FormatInt(template.ID, 10)})
+	}
+
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{Choices: choices},
+	})
+	if err != nil {
+		log.Error("cannot respond to autocomplete", "err", err, "channel", i.ChannelID, "guild", i.GuildID)
+	}
+}
+
+// respondTemplateSelect offers the member's templates before opening the goal editor, checkpointValue is a
+// checkpoint ID or nextCheckpointValue. It returns false without responding when the member has no templates.
+func respondTemplateSelect(ctx context.Context, db database.CheckpointDatabase, s *discordgo.Session, i *discordgo.InteractionCreate, checkpointValue string, targetUserID string) bool {
+	templates, err := service.Templates(ctx, db, i.GuildID, targetUserID)
+	if err != nil {
+		// The editor still works without templates
+		log.Error("cannot get goal templates", "err", err, "user", targetUserID, "guild", i.GuildID)
+		return false
+	}
+	if len(templates) == 0 {
+		return false
+	}
+
+	// A select menu has up to 25 options, including the blank goal
+	if len(templates) > 24 {
+		templates = templates[:24]
+	}
+	options := []discordgo.SelectMenuOption{{
+		Label:       "Blank goal",
+		Value:       blankTemplateValue,
+		Description: "Write a new goal",
+		Emoji:       &discordgo.ComponentEmoji{Name: "📝"},
+	}}
+	for n, template := range templates {
+		option := discordgo.SelectMenuOption{
+			Label:       template.Name,
+			Value:       strconv.FormatInt(template.ID, 10),
+			Description: audit.Truncate(templateUses(template)+" · "+strings.Join(strings.Fields(template.Description), " "), 100),
+			Emoji:       &discordgo.ComponentEmoji{Name: "📋"},
+		}
+		if n == 0 && template.Uses > 0 {
+			option.Emoji = &discordgo.ComponentEmoji{Name: "⭐"}
+		}
+		options = append(options, option)
+	}
+
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: "Start from a template? The most used ones come first.",
+			Components: []discordgo.MessageComponent{
+				discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+					discordgo.SelectMenu{
+						MenuType:    discordgo.StringSelectMenu,
+						CustomID:    fmt.Sprintf("%s%s_%s", templateSelectPrefix, checkpointValue, targetUserID),
+						Placeholder: "Pick a template",
+						Options:     options,
+					},
+				}},
+			},
+			Flags: discordgo.MessageFlagsEphemeral,
+		},
+	})
+	if err != nil {
+		log.Error("cannot respond with template menu", "err", err, "channel", i.ChannelID, "guild", i.GuildID)
+		return false
+	}
+	return true
+}
+
+// handleTemplateSelect opens the goal editor pre-filled from the template picked in /goal's template menu
+func handleTemplateSelect(db database.CheckpointDatabase, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	// Parse custom ID: goal_template_{checkpoint_id|next}_{user_id}
+	data := i.MessageComponentData()
+	parts := strings.Split(strings.TrimPrefix(data.CustomID, templateSelectPrefix), "_")
+	if len(parts) != 2 || len(data.Values) != 1 {
+		log.Error("invalid template menu custom ID format", "custom_id", data.CustomID)
+		respondEphemeral(s, i, "Error opening goal editor")
+		return
+	}
+	targetUserID := parts[1]
+
+	var checkpoint *queries.Checkpoint
+	if parts[0] != nextCheckpointValue {
+		checkpointID, err := strconv.ParseInt(parts[0], 10, 64)
+		if err != nil {
+			log.Error("cannot parse checkpoint ID from template menu custom ID", "err", err, "custom_id", data.CustomID)
+			respondEphemeral(s, i, "Error opening goal editor")
+			return
+		}
+		checkpoint, err = db.GetCheckpoint(ctx, checkpointID)
+		if err != nil {
+			log.Error("cannot get checkpoint", "err", err, "checkpoint_id", checkpointID)
+			respondEphemeral(s, i, "Error getting checkpoint")
+			return
+		}
+	}
+
+	var template *queries.GoalTemplate
+	if data.Values[0] != blankTemplateValue {
+		found, err := service.FindTemplate(ctx, db, i.GuildID, targetUserID, data.Values[0])
+		if service.IsUserError(err) {
+			respondEphemeral(s, i, userMessage(err))
+			return
+		} else if err != nil {
+			log.Error("cannot find goal template", "err", err, "template", data.Values[0], "user", targetUserID, "guild", i.GuildID)
+			respondEphemeral(s, i, "Error loading template")
+			return
+		}
+		template = found
+	}
+	openTemplateGoalModal(ctx, db, s, i, checkpoint, targetUserID, template)
+}
+
+// openTemplateGoalModal opens the goal editor pre-filled from the template, or empty without one,
+// for the checkpoint or the next checkpoint scheduled in the channel when it's nil
+func openTemplateGoalModal(ctx context.Context, db database.CheckpointDatabase, s *discordgo.Session, i *discordgo.InteractionCreate, checkpoint *queries.Checkpoint, targetUserID string, template *queries.GoalTemplate) {
+	customID := goalModalID(nextCheckpointValue, targetUserID, "")
+	title := "Set Goals for the Next Checkpoint"
+	if checkpoint != nil {
+		actor, err := goalActor(ctx, db, i)
+		if err != nil {
+			log.Error("cannot check goal permissions", "err", err, "user", interactionUserID(i), "guild", i.GuildID)
+			respondEphemeral(s, i, "Error checking permissions")
+			return
+		}
+		if err := service.CheckGoalLock(ctx, db, *checkpoint, actor.OverrideLock); err == service.ErrGoalsLocked {
+			respondEphemeral(s, i, userMessage(err))
+			return
+		} else if err != nil {
+			log.Error("cannot check goal lock", "err", err, "checkpoint_id", checkpoint.ID)
+			respondEphemeral(s, i, "Error checking goal lock")
+			return
+		}
+		customID = goalModalID(strconv.FormatInt(checkpoint.ID, 10), targetUserID, "")
+		title = "Set Goals"
+	}
+
+	var goalText, targetText string
+	if template != nil {
+		goalText = template.Description
+		targetText = targetInput(template.Target, template.Unit)
+	}
+	if err := respondGoalModal(s, i, customID, title, goalText, targetText, ""); err != nil {
+		log.Error("cannot respond with modal", "err", err, "channel", i.ChannelID, "guild", i.GuildID)
+		respondEphemeral(s, i, "Error opening goal editor")
+		return
+	}
+	if template == nil {
+		return
+	}
+	// Templates are suggested by how often they're picked
+	if err := service.UseTemplate(ctx, db, *template); err != nil {
+		log.Error("cannot count goal template use", "err", err, "template_id", template.ID)
+	}
+}
+
+// templateSummary describes a template's goal, target and uses on one line
+func templateSummary(template queries.GoalTemplate) string {
+	summary := audit.Truncate(strings.Join(strings.Fields(template.Description), " "), 150)
+	if target := targetInput(template.Target, template.Unit); target != "" {
+		summary += fmt.Sprintf(" (🎯 %s)", target)
+	}
+	return summary + " · " + templateUses(template)
+}
+
+func templateUses(template queries.GoalTemplate) string {
+	switch template.Uses {
+	case 0:
+		return "not used yet"
+	case 1:
+		return "used once"
+	}
+	return fmt.Sprintf("used %d times", template.Uses)
+}
+
+func init() {
+	registerCommand(TemplateCmd)
+	registerComponent(templateSelectPrefix, handleTemplateSelect)
+}
@@ -0,0 +1,303 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/charmbracelet/log"
+	"github.com/metruzanca/checkpoint-bot/internal/audit"
+	"github.com/metruzanca/checkpoint-bot/internal/database"
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+	"github.com/metruzanca/checkpoint-bot/internal/service"
+	"github.com/metruzanca/checkpoint-bot/internal/settings"
+)
+
+const (
+	// goalUpdateButtonPrefix is followed by the goal ID in the buttons of update prompts
+	goalUpdateButtonPrefix = "goal_update_"
+	// goalUpdateModalPrefix is followed by the goal ID in the update modal's custom ID
+	goalUpdateModalPrefix = "goal_update_modal_"
+	// updateEntryMaxLength keeps a single update from filling the timeline
+	updateEntryMaxLength = 200
+)
+
+// UpdatePromptsCmd lets members opt in to a DM asking for an update on their goals between checkpoints
+var UpdatePromptsCmd = &Command{
+	ApplicationCommand: discordgo.ApplicationCommand{
+		Name:        "update-prompts",
+		Description: "Get a DM asking how your goals are going between checkpoints",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "on",
+				Description: "Ask me for an update every day, or every few days",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "every",
+						Description: "Days between prompts (default: 1)",
+						Required:    false,
+						MinValue:    &[]float64{1}[0],
+						MaxValue:    service.MaxPromptInterval,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "off",
+				Description: "Stop asking me for updates",
+			},
+		},
+	},
+	Handler: func(db database.CheckpointDatabase, s *discordgo.Session, i *discordgo.InteractionCreate) {
+		ctx, cancel := dbContext()
+		defer cancel()
+
+		sub := i.ApplicationCommandData().Options[0]
+		switch sub.Name {
+		case "on":
+			intervalDays := int64(1)
+			for _, opt := range sub.Options {
+				if opt.Name == "every" {
+					intervalDays = opt.IntValue()
+				}
+			}
+
+			// Prompts reference the guild, which only exists once the bot has been used in it
+			if _, err := ensureGuild(ctx, db, s, i.GuildID); err != nil {
+				log.Error("cannot ensure guild", "err", err, "guild", i.GuildID)
+				respondEphemeral(s, i, "Error checking guild")
+				return
+			}
+			prompt, err := service.EnableUpdatePrompts(ctx, db, i.GuildID, i.Member.User.ID, intervalDays, time.Now())
+			if service.IsUserError(err) {
+				respondEphemeral(s, i, userMessage(err))
+				return
+			} else if err != nil {
+				log.Error("cannot enable update prompts", "err", err, "user", i.Member.User.ID, "guild", i.GuildID)
+				respondEphemeral(s, i, "Error enabling update prompts")
+				return
+			}
+
+			every := "day"
+			if intervalDays > 1 {
+				every = fmt.Sprintf("%d days", intervalDays)
+			}
+			first := prompt.NextPromptAt
+			if nextPromptAt, err := time.Parse(time.RFC3339, prompt.NextPromptAt); err == nil {
+				first = fmt.Sprintf("<t:%d:R>", nextPromptAt.Unix())
+			}
+			respondEphemeral(s, i, fmt.Sprintf("I'll DM you every %s while you have goals for an upcoming checkpoint, starting %s. Make sure you allow DMs from this server's members.", every, first))
+		case "off":
+			deleted, err := db.DeleteUpdatePrompt(ctx, queries.DeleteUpdatePromptParams{GuildID: i.GuildID, DiscordUser: i.Member.User.ID})
+			if err != nil {
+				log.Error("cannot disable update prompts", "err", err, "user", i.Member.User.ID, "guild", i.GuildID)
+				respondEphemeral(s, i, "Error disabling update prompts")
+				return
+			}
+			if deleted == 0 {
+				respondEphemeral(s, i, "Update prompts are already off")
+				return
+			}
+			respondEphemeral(s, i, "Update prompts turned off")
+		}
+	},
+}
+
+// NextCmd shows the channel's next checkpoint with its goals and the updates posted since they were set
+var NextCmd = &Command{
+	ApplicationCommand: discordgo.ApplicationCommand{
+		Name:        "next",
+		Description: "View the next checkpoint in this channel, its goals and their updates",
+	},
+	Handler: func(db database.CheckpointDatabase, s *discordgo.Session, i *discordgo.InteractionCreate) {
+		ctx, cancel := dbContext()
+		defer cancel()
+
+		checkpoint, err := service.UpcomingCheckpoint(ctx, db, i.GuildID, i.ChannelID)
+		if service.IsUserError(err) {
+			respondEphemeral(s, i, userMessage(err))
+			return
+		} else if err != nil {
+			log.Error("cannot get upcoming checkpoint", "err", err, "channel", i.ChannelID, "guild", i.GuildID)
+			respondEphemeral(s, i, "Error getting upcoming checkpoint")
+			return
+		}
+
+		embed, err := checkpointRecapEmbed(ctx, db, *checkpoint)
+		if err != nil {
+			log.Error("cannot create checkpoint recap", "err", err, "checkpoint_id", checkpoint.ID)
+		}
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Embeds: []*discordgo.MessageEmbed{embed},
+			},
+		})
+	},
+}
+
+// checkpointRecapEmbed is the checkpoint's embed with its goals, the timeline of their updates and their proof.
+// Updates and proof of goals that aren't public are left out. On error the embed is still usable, with whatever could be loaded.
+func checkpointRecapEmbed(ctx context.Context, db database.CheckpointDatabase, checkpoint queries.Checkpoint) (*discordgo.MessageEmbed, error) {
+	embed, err := createCheckpointEmbedWithGoals(ctx, db, checkpoint)
+	if err != nil {
+		return embed, err
+	}
+	goals, err := db.GetGoalsByCheckpoint(ctx, checkpoint.ID)
+	if err != nil {
+		return embed, err
+	}
+	hidden := make(map[int64]bool)
+	for _, goal := range goals {
+		hidden[goal.ID] = goal.Visibility != settings.VisibilityPublic
+	}
+
+	updates, err := db.GetGoalUpdatesByCheckpoint(ctx, checkpoint.ID)
+	if err != nil {
+		return embed, err
+	}
+	updates = slices.DeleteFunc(updates, func(update queries.GoalUpdate) bool { return hidden[update.GoalID] })
+	if len(updates) > 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  fmt.Sprintf("Updates (%d)", len(updates)),
+			Value: updatesTimeline(updates),
+		})
+	}
+	evidence, err := db.GetGoalEvidenceByCheckpoint(ctx, checkpoint.ID)
+	if err != nil {
+		return embed, err
+	}
+	evidence = slices.DeleteFunc(evidence, func(item queries.GetGoalEvidenceByCheckpointRow) bool { return hidden[item.GoalID] })
+	if len(evidence) > 0 {
+		lines := make([]string, len(evidence))
+		for n, item := range evidence {
+			lines[n] = fmt.Sprintf("<@%s> %s", item.DiscordUser, evidenceLine(item.Kind, item.Url, item.Filename))
+		}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  fmt.Sprintf("Proof (%d)", len(evidence)),
+			Value: evidenceList(lines, DiscordEmbedFieldMaxLength),
+		})
+	}
+	return embed, nil
+}
+
+// updatesTimeline lists updates oldest first, dropping the oldest ones that don't fit in an embed field
+func updatesTimeline(updates []queries.GoalUpdate) string {
+	entries := []string{}
+	length := 0
+	for n := len(updates) - 1; n >= 0; n-- {
+		update := updates[n]
+		entry := fmt.Sprintf("<@%s>: %s", update.DiscordUser, audit.Truncate(strings.Join(strings.Fields(update.Content), " "), updateEntryMaxLength))
+		if update.CreatedAt.Valid {
+			entry = fmt.Sprintf("<t:%d:d> %s", update.CreatedAt.Time.Unix(), entry)
+		}
+		// Leave room for the note about hidden updates
+		if length+len(entry)+1 > DiscordEmbedFieldMaxLength-40 {
+			entries = append(entries, fmt.Sprintf("-# …and %d earlier updates", n+1))
+			break
+		}
+		entries = append(entries, entry)
+		length += len(entry) + 1
+	}
+
+	// Entries were collected newest first
+	for left, right := 0, len(entries)-1; left < right; left, right = left+1, right-1 {
+		entries[left], entries[right] = entries[right], entries[left]
+	}
+	return strings.Join(entries, "\n")
+}
+
+// updatePromptMessage asks for an update on the user's goals, with a button per goal
+func updatePromptMessage(goals []queries.Goal) *discordgo.MessageSend {
+	// A message can have up to 5 buttons in a row, goals are sorted by their checkpoint's date
+	if len(goals) > 5 {
+		goals = goals[:5]
+	}
+
+	var sb strings.Builder
+	sb.WriteString("👋 How are your goals going? Post a quick update, it will be shown on `/next` and in the checkpoint's recap.\n")
+	buttons := make([]discordgo.MessageComponent, 0, len(goals))
+	for _, goal := range goals {
+		sb.WriteString(fmt.Sprintf("\n**Checkpoint #%d**: %s", goal.CheckpointID, audit.Truncate(strings.Join(strings.Fields(goal.Description), " "), 150)))
+		buttons = append(buttons, discordgo.Button{
+			Label:    fmt.Sprintf("Update checkpoint #%d", goal.CheckpointID),
+			Style:    discordgo.PrimaryButton,
+			CustomID: goalUpdateButtonPrefix + strconv.FormatInt(goal.ID, 10),
+		})
+	}
+	sb.WriteString("\n\n-# Turn these off with `/update-prompts off`")
+
+	return &discordgo.MessageSend{
+		Content:    sb.String(),
+		Components: []discordgo.MessageComponent{discordgo.ActionsRow{Components: buttons}},
+	}
+}
+
+// handleGoalUpdateButton opens the update modal for the goal of the clicked button
+func handleGoalUpdateButton(db database.CheckpointDatabase, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	goalID := strings.TrimPrefix(i.MessageComponentData().CustomID, goalUpdateButtonPrefix)
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseModal,
+		Data: &discordgo.InteractionResponseData{
+			CustomID: goalUpdateModalPrefix + goalID,
+			Title:    "Goal Update",
+			Components: []discordgo.MessageComponent{
+				discordgo.ActionsRow{
+					Components: []discordgo.MessageComponent{
+						discordgo.TextInput{
+							CustomID:    "update_text",
+							Label:       "How is it going?",
+							Style:       discordgo.TextInputParagraph,
+							Placeholder: "What you did, what's next, what's in the way...",
+							Required:    true,
+							MaxLength:   service.UpdateMaxLength,
+							MinLength:   1,
+						},
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		log.Error("cannot respond with update modal", "err", err, "goal_id", goalID, "user", interactionUserID(i))
+	}
+}
+
+// handleGoalUpdateModal saves an update submitted from the update modal
+func handleGoalUpdateModal(db database.CheckpointDatabase, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	data := i.ModalSubmitData()
+	userID := interactionUserID(i)
+	goalID, err := strconv.ParseInt(strings.TrimPrefix(data.CustomID, goalUpdateModalPrefix), 10, 64)
+	if err != nil {
+		log.Error("cannot parse goal ID from update modal custom ID", "err", err, "custom_id", data.CustomID)
+		respondEphemeral(s, i, "Error processing update")
+		return
+	}
+
+	_, err = service.PostGoalUpdate(ctx, db, goalID, userID, modalValue(data, "update_text"))
+	if service.IsUserError(err) {
+		respondEphemeral(s, i, userMessage(err))
+		return
+	} else if err != nil {
+		log.Error("cannot post goal update", "err", err, "goal_id", goalID, "user", userID)
+		respondEphemeral(s, i, "Error saving update")
+		return
+	}
+	respondEphemeral(s, i, "Update saved! It will be shown on `/next` and in the checkpoint's recap.")
+}
+
+func init() {
+	registerCommand(UpdatePromptsCmd)
+	registerCommand(NextCmd)
+	registerComponent(goalUpdateButtonPrefix, handleGoalUpdateButton)
+	registerModal(goalUpdateModalPrefix, handleGoalUpdateModal)
+}
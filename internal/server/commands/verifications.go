@@ -0,0 +1,87 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/charmbracelet/log"
+	"github.com/metruzanca/checkpoint-bot/internal/database"
+	"github.com/metruzanca/checkpoint-bot/internal/service"
+)
+
+// goalConfirmButtonPrefix is followed by the goal ID in the button of verification requests
+const goalConfirmButtonPrefix = "goal_confirm_"
+
+// verificationRequest is the message asking members to confirm a completed goal, updated after each confirmation
+func verificationRequest(confirmation service.Confirmation) (string, []discordgo.MessageComponent) {
+	goal := confirmation.Goal
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s <@%s> completed their goal for checkpoint #%d:\n> %s\n",
+		getStatusEmoji(goal.Status, goal.Verification), goal.DiscordUser, goal.CheckpointID,
+		sharedGoalText(goal.Description, goal.Visibility, 300)))
+
+	confirmedBy := make([]string, len(confirmation.ConfirmedBy))
+	for n, userID := range confirmation.ConfirmedBy {
+		confirmedBy[n] = fmt.Sprintf("<@%s>", userID)
+	}
+	switch {
+	case confirmation.Verified():
+		sb.WriteString(fmt.Sprintf("Verified by %s, it now counts in their stats.", strings.Join(confirmedBy, ", ")))
+	case len(confirmedBy) > 0:
+		sb.WriteString(fmt.Sprintf("Confirmed by %s, it counts once %d members confirm it.", strings.Join(confirmedBy, ", "), confirmation.Required))
+	default:
+		sb.WriteString(fmt.Sprintf("It counts once %d other members confirm it.", confirmation.Required))
+	}
+
+	button := discordgo.Button{
+		Label:    fmt.Sprintf("Confirm (%d/%d)", len(confirmedBy), confirmation.Required),
+		Style:    discordgo.SuccessButton,
+		Emoji:    &discordgo.ComponentEmoji{Name: "👍"},
+		CustomID: goalConfirmButtonPrefix + strconv.FormatInt(goal.ID, 10),
+		Disabled: confirmation.Verified(),
+	}
+	return sb.String(), []discordgo.MessageComponent{discordgo.ActionsRow{Components: []discordgo.MessageComponent{button}}}
+}
+
+// handleGoalConfirmButton confirms the goal of the clicked verification request and updates its count
+func handleGoalConfirmButton(db database.CheckpointDatabase, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	data := i.MessageComponentData()
+	userID := interactionUserID(i)
+	goalID, err := strconv.ParseInt(strings.TrimPrefix(data.CustomID, goalConfirmButtonPrefix), 10, 64)
+	if err != nil {
+		log.Error("cannot parse goal ID from confirm button custom ID", "err", err, "custom_id", data.CustomID)
+		respondEphemeral(s, i, "Error confirming goal")
+		return
+	}
+
+	confirmation, err := service.ConfirmGoal(ctx, db, goalID, userID)
+	if service.IsUserError(err) {
+		respondEphemeral(s, i, userMessage(err))
+		return
+	} else if err != nil {
+		log.Error("cannot confirm goal", "err", err, "goal_id", goalID, "user", userID)
+		respondEphemeral(s, i, "Error confirming goal")
+		return
+	}
+
+	content, components := verificationRequest(*confirmation)
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Content:    content,
+			Components: components,
+		},
+	})
+	if err != nil {
+		log.Error("cannot update verification request", "err", err, "goal_id", goalID)
+	}
+}
+
+func init() {
+	registerComponent(goalConfirmButtonPrefix, handleGoalConfirmButton)
+}
@@ -0,0 +1,116 @@
+package commands
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/charmbracelet/log"
+	"github.com/metruzanca/checkpoint-bot/internal/audit"
+	"github.com/metruzanca/checkpoint-bot/internal/database"
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+	"github.com/metruzanca/checkpoint-bot/internal/service"
+	"github.com/metruzanca/checkpoint-bot/internal/settings"
+)
+
+// visibilityChoices are the goal visibilities offered by /goal
+var visibilityChoices = []*discordgo.ApplicationCommandOptionChoice{
+	{Name: "public", Value: settings.VisibilityPublic},
+	{Name: "members only, others see a summary", Value: settings.VisibilityMembers},
+	{Name: "private, only you and moderators", Value: settings.VisibilityPrivate},
+}
+
+// goalViewer is who a checkpoint embed is shown to, the zero value is the whole channel
+type goalViewer struct {
+	UserID    string
+	Moderator bool
+}
+
+// sharedGoalText is the goal's text on one line for messages others may see, hidden unless the goal is public
+func sharedGoalText(description string, visibility string, maxLength int) string {
+	if visibility != settings.VisibilityPublic {
+		return service.HiddenGoalText(visibility)
+	}
+	return audit.Truncate(strings.Join(strings.Fields(description), " "), maxLength)
+}
+
+// GoalsCmd shows a checkpoint's goals to the member running it, with the text of the members-only and private goals they can see
+var GoalsCmd = &Command{
+	ApplicationCommand: discordgo.ApplicationCommand{
+		Name:        "goals",
+		Description: "Show a checkpoint's goals, including the private ones you're allowed to see",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Name:        "checkpoint",
+				Description: "Checkpoint ID (default: the channel's upcoming or latest checkpoint)",
+				Required:    false,
+			},
+		},
+	},
+	Handler: func(db database.CheckpointDatabase, s *discordgo.Session, i *discordgo.InteractionCreate) {
+		ctx, cancel := dbContext()
+		defer cancel()
+
+		var checkpoint *queries.Checkpoint
+		if options := i.ApplicationCommandData().Options; len(options) > 0 {
+			found, err := db.GetCheckpoint(ctx, options[0].IntValue())
+			if err == sql.ErrNoRows || (err == nil && found.GuildID != i.GuildID) {
+				respondEphemeral(s, i, userMessage(service.ErrCheckpointNotFound))
+				return
+			} else if err != nil {
+				log.Error("cannot get checkpoint", "err", err, "checkpoint_id", options[0].IntValue())
+				respondEphemeral(s, i, "Error getting checkpoint")
+				return
+			}
+			checkpoint = found
+		} else {
+			found, err := latestChannelCheckpoint(db, i)
+			if err != nil {
+				log.Error("cannot get channel checkpoint", "err", err, "channel", i.ChannelID, "guild", i.GuildID)
+				respondEphemeral(s, i, "Error getting checkpoint")
+				return
+			}
+			if found == nil {
+				respondEphemeral(s, i, "No checkpoint found for this channel")
+				return
+			}
+			checkpoint = found
+		}
+
+		actor, err := goalActor(ctx, db, i)
+		if err != nil {
+			log.Error("cannot check goal permissions", "err", err, "user", i.Member.User.ID, "guild", i.GuildID)
+			respondEphemeral(s, i, "Error checking permissions")
+			return
+		}
+		embed, err := checkpointEmbedFor(ctx, db, *checkpoint, goalViewer{UserID: actor.ActorID, Moderator: actor.Moderator})
+		if err != nil {
+			log.Error("cannot create checkpoint embed with goals", "err", err, "checkpoint_id", checkpoint.ID)
+		}
+
+		log.Info("goals command executed", "checkpoint_id", checkpoint.ID, "user", actor.ActorID, "guild", i.GuildID)
+		s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Embeds: []*discordgo.MessageEmbed{embed},
+				Flags:  discordgo.MessageFlagsEphemeral,
+			},
+		})
+	},
+}
+
+// visibilityLabel describes a goal visibility for replies, e.g. "private"
+func visibilityLabel(visibility string) string {
+	for _, choice := range visibilityChoices {
+		if choice.Value == visibility {
+			return fmt.Sprintf("**%s**", choice.Name)
+		}
+	}
+	return visibility
+}
+
+func init() {
+	registerCommand(GoalsCmd)
+}
@@ -0,0 +1,239 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/charmbracelet/log"
+	"github.com/metruzanca/checkpoint-bot/internal/database"
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+	"github.com/metruzanca/checkpoint-bot/internal/events"
+	"github.com/metruzanca/checkpoint-bot/internal/permissions"
+	"github.com/metruzanca/checkpoint-bot/internal/webhook"
+)
+
+// WebhookCmd manages the URLs receiving checkpoint and goal events (admin only)
+var WebhookCmd = &Command{
+	ApplicationCommand: discordgo.ApplicationCommand{
+		Name:        "webhook",
+		Description: "Manage outgoing webhooks for this server (admin only)",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "add",
+				Description: "Send events to a URL",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "url",
+						Description: "The http(s) URL receiving the events",
+						Required:    true,
+						MaxLength:   500,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "events",
+						Description: "Comma separated event types, e.g. checkpoint.created,goal.status_changed (default: all)",
+						Required:    false,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "list",
+				Description: "List this server's webhooks",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "remove",
+				Description: "Stop sending events to a webhook",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "id",
+						Description: "ID of the webhook, as shown by /webhook list",
+						Required:    true,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "deliveries",
+				Description: "Show a webhook's latest deliveries",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "id",
+						Description: "ID of the webhook, as shown by /webhook list",
+						Required:    true,
+					},
+				},
+			},
+		},
+	},
+	Handler: func(db database.CheckpointDatabase, s *discordgo.Session, i *discordgo.InteractionCreate) {
+		ctx, cancel := dbContext()
+		defer cancel()
+		if !authorize(ctx, db, s, i, permissions.ManageGuild, permissions.Target{}) {
+			return
+		}
+
+		sub := i.ApplicationCommandData().Options[0]
+		switch sub.Name {
+		case "add":
+			var rawURL, eventTypes string
+			for _, opt := range sub.Options {
+				switch opt.Name {
+				case "url":
+					rawURL = opt.StringValue()
+				case "events":
+					eventTypes = opt.StringValue()
+				}
+			}
+			addWebhook(db, s, i, rawURL, eventTypes)
+		case "list":
+			listWebhooks(db, s, i)
+		case "remove":
+			removeWebhook(db, s, i, sub.Options[0].IntValue())
+		case "deliveries":
+			listWebhookDeliveries(db, s, i, sub.Options[0].IntValue())
+		}
+	},
+}
+
+func addWebhook(db database.CheckpointDatabase, s *discordgo.Session, i *discordgo.InteractionCreate, rawURL string, rawEventTypes string) {
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	if err := webhook.ValidateURL(rawURL); err != nil {
+		respondEphemeral(s, i, userMessage(err))
+		return
+	}
+	eventTypes, err := webhook.ParseEventTypes(rawEventTypes)
+	if err != nil {
+		types := make([]string, 0, len(events.Types))
+		for _, t := range events.Types {
+			types = append(types, string(t))
+		}
+		respondEphemeral(s, i, fmt.Sprintf("%s. Available events: `%s`", userMessage(err), strings.Join(types, "`, `")))
+		return
+	}
+
+	// Webhooks reference the guild, which only exists once the bot has been used in it
+	if _, err := ensureGuild(ctx, db, s, i.GuildID); err != nil {
+		log.Error("cannot ensure guild", "err", err, "guild", i.GuildID)
+		respondEphemeral(s, i, "Error checking guild")
+		return
+	}
+
+	secret, err := webhook.GenerateSecret()
+	if err != nil {
+		log.Error("cannot generate webhook secret", "err", err)
+		respondEphemeral(s, i, "Error generating webhook secret")
+		return
+	}
+
+	record, err := db.CreateWebhook(ctx, queries.CreateWebhookParams{
+		GuildID:    i.GuildID,
+		Url:        rawURL,
+		Secret:     secret,
+		EventTypes: eventTypes,
+		CreatedBy:  i.Member.User.ID,
+	})
+	if err != nil {
+		log.Error("cannot create webhook", "err", err, "guild", i.GuildID)
+		respondEphemeral(s, i, "Error saving webhook")
+		return
+	}
+
+	respondEphemeral(s, i, fmt.Sprintf(
+		"Added webhook %d for %s. Its signing secret is only shown now:\n```\n%s\n```\nVerify `%s` as HMAC-SHA256 of `<%s>.<body>`.",
+		record.ID, webhookEventsLabel(record.EventTypes), secret, webhook.HeaderSignature, webhook.HeaderTimestamp,
+	))
+}
+
+func listWebhooks(db database.CheckpointDatabase, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	webhooks, err := db.ListWebhooksByGuild(ctx, i.GuildID)
+	if err != nil {
+		log.Error("cannot list webhooks", "err", err, "guild", i.GuildID)
+		respondEphemeral(s, i, "Error loading webhooks")
+		return
+	}
+
+	if len(webhooks) == 0 {
+		respondEphemeral(s, i, "This server has no webhooks. Add one with `/webhook add`.")
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString("**Webhooks**\n")
+	for _, hook := range webhooks {
+		sb.WriteString(fmt.Sprintf("`%d` <%s> for %s, added by <@%s>\n", hook.ID, hook.Url, webhookEventsLabel(hook.EventTypes), hook.CreatedBy))
+	}
+	respondEphemeral(s, i, sb.String())
+}
+
+func removeWebhook(db database.CheckpointDatabase, s *discordgo.Session, i *discordgo.InteractionCreate, id int64) {
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	deleted, err := db.DeleteWebhook(ctx, queries.DeleteWebhookParams{ID: id, GuildID: i.GuildID})
+	if err != nil {
+		log.Error("cannot delete webhook", "err", err, "guild", i.GuildID, "id", id)
+		respondEphemeral(s, i, "Error removing webhook")
+		return
+	}
+	if deleted == 0 {
+		respondEphemeral(s, i, fmt.Sprintf("No webhook with id %d in this server", id))
+		return
+	}
+
+	respondEphemeral(s, i, fmt.Sprintf("Removed webhook %d", id))
+}
+
+func listWebhookDeliveries(db database.CheckpointDatabase, s *discordgo.Session, i *discordgo.InteractionCreate, id int64) {
+	ctx, cancel := dbContext()
+	defer cancel()
+
+	deliveries, err := db.ListWebhookDeliveries(ctx, queries.ListWebhookDeliveriesParams{WebhookID: id, GuildID: i.GuildID, Limit: 10})
+	if err != nil {
+		log.Error("cannot list webhook deliveries", "err", err, "guild", i.GuildID, "id", id)
+		respondEphemeral(s, i, "Error loading deliveries")
+		return
+	}
+
+	if len(deliveries) == 0 {
+		respondEphemeral(s, i, fmt.Sprintf("No deliveries for webhook %d", id))
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("**Latest deliveries of webhook %d**\n", id))
+	for _, delivery := range deliveries {
+		line := fmt.Sprintf("`%d` %s **%s** after %d attempt(s)", delivery.ID, delivery.EventType, delivery.Status, delivery.Attempts)
+		if delivery.ResponseStatus.Valid {
+			line += fmt.Sprintf(", HTTP %d", delivery.ResponseStatus.Int64)
+		}
+		if delivery.Status != webhook.StatusDelivered && delivery.LastError.Valid {
+			line += fmt.Sprintf(": %s", delivery.LastError.String)
+		}
+		sb.WriteString(line + "\n")
+	}
+	respondEphemeral(s, i, sb.String())
+}
+
+// webhookEventsLabel describes the events a webhook receives
+func webhookEventsLabel(eventTypes string) string {
+	if eventTypes == "" {
+		return "all events"
+	}
+	return "`" + strings.ReplaceAll(eventTypes, ",", "`, `") + "`"
+}
+
+func init() {
+	registerCommand(WebhookCmd)
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/log"
+	"github.com/metruzanca/checkpoint-bot/internal/audit"
+	"github.com/metruzanca/checkpoint-bot/internal/database"
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+)
+
+const (
+	// UnitMaxLength keeps units short enough for the running totals in checkpoint embeds
+	UnitMaxLength = 20
+	// NoteMaxLength limits the note of a check-in
+	NoteMaxLength = 200
+)
+
+// targetPattern matches a target such as "20 km", "5000 words" or "3.5h"
+var targetPattern = regexp.MustCompile(`^(\d+(?:[.,]\d+)?)\s*(.*)$`)
+
+// ParseTarget splits a target such as "20 km" into its amount and unit, an empty value is no target
+func ParseTarget(value string) (float64, string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, "", nil
+	}
+	match := targetPattern.FindStringSubmatch(value)
+	if match == nil {
+		return 0, "", ErrInvalidTarget
+	}
+	target, err := strconv.ParseFloat(strings.Replace(match[1], ",", ".", 1), 64)
+	if err != nil || !validTarget(target, match[2]) || target == 0 {
+		return 0, "", ErrInvalidTarget
+	}
+	return target, match[2], nil
+}
+
+func validTarget(target float64, unit string) bool {
+	return target >= 0 && !math.IsInf(target, 0) && !math.IsNaN(target) && utf8.RuneCountInString(unit) <= UnitMaxLength
+}
+
+// TargetProgress is the progress of a goal with a target, completed once the total reaches it
+func TargetProgress(total float64, target float64) int64 {
+	if target <= 0 || total <= 0 {
+		return 0
+	}
+	return int64(math.Min(100, math.Floor(total/target*100)))
+}
+
+// FormatAmount formats an amount without trailing zeros, e.g. 12.5 or 20
+func FormatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', -1, 64)
+}
+
+// FormatTarget formats a goal's running total, e.g. "12.5/20 km", or "" for goals without a target
+func FormatTarget(total float64, target float64, unit string) string {
+	if target <= 0 {
+		return ""
+	}
+	return strings.TrimSpace(fmt.Sprintf("%s/%s %s", FormatAmount(total), FormatAmount(target), unit))
+}
+
+// Checkin is an amount logged towards a user's goal target
+type Checkin struct {
+	// ActorID is the user logging the amount, Moderator allows them to log for other users
+	ActorID   string
+	Moderator bool
+	// Source is recorded in the audit log, see audit.SourceDiscord
+	Source string
+
+	UserID string
+	// Amount is added to the goal's total, negative amounts correct earlier check-ins
+	Amount float64
+	Note   string
+}
+
+// CheckinResult is the goal after a check-in, Completed is true when the check-in reached its target
+type CheckinResult struct {
+	Goal      queries.Goal
+	Checkin   queries.GoalCheckin
+	Completed bool
+}
+
+// LogGoal adds a check-in to a user's goal for a checkpoint, its progress follows the total
+// and it's completed once the total reaches the target
+func LogGoal(ctx context.Context, db database.CheckpointDatabase, checkpointID int64, checkin Checkin) (*CheckinResult, error) {
+	if err := CanEditGoal(checkin.ActorID, checkin.UserID, checkin.Moderator); err != nil {
+		return nil, err
+	}
+	if checkin.Amount == 0 || math.IsInf(checkin.Amount, 0) || math.IsNaN(checkin.Amount) {
+		return nil, ErrInvalidAmount
+	}
+	if utf8.RuneCountInString(checkin.Note) > NoteMaxLength {
+		return nil, ErrNoteTooLong
+	}
+
+	goal, err := db.GetGoalByCheckpointAndUser(ctx, queries.GetGoalByCheckpointAndUserParams{
+		CheckpointID: checkpointID,
+		DiscordUser:  checkin.UserID,
+	})
+	if err == sql.ErrNoRows {
+		return nil, ErrNoTarget
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot get goal: %w", err)
+	}
+	if goal.Target <= 0 {
+		return nil, ErrNoTarget
+	}
+	if goal.Total+checkin.Amount < 0 {
+		return nil, ErrInvalidAmount
+	}
+
+	result := &CheckinResult{}
+	created, err := db.CreateGoalCheckin(ctx, queries.CreateGoalCheckinParams{
+		GoalID:   goal.ID,
+		Amount:   checkin.Amount,
+		Note:     checkin.Note,
+		LoggedBy: checkin.ActorID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot create check-in: %w", err)
+	}
+	result.Checkin = *created
+
+	updated, err := db.AddGoalTotal(ctx, queries.AddGoalTotalParams{Amount: checkin.Amount, ID: goal.ID})
+	if err != nil {
+		return nil, fmt.Errorf("cannot update goal total: %w", err)
+	}
+	log.Info("goal check-in logged", "checkpoint_id", checkpointID, "user", checkin.UserID, "amount", checkin.Amount, "total", updated.Total, "actor", checkin.ActorID)
+
+	change := GoalChange{ActorID: checkin.ActorID, Moderator: checkin.Moderator, Source: checkin.Source, UserID: checkin.UserID}
+	if checkin.ActorID != checkin.UserID {
+		recordGoalChange(ctx, db, checkpointID, goal.ID, change, audit.GoalOverride,
+			FormatTarget(goal.Total, goal.Target, goal.Unit), FormatTarget(updated.Total, updated.Target, updated.Unit))
+	}
+
+	progress := TargetProgress(updated.Total, updated.Target)
+	change.Progress = &progress
+	saved, err := SaveGoal(ctx, db, checkpointID, change)
+	if err != nil {
+		return nil, err
+	}
+	result.Goal = saved.Goal
+	result.Completed = goal.Status != StatusCompleted && saved.Goal.Status == StatusCompleted
+	return result, nil
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseTarget tests splitting targets into an amount and a unit
+func TestParseTarget(t *testing.T) {
+	tests := []struct {
+		value  string
+		target float64
+		unit   string
+	}{
+		{"20 km", 20, "km"},
+		{"5000 words", 5000, "words"},
+		{"3,5h", 3.5, "h"},
+		{" 12 ", 12, ""},
+		{"", 0, ""},
+	}
+	for _, test := range tests {
+		target, unit, err := ParseTarget(test.value)
+		require.NoError(t, err, test.value)
+		assert.Equal(t, test.target, target, test.value)
+		assert.Equal(t, test.unit, unit, test.value)
+	}
+
+	for _, value := range []string{"km", "-5 km", "0 km", "20 kilometres and then some more"} {
+		_, _, err := ParseTarget(value)
+		assert.ErrorIs(t, err, ErrInvalidTarget, value)
+	}
+}
+
+// TestLogGoal tests that check-ins add up to the target, moving the goal's progress and completing it
+func TestLogGoal(t *testing.T) {
+	db, guild := setupTestDB(t)
+	ctx := context.Background()
+
+	checkpoint, err := db.CreateCheckpoint(ctx, queries.CreateCheckpointParams{
+		ScheduledAt: time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339),
+		ChannelID:   "30",
+		GuildID:     guild.GuildID,
+		DiscordUser: "20",
+	})
+	require.NoError(t, err)
+
+	_, err = LogGoal(ctx, db, checkpoint.ID, Checkin{ActorID: "40", UserID: "40", Amount: 5})
+	assert.ErrorIs(t, err, ErrNoTarget)
+
+	_, err = SaveGoal(ctx, db, checkpoint.ID, GoalChange{ActorID: "40", UserID: "40", Description: "Run"})
+	require.NoError(t, err)
+	_, err = LogGoal(ctx, db, checkpoint.ID, Checkin{ActorID: "40", UserID: "40", Amount: 5})
+	assert.ErrorIs(t, err, ErrNoTarget)
+
+	target := 20.0
+	result, err := SaveGoal(ctx, db, checkpoint.ID, GoalChange{ActorID: "40", UserID: "40", Target: &target, Unit: "km"})
+	require.NoError(t, err)
+	assert.Equal(t, "km", result.Goal.Unit)
+	assert.Equal(t, StatusIncomplete, result.Goal.Status)
+
+	_, err = LogGoal(ctx, db, checkpoint.ID, Checkin{ActorID: "50", UserID: "40", Amount: 5})
+	assert.ErrorIs(t, err, ErrNotAllowed)
+	_, err = LogGoal(ctx, db, checkpoint.ID, Checkin{ActorID: "40", UserID: "40", Amount: -5})
+	assert.ErrorIs(t, err, ErrInvalidAmount)
+
+	logged, err := LogGoal(ctx, db, checkpoint.ID, Checkin{ActorID: "40", UserID: "40", Amount: 12.5, Note: "Morning run"})
+	require.NoError(t, err)
+	assert.Equal(t, 12.5, logged.Goal.Total)
+	assert.Equal(t, int64(62), logged.Goal.Progress)
+	assert.Equal(t, StatusPartial, logged.Goal.Status)
+	assert.False(t, logged.Completed)
+	assert.Equal(t, "12.5/20 km", FormatTarget(logged.Goal.Total, logged.Goal.Target, logged.Goal.Unit))
+
+	logged, err = LogGoal(ctx, db, checkpoint.ID, Checkin{ActorID: "20", Moderator: true, UserID: "40", Amount: 8})
+	require.NoError(t, err)
+	assert.Equal(t, 20.5, logged.Goal.Total)
+	assert.Equal(t, StatusCompleted, logged.Goal.Status)
+	assert.Equal(t, int64(100), logged.Goal.Progress)
+	assert.True(t, logged.Completed)
+
+	checkins, err := db.GetGoalCheckins(ctx, logged.Goal.ID)
+	require.NoError(t, err)
+	require.Len(t, checkins, 2)
+	assert.Equal(t, "Morning run", checkins[0].Note)
+	assert.Equal(t, "20", checkins[1].LoggedBy)
+
+	// Raising the target moves the goal back to partial
+	target = 41
+	result, err = SaveGoal(ctx, db, checkpoint.ID, GoalChange{ActorID: "40", UserID: "40", Target: &target, Unit: "km"})
+	require.NoError(t, err)
+	assert.Equal(t, StatusPartial, result.Goal.Status)
+	assert.Equal(t, int64(50), result.Goal.Progress)
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"github.com/metruzanca/checkpoint-bot/internal/database"
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+)
+
+const (
+	EvidenceAttachment = "attachment"
+	EvidenceLink       = "link"
+
+	// EvidenceURLMaxLength fits Discord's attachment URLs, which carry signature parameters
+	EvidenceURLMaxLength = 1000
+	// MaxGoalEvidence limits how much proof a goal can have, so it can all be listed in one message
+	MaxGoalEvidence = 10
+)
+
+var (
+	ErrNoEvidence         = errors.New("attach a file or give a link as proof")
+	ErrInvalidEvidenceURL = fmt.Errorf("link must be an http or https URL of up to %d characters", EvidenceURLMaxLength)
+	ErrTooMuchEvidence    = fmt.Errorf("a goal can have at most %d proofs", MaxGoalEvidence)
+	ErrNoGoalForProof     = errors.New("you have no goal for this checkpoint to attach proof to")
+)
+
+// Evidence is proof to attach to a goal, Filename is only set for attachments
+type Evidence struct {
+	Kind     string
+	URL      string
+	Filename string
+}
+
+// AddGoalEvidence attaches proof to the user's own goal for a checkpoint, it can be added after the checkpoint too
+func AddGoalEvidence(ctx context.Context, db database.CheckpointDatabase, checkpointID int64, userID string, evidence []Evidence) ([]queries.GoalEvidence, error) {
+	if len(evidence) == 0 {
+		return nil, ErrNoEvidence
+	}
+	for _, item := range evidence {
+		if !validEvidenceURL(item.URL) {
+			return nil, ErrInvalidEvidenceURL
+		}
+	}
+
+	goal, err := db.GetGoalByCheckpointAndUser(ctx, queries.GetGoalByCheckpointAndUserParams{
+		CheckpointID: checkpointID,
+		DiscordUser:  userID,
+	})
+	if err == sql.ErrNoRows {
+		return nil, ErrNoGoalForProof
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot get goal: %w", err)
+	}
+	existing, err := db.GetGoalEvidence(ctx, goal.ID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get goal evidence: %w", err)
+	}
+	if len(existing)+len(evidence) > MaxGoalEvidence {
+		return nil, ErrTooMuchEvidence
+	}
+
+	added := make([]queries.GoalEvidence, 0, len(evidence))
+	for _, item := range evidence {
+		record, err := db.CreateGoalEvidence(ctx, queries.CreateGoalEvidenceParams{
+			GoalID:   goal.ID,
+			Kind:     item.Kind,
+			Url:      item.URL,
+			Filename: item.Filename,
+			AddedBy:  userID,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("cannot create goal evidence: %w", err)
+		}
+		added = append(added, *record)
+	}
+	log.Info("goal evidence added", "goal_id", goal.ID, "checkpoint_id", checkpointID, "user", userID, "count", len(added))
+	return added, nil
+}
+
+// validEvidenceURL accepts absolute http and https URLs
+func validEvidenceURL(raw string) bool {
+	if raw == "" || len(raw) > EvidenceURLMaxLength || strings.ContainsAny(raw, " \n") {
+		return false
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	return (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAddGoalEvidence tests that members can attach valid links and files to their own goal, up to MaxGoalEvidence
+func TestAddGoalEvidence(t *testing.T) {
+	db, guild := setupTestDB(t)
+	ctx := context.Background()
+
+	checkpoint, err := db.CreateCheckpoint(ctx, queries.CreateCheckpointParams{
+		ScheduledAt: time.Now().Add(-time.Hour).UTC().Format(time.RFC3339),
+		ChannelID:   "30",
+		GuildID:     guild.GuildID,
+		DiscordUser: "20",
+	})
+	require.NoError(t, err)
+	goal, err := db.CreateGoal(ctx, queries.CreateGoalParams{DiscordUser: "40", Description: "Run 20 km", CheckpointID: checkpoint.ID})
+	require.NoError(t, err)
+
+	link := Evidence{Kind: EvidenceLink, URL: "https://strava.com/activities/1"}
+	_, err = AddGoalEvidence(ctx, db, checkpoint.ID, "40", nil)
+	assert.ErrorIs(t, err, ErrNoEvidence)
+	_, err = AddGoalEvidence(ctx, db, checkpoint.ID, "40", []Evidence{{Kind: EvidenceLink, URL: "javascript:alert(1)"}})
+	assert.ErrorIs(t, err, ErrInvalidEvidenceURL)
+	_, err = AddGoalEvidence(ctx, db, checkpoint.ID, "40", []Evidence{{Kind: EvidenceLink, URL: "strava.com"}})
+	assert.ErrorIs(t, err, ErrInvalidEvidenceURL)
+	_, err = AddGoalEvidence(ctx, db, checkpoint.ID, "50", []Evidence{link})
+	assert.ErrorIs(t, err, ErrNoGoalForProof)
+
+	// Proof can be added after the checkpoint started
+	added, err := AddGoalEvidence(ctx, db, checkpoint.ID, "40", []Evidence{
+		{Kind: EvidenceAttachment, URL: "https://cdn.discordapp.com/attachments/1/2/run.png", Filename: "run.png"},
+		link,
+	})
+	require.NoError(t, err)
+	require.Len(t, added, 2)
+	assert.Equal(t, "run.png", added[0].Filename)
+
+	evidence, err := db.GetGoalEvidenceByCheckpoint(ctx, checkpoint.ID)
+	require.NoError(t, err)
+	require.Len(t, evidence, 2)
+	assert.Equal(t, "40", evidence[1].DiscordUser)
+	assert.Equal(t, goal.ID, evidence[1].GoalID)
+
+	for range MaxGoalEvidence - 2 {
+		_, err := AddGoalEvidence(ctx, db, checkpoint.ID, "40", []Evidence{link})
+		require.NoError(t, err)
+	}
+	_, err = AddGoalEvidence(ctx, db, checkpoint.ID, "40", []Evidence{link})
+	assert.ErrorIs(t, err, ErrTooMuchEvidence)
+}
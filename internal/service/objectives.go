@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/log"
+	"github.com/metruzanca/checkpoint-bot/internal/database"
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+	"github.com/metruzanca/checkpoint-bot/internal/stats"
+	"github.com/metruzanca/checkpoint-bot/internal/util"
+)
+
+const (
+	ObjectiveActive   = "active"
+	ObjectiveAchieved = "achieved"
+
+	// ObjectiveTitleMaxLength keeps titles short enough for autocomplete choices
+	ObjectiveTitleMaxLength = 100
+	// MaxActiveObjectives limits the objectives a member works towards at once
+	MaxActiveObjectives = 10
+)
+
+var (
+	ErrInvalidObjectiveTitle = fmt.Errorf("objective title must be 1 to %d characters", ObjectiveTitleMaxLength)
+	ErrDeadlineInPast        = errors.New("an objective's deadline must be today or later")
+	ErrObjectiveNotFound     = errors.New("objective not found")
+	ErrTooManyObjectives     = fmt.Errorf("you can have at most %d active objectives, complete or delete one first", MaxActiveObjectives)
+	ErrObjectiveAchieved     = errors.New("that objective is already achieved")
+	ErrNoGoalToLink          = errors.New("you have no goal for this checkpoint to link")
+)
+
+// ObjectiveProgress is an objective with a summary of the goals linked to it
+type ObjectiveProgress struct {
+	Objective queries.Objective
+	Deadline  time.Time
+	Goals     int64
+	// Checkpoints is how many checkpoints the linked goals are spread over
+	Checkpoints int64
+	Completed   int64
+	// Progress is the completion rate of the linked goals between 0 and 1, see stats.CompletionRate
+	Progress float64
+}
+
+// Overdue reports whether the objective is still active after its deadline
+func (p ObjectiveProgress) Overdue(now time.Time) bool {
+	return p.Objective.Status == ObjectiveActive && now.After(p.Deadline)
+}
+
+// CreateObjective adds an objective for the member, due by the end of the deadline (YYYY-MM-DD) in the guild's timezone
+func CreateObjective(ctx context.Context, db database.CheckpointDatabase, guild queries.Guild, userID string, title string, deadline string) (*queries.Objective, error) {
+	title = strings.TrimSpace(title)
+	if title == "" || utf8.RuneCountInString(title) > ObjectiveTitleMaxLength {
+		return nil, ErrInvalidObjectiveTitle
+	}
+	date, err := util.ParseDate(strings.TrimSpace(deadline))
+	if err != nil {
+		return nil, ErrInvalidDate
+	}
+	due := time.Date(date.Year(), date.Month(), date.Day(), 23, 59, 59, 0, util.GuildLocation(guild))
+	if due.Before(time.Now()) {
+		return nil, ErrDeadlineInPast
+	}
+
+	objectives, err := Objectives(ctx, db, guild.GuildID, userID)
+	if err != nil {
+		return nil, err
+	}
+	active := 0
+	for _, objective := range objectives {
+		if objective.Objective.Status == ObjectiveActive {
+			active++
+		}
+	}
+	if active >= MaxActiveObjectives {
+		return nil, ErrTooManyObjectives
+	}
+
+	objective, err := db.CreateObjective(ctx, queries.CreateObjectiveParams{
+		GuildID:     guild.GuildID,
+		DiscordUser: userID,
+		Title:       title,
+		Deadline:    due.Format(time.RFC3339),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot create objective: %w", err)
+	}
+	return objective, nil
+}
+
+// Objectives returns the member's objectives with their progress, active ones first by deadline
+func Objectives(ctx context.Context, db database.CheckpointDatabase, guildID string, userID string) ([]ObjectiveProgress, error) {
+	rows, err := db.GetObjectivesByGuildAndUser(ctx, queries.GetObjectivesByGuildAndUserParams{GuildID: guildID, DiscordUser: userID})
+	if err != nil {
+		return nil, fmt.Errorf("cannot get objectives: %w", err)
+	}
+	objectives := make([]ObjectiveProgress, len(rows))
+	for n, row := range rows {
+		deadline, err := time.Parse(time.RFC3339, row.Objective.Deadline)
+		if err != nil {
+			log.Error("cannot parse objective deadline", "err", err, "objective_id", row.Objective.ID, "deadline", row.Objective.Deadline)
+		}
+		objectives[n] = ObjectiveProgress{
+			Objective:   row.Objective,
+			Deadline:    deadline,
+			Goals:       row.Goals,
+			Checkpoints: row.Checkpoints,
+			Completed:   row.Completed,
+			Progress:    stats.CompletionRate(row.Completed, row.PartialProgress, row.Goals),
+		}
+	}
+	return objectives, nil
+}
+
+// FindObjective finds one of the member's objectives by ID or title
+func FindObjective(ctx context.Context, db database.CheckpointDatabase, guildID string, userID string, value string) (*queries.Objective, error) {
+	value = strings.TrimSpace(value)
+	objectives, err := Objectives(ctx, db, guildID, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, objective := range objectives {
+		if strconv.FormatInt(objective.Objective.ID, 10) == value || strings.EqualFold(objective.Objective.Title, value) {
+			return &objective.Objective, nil
+		}
+	}
+	return nil, ErrObjectiveNotFound
+}
+
+// LinkGoal links the member's goal for a checkpoint to one of their active objectives, nil unlinks it
+func LinkGoal(ctx context.Context, db database.CheckpointDatabase, checkpointID int64, userID string, objective *queries.Objective) (*queries.Goal, error) {
+	goal, err := db.GetGoalByCheckpointAndUser(ctx, queries.GetGoalByCheckpointAndUserParams{
+		CheckpointID: checkpointID,
+		DiscordUser:  userID,
+	})
+	if err == sql.ErrNoRows {
+		return nil, ErrNoGoalToLink
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot get goal: %w", err)
+	}
+
+	var objectiveID sql.NullInt64
+	if objective != nil {
+		if objective.DiscordUser != userID {
+			return nil, ErrObjectiveNotFound
+		}
+		if objective.Status == ObjectiveAchieved {
+			return nil, ErrObjectiveAchieved
+		}
+		objectiveID = sql.NullInt64{Int64: objective.ID, Valid: true}
+	}
+	if err := db.UpdateGoalObjective(ctx, queries.UpdateGoalObjectiveParams{ObjectiveID: objectiveID, ID: goal.ID}); err != nil {
+		return nil, fmt.Errorf("cannot link goal: %w", err)
+	}
+	goal.ObjectiveID = objectiveID
+	return goal, nil
+}
+
+// CompleteObjective marks an objective achieved, its linked goals stay linked
+func CompleteObjective(ctx context.Context, db database.CheckpointDatabase, objective queries.Objective) error {
+	if objective.Status == ObjectiveAchieved {
+		return ErrObjectiveAchieved
+	}
+	err := db.UpdateObjectiveStatus(ctx, queries.UpdateObjectiveStatusParams{Status: ObjectiveAchieved, ID: objective.ID})
+	if err != nil {
+		return fmt.Errorf("cannot update objective status: %w", err)
+	}
+	return nil
+}
+
+// DeleteObjective deletes an objective, its linked goals are kept without it
+func DeleteObjective(ctx context.Context, db database.CheckpointDatabase, objective queries.Objective) error {
+	if _, err := db.DeleteObjective(ctx, objective.ID); err != nil {
+		return fmt.Errorf("cannot delete objective: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestObjectives tests that goals across checkpoints link to an objective and count towards its progress
+func TestObjectives(t *testing.T) {
+	db, guild := setupTestDB(t)
+	ctx := context.Background()
+	deadline := time.Now().AddDate(0, 2, 0).Format("2006-01-02")
+
+	_, err := CreateObjective(ctx, db, guild, "40", " ", deadline)
+	assert.ErrorIs(t, err, ErrInvalidObjectiveTitle)
+	_, err = CreateObjective(ctx, db, guild, "40", "Ship side project", "2020-01-01")
+	assert.ErrorIs(t, err, ErrDeadlineInPast)
+	_, err = CreateObjective(ctx, db, guild, "40", "Ship side project", "next march")
+	assert.ErrorIs(t, err, ErrInvalidDate)
+
+	objective, err := CreateObjective(ctx, db, guild, "40", "Ship side project", deadline)
+	require.NoError(t, err)
+	found, err := FindObjective(ctx, db, guild.GuildID, "40", "ship SIDE project")
+	require.NoError(t, err)
+	assert.Equal(t, objective.ID, found.ID)
+	_, err = FindObjective(ctx, db, guild.GuildID, "50", "Ship side project")
+	assert.ErrorIs(t, err, ErrObjectiveNotFound)
+
+	var checkpoints []int64
+	for n := range 3 {
+		checkpoint, err := db.CreateCheckpoint(ctx, queries.CreateCheckpointParams{
+			ScheduledAt: time.Now().Add(time.Duration(n+1) * 24 * time.Hour).UTC().Format(time.RFC3339),
+			ChannelID:   "30",
+			GuildID:     guild.GuildID,
+			DiscordUser: "20",
+		})
+		require.NoError(t, err)
+		checkpoints = append(checkpoints, checkpoint.ID)
+	}
+
+	_, err = LinkGoal(ctx, db, checkpoints[0], "40", objective)
+	assert.ErrorIs(t, err, ErrNoGoalToLink)
+
+	statuses := []string{StatusCompleted, StatusFailed, StatusCompleted}
+	for n, checkpointID := range checkpoints {
+		_, err = SaveGoal(ctx, db, checkpointID, GoalChange{ActorID: "40", UserID: "40", Description: "Work on it", Status: statuses[n]})
+		require.NoError(t, err)
+		goal, err := LinkGoal(ctx, db, checkpointID, "40", objective)
+		require.NoError(t, err)
+		assert.Equal(t, objective.ID, goal.ObjectiveID.Int64)
+	}
+
+	// An unlinked goal no longer counts
+	goal, err := LinkGoal(ctx, db, checkpoints[2], "40", nil)
+	require.NoError(t, err)
+	assert.False(t, goal.ObjectiveID.Valid)
+
+	objectives, err := Objectives(ctx, db, guild.GuildID, "40")
+	require.NoError(t, err)
+	require.Len(t, objectives, 1)
+	assert.Equal(t, int64(2), objectives[0].Goals)
+	assert.Equal(t, int64(2), objectives[0].Checkpoints)
+	assert.Equal(t, int64(1), objectives[0].Completed)
+	assert.Equal(t, 0.5, objectives[0].Progress)
+	assert.False(t, objectives[0].Overdue(time.Now()))
+	assert.True(t, objectives[0].Overdue(time.Now().AddDate(0, 3, 0)))
+
+	require.NoError(t, CompleteObjective(ctx, db, *objective))
+	achieved, err := FindObjective(ctx, db, guild.GuildID, "40", "Ship side project")
+	require.NoError(t, err)
+	assert.Equal(t, ObjectiveAchieved, achieved.Status)
+	assert.ErrorIs(t, CompleteObjective(ctx, db, *achieved), ErrObjectiveAchieved)
+	_, err = LinkGoal(ctx, db, checkpoints[2], "40", achieved)
+	assert.ErrorIs(t, err, ErrObjectiveAchieved)
+
+	// Deleting an objective keeps its goals
+	require.NoError(t, DeleteObjective(ctx, db, *achieved))
+	goals, err := db.GetGoalsByCheckpoint(ctx, checkpoints[0])
+	require.NoError(t, err)
+	require.Len(t, goals, 1)
+	assert.False(t, goals[0].ObjectiveID.Valid)
+}
+
+// TestTooManyObjectives tests that members have a limited number of active objectives
+func TestTooManyObjectives(t *testing.T) {
+	db, guild := setupTestDB(t)
+	ctx := context.Background()
+	deadline := time.Now().AddDate(1, 0, 0).Format("2006-01-02")
+
+	var last *queries.Objective
+	for n := range MaxActiveObjectives {
+		objective, err := CreateObjective(ctx, db, guild, "40", "Objective "+string(rune('A'+n)), deadline)
+		require.NoError(t, err)
+		last = objective
+	}
+	_, err := CreateObjective(ctx, db, guild, "40", "One more", deadline)
+	assert.ErrorIs(t, err, ErrTooManyObjectives)
+
+	// Achieved objectives don't count
+	require.NoError(t, CompleteObjective(ctx, db, *last))
+	_, err = CreateObjective(ctx, db, guild, "40", "One more", deadline)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,263 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/charmbracelet/log"
+	"github.com/metruzanca/checkpoint-bot/internal/database"
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+	"github.com/metruzanca/checkpoint-bot/internal/settings"
+)
+
+const (
+	// VerificationPending is a completed goal awaiting its partner's confirmation, it isn't counted in stats yet
+	VerificationPending  = "pending"
+	VerificationVerified = "verified"
+)
+
+var (
+	ErrSelfPartner     = errors.New("you can't be your own accountability partner")
+	ErrNoPartner       = errors.New("you don't have an accountability partner")
+	ErrNotPartner      = errors.New("only the member's accountability partner can verify this goal")
+	ErrNothingToVerify = errors.New("this goal isn't awaiting verification")
+)
+
+// SetPartner pairs two members of a guild, each member has at most one partner so
+// their previous partners are left without one. Both members have to agree, /partner set
+// only asks and the pair is set once the other member accepts.
+func SetPartner(ctx context.Context, db database.CheckpointDatabase, guildID string, userID string, partnerID string, actorID string) error {
+	if userID == partnerID {
+		return ErrSelfPartner
+	}
+	err := db.SetPartner(ctx, queries.SetPartnerParams{
+		GuildID:     guildID,
+		DiscordUser: userID,
+		PartnerUser: partnerID,
+		CreatedBy:   actorID,
+	})
+	if err != nil {
+		return fmt.Errorf("cannot set partner: %w", err)
+	}
+	log.Info("partners set", "guild", guildID, "user", userID, "partner", partnerID, "actor", actorID)
+	return nil
+}
+
+// RemovePartner unpairs a member and their partner, returning the former partner
+func RemovePartner(ctx context.Context, db database.CheckpointDatabase, guildID string, userID string) (string, error) {
+	partner, err := db.GetPartner(ctx, queries.GetPartnerParams{GuildID: guildID, DiscordUser: userID})
+	if err == sql.ErrNoRows {
+		return "", ErrNoPartner
+	} else if err != nil {
+		return "", fmt.Errorf("cannot get partner: %w", err)
+	}
+	if err := removePartner(ctx, db, guildID, userID); err != nil {
+		return "", err
+	}
+	log.Info("partners removed", "guild", guildID, "user", userID, "partner", partner.PartnerUser)
+	return partner.PartnerUser, nil
+}
+
+// removePartner deletes both rows of the member's pair
+func removePartner(ctx context.Context, db database.CheckpointDatabase, guildID string, userID string) error {
+	partner, err := db.GetPartner(ctx, queries.GetPartnerParams{GuildID: guildID, DiscordUser: userID})
+	if err == sql.ErrNoRows {
+		return ErrNoPartner
+	} else if err != nil {
+		return fmt.Errorf("cannot get partner: %w", err)
+	}
+	for _, member := range []string{userID, partner.PartnerUser} {
+		if _, err := db.DeletePartner(ctx, queries.DeletePartnerParams{GuildID: guildID, DiscordUser: member}); err != nil {
+			return fmt.Errorf("cannot delete partner: %w", err)
+		}
+	}
+	return nil
+}
+
+// GuildPartner returns the partner a member chose with /partner set, or "" without one
+func GuildPartner(ctx context.Context, db database.CheckpointDatabase, guildID string, userID string) (string, error) {
+	partner, err := db.GetPartner(ctx, queries.GetPartnerParams{GuildID: guildID, DiscordUser: userID})
+	if err == sql.ErrNoRows {
+		return "", nil
+	} else if err != nil {
+		return "", fmt.Errorf("cannot get partner: %w", err)
+	}
+	return partner.PartnerUser, nil
+}
+
+// PartnerFor returns a member's partner for a checkpoint, or "" without one.
+// The partner assigned by rotation takes precedence over the one set with /partner set.
+func PartnerFor(ctx context.Context, db database.CheckpointDatabase, checkpoint queries.Checkpoint, userID string) (string, error) {
+	assigned, err := db.GetCheckpointPartner(ctx, queries.GetCheckpointPartnerParams{CheckpointID: checkpoint.ID, DiscordUser: userID})
+	if err == nil {
+		return assigned.PartnerUser, nil
+	} else if err != sql.ErrNoRows {
+		return "", fmt.Errorf("cannot get checkpoint partner: %w", err)
+	}
+	return GuildPartner(ctx, db, checkpoint.GuildID, userID)
+}
+
+// RotatingPairs pairs users for a round of a round-robin, so each round pairs everyone with someone new
+// until every pair has met. With an odd number of users one of them sits the round out.
+func RotatingPairs(users []string, round int) [][2]string {
+	users = slices.Clone(users)
+	slices.Sort(users)
+	users = slices.Compact(users)
+	if len(users)%2 == 1 {
+		users = append(users, "")
+	}
+	n := len(users)
+	if n < 2 {
+		return nil
+	}
+
+	// Circle method: the first user stays in place while the others rotate one position per round
+	rest := users[1:]
+	shift := round % len(rest)
+	rotated := append([]string{users[0]}, append(slices.Clone(rest[len(rest)-shift:]), rest[:len(rest)-shift]...)...)
+
+	var pairs [][2]string
+	for i := 0; i < n/2; i++ {
+		a, b := rotated[i], rotated[n-1-i]
+		if a == "" || b == "" {
+			continue
+		}
+		pairs = append(pairs, [2]string{a, b})
+	}
+	return pairs
+}
+
+// assignRotatingPartners pairs the channel's members for a new checkpoint when the guild has
+// settings.PartnerRotation on. Members are those with a goal for the previous checkpoint or this one.
+// The checkpoint is already created, so failures are only logged.
+func assignRotatingPartners(ctx context.Context, db database.CheckpointDatabase, checkpoint queries.Checkpoint) {
+	guildSettings, err := settings.Load(ctx, db, checkpoint.GuildID)
+	if err != nil {
+		log.Error("cannot load guild settings", "err", err, "guild", checkpoint.GuildID)
+		return
+	}
+	if !guildSettings.PartnerRotation {
+		return
+	}
+
+	var members []string
+	previous, err := db.GetPreviousCheckpointByChannel(ctx, queries.GetPreviousCheckpointByChannelParams{
+		ChannelID: checkpoint.ChannelID,
+		Before:    checkpoint.ScheduledAt,
+	})
+	if err == nil {
+		goals, err := db.GetGoalsByCheckpoint(ctx, previous.ID)
+		if err != nil {
+			log.Error("cannot get previous checkpoint goals", "err", err, "checkpoint_id", previous.ID)
+			return
+		}
+		for _, goal := range goals {
+			members = append(members, goal.DiscordUser)
+		}
+	} else if err != sql.ErrNoRows {
+		log.Error("cannot get previous checkpoint", "err", err, "channel_id", checkpoint.ChannelID)
+		return
+	}
+	goals, err := db.GetGoalsByCheckpoint(ctx, checkpoint.ID)
+	if err != nil {
+		log.Error("cannot get checkpoint goals", "err", err, "checkpoint_id", checkpoint.ID)
+		return
+	}
+	for _, goal := range goals {
+		members = append(members, goal.DiscordUser)
+	}
+
+	round, err := db.CountCheckpointsByChannelBefore(ctx, queries.CountCheckpointsByChannelBeforeParams{
+		ChannelID: checkpoint.ChannelID,
+		Before:    checkpoint.ScheduledAt,
+	})
+	if err != nil {
+		log.Error("cannot count previous checkpoints", "err", err, "channel_id", checkpoint.ChannelID)
+		return
+	}
+
+	pairs := RotatingPairs(members, int(round))
+	for _, pair := range pairs {
+		for _, p := range [][2]string{pair, {pair[1], pair[0]}} {
+			err := db.SetCheckpointPartner(ctx, queries.SetCheckpointPartnerParams{
+				CheckpointID: checkpoint.ID,
+				DiscordUser:  p[0],
+				PartnerUser:  p[1],
+			})
+			if err != nil {
+				log.Error("cannot assign checkpoint partner", "err", err, "checkpoint_id", checkpoint.ID, "user", p[0])
+			}
+		}
+	}
+	if len(pairs) > 0 {
+		log.Info("rotating partners assigned", "checkpoint_id", checkpoint.ID, "round", round, "pairs", len(pairs))
+	}
+}
+
+// VerifyGoal confirms a completed goal as the member's partner, after which it counts in stats
+func VerifyGoal(ctx context.Context, db database.CheckpointDatabase, goalID int64, actorID string) (*queries.Goal, error) {
+	goal, err := db.GetGoal(ctx, goalID)
+	if err == sql.ErrNoRows {
+		return nil, ErrNothingToVerify
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot get goal: %w", err)
+	}
+	checkpoint, err := db.GetCheckpoint(ctx, goal.CheckpointID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get checkpoint: %w", err)
+	}
+	partner, err := PartnerFor(ctx, db, *checkpoint, goal.DiscordUser)
+	if err != nil {
+		return nil, err
+	}
+	if partner != actorID {
+		return nil, ErrNotPartner
+	}
+	if goal.Status != StatusCompleted || goal.Verification != VerificationPending {
+		return nil, ErrNothingToVerify
+	}
+
+	err = db.UpdateGoalVerification(ctx, queries.UpdateGoalVerificationParams{
+		Verification: VerificationVerified,
+		VerifiedBy:   actorID,
+		ID:           goal.ID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot verify goal: %w", err)
+	}
+	log.Info("goal verified", "goal_id", goal.ID, "user", goal.DiscordUser, "partner", actorID)
+	goal.Verification = VerificationVerified
+	goal.VerifiedBy = actorID
+	return goal, nil
+}
+
+// GoalsToVerify returns the guild's completed goals the member has to verify as their partner
+func GoalsToVerify(ctx context.Context, db database.CheckpointDatabase, guildID string, userID string) ([]queries.Goal, error) {
+	goals, err := db.GetGoalsPendingVerification(ctx, guildID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get goals pending verification: %w", err)
+	}
+	checkpoints := make(map[int64]*queries.Checkpoint)
+	var toVerify []queries.Goal
+	for _, goal := range goals {
+		checkpoint, ok := checkpoints[goal.CheckpointID]
+		if !ok {
+			checkpoint, err = db.GetCheckpoint(ctx, goal.CheckpointID)
+			if err != nil {
+				return nil, fmt.Errorf("cannot get checkpoint: %w", err)
+			}
+			checkpoints[goal.CheckpointID] = checkpoint
+		}
+		partner, err := PartnerFor(ctx, db, *checkpoint, goal.DiscordUser)
+		if err != nil {
+			return nil, err
+		}
+		if partner == userID {
+			toVerify = append(toVerify, goal)
+		}
+	}
+	return toVerify, nil
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+	"github.com/metruzanca/checkpoint-bot/internal/settings"
+	"github.com/metruzanca/checkpoint-bot/internal/stats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRotatingPairs tests that every round pairs members with someone new
+func TestRotatingPairs(t *testing.T) {
+	users := []string{"70", "40", "60", "50", "40"}
+	assert.Equal(t, [][2]string{{"40", "70"}, {"50", "60"}}, RotatingPairs(users, 0))
+	assert.Equal(t, [][2]string{{"40", "60"}, {"70", "50"}}, RotatingPairs(users, 1))
+	assert.Equal(t, [][2]string{{"40", "50"}, {"60", "70"}}, RotatingPairs(users, 2))
+	assert.Equal(t, RotatingPairs(users, 0), RotatingPairs(users, 3))
+
+	// With an odd number of members one sits the round out
+	assert.Equal(t, [][2]string{{"50", "60"}}, RotatingPairs([]string{"40", "50", "60"}, 0))
+	assert.Empty(t, RotatingPairs([]string{"40"}, 0))
+	assert.Empty(t, RotatingPairs(nil, 0))
+}
+
+// TestSetPartner tests that pairs are symmetric and setting a new partner breaks up previous pairs
+func TestSetPartner(t *testing.T) {
+	db, guild := setupTestDB(t)
+	ctx := context.Background()
+
+	assert.ErrorIs(t, SetPartner(ctx, db, guild.GuildID, "40", "40", "40"), ErrSelfPartner)
+	require.NoError(t, SetPartner(ctx, db, guild.GuildID, "40", "50", "40"))
+	require.NoError(t, SetPartner(ctx, db, guild.GuildID, "60", "70", "60"))
+
+	partner, err := GuildPartner(ctx, db, guild.GuildID, "50")
+	require.NoError(t, err)
+	assert.Equal(t, "40", partner)
+
+	// 40 pairs with 60, leaving 50 and 70 without a partner
+	require.NoError(t, SetPartner(ctx, db, guild.GuildID, "40", "60", "40"))
+	for user, want := range map[string]string{"40": "60", "60": "40", "50": "", "70": ""} {
+		partner, err := GuildPartner(ctx, db, guild.GuildID, user)
+		require.NoError(t, err)
+		assert.Equal(t, want, partner, user)
+	}
+
+	former, err := RemovePartner(ctx, db, guild.GuildID, "60")
+	require.NoError(t, err)
+	assert.Equal(t, "40", former)
+	_, err = RemovePartner(ctx, db, guild.GuildID, "40")
+	assert.ErrorIs(t, err, ErrNoPartner)
+}
+
+// TestRotatingPartners tests that new checkpoints pair the channel's members when partner_rotation is on
+func TestRotatingPartners(t *testing.T) {
+	db, guild := setupTestDB(t)
+	ctx := context.Background()
+
+	previous, err := db.CreateCheckpoint(ctx, queries.CreateCheckpointParams{
+		ScheduledAt: time.Now().Add(-24 * time.Hour).UTC().Format(time.RFC3339),
+		ChannelID:   "30",
+		GuildID:     guild.GuildID,
+		DiscordUser: "20",
+	})
+	require.NoError(t, err)
+	for _, user := range []string{"40", "50", "60", "70"} {
+		_, err := db.CreateGoal(ctx, queries.CreateGoalParams{DiscordUser: user, Description: "Ship it", CheckpointID: previous.ID})
+		require.NoError(t, err)
+	}
+	require.NoError(t, SetPartner(ctx, db, guild.GuildID, "40", "50", "40"))
+	_, err = settings.Set(ctx, db, guild.GuildID, settings.PartnerRotation, "on", "20")
+	require.NoError(t, err)
+
+	tomorrow := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+	checkpoint, err := CreateCheckpoint(ctx, db, guild, NewCheckpoint{ChannelID: "30", UserID: "20", Date: tomorrow, Time: "19:00"})
+	require.NoError(t, err)
+
+	// The second checkpoint of the channel uses the second round, overriding /partner set
+	for user, want := range map[string]string{"40": "60", "60": "40", "50": "70", "70": "50", "80": ""} {
+		partner, err := PartnerFor(ctx, db, *checkpoint, user)
+		require.NoError(t, err)
+		assert.Equal(t, want, partner, user)
+	}
+}
+
+// TestVerifyGoal tests that completed goals of members with a partner only count once the partner verifies them
+func TestVerifyGoal(t *testing.T) {
+	db, guild := setupTestDB(t)
+	ctx := context.Background()
+
+	checkpoint, err := db.CreateCheckpoint(ctx, queries.CreateCheckpointParams{
+		ScheduledAt: time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339),
+		ChannelID:   "30",
+		GuildID:     guild.GuildID,
+		DiscordUser: "20",
+	})
+	require.NoError(t, err)
+	require.NoError(t, SetPartner(ctx, db, guild.GuildID, "40", "50", "40"))
+
+	result, err := SaveGoal(ctx, db, checkpoint.ID, GoalChange{ActorID: "40", UserID: "40", Description: "Ship it", Status: StatusCompleted})
+	require.NoError(t, err)
+	assert.Equal(t, VerificationPending, result.Goal.Verification)
+	goalID := result.Goal.ID
+
+	userStats, err := stats.ForUser(ctx, db, guild.GuildID, "40")
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), userStats.Completed)
+	assert.Equal(t, int64(1), userStats.Unverified)
+
+	toVerify, err := GoalsToVerify(ctx, db, guild.GuildID, "50")
+	require.NoError(t, err)
+	require.Len(t, toVerify, 1)
+	assert.Equal(t, goalID, toVerify[0].ID)
+
+	_, err = VerifyGoal(ctx, db, goalID, "40")
+	assert.ErrorIs(t, err, ErrNotPartner)
+	goal, err := VerifyGoal(ctx, db, goalID, "50")
+	require.NoError(t, err)
+	assert.Equal(t, VerificationVerified, goal.Verification)
+	_, err = VerifyGoal(ctx, db, goalID, "50")
+	assert.ErrorIs(t, err, ErrNothingToVerify)
+
+	userStats, err = stats.ForUser(ctx, db, guild.GuildID, "40")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), userStats.Completed)
+	assert.Equal(t, int64(0), userStats.Unverified)
+
+	// Reopening the goal clears the verification
+	result, err = SaveGoal(ctx, db, checkpoint.ID, GoalChange{ActorID: "40", UserID: "40", Status: StatusIncomplete})
+	require.NoError(t, err)
+	assert.Empty(t, result.Goal.Verification)
+
+	// Members without a partner complete goals right away
+	result, err = SaveGoal(ctx, db, checkpoint.ID, GoalChange{ActorID: "60", UserID: "60", Description: "Read", Status: StatusCompleted})
+	require.NoError(t, err)
+	assert.Empty(t, result.Goal.Verification)
+}
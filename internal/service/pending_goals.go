@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/log"
+	"github.com/metruzanca/checkpoint-bot/internal/audit"
+	"github.com/metruzanca/checkpoint-bot/internal/database"
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+	"github.com/metruzanca/checkpoint-bot/internal/settings"
+)
+
+// SavePendingGoal sets a user's goal for the next checkpoint scheduled in the channel,
+// it becomes a regular goal when CreateCheckpoint schedules one
+func SavePendingGoal(ctx context.Context, db database.CheckpointDatabase, guildID string, channelID string, change GoalChange) (*queries.PendingGoal, error) {
+	if err := CanEditGoal(change.ActorID, change.UserID, change.Moderator); err != nil {
+		return nil, err
+	}
+	if change.Status != "" || change.Progress != nil {
+		return nil, ErrPendingGoalStatus
+	}
+	var target float64
+	if change.Target != nil {
+		if !validTarget(*change.Target, change.Unit) {
+			return nil, ErrInvalidTarget
+		}
+		target = *change.Target
+	}
+	if len(change.Tags) > MaxGoalTags {
+		return nil, ErrTooManyTags
+	}
+	if change.Visibility != "" && !ValidVisibility(change.Visibility) {
+		return nil, ErrInvalidVisibility
+	}
+	if change.Description == "" {
+		return nil, ErrEmptyGoal
+	}
+	if utf8.RuneCountInString(change.Description) > GoalMaxLength {
+		return nil, ErrGoalTooLong
+	}
+
+	pending, err := db.SavePendingGoal(ctx, queries.SavePendingGoalParams{
+		GuildID:     guildID,
+		ChannelID:   channelID,
+		DiscordUser: change.UserID,
+		Description: change.Description,
+		Target:      target,
+		Unit:        change.Unit,
+		Tags:        strings.Join(change.Tags, ","),
+		Visibility:  change.Visibility,
+		UpdatedBy:   change.ActorID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot save pending goal: %w", err)
+	}
+
+	if change.ActorID != change.UserID {
+		// An empty visibility is the guild's default, hidden if it can't be loaded
+		visibility, err := newGoalVisibility(ctx, db, guildID, pending.Visibility)
+		if err != nil {
+			log.Error("cannot get goal visibility for audit entry", "err", err, "guild_id", guildID)
+			visibility = settings.VisibilityPrivate
+		}
+		audit.Record(ctx, db, audit.Entry{
+			GuildID:    guildID,
+			ActorID:    change.ActorID,
+			Source:     change.Source,
+			Action:     audit.GoalOverride,
+			TargetType: audit.TargetPendingGoal,
+			TargetID:   strconv.FormatInt(pending.ID, 10),
+			TargetUser: change.UserID,
+			After:      goalAuditText(change.Description, visibility),
+		})
+	}
+	return pending, nil
+}
+
+// attachPendingGoals turns the channel's pending goals into goals of the new checkpoint.
+// The checkpoint is already created, so failures are only logged.
+func attachPendingGoals(ctx context.Context, db database.CheckpointDatabase, checkpoint queries.Checkpoint) {
+	pendingGoals, err := db.GetPendingGoalsByChannel(ctx, checkpoint.ChannelID)
+	if err != nil {
+		log.Error("cannot get pending goals", "err", err, "channel_id", checkpoint.ChannelID)
+		return
+	}
+
+	for _, pending := range pendingGoals {
+		// Pending goals without a visibility get the guild's default when they're attached
+		visibility, err := newGoalVisibility(ctx, db, checkpoint.GuildID, pending.Visibility)
+		if err != nil {
+			log.Error("cannot get pending goal visibility", "err", err, "pending_goal_id", pending.ID, "checkpoint_id", checkpoint.ID)
+			// Better hide a goal meant to be public than show one meant to be private
+			visibility = settings.VisibilityPrivate
+		}
+		goal, err := db.CreateGoal(ctx, queries.CreateGoalParams{
+			DiscordUser:  pending.DiscordUser,
+			Description:  pending.Description,
+			CheckpointID: checkpoint.ID,
+			Visibility:   visibility,
+		})
+		if err != nil {
+			log.Error("cannot attach pending goal", "err", err, "pending_goal_id", pending.ID, "checkpoint_id", checkpoint.ID)
+			continue
+		}
+		if pending.Target > 0 {
+			err := db.UpdateGoalTarget(ctx, queries.UpdateGoalTargetParams{
+				Target:       pending.Target,
+				Unit:         pending.Unit,
+				CheckpointID: checkpoint.ID,
+				DiscordUser:  pending.DiscordUser,
+			})
+			if err != nil {
+				log.Error("cannot set pending goal target", "err", err, "pending_goal_id", pending.ID, "checkpoint_id", checkpoint.ID)
+			}
+		}
+		if pending.Tags != "" {
+			if err := db.SetGoalTags(ctx, goal.ID, strings.Split(pending.Tags, ",")); err != nil {
+				log.Error("cannot set pending goal tags", "err", err, "pending_goal_id", pending.ID, "checkpoint_id", checkpoint.ID)
+			}
+		}
+		recordGoalRevision(ctx, db, *goal, pending.UpdatedBy)
+		if err := db.DeletePendingGoal(ctx, pending.ID); err != nil {
+			log.Error("cannot delete attached pending goal", "err", err, "pending_goal_id", pending.ID)
+		}
+	}
+	if len(pendingGoals) > 0 {
+		log.Info("pending goals attached", "checkpoint_id", checkpoint.ID, "count", len(pendingGoals))
+	}
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPendingGoals tests goals set before a checkpoint is scheduled and their attachment to the next one
+func TestPendingGoals(t *testing.T) {
+	db, guild := setupTestDB(t)
+	ctx := context.Background()
+	tomorrow := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+
+	_, err := SavePendingGoal(ctx, db, guild.GuildID, "30", GoalChange{ActorID: "40", UserID: "40", Status: StatusCompleted})
+	assert.ErrorIs(t, err, ErrPendingGoalStatus)
+	_, err = SavePendingGoal(ctx, db, guild.GuildID, "30", GoalChange{ActorID: "40", UserID: "40"})
+	assert.ErrorIs(t, err, ErrEmptyGoal)
+	_, err = SavePendingGoal(ctx, db, guild.GuildID, "30", GoalChange{ActorID: "50", UserID: "40", Description: "Not mine"})
+	assert.ErrorIs(t, err, ErrNotAllowed)
+
+	_, err = SavePendingGoal(ctx, db, guild.GuildID, "30", GoalChange{ActorID: "40", UserID: "40", Description: "Plan ahead"})
+	require.NoError(t, err)
+	target := 20.0
+	pending, err := SavePendingGoal(ctx, db, guild.GuildID, "30", GoalChange{ActorID: "40", UserID: "40", Description: "Plan further ahead", Target: &target, Unit: "km"})
+	require.NoError(t, err)
+	assert.Equal(t, "Plan further ahead", pending.Description)
+	_, err = SavePendingGoal(ctx, db, guild.GuildID, "31", GoalChange{ActorID: "40", UserID: "40", Description: "Other channel"})
+	require.NoError(t, err)
+
+	checkpoint, err := CreateCheckpoint(ctx, db, guild, NewCheckpoint{ChannelID: "30", UserID: "20", Date: tomorrow, Time: "19:00"})
+	require.NoError(t, err)
+
+	goals, err := db.GetGoalsByCheckpoint(ctx, checkpoint.ID)
+	require.NoError(t, err)
+	require.Len(t, goals, 1)
+	assert.Equal(t, "40", goals[0].DiscordUser)
+	assert.Equal(t, "Plan further ahead", goals[0].Description)
+	assert.Equal(t, 20.0, goals[0].Target)
+	assert.Equal(t, "km", goals[0].Unit)
+
+	revisions, err := db.GetGoalRevisions(ctx, goals[0].ID)
+	require.NoError(t, err)
+	assert.Len(t, revisions, 1)
+
+	// Attached goals are no longer pending, the other channel's goal still is
+	remaining, err := db.GetPendingGoalsByChannel(ctx, "30")
+	require.NoError(t, err)
+	assert.Empty(t, remaining)
+	_, err = db.GetPendingGoal(ctx, queries.GetPendingGoalParams{ChannelID: "31", DiscordUser: "40"})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,69 @@
+package service
+
+import "strings"
+
+// DiffOp tells whether a word of a diff was kept, removed or added
+type DiffOp int
+
+const (
+	DiffEqual DiffOp = iota
+	DiffRemoved
+	DiffAdded
+)
+
+// DiffPart is a run of consecutive words with the same DiffOp
+type DiffPart struct {
+	Op   DiffOp
+	Text string
+}
+
+// DiffWords compares two goal descriptions word by word, whitespace is normalized to single spaces
+func DiffWords(before string, after string) []DiffPart {
+	a, b := strings.Fields(before), strings.Fields(after)
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var parts []DiffPart
+	add := func(op DiffOp, word string) {
+		if n := len(parts); n > 0 && parts[n-1].Op == op {
+			parts[n-1].Text += " " + word
+			return
+		}
+		parts = append(parts, DiffPart{Op: op, Text: word})
+	}
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			add(DiffEqual, a[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			add(DiffRemoved, a[i])
+			i++
+		default:
+			add(DiffAdded, b[j])
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		add(DiffRemoved, a[i])
+	}
+	for ; j < len(b); j++ {
+		add(DiffAdded, b[j])
+	}
+	return parts
+}
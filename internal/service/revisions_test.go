@@ -0,0 +1,21 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDiffWords tests word diffs between goal descriptions
+func TestDiffWords(t *testing.T) {
+	assert.Equal(t, []DiffPart{
+		{DiffEqual, "Ship the"},
+		{DiffRemoved, "whole"},
+		{DiffEqual, "dashboard"},
+		{DiffAdded, "login page"},
+	}, DiffWords("Ship the whole dashboard", "Ship the  dashboard\nlogin page"))
+
+	assert.Equal(t, []DiffPart{{DiffRemoved, "Write tests"}, {DiffAdded, "Rest"}}, DiffWords("Write tests", "Rest"))
+	assert.Equal(t, []DiffPart{{DiffAdded, "New goal"}}, DiffWords("", "New goal"))
+	assert.Nil(t, DiffWords("", ""))
+}
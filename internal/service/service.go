@@ -0,0 +1,532 @@
+// service package holds the checkpoint and goal rules shared by the slash commands and the REST API.
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/log"
+	"github.com/metruzanca/checkpoint-bot/internal/audit"
+	"github.com/metruzanca/checkpoint-bot/internal/database"
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+	"github.com/metruzanca/checkpoint-bot/internal/settings"
+	"github.com/metruzanca/checkpoint-bot/internal/util"
+)
+
+const (
+	StatusIncomplete = "incomplete"
+	StatusCompleted  = "completed"
+	StatusFailed     = "failed"
+	// StatusPartial is a goal that's partly done, see queries.Goal.Progress
+	StatusPartial = "partial"
+
+	// GoalMaxLength matches the length limit of the goal modal's text input
+	GoalMaxLength = 2000
+)
+
+var (
+	ErrInvalidDate           = errors.New("date is not a valid date (expected YYYY-MM-DD)")
+	ErrInvalidTime           = errors.New("time is not a valid time (expected HH:MM or H:MM AM/PM)")
+	ErrCheckpointInPast      = errors.New("cannot schedule a checkpoint in the past, please pick a future date and time")
+	ErrNoUpcomingCheckpoint  = errors.New("no upcoming checkpoint found for this channel")
+	ErrCheckpointNotFound    = errors.New("checkpoint not found")
+	ErrCheckpointNotUpcoming = errors.New("goals can only be changed for an upcoming checkpoint")
+	ErrNotAllowed            = errors.New("you don't have permission to edit other users' goals")
+	ErrInvalidStatus         = errors.New("status must be incomplete, partial, completed or failed")
+	ErrInvalidProgress       = errors.New("progress must be between 0 and 100")
+	ErrGoalNotFound          = errors.New("you must create a goal first before setting its status")
+	ErrEmptyGoal             = errors.New("goal text cannot be empty")
+	ErrGoalTooLong           = fmt.Errorf("goal text cannot be longer than %d characters", GoalMaxLength)
+	ErrGoalsLocked           = errors.New("goals are locked for this checkpoint, only administrators can change them now")
+	ErrPendingGoalStatus     = errors.New("a goal's status can only be set once its checkpoint is scheduled")
+	ErrInvalidTarget         = fmt.Errorf("target must be a positive number followed by an optional unit of up to %d characters, e.g. 20 km", UnitMaxLength)
+	ErrNoTarget              = errors.New("you must set a goal with a target first, e.g. 20 km")
+	ErrInvalidAmount         = errors.New("amount must not be zero or bring the total below zero")
+	ErrNoteTooLong           = fmt.Errorf("note cannot be longer than %d characters", NoteMaxLength)
+)
+
+// CheckpointExistsError is returned when a checkpoint can't be created because of another one in the channel
+type CheckpointExistsError struct {
+	Checkpoint queries.Checkpoint
+	// Duplicate is true when the existing checkpoint is scheduled at the same time,
+	// false when it's the channel's upcoming checkpoint
+	Duplicate bool
+}
+
+func (e *CheckpointExistsError) Error() string {
+	if e.Duplicate {
+		return "a checkpoint already exists for this time"
+	}
+	return "an upcoming checkpoint already exists for this channel"
+}
+
+// NewCheckpoint describes a checkpoint to schedule, date and time are parsed in the guild's timezone
+type NewCheckpoint struct {
+	ChannelID string
+	UserID    string
+	Date      string
+	Time      string
+}
+
+// ScheduleTime parses a date (YYYY-MM-DD) and time (HH:MM or H:MM AM/PM) in the guild's timezone
+// and checks it's in the future
+func ScheduleTime(guild queries.Guild, dateStr string, timeStr string) (time.Time, error) {
+	date, err := util.ParseDate(dateStr)
+	if err != nil {
+		return time.Time{}, ErrInvalidDate
+	}
+	hour, minute, err := util.ParseTime(timeStr)
+	if err != nil {
+		return time.Time{}, ErrInvalidTime
+	}
+
+	scheduledAt := time.Date(date.Year(), date.Month(), date.Day(), hour, minute, 0, 0, util.GuildLocation(guild))
+	if scheduledAt.Before(time.Now()) {
+		return time.Time{}, ErrCheckpointInPast
+	}
+	return scheduledAt, nil
+}
+
+// CreateCheckpoint schedules a checkpoint in the future.
+// Unless the guild allows multiple upcoming checkpoints, a channel can only have one
+// and a *CheckpointExistsError is returned otherwise.
+func CreateCheckpoint(ctx context.Context, db database.CheckpointDatabase, guild queries.Guild, params NewCheckpoint) (*queries.Checkpoint, error) {
+	scheduledAt, err := ScheduleTime(guild, params.Date, params.Time)
+	if err != nil {
+		return nil, err
+	}
+	scheduledAtStr := scheduledAt.Format(time.RFC3339)
+
+	guildSettings, err := settings.Load(ctx, db, guild.GuildID)
+	if err != nil {
+		return nil, err
+	}
+	if !guildSettings.AllowMultipleUpcoming {
+		upcoming, err := db.GetUpcomingCheckpointByGuildAndChannel(ctx, queries.GetUpcomingCheckpointByGuildAndChannelParams{
+			GuildID:   guild.GuildID,
+			ChannelID: params.ChannelID,
+		})
+		if err == nil {
+			return nil, &CheckpointExistsError{Checkpoint: *upcoming}
+		} else if err != sql.ErrNoRows {
+			return nil, fmt.Errorf("cannot check for existing upcoming checkpoint: %w", err)
+		}
+	}
+
+	// Check for exact duplicate (same scheduled_at and channel) before attempting insert
+	duplicate, err := db.GetCheckpointByScheduledAtAndChannel(ctx, queries.GetCheckpointByScheduledAtAndChannelParams{
+		ScheduledAt: scheduledAtStr,
+		ChannelID:   params.ChannelID,
+	})
+	if err == nil {
+		return nil, &CheckpointExistsError{Checkpoint: *duplicate, Duplicate: true}
+	} else if err != sql.ErrNoRows {
+		return nil, fmt.Errorf("cannot check for duplicate checkpoint: %w", err)
+	}
+
+	checkpoint, err := db.CreateCheckpoint(ctx, queries.CreateCheckpointParams{
+		ScheduledAt: scheduledAtStr,
+		ChannelID:   params.ChannelID,
+		GuildID:     guild.GuildID,
+		DiscordUser: params.UserID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot create checkpoint: %w", err)
+	}
+	attachPendingGoals(ctx, db, *checkpoint)
+	assignRotatingPartners(ctx, db, *checkpoint)
+	return checkpoint, nil
+}
+
+// UpcomingCheckpoint returns the channel's upcoming checkpoint, or ErrNoUpcomingCheckpoint
+func UpcomingCheckpoint(ctx context.Context, db database.CheckpointDatabase, guildID string, channelID string) (*queries.Checkpoint, error) {
+	checkpoint, err := db.GetUpcomingCheckpointByGuildAndChannel(ctx, queries.GetUpcomingCheckpointByGuildAndChannelParams{
+		GuildID:   guildID,
+		ChannelID: channelID,
+	})
+	if err == sql.ErrNoRows {
+		return nil, ErrNoUpcomingCheckpoint
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot get upcoming checkpoint: %w", err)
+	}
+	return checkpoint, nil
+}
+
+// GoalCheckpoint returns a checkpoint of the guild whose goals can still be changed
+func GoalCheckpoint(ctx context.Context, db database.CheckpointDatabase, guildID string, checkpointID int64) (*queries.Checkpoint, error) {
+	checkpoint, err := db.GetCheckpoint(ctx, checkpointID)
+	if err == sql.ErrNoRows || (err == nil && checkpoint.GuildID != guildID) {
+		return nil, ErrCheckpointNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot get checkpoint: %w", err)
+	}
+
+	scheduledAt, err := time.Parse(time.RFC3339, checkpoint.ScheduledAt)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse checkpoint scheduled_at: %w", err)
+	}
+	if !scheduledAt.After(time.Now()) {
+		return nil, ErrCheckpointNotUpcoming
+	}
+	return checkpoint, nil
+}
+
+// CanEditGoal checks that actorID may edit userID's goals, only moderators can edit other users' goals
+func CanEditGoal(actorID string, userID string, moderator bool) error {
+	if actorID != userID && !moderator {
+		return ErrNotAllowed
+	}
+	return nil
+}
+
+// ValidStatus reports whether status is one of the goal statuses
+func ValidStatus(status string) bool {
+	switch status {
+	case StatusIncomplete, StatusCompleted, StatusFailed, StatusPartial:
+		return true
+	}
+	return false
+}
+
+// StatusForProgress is the status of a goal with progress set: completed at 100, partial in between
+func StatusForProgress(progress int64) string {
+	switch {
+	case progress >= 100:
+		return StatusCompleted
+	case progress > 0:
+		return StatusPartial
+	}
+	return StatusIncomplete
+}
+
+// GoalChange describes an edit of a user's goal, empty fields are left unchanged
+type GoalChange struct {
+	// ActorID is the user making the change, Moderator allows them to edit other users' goals
+	// (see permissions.ModerateGoals)
+	ActorID   string
+	Moderator bool
+	// OverrideLock allows setting and editing goals after the checkpoint's goal lock (see GoalLockTime)
+	OverrideLock bool
+	// Source is recorded in the audit log, see audit.SourceDiscord
+	Source string
+
+	UserID      string
+	Description string
+	Status      string
+	// Progress is between 0 and 100, nil leaves it unchanged.
+	// Without a Status it also sets the status, see StatusForProgress.
+	Progress *int64
+	// Target is the amount of Unit to reach (see ParseTarget), 0 removes it and nil leaves both unchanged
+	Target *float64
+	Unit   string
+	// Tags replace the goal's tags (see ParseTags), nil leaves them unchanged
+	Tags []string
+	// Visibility is one of the settings.Visibility values, new goals get the guild's goal_visibility setting by default
+	Visibility string
+}
+
+// GoalResult is the goal after a change
+type GoalResult struct {
+	Goal    queries.Goal
+	Created bool
+}
+
+// SaveGoal creates or updates a user's goal for a checkpoint and optionally sets its status.
+// Setting only the status requires the goal to exist.
+func SaveGoal(ctx context.Context, db database.CheckpointDatabase, checkpointID int64, change GoalChange) (*GoalResult, error) {
+	if err := CanEditGoal(change.ActorID, change.UserID, change.Moderator); err != nil {
+		return nil, err
+	}
+	if change.Status != "" && !ValidStatus(change.Status) {
+		return nil, ErrInvalidStatus
+	}
+	if change.Progress != nil && (*change.Progress < 0 || *change.Progress > 100) {
+		return nil, ErrInvalidProgress
+	}
+	if change.Target != nil && !validTarget(*change.Target, change.Unit) {
+		return nil, ErrInvalidTarget
+	}
+	if len(change.Tags) > MaxGoalTags {
+		return nil, ErrTooManyTags
+	}
+	if change.Visibility != "" && !ValidVisibility(change.Visibility) {
+		return nil, ErrInvalidVisibility
+	}
+	status, progress := change.Status, change.Progress
+	if status == "" && progress != nil {
+		status = StatusForProgress(*progress)
+	}
+	if utf8.RuneCountInString(change.Description) > GoalMaxLength {
+		return nil, ErrGoalTooLong
+	}
+
+	result := &GoalResult{}
+	existing, err := db.GetGoalByCheckpointAndUser(ctx, queries.GetGoalByCheckpointAndUserParams{
+		CheckpointID: checkpointID,
+		DiscordUser:  change.UserID,
+	})
+	if err == sql.ErrNoRows {
+		if change.Description == "" {
+			if status != "" || change.Visibility != "" {
+				return nil, ErrGoalNotFound
+			}
+			return nil, ErrEmptyGoal
+		}
+		if err := checkGoalLock(ctx, db, checkpointID, change); err != nil {
+			return nil, err
+		}
+		checkpoint, err := db.GetCheckpoint(ctx, checkpointID)
+		if err != nil {
+			return nil, fmt.Errorf("cannot get checkpoint: %w", err)
+		}
+		visibility, err := newGoalVisibility(ctx, db, checkpoint.GuildID, change.Visibility)
+		if err != nil {
+			return nil, err
+		}
+		goal, err := db.CreateGoal(ctx, queries.CreateGoalParams{
+			DiscordUser:  change.UserID,
+			Description:  change.Description,
+			CheckpointID: checkpointID,
+			Visibility:   visibility,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("cannot create goal: %w", err)
+		}
+		log.Info("goal created", "checkpoint_id", checkpointID, "user", change.UserID, "actor", change.ActorID)
+		result.Goal = *goal
+		result.Created = true
+		if change.ActorID != change.UserID {
+			recordGoalChange(ctx, db, checkpointID, result.Goal.ID, change, audit.GoalOverride, "", goalAuditText(change.Description, visibility))
+		}
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot check for existing goal: %w", err)
+	} else {
+		result.Goal = *existing
+		if change.Description != "" && change.Description != existing.Description {
+			if err := checkGoalLock(ctx, db, checkpointID, change); err != nil {
+				return nil, err
+			}
+			err = db.UpdateGoalDescription(ctx, queries.UpdateGoalDescriptionParams{
+				Description:  change.Description,
+				CheckpointID: checkpointID,
+				DiscordUser:  change.UserID,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("cannot update goal: %w", err)
+			}
+			log.Info("goal updated", "checkpoint_id", checkpointID, "user", change.UserID, "actor", change.ActorID)
+			result.Goal.Description = change.Description
+			if change.ActorID != change.UserID {
+				// A goal made public by this change was still hidden before it
+				visibility := existing.Visibility
+				if change.Visibility != "" && change.Visibility != settings.VisibilityPublic {
+					visibility = change.Visibility
+				}
+				recordGoalChange(ctx, db, checkpointID, result.Goal.ID, change, audit.GoalOverride,
+					goalAuditText(existing.Description, visibility), goalAuditText(change.Description, visibility))
+			}
+		}
+	}
+
+	if change.Target != nil && (*change.Target != result.Goal.Target || change.Unit != result.Goal.Unit) {
+		if err := checkGoalLock(ctx, db, checkpointID, change); err != nil {
+			return nil, err
+		}
+		err = db.UpdateGoalTarget(ctx, queries.UpdateGoalTargetParams{
+			Target:       *change.Target,
+			Unit:         change.Unit,
+			CheckpointID: checkpointID,
+			DiscordUser:  change.UserID,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("cannot update goal target: %w", err)
+		}
+		log.Info("goal target updated", "checkpoint_id", checkpointID, "user", change.UserID, "target", *change.Target, "unit", change.Unit, "actor", change.ActorID)
+		if change.ActorID != change.UserID && !result.Created {
+			recordGoalChange(ctx, db, checkpointID, result.Goal.ID, change, audit.GoalOverride,
+				FormatTarget(result.Goal.Total, result.Goal.Target, result.Goal.Unit), FormatTarget(result.Goal.Total, *change.Target, change.Unit))
+		}
+		result.Goal.Target = *change.Target
+		result.Goal.Unit = change.Unit
+
+		// A new target moves the goal's progress, unless it's being set explicitly
+		if result.Goal.Target > 0 && status == "" && progress == nil {
+			targetProgress := TargetProgress(result.Goal.Total, result.Goal.Target)
+			progress = &targetProgress
+			status = StatusForProgress(targetProgress)
+		}
+	}
+	// Tags only sort goals for stats, so they can be changed after the goal lock
+	if change.Tags != nil {
+		if err := saveGoalTags(ctx, db, result.Goal.ID, change.Tags); err != nil {
+			return nil, err
+		}
+	}
+	// Neither does the visibility change the goal
+	if change.Visibility != "" {
+		if err := setGoalVisibility(ctx, db, &result.Goal, change.Visibility); err != nil {
+			return nil, err
+		}
+	}
+	if status == StatusCompleted {
+		done := int64(100)
+		progress = &done
+	}
+
+	if status != "" && status != result.Goal.Status {
+		// The verification is updated first so the status change event tells partners to verify the goal
+		verification, err := goalVerification(ctx, db, checkpointID, change.UserID, status)
+		if err != nil {
+			return nil, err
+		}
+		if verification != result.Goal.Verification {
+			err = db.UpdateGoalVerification(ctx, queries.UpdateGoalVerificationParams{Verification: verification, ID: result.Goal.ID})
+			if err != nil {
+				return nil, fmt.Errorf("cannot update goal verification: %w", err)
+			}
+			// Completing the goal again needs new confirmations
+			if verification == "" {
+				if err := db.DeleteGoalVerifications(ctx, result.Goal.ID); err != nil {
+					return nil, fmt.Errorf("cannot delete goal verifications: %w", err)
+				}
+			}
+			result.Goal.Verification = verification
+			result.Goal.VerifiedBy = ""
+		}
+		err = db.UpdateGoalStatus(ctx, queries.UpdateGoalStatusParams{
+			Status:       status,
+			CheckpointID: checkpointID,
+			DiscordUser:  change.UserID,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("cannot update goal status: %w", err)
+		}
+		log.Info("goal status updated", "checkpoint_id", checkpointID, "user", change.UserID, "status", status, "actor", change.ActorID)
+		recordGoalChange(ctx, db, checkpointID, result.Goal.ID, change, audit.GoalStatusChanged, result.Goal.Status, status)
+		result.Goal.Status = status
+	}
+
+	previousProgress := result.Goal.Progress
+	if progress != nil && *progress != result.Goal.Progress {
+		err = db.UpdateGoalProgress(ctx, queries.UpdateGoalProgressParams{
+			Progress:     *progress,
+			CheckpointID: checkpointID,
+			DiscordUser:  change.UserID,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("cannot update goal progress: %w", err)
+		}
+		log.Info("goal progress updated", "checkpoint_id", checkpointID, "user", change.UserID, "progress", *progress, "actor", change.ActorID)
+		result.Goal.Progress = *progress
+	}
+
+	if result.Created || result.Goal.Description != existing.Description || result.Goal.Status != existing.Status || result.Goal.Progress != previousProgress {
+		recordGoalRevision(ctx, db, result.Goal, change.ActorID)
+	}
+	return result, nil
+}
+
+// recordGoalChange adds an audit entry about a user's goal, looking up the checkpoint's guild
+func recordGoalChange(ctx context.Context, db database.CheckpointDatabase, checkpointID int64, goalID int64, change GoalChange, action string, before string, after string) {
+	checkpoint, err := db.GetCheckpoint(ctx, checkpointID)
+	if err != nil {
+		log.Error("cannot get checkpoint for audit entry", "err", err, "checkpoint_id", checkpointID)
+		return
+	}
+	audit.Record(ctx, db, audit.Entry{
+		GuildID:    checkpoint.GuildID,
+		ActorID:    change.ActorID,
+		Source:     change.Source,
+		Action:     action,
+		TargetType: audit.TargetGoal,
+		TargetID:   strconv.FormatInt(goalID, 10),
+		TargetUser: change.UserID,
+		Before:     before,
+		After:      after,
+	})
+}
+
+// GoalLockTime returns when the checkpoint's goals can no longer be set or edited, see settings.GoalLock
+func GoalLockTime(checkpoint queries.Checkpoint, guildSettings settings.Settings) (time.Time, error) {
+	scheduledAt, err := time.Parse(time.RFC3339, checkpoint.ScheduledAt)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("cannot parse checkpoint scheduled_at: %w", err)
+	}
+	return scheduledAt.Add(-guildSettings.GoalLock), nil
+}
+
+// CheckGoalLock returns ErrGoalsLocked once the checkpoint's goals are locked, unless overrideLock is set.
+// Statuses can still be changed after the lock.
+func CheckGoalLock(ctx context.Context, db database.CheckpointDatabase, checkpoint queries.Checkpoint, overrideLock bool) error {
+	if overrideLock {
+		return nil
+	}
+	guildSettings, err := settings.Load(ctx, db, checkpoint.GuildID)
+	if err != nil {
+		return err
+	}
+	lockTime, err := GoalLockTime(checkpoint, guildSettings)
+	if err != nil {
+		return err
+	}
+	if !time.Now().Before(lockTime) {
+		return ErrGoalsLocked
+	}
+	return nil
+}
+
+func checkGoalLock(ctx context.Context, db database.CheckpointDatabase, checkpointID int64, change GoalChange) error {
+	if change.OverrideLock {
+		return nil
+	}
+	checkpoint, err := db.GetCheckpoint(ctx, checkpointID)
+	if err != nil {
+		return fmt.Errorf("cannot get checkpoint: %w", err)
+	}
+	return CheckGoalLock(ctx, db, *checkpoint, false)
+}
+
+// recordGoalRevision snapshots the goal after a change. The change already happened, so failures are only logged.
+func recordGoalRevision(ctx context.Context, db database.CheckpointDatabase, goal queries.Goal, actorID string) {
+	_, err := db.CreateGoalRevision(ctx, queries.CreateGoalRevisionParams{
+		GoalID:      goal.ID,
+		Description: goal.Description,
+		Status:      goal.Status,
+		Progress:    goal.Progress,
+		EditedBy:    actorID,
+	})
+	if err != nil {
+		log.Error("cannot record goal revision", "err", err, "goal_id", goal.ID, "actor", actorID)
+	}
+}
+
+// IsUserError reports whether err is caused by the request rather than by the bot,
+// its message can be shown to the user as is
+func IsUserError(err error) bool {
+	var existsErr *CheckpointExistsError
+	if errors.As(err, &existsErr) {
+		return true
+	}
+	for _, userErr := range []error{
+		ErrInvalidDate, ErrInvalidTime, ErrCheckpointInPast, ErrNoUpcomingCheckpoint, ErrCheckpointNotFound,
+		ErrCheckpointNotUpcoming, ErrNotAllowed, ErrInvalidStatus, ErrGoalNotFound, ErrEmptyGoal, ErrGoalTooLong, ErrGoalsLocked,
+		ErrPendingGoalStatus, ErrInvalidProgress, ErrInvalidTarget, ErrNoTarget, ErrInvalidAmount, ErrNoteTooLong,
+		ErrEmptyUpdate, ErrUpdateTooLong, ErrUpdateClosed, ErrInvalidPromptInterval,
+		ErrSelfPartner, ErrNoPartner, ErrNotPartner, ErrNothingToVerify, ErrSelfConfirm, ErrAlreadyConfirmed,
+		ErrNoEvidence, ErrInvalidEvidenceURL, ErrTooMuchEvidence, ErrNoGoalForProof,
+		ErrInvalidTemplateName, ErrTemplateNotFound, ErrTooManyTemplates, ErrGuildTemplateNotAllowed,
+		ErrInvalidTag, ErrTooManyTags, ErrNotACategory, ErrNoCategories, ErrNoGoalToTag,
+		ErrInvalidObjectiveTitle, ErrDeadlineInPast, ErrObjectiveNotFound, ErrTooManyObjectives, ErrObjectiveAchieved, ErrNoGoalToLink,
+		ErrInvalidVisibility,
+	} {
+		if errors.Is(err, userErr) {
+			return true
+		}
+	}
+	return false
+}
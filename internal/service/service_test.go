@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/metruzanca/checkpoint-bot/internal/audit"
+	"github.com/metruzanca/checkpoint-bot/internal/database"
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+	"github.com/metruzanca/checkpoint-bot/internal/database/sqlite"
+	"github.com/metruzanca/checkpoint-bot/internal/settings"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupTestDB creates a file SQLite database with a guild
+func setupTestDB(t *testing.T) (database.CheckpointDatabase, queries.Guild) {
+	t.Helper()
+
+	db := sqlite.NewSqliteDatabase(filepath.Join(t.TempDir(), "checkpoint.db"))
+	t.Cleanup(func() { db.Close() })
+
+	guild, err := db.CreateGuild(context.Background(), queries.CreateGuildParams{GuildID: "10", Timezone: "Europe/Rome", OwnerID: "20"})
+	require.NoError(t, err)
+	return db, *guild
+}
+
+// TestCreateCheckpoint tests the future-only and one upcoming per channel rules
+func TestCreateCheckpoint(t *testing.T) {
+	db, guild := setupTestDB(t)
+	ctx := context.Background()
+	tomorrow := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+
+	_, err := CreateCheckpoint(ctx, db, guild, NewCheckpoint{ChannelID: "30", UserID: "20", Date: "2020-01-01", Time: "19:00"})
+	assert.ErrorIs(t, err, ErrCheckpointInPast)
+
+	_, err = CreateCheckpoint(ctx, db, guild, NewCheckpoint{ChannelID: "30", UserID: "20", Date: tomorrow, Time: "25:00"})
+	assert.ErrorIs(t, err, ErrInvalidTime)
+
+	checkpoint, err := CreateCheckpoint(ctx, db, guild, NewCheckpoint{ChannelID: "30", UserID: "20", Date: tomorrow, Time: "7 pm"})
+	require.NoError(t, err)
+	scheduledAt, err := time.Parse(time.RFC3339, checkpoint.ScheduledAt)
+	require.NoError(t, err)
+	assert.Equal(t, 19, scheduledAt.Hour())
+
+	_, err = CreateCheckpoint(ctx, db, guild, NewCheckpoint{ChannelID: "30", UserID: "20", Date: tomorrow, Time: "20:00"})
+	var existsErr *CheckpointExistsError
+	require.True(t, errors.As(err, &existsErr))
+	assert.Equal(t, checkpoint.ID, existsErr.Checkpoint.ID)
+	assert.True(t, IsUserError(err))
+
+	_, err = CreateCheckpoint(ctx, db, guild, NewCheckpoint{ChannelID: "31", UserID: "20", Date: tomorrow, Time: "20:00"})
+	assert.NoError(t, err)
+
+	// Guilds can allow several upcoming checkpoints per channel, exact duplicates are still rejected
+	_, err = settings.Set(ctx, db, guild.GuildID, settings.AllowMultipleUpcoming, "true", "20")
+	require.NoError(t, err)
+	_, err = CreateCheckpoint(ctx, db, guild, NewCheckpoint{ChannelID: "30", UserID: "20", Date: tomorrow, Time: "20:00"})
+	assert.NoError(t, err)
+	_, err = CreateCheckpoint(ctx, db, guild, NewCheckpoint{ChannelID: "30", UserID: "20", Date: tomorrow, Time: "20:00"})
+	require.True(t, errors.As(err, &existsErr))
+	assert.True(t, existsErr.Duplicate)
+}
+
+// TestSaveGoal tests goal creation, status updates, the moderator override rule and its audit entries and revisions
+func TestSaveGoal(t *testing.T) {
+	db, guild := setupTestDB(t)
+	ctx := context.Background()
+	tomorrow := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+
+	checkpoint, err := CreateCheckpoint(ctx, db, guild, NewCheckpoint{ChannelID: "30", UserID: "20", Date: tomorrow, Time: "19:00"})
+	require.NoError(t, err)
+
+	_, err = SaveGoal(ctx, db, checkpoint.ID, GoalChange{ActorID: "40", UserID: "40", Status: StatusCompleted})
+	assert.ErrorIs(t, err, ErrGoalNotFound)
+
+	result, err := SaveGoal(ctx, db, checkpoint.ID, GoalChange{ActorID: "40", UserID: "40", Description: "Ship it"})
+	require.NoError(t, err)
+	assert.True(t, result.Created)
+	assert.Equal(t, StatusIncomplete, result.Goal.Status)
+
+	_, err = SaveGoal(ctx, db, checkpoint.ID, GoalChange{ActorID: "50", UserID: "40", Status: StatusFailed})
+	assert.ErrorIs(t, err, ErrNotAllowed)
+
+	_, err = SaveGoal(ctx, db, checkpoint.ID, GoalChange{ActorID: "40", UserID: "40", Status: "done"})
+	assert.ErrorIs(t, err, ErrInvalidStatus)
+
+	result, err = SaveGoal(ctx, db, checkpoint.ID, GoalChange{ActorID: "50", Moderator: true, UserID: "40", Status: StatusCompleted})
+	require.NoError(t, err)
+	assert.False(t, result.Created)
+	assert.Equal(t, "Ship it", result.Goal.Description)
+	assert.Equal(t, StatusCompleted, result.Goal.Status)
+
+	goal, err := db.GetGoalByCheckpointAndUser(ctx, queries.GetGoalByCheckpointAndUserParams{CheckpointID: checkpoint.ID, DiscordUser: "40"})
+	require.NoError(t, err)
+	assert.Equal(t, StatusCompleted, goal.Status)
+
+	// Only the moderator's status change is audited, the owner's own goal isn't an override
+	entries, err := db.ListAuditEntries(ctx, queries.ListAuditEntriesParams{GuildID: guild.GuildID, MaxEntries: 10})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, audit.GoalStatusChanged, entries[0].Action)
+	assert.Equal(t, "50", entries[0].ActorID)
+	assert.Equal(t, "40", entries[0].TargetUser)
+	assert.Equal(t, StatusIncomplete, entries[0].BeforeValue)
+	assert.Equal(t, StatusCompleted, entries[0].AfterValue)
+
+	_, err = SaveGoal(ctx, db, checkpoint.ID, GoalChange{ActorID: "50", Moderator: true, UserID: "40", Description: "Ship it twice", Source: audit.SourceAPI})
+	require.NoError(t, err)
+	entries, err = db.ListAuditEntries(ctx, queries.ListAuditEntriesParams{GuildID: guild.GuildID, MaxEntries: 1})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, audit.GoalOverride, entries[0].Action)
+	assert.Equal(t, audit.SourceAPI, entries[0].Source)
+	assert.Equal(t, "Ship it", entries[0].BeforeValue)
+	assert.Equal(t, "Ship it twice", entries[0].AfterValue)
+
+	// Every change is kept as a revision, a status-only change doesn't mark the goal as edited
+	revisions, err := db.GetGoalRevisions(ctx, goal.ID)
+	require.NoError(t, err)
+	require.Len(t, revisions, 3)
+	assert.Equal(t, "40", revisions[0].EditedBy)
+	assert.Equal(t, StatusIncomplete, revisions[0].Status)
+	assert.Equal(t, StatusCompleted, revisions[1].Status)
+	assert.Equal(t, "Ship it", revisions[1].Description)
+	assert.Equal(t, "50", revisions[2].EditedBy)
+	assert.Equal(t, "Ship it twice", revisions[2].Description)
+
+	edited, err := db.GetEditedGoalIDsByCheckpoint(ctx, checkpoint.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{goal.ID}, edited)
+}
+
+// TestGoalLock tests that goal text is frozen after the guild's goal_lock while statuses can still change
+func TestGoalLock(t *testing.T) {
+	db, guild := setupTestDB(t)
+	ctx := context.Background()
+
+	checkpoint, err := db.CreateCheckpoint(ctx, queries.CreateCheckpointParams{
+		ScheduledAt: time.Now().Add(2 * time.Hour).UTC().Format(time.RFC3339),
+		ChannelID:   "30",
+		GuildID:     guild.GuildID,
+		DiscordUser: "20",
+	})
+	require.NoError(t, err)
+
+	_, err = SaveGoal(ctx, db, checkpoint.ID, GoalChange{ActorID: "40", UserID: "40", Description: "Ship it"})
+	require.NoError(t, err)
+	require.NoError(t, CheckGoalLock(ctx, db, *checkpoint, false))
+
+	_, err = settings.Set(ctx, db, guild.GuildID, settings.GoalLock, "3h", "20")
+	require.NoError(t, err)
+	assert.ErrorIs(t, CheckGoalLock(ctx, db, *checkpoint, false), ErrGoalsLocked)
+
+	_, err = SaveGoal(ctx, db, checkpoint.ID, GoalChange{ActorID: "40", UserID: "40", Description: "Ship half of it"})
+	assert.ErrorIs(t, err, ErrGoalsLocked)
+	assert.True(t, IsUserError(err))
+	_, err = SaveGoal(ctx, db, checkpoint.ID, GoalChange{ActorID: "50", UserID: "50", Description: "Late goal"})
+	assert.ErrorIs(t, err, ErrGoalsLocked)
+
+	result, err := SaveGoal(ctx, db, checkpoint.ID, GoalChange{ActorID: "40", UserID: "40", Status: StatusCompleted})
+	require.NoError(t, err)
+	assert.Equal(t, StatusCompleted, result.Goal.Status)
+
+	result, err = SaveGoal(ctx, db, checkpoint.ID, GoalChange{ActorID: "20", Moderator: true, OverrideLock: true, UserID: "40", Description: "Ship half of it"})
+	require.NoError(t, err)
+	assert.Equal(t, "Ship half of it", result.Goal.Description)
+
+	_, err = settings.Set(ctx, db, guild.GuildID, settings.GoalLock, "1h", "20")
+	require.NoError(t, err)
+	assert.NoError(t, CheckGoalLock(ctx, db, *checkpoint, false))
+}
+
+// TestGoalProgress tests that progress sets a partial status and completing a goal sets it to 100
+func TestGoalProgress(t *testing.T) {
+	db, guild := setupTestDB(t)
+	ctx := context.Background()
+
+	checkpoint, err := db.CreateCheckpoint(ctx, queries.CreateCheckpointParams{
+		ScheduledAt: time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339),
+		ChannelID:   "30",
+		GuildID:     guild.GuildID,
+		DiscordUser: "20",
+	})
+	require.NoError(t, err)
+
+	progress := func(value int64) *int64 { return &value }
+
+	_, err = SaveGoal(ctx, db, checkpoint.ID, GoalChange{ActorID: "40", UserID: "40", Progress: progress(40)})
+	assert.ErrorIs(t, err, ErrGoalNotFound)
+
+	_, err = SaveGoal(ctx, db, checkpoint.ID, GoalChange{ActorID: "40", UserID: "40", Description: "Ship it"})
+	require.NoError(t, err)
+
+	_, err = SaveGoal(ctx, db, checkpoint.ID, GoalChange{ActorID: "40", UserID: "40", Progress: progress(101)})
+	assert.ErrorIs(t, err, ErrInvalidProgress)
+	assert.True(t, IsUserError(err))
+
+	result, err := SaveGoal(ctx, db, checkpoint.ID, GoalChange{ActorID: "40", UserID: "40", Progress: progress(40)})
+	require.NoError(t, err)
+	assert.Equal(t, StatusPartial, result.Goal.Status)
+	assert.Equal(t, int64(40), result.Goal.Progress)
+
+	result, err = SaveGoal(ctx, db, checkpoint.ID, GoalChange{ActorID: "40", UserID: "40", Status: StatusCompleted})
+	require.NoError(t, err)
+	assert.Equal(t, StatusCompleted, result.Goal.Status)
+	assert.Equal(t, int64(100), result.Goal.Progress)
+
+	revisions, err := db.GetGoalRevisions(ctx, result.Goal.ID)
+	require.NoError(t, err)
+	require.Len(t, revisions, 3)
+	assert.Equal(t, int64(40), revisions[1].Progress)
+	assert.Equal(t, StatusPartial, revisions[1].Status)
+	assert.Equal(t, int64(100), revisions[2].Progress)
+
+	stats, err := db.GetUserGoalStats(ctx, queries.GetUserGoalStatsParams{GuildID: guild.GuildID, DiscordUser: "40"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stats.Completed)
+}
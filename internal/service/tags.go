@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/metruzanca/checkpoint-bot/internal/database"
+	"github.com/metruzanca/checkpoint-bot/internal/settings"
+)
+
+// MaxGoalTags limits how many tags a goal can have, categories included
+const MaxGoalTags = 5
+
+var (
+	ErrInvalidTag   = fmt.Errorf("tags are separated by commas and made of letters, digits and dashes, up to %d characters each", settings.TagMaxLength)
+	ErrTooManyTags  = fmt.Errorf("a goal can have at most %d tags", MaxGoalTags)
+	ErrNotACategory = errors.New("that's not one of the server's goal categories")
+	ErrNoCategories = errors.New("this server has no goal categories, a manager can set them with the goal_categories setting")
+	ErrNoGoalToTag  = errors.New("that goal no longer exists")
+)
+
+// ParseTags splits tags separated by commas, normalized and sorted (see settings.NormalizeTag).
+// An empty value is no tags, which isn't nil so it clears a goal's tags.
+func ParseTags(value string) ([]string, error) {
+	tags := []string{}
+	for _, part := range strings.Split(value, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		tag, ok := settings.NormalizeTag(part)
+		if !ok {
+			return nil, ErrInvalidTag
+		}
+		if !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	if len(tags) > MaxGoalTags {
+		return nil, ErrTooManyTags
+	}
+	slices.Sort(tags)
+	return tags, nil
+}
+
+// FormatTags formats tags for the goal editor, e.g. "fitness, running"
+func FormatTags(tags []string) string {
+	return strings.Join(tags, ", ")
+}
+
+// saveGoalTags replaces the goal's tags unless they're the same
+func saveGoalTags(ctx context.Context, db database.CheckpointDatabase, goalID int64, tags []string) error {
+	current, err := db.GetGoalTags(ctx, goalID)
+	if err != nil {
+		return fmt.Errorf("cannot get goal tags: %w", err)
+	}
+	if slices.Equal(current, tags) {
+		return nil
+	}
+	if err := db.SetGoalTags(ctx, goalID, tags); err != nil {
+		return fmt.Errorf("cannot set goal tags: %w", err)
+	}
+	return nil
+}
+
+// SetGoalCategories sets which of the guild's categories a goal is in, keeping its other tags
+func SetGoalCategories(ctx context.Context, db database.CheckpointDatabase, goalID int64, actorID string, moderator bool, selected []string) ([]string, error) {
+	goal, err := db.GetGoal(ctx, goalID)
+	if err == sql.ErrNoRows {
+		return nil, ErrNoGoalToTag
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot get goal: %w", err)
+	}
+	if err := CanEditGoal(actorID, goal.DiscordUser, moderator); err != nil {
+		return nil, err
+	}
+	checkpoint, err := db.GetCheckpoint(ctx, goal.CheckpointID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get checkpoint: %w", err)
+	}
+	guildSettings, err := settings.Load(ctx, db, checkpoint.GuildID)
+	if err != nil {
+		return nil, err
+	}
+	if len(guildSettings.GoalCategories) == 0 {
+		return nil, ErrNoCategories
+	}
+	for _, category := range selected {
+		if !slices.Contains(guildSettings.GoalCategories, category) {
+			return nil, ErrNotACategory
+		}
+	}
+
+	current, err := db.GetGoalTags(ctx, goalID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get goal tags: %w", err)
+	}
+	tags := slices.Clone(selected)
+	for _, tag := range current {
+		if !slices.Contains(guildSettings.GoalCategories, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	slices.Sort(tags)
+	tags = slices.Compact(tags)
+	if len(tags) > MaxGoalTags {
+		return nil, ErrTooManyTags
+	}
+	if err := saveGoalTags(ctx, db, goalID, tags); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+	"github.com/metruzanca/checkpoint-bot/internal/settings"
+	"github.com/metruzanca/checkpoint-bot/internal/stats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseTags tests that tags are normalized, deduplicated and sorted
+func TestParseTags(t *testing.T) {
+	tags, err := ParseTags(" #Running, fitness,, Deep Work ,running")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"deep-work", "fitness", "running"}, tags)
+
+	tags, err = ParseTags("")
+	require.NoError(t, err)
+	assert.NotNil(t, tags)
+	assert.Empty(t, tags)
+
+	_, err = ParseTags("work, side/project")
+	assert.ErrorIs(t, err, ErrInvalidTag)
+	_, err = ParseTags("a,b,c,d,e,f")
+	assert.ErrorIs(t, err, ErrTooManyTags)
+}
+
+// TestGoalTags tests that goals keep their tags, categories are picked from the guild's list and stats are broken down by tag
+func TestGoalTags(t *testing.T) {
+	db, guild := setupTestDB(t)
+	ctx := context.Background()
+
+	checkpoint, err := db.CreateCheckpoint(ctx, queries.CreateCheckpointParams{
+		ScheduledAt: time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339),
+		ChannelID:   "30",
+		GuildID:     guild.GuildID,
+		DiscordUser: "20",
+	})
+	require.NoError(t, err)
+
+	result, err := SaveGoal(ctx, db, checkpoint.ID, GoalChange{ActorID: "40", UserID: "40", Description: "Run 20 km", Tags: []string{"running"}})
+	require.NoError(t, err)
+	goalID := result.Goal.ID
+	tags, err := db.GetGoalTags(ctx, goalID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"running"}, tags)
+
+	// Without tags in the change they're left unchanged
+	_, err = SaveGoal(ctx, db, checkpoint.ID, GoalChange{ActorID: "40", UserID: "40", Status: StatusCompleted})
+	require.NoError(t, err)
+	tags, err = db.GetGoalTags(ctx, goalID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"running"}, tags)
+
+	_, err = SetGoalCategories(ctx, db, goalID, "40", false, []string{"fitness"})
+	assert.ErrorIs(t, err, ErrNoCategories)
+	_, err = settings.Set(ctx, db, guild.GuildID, settings.GoalCategories, "fitness,work", "20")
+	require.NoError(t, err)
+	_, err = SetGoalCategories(ctx, db, goalID, "40", false, []string{"cooking"})
+	assert.ErrorIs(t, err, ErrNotACategory)
+	_, err = SetGoalCategories(ctx, db, goalID, "50", false, []string{"fitness"})
+	assert.ErrorIs(t, err, ErrNotAllowed)
+
+	// Picking categories keeps the free tags
+	tags, err = SetGoalCategories(ctx, db, goalID, "40", false, []string{"fitness"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"fitness", "running"}, tags)
+	tags, err = SetGoalCategories(ctx, db, goalID, "40", false, []string{"work"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"running", "work"}, tags)
+
+	other, err := db.CreateCheckpoint(ctx, queries.CreateCheckpointParams{
+		ScheduledAt: time.Now().Add(48 * time.Hour).UTC().Format(time.RFC3339),
+		ChannelID:   "31",
+		GuildID:     guild.GuildID,
+		DiscordUser: "20",
+	})
+	require.NoError(t, err)
+	_, err = SaveGoal(ctx, db, other.ID, GoalChange{ActorID: "40", UserID: "40", Description: "Write a report", Tags: []string{"work"}, Status: StatusFailed})
+	require.NoError(t, err)
+
+	work, err := stats.ForUserTag(ctx, db, guild.GuildID, "40", "work")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), work.Goals)
+	assert.Equal(t, int64(1), work.Completed)
+	assert.Equal(t, 0.5, work.CompletionRate)
+
+	byTag, err := stats.ByTag(ctx, db, guild.GuildID, "40")
+	require.NoError(t, err)
+	assert.Equal(t, []stats.TagStats{
+		{Tag: "work", Goals: 2, Completed: 1, CompletionRate: 0.5},
+		{Tag: "running", Goals: 1, Completed: 1, CompletionRate: 1},
+	}, byTag)
+
+	// Pending goals keep their tags until they're attached
+	_, err = SavePendingGoal(ctx, db, guild.GuildID, "32", GoalChange{ActorID: "40", UserID: "40", Description: "Plan ahead", Tags: []string{"fitness", "planning"}})
+	require.NoError(t, err)
+	tomorrow := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+	scheduled, err := CreateCheckpoint(ctx, db, guild, NewCheckpoint{ChannelID: "32", UserID: "20", Date: tomorrow, Time: "19:00"})
+	require.NoError(t, err)
+	goals, err := db.GetGoalTagsByCheckpoint(ctx, scheduled.ID)
+	require.NoError(t, err)
+	require.Len(t, goals, 2)
+	assert.Equal(t, "planning", goals[1].Tag)
+}
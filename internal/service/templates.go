@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/log"
+	"github.com/metruzanca/checkpoint-bot/internal/database"
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+)
+
+const (
+	// TemplateNameMaxLength keeps template names short enough for select menu labels
+	TemplateNameMaxLength = 50
+	// MaxTemplates limits the personal templates of a member and the guild-wide templates of a guild,
+	// so a member's templates fit in the goal editor's select menu
+	MaxTemplates = 12
+)
+
+var (
+	ErrInvalidTemplateName     = fmt.Errorf("template name must be 1 to %d characters", TemplateNameMaxLength)
+	ErrTemplateNotFound        = errors.New("template not found")
+	ErrTooManyTemplates        = fmt.Errorf("there can be at most %d templates, delete one with /template delete first", MaxTemplates)
+	ErrGuildTemplateNotAllowed = errors.New("only goal moderators can manage server templates")
+)
+
+// NewTemplate describes a goal template to save, Target is formatted like the goal editor's, e.g. "20 km"
+type NewTemplate struct {
+	GuildID     string
+	Name        string
+	Description string
+	Target      string
+	// Guild shares the template with the whole guild, which only goal moderators can do
+	Guild     bool
+	ActorID   string
+	Moderator bool
+}
+
+// SaveTemplate saves a personal or guild-wide goal template, replacing the one with the same name
+func SaveTemplate(ctx context.Context, db database.CheckpointDatabase, template NewTemplate) (*queries.GoalTemplate, error) {
+	name := strings.TrimSpace(template.Name)
+	if name == "" || utf8.RuneCountInString(name) > TemplateNameMaxLength {
+		return nil, ErrInvalidTemplateName
+	}
+	description := strings.TrimSpace(template.Description)
+	if description == "" {
+		return nil, ErrEmptyGoal
+	}
+	if utf8.RuneCountInString(description) > GoalMaxLength {
+		return nil, ErrGoalTooLong
+	}
+	target, unit, err := ParseTarget(template.Target)
+	if err != nil {
+		return nil, err
+	}
+	owner := template.ActorID
+	if template.Guild {
+		if !template.Moderator {
+			return nil, ErrGuildTemplateNotAllowed
+		}
+		owner = ""
+	}
+
+	existing, err := Templates(ctx, db, template.GuildID, template.ActorID)
+	if err != nil {
+		return nil, err
+	}
+	count := 0
+	for _, t := range existing {
+		if t.DiscordUser != owner {
+			continue
+		}
+		// Saving over a template doesn't add one
+		if strings.EqualFold(t.Name, name) {
+			count = 0
+			break
+		}
+		count++
+	}
+	if count >= MaxTemplates {
+		return nil, ErrTooManyTemplates
+	}
+
+	saved, err := db.SaveGoalTemplate(ctx, queries.SaveGoalTemplateParams{
+		GuildID:     template.GuildID,
+		DiscordUser: owner,
+		Name:        name,
+		Description: description,
+		Target:      target,
+		Unit:        unit,
+		CreatedBy:   template.ActorID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot save goal template: %w", err)
+	}
+	return saved, nil
+}
+
+// Templates returns the member's personal templates and the guild's templates, the most used first
+func Templates(ctx context.Context, db database.CheckpointDatabase, guildID string, userID string) ([]queries.GoalTemplate, error) {
+	templates, err := db.GetGoalTemplatesByGuildAndUser(ctx, queries.GetGoalTemplatesByGuildAndUserParams{
+		GuildID:     guildID,
+		DiscordUser: userID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot get goal templates: %w", err)
+	}
+	return templates, nil
+}
+
+// FindTemplate finds one of the member's templates by ID or name, a personal template wins over a guild one with the same name
+func FindTemplate(ctx context.Context, db database.CheckpointDatabase, guildID string, userID string, value string) (*queries.GoalTemplate, error) {
+	value = strings.TrimSpace(value)
+	templates, err := Templates(ctx, db, guildID, userID)
+	if err != nil {
+		return nil, err
+	}
+	var found *queries.GoalTemplate
+	for n, template := range templates {
+		if strconv.FormatInt(template.ID, 10) == value {
+			return &templates[n], nil
+		}
+		if strings.EqualFold(template.Name, value) && (found == nil || template.DiscordUser != "") {
+			found = &templates[n]
+		}
+	}
+	if found == nil {
+		return nil, ErrTemplateNotFound
+	}
+	return found, nil
+}
+
+// UseTemplate counts a goal started from the template, templates are suggested by how often they're used
+func UseTemplate(ctx context.Context, db database.CheckpointDatabase, template queries.GoalTemplate) error {
+	if err := db.IncrementGoalTemplateUses(ctx, template.ID); err != nil {
+		return fmt.Errorf("cannot count goal template use: %w", err)
+	}
+	log.Info("goal template used", "template_id", template.ID, "guild_id", template.GuildID, "uses", template.Uses+1)
+	return nil
+}
+
+// DeleteTemplate deletes one of the member's templates by ID or name, guild templates can only be deleted by goal moderators
+func DeleteTemplate(ctx context.Context, db database.CheckpointDatabase, guildID string, userID string, value string, moderator bool) (*queries.GoalTemplate, error) {
+	template, err := FindTemplate(ctx, db, guildID, userID, value)
+	if err != nil {
+		return nil, err
+	}
+	if template.DiscordUser == "" && !moderator {
+		return nil, ErrGuildTemplateNotAllowed
+	}
+	if _, err := db.DeleteGoalTemplate(ctx, template.ID); err != nil {
+		return nil, fmt.Errorf("cannot delete goal template: %w", err)
+	}
+	return template, nil
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSaveTemplate tests that members save personal templates, only moderators save guild ones, and names are replaced in place
+func TestSaveTemplate(t *testing.T) {
+	db, guild := setupTestDB(t)
+	ctx := context.Background()
+
+	_, err := SaveTemplate(ctx, db, NewTemplate{GuildID: guild.GuildID, Name: " ", Description: "Run", ActorID: "40"})
+	assert.ErrorIs(t, err, ErrInvalidTemplateName)
+	_, err = SaveTemplate(ctx, db, NewTemplate{GuildID: guild.GuildID, Name: "Run", Description: " ", ActorID: "40"})
+	assert.ErrorIs(t, err, ErrEmptyGoal)
+	_, err = SaveTemplate(ctx, db, NewTemplate{GuildID: guild.GuildID, Name: "Run", Description: "Run", Target: "far", ActorID: "40"})
+	assert.ErrorIs(t, err, ErrInvalidTarget)
+	_, err = SaveTemplate(ctx, db, NewTemplate{GuildID: guild.GuildID, Name: "Weekly", Description: "Review the week", Guild: true, ActorID: "40"})
+	assert.ErrorIs(t, err, ErrGuildTemplateNotAllowed)
+
+	personal, err := SaveTemplate(ctx, db, NewTemplate{GuildID: guild.GuildID, Name: "Run", Description: "Run every morning", Target: "20 km", ActorID: "40"})
+	require.NoError(t, err)
+	assert.Equal(t, "40", personal.DiscordUser)
+	assert.Equal(t, 20.0, personal.Target)
+	assert.Equal(t, "km", personal.Unit)
+	shared, err := SaveTemplate(ctx, db, NewTemplate{GuildID: guild.GuildID, Name: "Weekly", Description: "Review the week", Guild: true, ActorID: "20", Moderator: true})
+	require.NoError(t, err)
+	assert.Empty(t, shared.DiscordUser)
+
+	// Names are case-insensitive, saving again replaces the template
+	replaced, err := SaveTemplate(ctx, db, NewTemplate{GuildID: guild.GuildID, Name: "run", Description: "Run every evening", ActorID: "40"})
+	require.NoError(t, err)
+	assert.Equal(t, personal.ID, replaced.ID)
+	assert.Equal(t, "Run every evening", replaced.Description)
+	assert.Zero(t, replaced.Target)
+
+	// Members see their own templates and the guild's
+	templates, err := Templates(ctx, db, guild.GuildID, "40")
+	require.NoError(t, err)
+	assert.Len(t, templates, 2)
+	templates, err = Templates(ctx, db, guild.GuildID, "50")
+	require.NoError(t, err)
+	require.Len(t, templates, 1)
+	assert.Equal(t, "Weekly", templates[0].Name)
+
+	for n := 1; n < MaxTemplates; n++ {
+		_, err := SaveTemplate(ctx, db, NewTemplate{GuildID: guild.GuildID, Name: fmt.Sprintf("Goal %d", n), Description: "Goal", ActorID: "40"})
+		require.NoError(t, err)
+	}
+	_, err = SaveTemplate(ctx, db, NewTemplate{GuildID: guild.GuildID, Name: "One more", Description: "Goal", ActorID: "40"})
+	assert.ErrorIs(t, err, ErrTooManyTemplates)
+	_, err = SaveTemplate(ctx, db, NewTemplate{GuildID: guild.GuildID, Name: "Run", Description: "Run", ActorID: "40"})
+	assert.NoError(t, err)
+}
+
+// TestUseTemplate tests that templates are found by ID or name, personal first, and sorted by how often they're used
+func TestUseTemplate(t *testing.T) {
+	db, guild := setupTestDB(t)
+	ctx := context.Background()
+
+	shared, err := SaveTemplate(ctx, db, NewTemplate{GuildID: guild.GuildID, Name: "Read", Description: "Read a book", Guild: true, ActorID: "20", Moderator: true})
+	require.NoError(t, err)
+	personal, err := SaveTemplate(ctx, db, NewTemplate{GuildID: guild.GuildID, Name: "Read", Description: "Read 50 pages", ActorID: "40"})
+	require.NoError(t, err)
+	other, err := SaveTemplate(ctx, db, NewTemplate{GuildID: guild.GuildID, Name: "Write", Description: "Write a chapter", ActorID: "50"})
+	require.NoError(t, err)
+
+	found, err := FindTemplate(ctx, db, guild.GuildID, "40", "read")
+	require.NoError(t, err)
+	assert.Equal(t, personal.ID, found.ID)
+	found, err = FindTemplate(ctx, db, guild.GuildID, "50", "read")
+	require.NoError(t, err)
+	assert.Equal(t, shared.ID, found.ID)
+	found, err = FindTemplate(ctx, db, guild.GuildID, "40", fmt.Sprint(shared.ID))
+	require.NoError(t, err)
+	assert.Equal(t, shared.ID, found.ID)
+	// Other members' templates are private
+	_, err = FindTemplate(ctx, db, guild.GuildID, "40", fmt.Sprint(other.ID))
+	assert.ErrorIs(t, err, ErrTemplateNotFound)
+
+	require.NoError(t, UseTemplate(ctx, db, *shared))
+	templates, err := Templates(ctx, db, guild.GuildID, "40")
+	require.NoError(t, err)
+	require.Len(t, templates, 2)
+	assert.Equal(t, shared.ID, templates[0].ID)
+	assert.Equal(t, int64(1), templates[0].Uses)
+
+	_, err = DeleteTemplate(ctx, db, guild.GuildID, "50", "Read", false)
+	assert.ErrorIs(t, err, ErrGuildTemplateNotAllowed)
+	deleted, err := DeleteTemplate(ctx, db, guild.GuildID, "40", "Read", false)
+	require.NoError(t, err)
+	assert.Equal(t, personal.ID, deleted.ID)
+	_, err = DeleteTemplate(ctx, db, guild.GuildID, "20", "Read", true)
+	require.NoError(t, err)
+	_, err = FindTemplate(ctx, db, guild.GuildID, "40", "Read")
+	assert.ErrorIs(t, err, ErrTemplateNotFound)
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/log"
+	"github.com/metruzanca/checkpoint-bot/internal/database"
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+)
+
+const (
+	// UpdateMaxLength keeps goal updates short enough to list several in a checkpoint's timeline
+	UpdateMaxLength = 500
+	// MaxPromptInterval is the longest interval between update prompts, in days
+	MaxPromptInterval = 14
+)
+
+var (
+	ErrEmptyUpdate           = errors.New("update cannot be empty")
+	ErrUpdateTooLong         = fmt.Errorf("update cannot be longer than %d characters", UpdateMaxLength)
+	ErrUpdateClosed          = errors.New("updates can only be posted for goals of an upcoming checkpoint")
+	ErrInvalidPromptInterval = fmt.Errorf("update prompts can be sent every 1 to %d days", MaxPromptInterval)
+)
+
+// PostGoalUpdate adds a progress note to the user's own goal, until its checkpoint starts
+func PostGoalUpdate(ctx context.Context, db database.CheckpointDatabase, goalID int64, userID string, content string) (*queries.GoalUpdate, error) {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return nil, ErrEmptyUpdate
+	}
+	if utf8.RuneCountInString(content) > UpdateMaxLength {
+		return nil, ErrUpdateTooLong
+	}
+
+	// Goals are deleted along with cancelled checkpoints
+	goal, err := db.GetGoal(ctx, goalID)
+	if err == sql.ErrNoRows {
+		return nil, ErrUpdateClosed
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot get goal: %w", err)
+	}
+	if goal.DiscordUser != userID {
+		return nil, ErrNotAllowed
+	}
+	checkpoint, err := db.GetCheckpoint(ctx, goal.CheckpointID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get checkpoint: %w", err)
+	}
+	if _, err := GoalCheckpoint(ctx, db, checkpoint.GuildID, checkpoint.ID); err == ErrCheckpointNotUpcoming {
+		return nil, ErrUpdateClosed
+	} else if err != nil {
+		return nil, err
+	}
+
+	update, err := db.CreateGoalUpdate(ctx, queries.CreateGoalUpdateParams{
+		GoalID:      goal.ID,
+		DiscordUser: userID,
+		Content:     content,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot create goal update: %w", err)
+	}
+	log.Info("goal update posted", "goal_id", goal.ID, "checkpoint_id", goal.CheckpointID, "user", userID)
+	return update, nil
+}
+
+// EnableUpdatePrompts asks the user for a goal update by DM every intervalDays, starting intervalDays from now
+func EnableUpdatePrompts(ctx context.Context, db database.CheckpointDatabase, guildID string, userID string, intervalDays int64, now time.Time) (*queries.UpdatePrompt, error) {
+	if intervalDays < 1 || intervalDays > MaxPromptInterval {
+		return nil, ErrInvalidPromptInterval
+	}
+	return db.SaveUpdatePrompt(ctx, queries.SaveUpdatePromptParams{
+		GuildID:      guildID,
+		DiscordUser:  userID,
+		IntervalDays: intervalDays,
+		NextPromptAt: now.Add(promptInterval(intervalDays)).UTC().Format(time.RFC3339),
+	})
+}
+
+// DueUpdatePrompt is a user to prompt for updates on their upcoming goals
+type DueUpdatePrompt struct {
+	Prompt queries.UpdatePrompt
+	Goals  []queries.Goal
+}
+
+// ClaimDueUpdatePrompts returns the prompts due at now and schedules their next one.
+// Users without goals for an upcoming checkpoint are skipped until their next prompt.
+func ClaimDueUpdatePrompts(ctx context.Context, db database.CheckpointDatabase, now time.Time) ([]DueUpdatePrompt, error) {
+	prompts, err := db.GetDueUpdatePrompts(ctx, now.UTC().Format(time.RFC3339))
+	if err != nil {
+		return nil, fmt.Errorf("cannot get due update prompts: %w", err)
+	}
+
+	due := []DueUpdatePrompt{}
+	for _, prompt := range prompts {
+		err := db.SetUpdatePromptNext(ctx, queries.SetUpdatePromptNextParams{
+			NextPromptAt: nextPromptAt(prompt, now).UTC().Format(time.RFC3339),
+			GuildID:      prompt.GuildID,
+			DiscordUser:  prompt.DiscordUser,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("cannot schedule next update prompt: %w", err)
+		}
+
+		goals, err := db.GetUpcomingGoalsByGuildAndUser(ctx, queries.GetUpcomingGoalsByGuildAndUserParams{
+			GuildID:     prompt.GuildID,
+			DiscordUser: prompt.DiscordUser,
+		})
+		if err != nil {
+			log.Error("cannot get upcoming goals for update prompt", "err", err, "guild_id", prompt.GuildID, "user", prompt.DiscordUser)
+			continue
+		}
+		if len(goals) > 0 {
+			due = append(due, DueUpdatePrompt{Prompt: prompt, Goals: goals})
+		}
+	}
+	return due, nil
+}
+
+// nextPromptAt keeps prompts at the same time of day, after downtime the missed prompts aren't caught up on
+func nextPromptAt(prompt queries.UpdatePrompt, now time.Time) time.Time {
+	interval := promptInterval(prompt.IntervalDays)
+	next, err := time.Parse(time.RFC3339, prompt.NextPromptAt)
+	if err != nil || interval <= 0 {
+		return now.Add(promptInterval(1))
+	}
+	for !next.After(now) {
+		next = next.Add(interval)
+	}
+	return next
+}
+
+func promptInterval(days int64) time.Duration {
+	return time.Duration(days) * 24 * time.Hour
+}
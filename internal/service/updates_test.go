@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPostGoalUpdate tests that only the goal's owner can post updates, until its checkpoint starts
+func TestPostGoalUpdate(t *testing.T) {
+	db, guild := setupTestDB(t)
+	ctx := context.Background()
+
+	checkpoint, err := db.CreateCheckpoint(ctx, queries.CreateCheckpointParams{
+		ScheduledAt: time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339),
+		ChannelID:   "30",
+		GuildID:     guild.GuildID,
+		DiscordUser: "20",
+	})
+	require.NoError(t, err)
+	result, err := SaveGoal(ctx, db, checkpoint.ID, GoalChange{ActorID: "40", UserID: "40", Description: "Ship it"})
+	require.NoError(t, err)
+
+	_, err = PostGoalUpdate(ctx, db, result.Goal.ID, "40", "   ")
+	assert.ErrorIs(t, err, ErrEmptyUpdate)
+	_, err = PostGoalUpdate(ctx, db, result.Goal.ID, "50", "Not mine")
+	assert.ErrorIs(t, err, ErrNotAllowed)
+	_, err = PostGoalUpdate(ctx, db, result.Goal.ID+1, "40", "No goal")
+	assert.ErrorIs(t, err, ErrUpdateClosed)
+
+	_, err = PostGoalUpdate(ctx, db, result.Goal.ID, "40", " Halfway there ")
+	require.NoError(t, err)
+	updates, err := db.GetGoalUpdatesByCheckpoint(ctx, checkpoint.ID)
+	require.NoError(t, err)
+	require.Len(t, updates, 1)
+	assert.Equal(t, "Halfway there", updates[0].Content)
+
+	past, err := db.CreateCheckpoint(ctx, queries.CreateCheckpointParams{
+		ScheduledAt: time.Now().Add(-time.Hour).UTC().Format(time.RFC3339),
+		ChannelID:   "31",
+		GuildID:     guild.GuildID,
+		DiscordUser: "20",
+	})
+	require.NoError(t, err)
+	goal, err := db.CreateGoal(ctx, queries.CreateGoalParams{DiscordUser: "40", Description: "Too late", CheckpointID: past.ID})
+	require.NoError(t, err)
+	_, err = PostGoalUpdate(ctx, db, goal.ID, "40", "Done")
+	assert.ErrorIs(t, err, ErrUpdateClosed)
+}
+
+// TestClaimDueUpdatePrompts tests that prompts are due every interval and skip users without upcoming goals
+func TestClaimDueUpdatePrompts(t *testing.T) {
+	db, guild := setupTestDB(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	_, err := EnableUpdatePrompts(ctx, db, guild.GuildID, "40", 0, now)
+	assert.ErrorIs(t, err, ErrInvalidPromptInterval)
+	_, err = EnableUpdatePrompts(ctx, db, guild.GuildID, "40", 2, now)
+	require.NoError(t, err)
+	_, err = EnableUpdatePrompts(ctx, db, guild.GuildID, "50", 1, now)
+	require.NoError(t, err)
+
+	checkpoint, err := db.CreateCheckpoint(ctx, queries.CreateCheckpointParams{
+		ScheduledAt: now.Add(7 * 24 * time.Hour).UTC().Format(time.RFC3339),
+		ChannelID:   "30",
+		GuildID:     guild.GuildID,
+		DiscordUser: "20",
+	})
+	require.NoError(t, err)
+	_, err = SaveGoal(ctx, db, checkpoint.ID, GoalChange{ActorID: "40", UserID: "40", Description: "Ship it"})
+	require.NoError(t, err)
+
+	due, err := ClaimDueUpdatePrompts(ctx, db, now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, due)
+
+	// User 50 is due too, but has no goals
+	due, err = ClaimDueUpdatePrompts(ctx, db, now.Add(49*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, "40", due[0].Prompt.DiscordUser)
+	require.Len(t, due[0].Goals, 1)
+	assert.Equal(t, "Ship it", due[0].Goals[0].Description)
+
+	// Claimed prompts aren't due again until their next interval
+	due, err = ClaimDueUpdatePrompts(ctx, db, now.Add(50*time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, due)
+	due, err = ClaimDueUpdatePrompts(ctx, db, now.Add(97*time.Hour))
+	require.NoError(t, err)
+	assert.Len(t, due, 1)
+
+	deleted, err := db.DeleteUpdatePrompt(ctx, queries.DeleteUpdatePromptParams{GuildID: guild.GuildID, DiscordUser: "40"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+	due, err = ClaimDueUpdatePrompts(ctx, db, now.Add(200*time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, due)
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/charmbracelet/log"
+	"github.com/metruzanca/checkpoint-bot/internal/database"
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+	"github.com/metruzanca/checkpoint-bot/internal/settings"
+)
+
+var (
+	ErrSelfConfirm      = errors.New("you can't confirm your own goal")
+	ErrAlreadyConfirmed = errors.New("you already confirmed this goal")
+)
+
+// Confirmation is a completed goal's verification after a member confirmed it
+type Confirmation struct {
+	Goal queries.Goal
+	// ConfirmedBy lists the members who confirmed the goal, oldest first
+	ConfirmedBy []string
+	// Required is the guild's settings.VerificationConfirmations, zero when the member's partner verifies goals
+	Required int64
+}
+
+// Verified reports whether the goal now counts in stats
+func (c Confirmation) Verified() bool {
+	return c.Goal.Verification == VerificationVerified
+}
+
+// goalVerification is the verification a goal needs after its status changes: completed goals wait for
+// peer confirmations when the guild requires them, otherwise for the member's partner if they have one
+func goalVerification(ctx context.Context, db database.CheckpointDatabase, checkpointID int64, userID string, status string) (string, error) {
+	if status != StatusCompleted {
+		return "", nil
+	}
+	checkpoint, err := db.GetCheckpoint(ctx, checkpointID)
+	if err != nil {
+		return "", fmt.Errorf("cannot get checkpoint: %w", err)
+	}
+	guildSettings, err := settings.Load(ctx, db, checkpoint.GuildID)
+	if err != nil {
+		return "", err
+	}
+	if guildSettings.VerificationConfirmations > 0 {
+		return VerificationPending, nil
+	}
+	partner, err := PartnerFor(ctx, db, *checkpoint, userID)
+	if err != nil || partner == "" {
+		return "", err
+	}
+	return VerificationPending, nil
+}
+
+// ConfirmGoal records a member's confirmation of a completed goal. With peer verification on, any member
+// but the goal's owner can confirm it and it's verified once enough of them did, otherwise only the
+// member's partner can and verifies it right away (see VerifyGoal).
+func ConfirmGoal(ctx context.Context, db database.CheckpointDatabase, goalID int64, actorID string) (*Confirmation, error) {
+	goal, err := db.GetGoal(ctx, goalID)
+	if err == sql.ErrNoRows {
+		return nil, ErrNothingToVerify
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot get goal: %w", err)
+	}
+	if goal.DiscordUser == actorID {
+		return nil, ErrSelfConfirm
+	}
+	checkpoint, err := db.GetCheckpoint(ctx, goal.CheckpointID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get checkpoint: %w", err)
+	}
+	guildSettings, err := settings.Load(ctx, db, checkpoint.GuildID)
+	if err != nil {
+		return nil, err
+	}
+	if guildSettings.VerificationConfirmations == 0 {
+		verified, err := VerifyGoal(ctx, db, goalID, actorID)
+		if err != nil {
+			return nil, err
+		}
+		return &Confirmation{Goal: *verified, ConfirmedBy: []string{actorID}}, nil
+	}
+	if goal.Status != StatusCompleted || goal.Verification != VerificationPending {
+		return nil, ErrNothingToVerify
+	}
+
+	created, err := db.CreateGoalVerification(ctx, queries.CreateGoalVerificationParams{GoalID: goal.ID, DiscordUser: actorID})
+	if err != nil {
+		return nil, fmt.Errorf("cannot confirm goal: %w", err)
+	}
+	if created == 0 {
+		return nil, ErrAlreadyConfirmed
+	}
+	result, err := goalConfirmation(ctx, db, *goal, guildSettings.VerificationConfirmations)
+	if err != nil {
+		return nil, err
+	}
+	log.Info("goal confirmed", "goal_id", goal.ID, "user", goal.DiscordUser, "actor", actorID, "confirmations", len(result.ConfirmedBy), "required", result.Required)
+
+	if int64(len(result.ConfirmedBy)) >= result.Required {
+		err = db.UpdateGoalVerification(ctx, queries.UpdateGoalVerificationParams{
+			Verification: VerificationVerified,
+			VerifiedBy:   actorID,
+			ID:           goal.ID,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("cannot verify goal: %w", err)
+		}
+		log.Info("goal verified", "goal_id", goal.ID, "user", goal.DiscordUser, "confirmations", len(result.ConfirmedBy))
+		result.Goal.Verification = VerificationVerified
+		result.Goal.VerifiedBy = actorID
+	}
+	return result, nil
+}
+
+// GoalConfirmation returns who confirmed a goal so far and how many confirmations the guild requires
+func GoalConfirmation(ctx context.Context, db database.CheckpointDatabase, goal queries.Goal) (*Confirmation, error) {
+	checkpoint, err := db.GetCheckpoint(ctx, goal.CheckpointID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get checkpoint: %w", err)
+	}
+	guildSettings, err := settings.Load(ctx, db, checkpoint.GuildID)
+	if err != nil {
+		return nil, err
+	}
+	return goalConfirmation(ctx, db, goal, guildSettings.VerificationConfirmations)
+}
+
+func goalConfirmation(ctx context.Context, db database.CheckpointDatabase, goal queries.Goal, required int64) (*Confirmation, error) {
+	verifications, err := db.GetGoalVerifications(ctx, goal.ID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get goal verifications: %w", err)
+	}
+	result := &Confirmation{Goal: goal, Required: required}
+	for _, verification := range verifications {
+		result.ConfirmedBy = append(result.ConfirmedBy, verification.DiscordUser)
+	}
+	return result, nil
+}
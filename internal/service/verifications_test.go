@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+	"github.com/metruzanca/checkpoint-bot/internal/settings"
+	"github.com/metruzanca/checkpoint-bot/internal/stats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestConfirmGoal tests that completed goals count once enough other members confirmed them
+func TestConfirmGoal(t *testing.T) {
+	db, guild := setupTestDB(t)
+	ctx := context.Background()
+
+	checkpoint, err := db.CreateCheckpoint(ctx, queries.CreateCheckpointParams{
+		ScheduledAt: time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339),
+		ChannelID:   "30",
+		GuildID:     guild.GuildID,
+		DiscordUser: "20",
+	})
+	require.NoError(t, err)
+	_, err = settings.Set(ctx, db, guild.GuildID, settings.VerificationConfirmations, "2", "20")
+	require.NoError(t, err)
+
+	result, err := SaveGoal(ctx, db, checkpoint.ID, GoalChange{ActorID: "40", UserID: "40", Description: "Ship it"})
+	require.NoError(t, err)
+	goalID := result.Goal.ID
+	_, err = ConfirmGoal(ctx, db, goalID, "50")
+	assert.ErrorIs(t, err, ErrNothingToVerify)
+
+	result, err = SaveGoal(ctx, db, checkpoint.ID, GoalChange{ActorID: "40", UserID: "40", Status: StatusCompleted})
+	require.NoError(t, err)
+	assert.Equal(t, VerificationPending, result.Goal.Verification)
+
+	_, err = ConfirmGoal(ctx, db, goalID, "40")
+	assert.ErrorIs(t, err, ErrSelfConfirm)
+	confirmation, err := ConfirmGoal(ctx, db, goalID, "50")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"50"}, confirmation.ConfirmedBy)
+	assert.Equal(t, int64(2), confirmation.Required)
+	assert.False(t, confirmation.Verified())
+	_, err = ConfirmGoal(ctx, db, goalID, "50")
+	assert.ErrorIs(t, err, ErrAlreadyConfirmed)
+
+	userStats, err := stats.ForUser(ctx, db, guild.GuildID, "40")
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), userStats.Completed)
+	assert.Equal(t, int64(1), userStats.Unverified)
+
+	confirmation, err = ConfirmGoal(ctx, db, goalID, "60")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"50", "60"}, confirmation.ConfirmedBy)
+	assert.True(t, confirmation.Verified())
+	assert.Equal(t, "60", confirmation.Goal.VerifiedBy)
+	_, err = ConfirmGoal(ctx, db, goalID, "70")
+	assert.ErrorIs(t, err, ErrNothingToVerify)
+
+	userStats, err = stats.ForUser(ctx, db, guild.GuildID, "40")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), userStats.Completed)
+
+	// Completing the goal again starts over
+	_, err = SaveGoal(ctx, db, checkpoint.ID, GoalChange{ActorID: "40", UserID: "40", Status: StatusPartial})
+	require.NoError(t, err)
+	_, err = SaveGoal(ctx, db, checkpoint.ID, GoalChange{ActorID: "40", UserID: "40", Status: StatusCompleted})
+	require.NoError(t, err)
+	goal, err := db.GetGoal(ctx, goalID)
+	require.NoError(t, err)
+	confirmation, err = GoalConfirmation(ctx, db, *goal)
+	require.NoError(t, err)
+	assert.Empty(t, confirmation.ConfirmedBy)
+	assert.Equal(t, VerificationPending, confirmation.Goal.Verification)
+}
+
+// TestConfirmGoalPartner tests that without peer verification only the member's partner can confirm goals
+func TestConfirmGoalPartner(t *testing.T) {
+	db, guild := setupTestDB(t)
+	ctx := context.Background()
+
+	checkpoint, err := db.CreateCheckpoint(ctx, queries.CreateCheckpointParams{
+		ScheduledAt: time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339),
+		ChannelID:   "30",
+		GuildID:     guild.GuildID,
+		DiscordUser: "20",
+	})
+	require.NoError(t, err)
+	require.NoError(t, SetPartner(ctx, db, guild.GuildID, "40", "50", "40"))
+	result, err := SaveGoal(ctx, db, checkpoint.ID, GoalChange{ActorID: "40", UserID: "40", Description: "Ship it", Status: StatusCompleted})
+	require.NoError(t, err)
+
+	_, err = ConfirmGoal(ctx, db, result.Goal.ID, "60")
+	assert.ErrorIs(t, err, ErrNotPartner)
+	confirmation, err := ConfirmGoal(ctx, db, result.Goal.ID, "50")
+	require.NoError(t, err)
+	assert.True(t, confirmation.Verified())
+}
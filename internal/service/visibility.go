@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/charmbracelet/log"
+	"github.com/metruzanca/checkpoint-bot/internal/database"
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+	"github.com/metruzanca/checkpoint-bot/internal/settings"
+)
+
+var ErrInvalidVisibility = errors.New("visibility must be public, members or private")
+
+// ValidVisibility reports whether visibility is one of the goal visibilities
+func ValidVisibility(visibility string) bool {
+	switch visibility {
+	case settings.VisibilityPublic, settings.VisibilityMembers, settings.VisibilityPrivate:
+		return true
+	}
+	return false
+}
+
+// CanViewGoal reports whether viewerID may see the goal's text. Its owner and goal moderators always can,
+// members of the goal's checkpoint (checkpointMember) can see members-only goals.
+func CanViewGoal(goal queries.Goal, viewerID string, moderator bool, checkpointMember bool) bool {
+	switch {
+	case goal.Visibility == settings.VisibilityPublic:
+		return true
+	case viewerID != "" && (viewerID == goal.DiscordUser || moderator):
+		return true
+	}
+	return goal.Visibility == settings.VisibilityMembers && checkpointMember
+}
+
+// HiddenGoalText replaces the text of a goal for members who can't see it
+func HiddenGoalText(visibility string) string {
+	if visibility == settings.VisibilityMembers {
+		return "👥 members-only goal"
+	}
+	return "🔒 private goal"
+}
+
+// goalAuditText is the goal text kept in audit entries. They're posted to the mod-log channel and sent to webhooks,
+// so only public goals keep their text.
+func goalAuditText(description string, visibility string) string {
+	if visibility == settings.VisibilityPublic {
+		return description
+	}
+	return HiddenGoalText(visibility)
+}
+
+// GoalVisibleTo is CanViewGoal for a viewer who's a checkpoint member when they have a goal in it
+func GoalVisibleTo(ctx context.Context, db database.CheckpointDatabase, goal queries.Goal, viewerID string, moderator bool) (bool, error) {
+	visible := CanViewGoal(goal, viewerID, moderator, false)
+	if visible || goal.Visibility != settings.VisibilityMembers || viewerID == "" {
+		return visible, nil
+	}
+	_, err := db.GetGoalByCheckpointAndUser(ctx, queries.GetGoalByCheckpointAndUserParams{
+		CheckpointID: goal.CheckpointID,
+		DiscordUser:  viewerID,
+	})
+	if err == sql.ErrNoRows {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("cannot get viewer's goal: %w", err)
+	}
+	return true, nil
+}
+
+// newGoalVisibility is the visibility of a new goal, the guild's goal_visibility setting unless one is chosen
+func newGoalVisibility(ctx context.Context, db database.CheckpointDatabase, guildID string, visibility string) (string, error) {
+	if visibility != "" {
+		return visibility, nil
+	}
+	guildSettings, err := settings.Load(ctx, db, guildID)
+	if err != nil {
+		return "", err
+	}
+	return guildSettings.GoalVisibility, nil
+}
+
+// setGoalVisibility changes the goal's visibility unless it's the same
+func setGoalVisibility(ctx context.Context, db database.CheckpointDatabase, goal *queries.Goal, visibility string) error {
+	if visibility == goal.Visibility {
+		return nil
+	}
+	err := db.UpdateGoalVisibility(ctx, queries.UpdateGoalVisibilityParams{Visibility: visibility, ID: goal.ID})
+	if err != nil {
+		return fmt.Errorf("cannot update goal visibility: %w", err)
+	}
+	log.Info("goal visibility updated", "goal_id", goal.ID, "user", goal.DiscordUser, "visibility", visibility)
+	goal.Visibility = visibility
+	return nil
+}
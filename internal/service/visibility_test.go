@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+	"github.com/metruzanca/checkpoint-bot/internal/events"
+	"github.com/metruzanca/checkpoint-bot/internal/settings"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCanViewGoal tests who can see the text of goals of each visibility
+func TestCanViewGoal(t *testing.T) {
+	public := queries.Goal{DiscordUser: "40", Visibility: settings.VisibilityPublic}
+	members := queries.Goal{DiscordUser: "40", Visibility: settings.VisibilityMembers}
+	private := queries.Goal{DiscordUser: "40", Visibility: settings.VisibilityPrivate}
+
+	assert.True(t, CanViewGoal(public, "", false, false))
+	assert.False(t, CanViewGoal(members, "", false, false))
+	assert.False(t, CanViewGoal(private, "", false, false))
+
+	assert.True(t, CanViewGoal(members, "40", false, false))
+	assert.True(t, CanViewGoal(private, "40", false, false))
+	assert.True(t, CanViewGoal(private, "50", true, false))
+
+	assert.True(t, CanViewGoal(members, "50", false, true))
+	assert.False(t, CanViewGoal(members, "50", false, false))
+	assert.False(t, CanViewGoal(private, "50", false, true))
+}
+
+// TestGoalVisibility tests that goals get the guild's default visibility and keep the one they're given
+func TestGoalVisibility(t *testing.T) {
+	db, guild := setupTestDB(t)
+	ctx := context.Background()
+
+	checkpoint, err := db.CreateCheckpoint(ctx, queries.CreateCheckpointParams{
+		ScheduledAt: time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339),
+		ChannelID:   "30",
+		GuildID:     guild.GuildID,
+		DiscordUser: "20",
+	})
+	require.NoError(t, err)
+
+	result, err := SaveGoal(ctx, db, checkpoint.ID, GoalChange{ActorID: "40", UserID: "40", Description: "Run 20 km"})
+	require.NoError(t, err)
+	assert.Equal(t, settings.VisibilityPublic, result.Goal.Visibility)
+
+	_, err = SaveGoal(ctx, db, checkpoint.ID, GoalChange{ActorID: "40", UserID: "40", Visibility: "secret"})
+	assert.ErrorIs(t, err, ErrInvalidVisibility)
+	_, err = SaveGoal(ctx, db, checkpoint.ID, GoalChange{ActorID: "50", UserID: "50", Visibility: settings.VisibilityPrivate})
+	assert.ErrorIs(t, err, ErrGoalNotFound)
+
+	// The visibility alone can be changed, and other changes keep it
+	result, err = SaveGoal(ctx, db, checkpoint.ID, GoalChange{ActorID: "40", UserID: "40", Visibility: settings.VisibilityMembers})
+	require.NoError(t, err)
+	assert.Equal(t, settings.VisibilityMembers, result.Goal.Visibility)
+	result, err = SaveGoal(ctx, db, checkpoint.ID, GoalChange{ActorID: "40", UserID: "40", Description: "Run 25 km"})
+	require.NoError(t, err)
+	assert.Equal(t, settings.VisibilityMembers, result.Goal.Visibility)
+
+	goal := result.Goal
+	visible, err := GoalVisibleTo(ctx, db, goal, "50", false)
+	require.NoError(t, err)
+	assert.False(t, visible)
+	_, err = SaveGoal(ctx, db, checkpoint.ID, GoalChange{ActorID: "50", UserID: "50", Description: "Read a book"})
+	require.NoError(t, err)
+	visible, err = GoalVisibleTo(ctx, db, goal, "50", false)
+	require.NoError(t, err)
+	assert.True(t, visible)
+
+	// New goals, pending ones included, follow the goal_visibility setting
+	_, err = settings.Set(ctx, db, guild.GuildID, settings.GoalVisibility, settings.VisibilityPrivate, "20")
+	require.NoError(t, err)
+	result, err = SaveGoal(ctx, db, checkpoint.ID, GoalChange{ActorID: "60", UserID: "60", Description: "Write a report"})
+	require.NoError(t, err)
+	assert.Equal(t, settings.VisibilityPrivate, result.Goal.Visibility)
+
+	_, err = SavePendingGoal(ctx, db, guild.GuildID, "32", GoalChange{ActorID: "40", UserID: "40", Description: "Plan ahead", Visibility: settings.VisibilityPublic})
+	require.NoError(t, err)
+	_, err = SavePendingGoal(ctx, db, guild.GuildID, "32", GoalChange{ActorID: "50", UserID: "50", Description: "Keep it quiet"})
+	require.NoError(t, err)
+	// Editing a pending goal without a visibility keeps the one it has
+	_, err = SavePendingGoal(ctx, db, guild.GuildID, "32", GoalChange{ActorID: "40", UserID: "40", Description: "Plan further ahead"})
+	require.NoError(t, err)
+
+	tomorrow := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+	scheduled, err := CreateCheckpoint(ctx, db, guild, NewCheckpoint{ChannelID: "32", UserID: "20", Date: tomorrow, Time: "19:00"})
+	require.NoError(t, err)
+	goals, err := db.GetGoalsByCheckpoint(ctx, scheduled.ID)
+	require.NoError(t, err)
+	require.Len(t, goals, 2)
+	visibilities := map[string]string{}
+	for _, goal := range goals {
+		visibilities[goal.DiscordUser] = goal.Visibility
+	}
+	assert.Equal(t, map[string]string{"40": settings.VisibilityPublic, "50": settings.VisibilityPrivate}, visibilities)
+}
+
+// TestGoalOverrideAudit tests that moderator edits of hidden goals don't send their text to webhooks
+func TestGoalOverrideAudit(t *testing.T) {
+	db, guild := setupTestDB(t)
+	ctx := context.Background()
+
+	var payloads []string
+	db.Events().Subscribe(func(e events.Event) {
+		if e.Type == events.AuditRecorded {
+			payload, err := json.Marshal(e)
+			require.NoError(t, err)
+			payloads = append(payloads, string(payload))
+		}
+	})
+
+	checkpoint, err := db.CreateCheckpoint(ctx, queries.CreateCheckpointParams{
+		ScheduledAt: time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339),
+		ChannelID:   "30",
+		GuildID:     guild.GuildID,
+		DiscordUser: "20",
+	})
+	require.NoError(t, err)
+
+	moderator := GoalChange{ActorID: "20", Moderator: true, UserID: "40"}
+	change := moderator
+	change.Description, change.Visibility = "Secret plan", settings.VisibilityPrivate
+	_, err = SaveGoal(ctx, db, checkpoint.ID, change)
+	require.NoError(t, err)
+	change = moderator
+	change.Description = "Secret plan B"
+	_, err = SaveGoal(ctx, db, checkpoint.ID, change)
+	require.NoError(t, err)
+	change = moderator
+	change.Description, change.Visibility = "Secret pending plan", settings.VisibilityMembers
+	_, err = SavePendingGoal(ctx, db, guild.GuildID, "32", change)
+	require.NoError(t, err)
+
+	// Public goals keep their text
+	change = moderator
+	change.UserID, change.Description = "50", "Open plan"
+	_, err = SaveGoal(ctx, db, checkpoint.ID, change)
+	require.NoError(t, err)
+
+	require.Len(t, payloads, 4)
+	for _, payload := range payloads[:3] {
+		assert.NotContains(t, payload, "Secret")
+	}
+	assert.Contains(t, payloads[0], "🔒 private goal")
+	assert.Contains(t, payloads[2], "👥 members-only goal")
+	assert.Contains(t, payloads[3], "Open plan")
+}
@@ -0,0 +1,512 @@
+// settings package defines the per-guild settings, how they're validated and their defaults.
+package settings
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/metruzanca/checkpoint-bot/internal/database"
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+)
+
+const (
+	ReminderOffsets           = "reminder_offsets"
+	ManagerRole               = "manager_role"
+	GoalModeratorRole         = "goal_moderator_role"
+	CheckpointCreators        = "checkpoint_creators"
+	CheckpointChannels        = "checkpoint_channels"
+	AllowMultipleUpcoming     = "allow_multiple_upcoming"
+	GoalVisibility            = "goal_visibility"
+	Locale                    = "locale"
+	AnnouncementChannel       = "announcement_channel"
+	ModLogChannel             = "mod_log_channel"
+	GoalLock                  = "goal_lock"
+	PartnerRotation           = "partner_rotation"
+	VerificationConfirmations = "verification_confirmations"
+	GoalCategories            = "goal_categories"
+
+	VisibilityPublic = "public"
+	// VisibilityMembers shows a goal's text to the members of its checkpoint, others only see a summary
+	VisibilityMembers = "members"
+	VisibilityPrivate = "private"
+
+	CreatorsEveryone = "everyone"
+	CreatorsManagers = "managers"
+
+	// MaxReminderOffsets limits how many reminders a checkpoint can have
+	MaxReminderOffsets = 5
+	// MaxReminderOffset is the earliest a reminder can be sent before a checkpoint
+	MaxReminderOffset = 7 * 24 * time.Hour
+	// MaxGoalLock is the earliest goals can be locked before a checkpoint
+	MaxGoalLock = 7 * 24 * time.Hour
+	// MaxVerificationConfirmations limits how many members must confirm a completed goal
+	MaxVerificationConfirmations = 10
+	// MaxGoalCategories keeps the categories in one select menu
+	MaxGoalCategories = 20
+	// TagMaxLength limits goal tags and categories
+	TagMaxLength = 24
+)
+
+// ErrUnknownKey is returned for a key that isn't in Definitions
+var ErrUnknownKey = errors.New("unknown setting")
+
+// Definition describes a setting, values are stored as the normalized string returned by Parse
+type Definition struct {
+	Key         string
+	Description string
+	// Default is used when the guild hasn't set the key, in normalized form
+	Default string
+	// Parse validates user input and normalizes it, the returned error is shown to the user
+	Parse func(value string) (string, error)
+}
+
+// Definitions lists every setting in the order they're shown
+var Definitions = []Definition{
+	{
+		Key:         ReminderOffsets,
+		Description: "How long before a checkpoint to send reminders, e.g. 24h,1h (none to disable)",
+		Default:     "24h,1h",
+		Parse:       parseReminderOffsets,
+	},
+	{
+		Key:         ManagerRole,
+		Description: "Checkpoint manager role, can reschedule and cancel any checkpoint (none to unset)",
+		Default:     "",
+		Parse:       parseSnowflake(`^<@&(\d+)>$`, "a role mention or ID"),
+	},
+	{
+		Key:         GoalModeratorRole,
+		Description: "Goal moderator role, can edit other members' goals (none to unset)",
+		Default:     "",
+		Parse:       parseSnowflake(`^<@&(\d+)>$`, "a role mention or ID"),
+	},
+	{
+		Key:         CheckpointCreators,
+		Description: "Who can create checkpoints, everyone or managers",
+		Default:     CreatorsEveryone,
+		Parse:       parseCreators,
+	},
+	{
+		Key:         CheckpointChannels,
+		Description: "Channels where checkpoints can be created, e.g. #standup,#goals (none for any channel)",
+		Default:     "",
+		Parse:       parseChannels,
+	},
+	{
+		Key:         AllowMultipleUpcoming,
+		Description: "Allow more than one upcoming checkpoint per channel",
+		Default:     "false",
+		Parse:       parseBool,
+	},
+	{
+		Key:         GoalVisibility,
+		Description: "Default visibility of new goals, public, members or private",
+		Default:     VisibilityPublic,
+		Parse:       parseVisibility,
+	},
+	{
+		Key:         Locale,
+		Description: "Language of the bot's messages, e.g. en-US",
+		Default:     string(discordgo.EnglishUS),
+		Parse:       parseLocale,
+	},
+	{
+		Key:         AnnouncementChannel,
+		Description: "Channel for announcements, defaults to the checkpoint's channel (none to unset)",
+		Default:     "",
+		Parse:       parseSnowflake(`^<#(\d+)>$`, "a channel mention or ID"),
+	},
+	{
+		Key:         ModLogChannel,
+		Description: "Channel where audit log entries are posted (none to disable)",
+		Default:     "",
+		Parse:       parseSnowflake(`^<#(\d+)>$`, "a channel mention or ID"),
+	},
+	{
+		Key:         GoalLock,
+		Description: "How long before a checkpoint goals can no longer be set or edited, e.g. 12h (none to lock them when it starts)",
+		Default:     "",
+		Parse:       parseGoalLock,
+	},
+	{
+		Key:         PartnerRotation,
+		Description: "Pair members with a new accountability partner for each checkpoint of a channel",
+		Default:     "false",
+		Parse:       parseBool,
+	},
+	{
+		Key:         VerificationConfirmations,
+		Description: "How many other members must confirm a completed goal before it counts, e.g. 2 (none to disable)",
+		Default:     "",
+		Parse:       parseConfirmations,
+	},
+	{
+		Key:         GoalCategories,
+		Description: "Categories members can pick for their goals, e.g. fitness,work,learning (none to disable)",
+		Default:     "",
+		Parse:       parseGoalCategories,
+	},
+}
+
+// Lookup returns the definition of key
+func Lookup(key string) (Definition, bool) {
+	for _, def := range Definitions {
+		if def.Key == key {
+			return def, true
+		}
+	}
+	return Definition{}, false
+}
+
+// Settings are a guild's settings with defaults applied
+type Settings struct {
+	// ReminderOffsets are sorted from the earliest reminder to the latest
+	ReminderOffsets     []time.Duration
+	ManagerRoleID       string
+	GoalModeratorRoleID string
+	CheckpointCreators  string
+	// CheckpointChannelIDs is empty when checkpoints can be created in any channel
+	CheckpointChannelIDs  []string
+	AllowMultipleUpcoming bool
+	GoalVisibility        string
+	Locale                string
+	AnnouncementChannelID string
+	ModLogChannelID       string
+	// GoalLock is how long before a checkpoint goals are locked, zero locks them when it starts
+	GoalLock time.Duration
+	// PartnerRotation assigns rotating partners when a checkpoint is created, overriding /partner set
+	PartnerRotation bool
+	// VerificationConfirmations is how many members must confirm a completed goal, zero disables peer verification
+	VerificationConfirmations int64
+	// GoalCategories are tags offered when setting a goal, stats are broken down by them
+	GoalCategories []string
+}
+
+// Values returns the guild's raw setting values by key, with defaults for keys it hasn't set
+func Values(ctx context.Context, db database.CheckpointDatabase, guildID string) (map[string]string, error) {
+	rows, err := db.GetGuildSettings(ctx, guildID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get guild settings: %w", err)
+	}
+
+	values := make(map[string]string, len(Definitions))
+	for _, def := range Definitions {
+		values[def.Key] = def.Default
+	}
+	for _, row := range rows {
+		// Keys removed from Definitions are ignored
+		if _, ok := values[row.Key]; ok {
+			values[row.Key] = row.Value
+		}
+	}
+	return values, nil
+}
+
+// Load returns the guild's typed settings
+func Load(ctx context.Context, db database.CheckpointDatabase, guildID string) (Settings, error) {
+	values, err := Values(ctx, db, guildID)
+	if err != nil {
+		return Settings{}, err
+	}
+	return decode(values), nil
+}
+
+// decode converts stored values, they were validated when set so parse errors fall back to zero values
+func decode(values map[string]string) Settings {
+	settings := Settings{
+		ManagerRoleID:         values[ManagerRole],
+		GoalModeratorRoleID:   values[GoalModeratorRole],
+		CheckpointCreators:    values[CheckpointCreators],
+		GoalVisibility:        values[GoalVisibility],
+		Locale:                values[Locale],
+		AnnouncementChannelID: values[AnnouncementChannel],
+		ModLogChannelID:       values[ModLogChannel],
+	}
+	settings.AllowMultipleUpcoming, _ = strconv.ParseBool(values[AllowMultipleUpcoming])
+	settings.PartnerRotation, _ = strconv.ParseBool(values[PartnerRotation])
+	if values[VerificationConfirmations] != "" {
+		settings.VerificationConfirmations, _ = strconv.ParseInt(values[VerificationConfirmations], 10, 64)
+	}
+	if values[GoalLock] != "" {
+		settings.GoalLock, _ = parseOffset(values[GoalLock])
+	}
+	if values[CheckpointChannels] != "" {
+		settings.CheckpointChannelIDs = strings.Split(values[CheckpointChannels], ",")
+	}
+	if values[GoalCategories] != "" {
+		settings.GoalCategories = strings.Split(values[GoalCategories], ",")
+	}
+	for _, part := range strings.Split(values[ReminderOffsets], ",") {
+		if offset, err := parseOffset(part); err == nil {
+			settings.ReminderOffsets = append(settings.ReminderOffsets, offset)
+		}
+	}
+	return settings
+}
+
+// Change is a setting's value before and after Set or Reset
+type Change struct {
+	Key    string
+	Before string
+	After  string
+}
+
+// Set validates value and stores it for the guild, After is the normalized value
+func Set(ctx context.Context, db database.CheckpointDatabase, guildID string, key string, value string, userID string) (Change, error) {
+	def, ok := Lookup(key)
+	if !ok {
+		return Change{}, ErrUnknownKey
+	}
+	normalized, err := def.Parse(strings.TrimSpace(value))
+	if err != nil {
+		return Change{}, &ValidationError{Key: key, Err: err}
+	}
+	values, err := Values(ctx, db, guildID)
+	if err != nil {
+		return Change{}, err
+	}
+
+	_, err = db.SetGuildSetting(ctx, queries.SetGuildSettingParams{
+		GuildID:   guildID,
+		Key:       key,
+		Value:     normalized,
+		UpdatedBy: userID,
+	})
+	if err != nil {
+		return Change{}, fmt.Errorf("cannot save guild setting: %w", err)
+	}
+	return Change{Key: key, Before: values[key], After: normalized}, nil
+}
+
+// Reset restores the default value of key for the guild
+func Reset(ctx context.Context, db database.CheckpointDatabase, guildID string, key string) (Change, error) {
+	def, ok := Lookup(key)
+	if !ok {
+		return Change{}, ErrUnknownKey
+	}
+	values, err := Values(ctx, db, guildID)
+	if err != nil {
+		return Change{}, err
+	}
+	if _, err := db.DeleteGuildSetting(ctx, queries.DeleteGuildSettingParams{GuildID: guildID, Key: key}); err != nil {
+		return Change{}, fmt.Errorf("cannot reset guild setting: %w", err)
+	}
+	return Change{Key: key, Before: values[key], After: def.Default}, nil
+}
+
+// ValidationError is returned by Set when a value is invalid for its key
+type ValidationError struct {
+	Key string
+	Err error
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid value for %s: %s", e.Key, e.Err)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// isNone reports whether the user asked to clear an optional setting
+func isNone(value string) bool {
+	return value == "" || strings.EqualFold(value, "none")
+}
+
+func parseReminderOffsets(value string) (string, error) {
+	if isNone(value) {
+		return "", nil
+	}
+
+	var offsets []time.Duration
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		offset, err := parseOffset(part)
+		if err != nil {
+			return "", fmt.Errorf("%q is not a duration, use values like 30m, 1h or 2d", part)
+		}
+		if offset < time.Minute || offset > MaxReminderOffset {
+			return "", errors.New("reminders must be between 1m and 7d before the checkpoint")
+		}
+		if !slices.Contains(offsets, offset) {
+			offsets = append(offsets, offset)
+		}
+	}
+	if len(offsets) > MaxReminderOffsets {
+		return "", fmt.Errorf("at most %d reminders can be set", MaxReminderOffsets)
+	}
+
+	// Earliest reminder first
+	slices.SortFunc(offsets, func(a, b time.Duration) int { return int(b - a) })
+	parts := make([]string, len(offsets))
+	for i, offset := range offsets {
+		parts[i] = FormatDuration(offset)
+	}
+	return strings.Join(parts, ","), nil
+}
+
+func parseGoalLock(value string) (string, error) {
+	if isNone(value) {
+		return "", nil
+	}
+	offset, err := parseOffset(value)
+	if err != nil {
+		return "", fmt.Errorf("%q is not a duration, use values like 30m, 12h or 1d", value)
+	}
+	if offset < time.Minute || offset > MaxGoalLock {
+		return "", errors.New("goals can be locked between 1m and 7d before the checkpoint")
+	}
+	return FormatDuration(offset), nil
+}
+
+// parseOffset parses a Go duration truncated to the minute, also accepting whole days such as 2d
+func parseOffset(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	offset, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	return offset.Truncate(time.Minute), nil
+}
+
+// FormatDuration formats a whole number of minutes without the zero units time.Duration.String adds
+func FormatDuration(d time.Duration) string {
+	switch {
+	case d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	}
+	return fmt.Sprintf("%dh%dm", d/time.Hour, (d%time.Hour)/time.Minute)
+}
+
+// parseSnowflake accepts a raw Discord ID or a mention matching pattern
+func parseSnowflake(pattern string, expected string) func(string) (string, error) {
+	mention := regexp.MustCompile(pattern)
+	id := regexp.MustCompile(`^\d+$`)
+	return func(value string) (string, error) {
+		if isNone(value) {
+			return "", nil
+		}
+		if match := mention.FindStringSubmatch(value); match != nil {
+			return match[1], nil
+		}
+		if id.MatchString(value) {
+			return value, nil
+		}
+		return "", fmt.Errorf("expected %s", expected)
+	}
+}
+
+// tagPattern matches a normalized tag, made of letters, digits, dashes and underscores
+var tagPattern = regexp.MustCompile(`^[\p{L}\p{N}_-]+$`)
+
+// NormalizeTag lowercases a tag such as "#Deep Work" to "deep-work", ok is false when it isn't a valid tag
+func NormalizeTag(tag string) (string, bool) {
+	tag = strings.ToLower(strings.Join(strings.Fields(strings.TrimPrefix(strings.TrimSpace(tag), "#")), "-"))
+	if !tagPattern.MatchString(tag) || utf8.RuneCountInString(tag) > TagMaxLength {
+		return "", false
+	}
+	return tag, true
+}
+
+// parseGoalCategories accepts tags separated by commas, see NormalizeTag
+func parseGoalCategories(value string) (string, error) {
+	if isNone(value) {
+		return "", nil
+	}
+	var categories []string
+	for _, part := range strings.Split(value, ",") {
+		category, ok := NormalizeTag(part)
+		if !ok {
+			return "", fmt.Errorf("%q is not a valid category, use letters, digits and dashes, up to %d characters", strings.TrimSpace(part), TagMaxLength)
+		}
+		if !slices.Contains(categories, category) {
+			categories = append(categories, category)
+		}
+	}
+	if len(categories) > MaxGoalCategories {
+		return "", fmt.Errorf("at most %d categories can be set", MaxGoalCategories)
+	}
+	return strings.Join(categories, ","), nil
+}
+
+// parseChannels accepts channel mentions or IDs separated by commas or spaces
+func parseChannels(value string) (string, error) {
+	if isNone(value) {
+		return "", nil
+	}
+
+	parseChannel := parseSnowflake(`^<#(\d+)>$`, "channel mentions or IDs")
+	var ids []string
+	for _, part := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' }) {
+		id, err := parseChannel(part)
+		if err != nil {
+			return "", err
+		}
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	return strings.Join(ids, ","), nil
+}
+
+func parseCreators(value string) (string, error) {
+	value = strings.ToLower(value)
+	if value != CreatorsEveryone && value != CreatorsManagers {
+		return "", errors.New("expected everyone or managers")
+	}
+	return value, nil
+}
+
+func parseBool(value string) (string, error) {
+	switch strings.ToLower(value) {
+	case "true", "yes", "on", "1":
+		return "true", nil
+	case "false", "no", "off", "0":
+		return "false", nil
+	}
+	return "", errors.New("expected true or false")
+}
+
+func parseConfirmations(value string) (string, error) {
+	if isNone(value) || value == "0" {
+		return "", nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 || n > MaxVerificationConfirmations {
+		return "", fmt.Errorf("expected a number of confirmations between 1 and %d", MaxVerificationConfirmations)
+	}
+	return strconv.Itoa(n), nil
+}
+
+func parseVisibility(value string) (string, error) {
+	value = strings.ToLower(value)
+	if value != VisibilityPublic && value != VisibilityMembers && value != VisibilityPrivate {
+		return "", errors.New("expected public, members or private")
+	}
+	return value, nil
+}
+
+func parseLocale(value string) (string, error) {
+	for locale := range discordgo.Locales {
+		if strings.EqualFold(string(locale), value) {
+			return string(locale), nil
+		}
+	}
+	return "", fmt.Errorf("%q is not a Discord locale, use codes such as en-US, de or pt-BR", value)
+}
@@ -0,0 +1,147 @@
+package settings
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+	"github.com/metruzanca/checkpoint-bot/internal/database/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParse tests validation and normalization of every setting
+func TestParse(t *testing.T) {
+	tests := []struct {
+		key     string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{ReminderOffsets, "1h, 2d,30m,1h", "48h,1h,30m", false},
+		{ReminderOffsets, "90m", "1h30m", false},
+		{ReminderOffsets, "none", "", false},
+		{ReminderOffsets, "soon", "", true},
+		{ReminderOffsets, "8d", "", true},
+		{ReminderOffsets, "1m,2m,3m,4m,5m,6m", "", true},
+		{ManagerRole, "<@&123>", "123", false},
+		{ManagerRole, "123", "123", false},
+		{ManagerRole, "<#123>", "", true},
+		{GoalModeratorRole, "<@&7>", "7", false},
+		{CheckpointCreators, "Managers", CreatorsManagers, false},
+		{CheckpointCreators, "admins", "", true},
+		{CheckpointChannels, "<#1>, <#2> 3,<#1>", "1,2,3", false},
+		{CheckpointChannels, "<#1>,general", "", true},
+		{AllowMultipleUpcoming, "Yes", "true", false},
+		{AllowMultipleUpcoming, "maybe", "", true},
+		{GoalVisibility, "PRIVATE", VisibilityPrivate, false},
+		{GoalVisibility, "Members", VisibilityMembers, false},
+		{GoalVisibility, "secret", "", true},
+		{Locale, "pt-br", "pt-BR", false},
+		{Locale, "klingon", "", true},
+		{AnnouncementChannel, "<#456>", "456", false},
+		{AnnouncementChannel, "none", "", false},
+		{GoalLock, "12h", "12h", false},
+		{GoalLock, "1d", "24h", false},
+		{GoalLock, "none", "", false},
+		{GoalLock, "30s", "", true},
+		{GoalLock, "8d", "", true},
+		{PartnerRotation, "on", "true", false},
+		{PartnerRotation, "weekly", "", true},
+		{VerificationConfirmations, "3", "3", false},
+		{VerificationConfirmations, "0", "", false},
+		{VerificationConfirmations, "none", "", false},
+		{VerificationConfirmations, "11", "", true},
+		{VerificationConfirmations, "some", "", true},
+		{GoalCategories, "Fitness, #Deep Work,fitness", "fitness,deep-work", false},
+		{GoalCategories, "none", "", false},
+		{GoalCategories, "work,side/projects", "", true},
+	}
+
+	for _, tt := range tests {
+		def, ok := Lookup(tt.key)
+		require.True(t, ok, tt.key)
+		got, err := def.Parse(tt.value)
+		if tt.wantErr {
+			assert.Error(t, err, "%s=%s", tt.key, tt.value)
+			continue
+		}
+		require.NoError(t, err, "%s=%s", tt.key, tt.value)
+		assert.Equal(t, tt.want, got, "%s=%s", tt.key, tt.value)
+	}
+
+	// Defaults must be valid values
+	for _, def := range Definitions {
+		normalized, err := def.Parse(def.Default)
+		require.NoError(t, err, def.Key)
+		assert.Equal(t, def.Default, normalized, def.Key)
+	}
+}
+
+// TestStore tests setting, loading and resetting a guild's settings
+func TestStore(t *testing.T) {
+	db := sqlite.NewSqliteDatabase(filepath.Join(t.TempDir(), "checkpoint.db"))
+	t.Cleanup(func() { db.Close() })
+	ctx := context.Background()
+	_, err := db.CreateGuild(ctx, queries.CreateGuildParams{GuildID: "1", Timezone: "UTC", OwnerID: "2"})
+	require.NoError(t, err)
+
+	loaded, err := Load(ctx, db, "1")
+	require.NoError(t, err)
+	assert.Equal(t, []time.Duration{24 * time.Hour, time.Hour}, loaded.ReminderOffsets)
+	assert.False(t, loaded.AllowMultipleUpcoming)
+	assert.Equal(t, VisibilityPublic, loaded.GoalVisibility)
+	assert.Equal(t, "en-US", loaded.Locale)
+
+	_, err = Set(ctx, db, "1", ReminderOffsets, "none", "2")
+	require.NoError(t, err)
+	change, err := Set(ctx, db, "1", ManagerRole, "<@&99>", "2")
+	require.NoError(t, err)
+	assert.Equal(t, Change{Key: ManagerRole, Before: "", After: "99"}, change)
+	_, err = Set(ctx, db, "1", AllowMultipleUpcoming, "on", "2")
+	require.NoError(t, err)
+	_, err = Set(ctx, db, "1", CheckpointChannels, "<#5>,<#6>", "2")
+	require.NoError(t, err)
+	_, err = Set(ctx, db, "1", GoalLock, "90m", "2")
+	require.NoError(t, err)
+	_, err = Set(ctx, db, "1", PartnerRotation, "yes", "2")
+	require.NoError(t, err)
+	_, err = Set(ctx, db, "1", VerificationConfirmations, "2", "2")
+	require.NoError(t, err)
+	_, err = Set(ctx, db, "1", GoalCategories, "fitness,work", "2")
+	require.NoError(t, err)
+
+	_, err = Set(ctx, db, "1", GoalVisibility, "hidden", "2")
+	var validationErr *ValidationError
+	assert.True(t, errors.As(err, &validationErr))
+	_, err = Set(ctx, db, "1", "colour", "blue", "2")
+	assert.ErrorIs(t, err, ErrUnknownKey)
+
+	loaded, err = Load(ctx, db, "1")
+	require.NoError(t, err)
+	assert.Empty(t, loaded.ReminderOffsets)
+	assert.Equal(t, "99", loaded.ManagerRoleID)
+	assert.True(t, loaded.AllowMultipleUpcoming)
+	assert.Equal(t, []string{"5", "6"}, loaded.CheckpointChannelIDs)
+	assert.Equal(t, 90*time.Minute, loaded.GoalLock)
+	assert.True(t, loaded.PartnerRotation)
+	assert.Equal(t, int64(2), loaded.VerificationConfirmations)
+	assert.Equal(t, []string{"fitness", "work"}, loaded.GoalCategories)
+	assert.Equal(t, CreatorsEveryone, loaded.CheckpointCreators)
+	assert.Equal(t, VisibilityPublic, loaded.GoalVisibility)
+
+	change, err = Reset(ctx, db, "1", AllowMultipleUpcoming)
+	require.NoError(t, err)
+	assert.Equal(t, Change{Key: AllowMultipleUpcoming, Before: "true", After: "false"}, change)
+	loaded, err = Load(ctx, db, "1")
+	require.NoError(t, err)
+	assert.False(t, loaded.AllowMultipleUpcoming)
+
+	// Other guilds are unaffected
+	loaded, err = Load(ctx, db, "3")
+	require.NoError(t, err)
+	assert.Empty(t, loaded.ManagerRoleID)
+}
@@ -0,0 +1,102 @@
+// stats package computes accountability statistics from the database.
+package stats
+
+import (
+	"context"
+
+	"github.com/metruzanca/checkpoint-bot/internal/database"
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+)
+
+// UserStats summarizes a user's goals and attendance in a guild
+type UserStats struct {
+	GuildID   string `json:"guild_id"`
+	UserID    string `json:"user_id"`
+	Goals     int64  `json:"goals"`
+	Completed int64  `json:"completed"`
+	// Unverified goals are completed but still awaiting their partner's verification, they aren't counted in Completed
+	Unverified int64 `json:"unverified"`
+	Failed     int64 `json:"failed"`
+	Incomplete int64 `json:"incomplete"`
+	Partial    int64 `json:"partial"`
+	Attended   int64 `json:"attended"`
+	// CompletionRate is the share of goals completed, between 0 and 1, partial goals count for their progress
+	CompletionRate float64 `json:"completion_rate"`
+}
+
+// ForUser computes a user's statistics in a guild
+func ForUser(ctx context.Context, db database.CheckpointDatabase, guildID string, userID string) (*UserStats, error) {
+	goals, err := db.GetUserGoalStats(ctx, queries.GetUserGoalStatsParams{GuildID: guildID, DiscordUser: userID})
+	if err != nil {
+		return nil, err
+	}
+	attended, err := db.GetUserAttendanceCount(ctx, queries.GetUserAttendanceCountParams{GuildID: guildID, DiscordUser: userID})
+	if err != nil {
+		return nil, err
+	}
+	return userStats(guildID, userID, *goals, attended), nil
+}
+
+// ForUserTag computes a user's statistics in a guild for their goals tagged tag, attendance isn't filtered
+func ForUserTag(ctx context.Context, db database.CheckpointDatabase, guildID string, userID string, tag string) (*UserStats, error) {
+	goals, err := db.GetUserGoalStatsByTag(ctx, queries.GetUserGoalStatsByTagParams{GuildID: guildID, DiscordUser: userID, Tag: tag})
+	if err != nil {
+		return nil, err
+	}
+	attended, err := db.GetUserAttendanceCount(ctx, queries.GetUserAttendanceCountParams{GuildID: guildID, DiscordUser: userID})
+	if err != nil {
+		return nil, err
+	}
+	return userStats(guildID, userID, queries.GetUserGoalStatsRow(*goals), attended), nil
+}
+
+func userStats(guildID string, userID string, goals queries.GetUserGoalStatsRow, attended int64) *UserStats {
+	stats := &UserStats{
+		GuildID:    guildID,
+		UserID:     userID,
+		Goals:      goals.Goals,
+		Completed:  goals.Completed,
+		Unverified: goals.Unverified,
+		Failed:     goals.Failed,
+		Incomplete: goals.Incomplete,
+		Partial:    goals.Partial,
+		Attended:   attended,
+	}
+	stats.CompletionRate = CompletionRate(goals.Completed, goals.PartialProgress, goals.Goals)
+	return stats
+}
+
+// TagStats summarizes a user's goals with a tag
+type TagStats struct {
+	Tag            string  `json:"tag"`
+	Goals          int64   `json:"goals"`
+	Completed      int64   `json:"completed"`
+	CompletionRate float64 `json:"completion_rate"`
+}
+
+// ByTag computes a user's statistics in a guild for each tag of their goals, the most used tags first
+func ByTag(ctx context.Context, db database.CheckpointDatabase, guildID string, userID string) ([]TagStats, error) {
+	rows, err := db.GetUserTagStats(ctx, queries.GetUserTagStatsParams{GuildID: guildID, DiscordUser: userID})
+	if err != nil {
+		return nil, err
+	}
+	tags := make([]TagStats, len(rows))
+	for n, row := range rows {
+		tags[n] = TagStats{
+			Tag:            row.Tag,
+			Goals:          row.Goals,
+			Completed:      row.Completed,
+			CompletionRate: CompletionRate(row.Completed, row.PartialProgress, row.Goals),
+		}
+	}
+	return tags, nil
+}
+
+// CompletionRate weights each completed goal as 1 and each partial goal by its progress,
+// partialProgress is the sum of the partial goals' progress (0-100)
+func CompletionRate(completed, partialProgress, goals int64) float64 {
+	if goals == 0 {
+		return 0
+	}
+	return (float64(completed) + float64(partialProgress)/100) / float64(goals)
+}
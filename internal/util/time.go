@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"strings"
 	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
 )
 
 // ParseDate parses a date string in YYYY-MM-DD format and returns a time.Time at midnight
@@ -157,3 +160,16 @@ func FormatCountdown(datetime time.Time) string {
 
 	return "Now"
 }
+
+// GuildLocation returns the guild's timezone, falling back to UTC if it's missing or invalid
+func GuildLocation(guild queries.Guild) *time.Location {
+	if guild.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(guild.Timezone)
+	if err != nil {
+		log.Warn("cannot load guild timezone, using UTC", "timezone", guild.Timezone, "err", err, "guild", guild.GuildID)
+		return time.UTC
+	}
+	return loc
+}
@@ -0,0 +1,45 @@
+// version package exposes build information about the running binary.
+package version
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Version is set at build time:
+//
+//	go build -ldflags "-X github.com/metruzanca/checkpoint-bot/internal/version.Version=v1.2.3"
+var Version = "dev"
+
+// Info describes the running build
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	BuildTime string `json:"build_time,omitempty"`
+	Modified  bool   `json:"modified,omitempty"`
+	GoVersion string `json:"go_version"`
+}
+
+// Get returns the build information, VCS details are embedded by the Go toolchain when building from a git checkout
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		GoVersion: runtime.Version(),
+	}
+
+	buildInfo, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	for _, setting := range buildInfo.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			info.Commit = setting.Value
+		case "vcs.time":
+			info.BuildTime = setting.Value
+		case "vcs.modified":
+			info.Modified = setting.Value == "true"
+		}
+	}
+	return info
+}
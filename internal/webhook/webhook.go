@@ -0,0 +1,357 @@
+// webhook package delivers events to per-guild webhook URLs as signed JSON, retrying failures with backoff.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/metruzanca/checkpoint-bot/internal/database"
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+	"github.com/metruzanca/checkpoint-bot/internal/events"
+	"github.com/metruzanca/checkpoint-bot/internal/version"
+)
+
+const (
+	StatusPending   = "pending"
+	StatusDelivered = "delivered"
+	StatusFailed    = "failed"
+
+	// MaxAttempts is how many times a delivery is tried before it's marked failed
+	MaxAttempts = 8
+
+	// Headers sent with every delivery
+	HeaderEvent     = "X-Checkpoint-Event"
+	HeaderDelivery  = "X-Checkpoint-Delivery"
+	HeaderTimestamp = "X-Checkpoint-Timestamp"
+	HeaderSignature = "X-Checkpoint-Signature"
+)
+
+// GenerateSecret returns a random signing secret for a new webhook
+func GenerateSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(buf), nil
+}
+
+// Sign returns the X-Checkpoint-Signature value for a body sent at timestamp (unix seconds).
+// Receivers compute HMAC-SHA256 of "<timestamp>.<body>" with the secret and compare.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Backoff returns the delay before retrying after the given number of failed attempts: 30s, 1m, 2m... up to 1h
+func Backoff(attempts int64) time.Duration {
+	delay := 30 * time.Second
+	for i := int64(1); i < attempts && delay < time.Hour; i++ {
+		delay *= 2
+	}
+	return min(delay, time.Hour)
+}
+
+var (
+	ErrInvalidURL  = errors.New("webhook URL must be an absolute https URL")
+	ErrInternalURL = errors.New("webhook URL cannot point to a local or private network address")
+)
+
+// ValidateURL checks a webhook URL is an absolute https URL that doesn't name a local or private address.
+// Host names are only checked when connecting, see refuseInternal.
+func ValidateURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "https" || u.Hostname() == "" {
+		return ErrInvalidURL
+	}
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrInternalURL
+	}
+	if ip, err := netip.ParseAddr(host); err == nil && internalAddr(ip) {
+		return ErrInternalURL
+	}
+	return nil
+}
+
+// internalAddr reports whether ip is a loopback, link-local (cloud metadata), private or unspecified address
+func internalAddr(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	return ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsPrivate() || ip.IsUnspecified()
+}
+
+// refuseInternal is a net.Dialer Control hook refusing internal addresses once host names are resolved,
+// so a webhook's DNS can't be changed to point at them after it was added
+func refuseInternal(network string, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("cannot parse dialed address %q: %w", address, err)
+	}
+	if internalAddr(addrPort.Addr()) {
+		return ErrInternalURL
+	}
+	return nil
+}
+
+// newClient returns the HTTP client used for deliveries, which only connects to public addresses.
+// Proxies aren't used since they would connect on the client's behalf, and redirects aren't followed.
+func newClient() *http.Client {
+	dialer := &net.Dialer{Timeout: 10 * time.Second, Control: refuseInternal}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   10 * time.Second,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// ParseEventTypes validates a comma separated list of event types, empty means every event
+func ParseEventTypes(raw string) (string, error) {
+	if strings.TrimSpace(raw) == "" {
+		return "", nil
+	}
+	var types []string
+	for _, t := range strings.Split(raw, ",") {
+		t = strings.TrimSpace(t)
+		if !slices.Contains(events.Types, events.Type(t)) {
+			return "", fmt.Errorf("unknown event type %q", t)
+		}
+		types = append(types, t)
+	}
+	return strings.Join(types, ","), nil
+}
+
+// subscribed reports whether a webhook wants an event type
+func subscribed(webhook queries.Webhook, t events.Type) bool {
+	return webhook.EventTypes == "" || slices.Contains(strings.Split(webhook.EventTypes, ","), string(t))
+}
+
+// Dispatcher records a delivery for every event and webhook, then sends pending deliveries in the background.
+// Deliveries live in the database, so retries survive restarts.
+type Dispatcher struct {
+	db     database.CheckpointDatabase
+	client *http.Client
+
+	// PollInterval is how often due retries are looked up
+	PollInterval time.Duration
+
+	queue chan events.Event
+	wake  chan struct{}
+	stop  chan struct{}
+	wg    sync.WaitGroup
+}
+
+// NewDispatcher creates a dispatcher, a nil client uses one that refuses local and private addresses
+func NewDispatcher(db database.CheckpointDatabase, client *http.Client) *Dispatcher {
+	if client == nil {
+		client = newClient()
+	}
+	return &Dispatcher{
+		db:           db,
+		client:       client,
+		PollInterval: 10 * time.Second,
+		queue:        make(chan events.Event, 256),
+		wake:         make(chan struct{}, 1),
+		stop:         make(chan struct{}),
+	}
+}
+
+// Start subscribes to the database's events and starts delivering until Stop is called
+func (d *Dispatcher) Start() {
+	d.db.Events().Subscribe(d.handle)
+
+	d.wg.Add(2)
+	go d.enqueueLoop()
+	go d.deliverLoop()
+}
+
+func (d *Dispatcher) Stop() {
+	close(d.stop)
+	d.wg.Wait()
+}
+
+// handle runs on the publisher's goroutine, so it only hands the event over
+func (d *Dispatcher) handle(event events.Event) {
+	select {
+	case d.queue <- event:
+	default:
+		log.Warn("webhook queue full, dropping event", "event_id", event.ID, "type", event.Type, "guild_id", event.GuildID)
+	}
+}
+
+func (d *Dispatcher) enqueueLoop() {
+	defer d.wg.Done()
+	for {
+		select {
+		case <-d.stop:
+			return
+		case event := <-d.queue:
+			if d.Enqueue(event) > 0 {
+				select {
+				case d.wake <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}
+}
+
+func (d *Dispatcher) deliverLoop() {
+	defer d.wg.Done()
+	ticker := time.NewTicker(d.PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-d.stop:
+			return
+		case <-ticker.C:
+		case <-d.wake:
+		}
+		d.DeliverDue(time.Now())
+	}
+}
+
+// Enqueue records a pending delivery of the event for every subscribed webhook of its guild
+func (d *Dispatcher) Enqueue(event events.Event) int {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	webhooks, err := d.db.ListWebhooksByGuild(ctx, event.GuildID)
+	if err != nil {
+		log.Error("cannot list webhooks", "err", err, "guild_id", event.GuildID)
+		return 0
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		log.Error("cannot encode event", "err", err, "event_id", event.ID, "type", event.Type)
+		return 0
+	}
+
+	enqueued := 0
+	for _, webhook := range webhooks {
+		if !subscribed(webhook, event.Type) {
+			continue
+		}
+		_, err := d.db.CreateWebhookDelivery(ctx, queries.CreateWebhookDeliveryParams{
+			WebhookID:     webhook.ID,
+			EventID:       event.ID,
+			EventType:     string(event.Type),
+			Payload:       string(payload),
+			NextAttemptAt: event.OccurredAt.Format(time.RFC3339),
+		})
+		if err != nil {
+			log.Error("cannot create webhook delivery", "err", err, "webhook_id", webhook.ID, "event_id", event.ID)
+			continue
+		}
+		enqueued++
+	}
+	return enqueued
+}
+
+// DeliverDue sends every pending delivery whose next attempt is due at now
+func (d *Dispatcher) DeliverDue(now time.Time) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	deliveries, err := d.db.GetDueWebhookDeliveries(ctx, queries.GetDueWebhookDeliveriesParams{
+		Now:           now.UTC().Format(time.RFC3339),
+		MaxDeliveries: 50,
+	})
+	if err != nil {
+		log.Error("cannot get due webhook deliveries", "err", err)
+		return
+	}
+
+	for _, delivery := range deliveries {
+		d.deliver(ctx, delivery)
+	}
+}
+
+// deliver sends one delivery and records the outcome, scheduling a retry on failure
+func (d *Dispatcher) deliver(ctx context.Context, delivery queries.GetDueWebhookDeliveriesRow) {
+	params := queries.UpdateWebhookDeliveryParams{
+		ID:            delivery.ID,
+		Attempts:      delivery.Attempts + 1,
+		NextAttemptAt: time.Now().UTC().Format(time.RFC3339),
+	}
+
+	statusCode, err := d.send(ctx, delivery)
+	if statusCode != 0 {
+		params.ResponseStatus = sql.NullInt64{Int64: int64(statusCode), Valid: true}
+	}
+
+	switch {
+	case err == nil:
+		params.Status = StatusDelivered
+		params.DeliveredAt = sql.NullTime{Time: time.Now(), Valid: true}
+		log.Debug("webhook delivered", "delivery_id", delivery.ID, "event_type", delivery.EventType, "status", statusCode)
+	case params.Attempts >= MaxAttempts:
+		params.Status = StatusFailed
+		params.LastError = sql.NullString{String: err.Error(), Valid: true}
+		log.Warn("webhook delivery failed, giving up", "err", err, "delivery_id", delivery.ID, "attempts", params.Attempts)
+	default:
+		params.Status = StatusPending
+		params.LastError = sql.NullString{String: err.Error(), Valid: true}
+		params.NextAttemptAt = time.Now().Add(Backoff(params.Attempts)).UTC().Format(time.RFC3339)
+		log.Warn("webhook delivery failed, retrying", "err", err, "delivery_id", delivery.ID, "attempts", params.Attempts, "next_attempt_at", params.NextAttemptAt)
+	}
+
+	if err := d.db.UpdateWebhookDelivery(ctx, params); err != nil {
+		log.Error("cannot update webhook delivery", "err", err, "delivery_id", delivery.ID)
+	}
+}
+
+// send POSTs the signed payload, any non-2xx response is an error
+func (d *Dispatcher) send(ctx context.Context, delivery queries.GetDueWebhookDeliveriesRow) (int, error) {
+	body := []byte(delivery.Payload)
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "checkpoint-bot/"+version.Version)
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(delivery.Secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected response status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
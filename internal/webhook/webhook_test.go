@@ -0,0 +1,149 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/metruzanca/checkpoint-bot/internal/database/queries"
+	"github.com/metruzanca/checkpoint-bot/internal/database/sqlite"
+	"github.com/metruzanca/checkpoint-bot/internal/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDispatcher tests signed delivery, event filtering and retries with backoff
+func TestDispatcher(t *testing.T) {
+	db := sqlite.NewSqliteDatabase(filepath.Join(t.TempDir(), "checkpoint.db"))
+	t.Cleanup(func() { db.Close() })
+	ctx := context.Background()
+
+	var received []*http.Request
+	var bodies [][]byte
+	failing := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = append(received, r)
+		bodies = append(bodies, body)
+		if failing && r.URL.Path == "/flaky" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(server.Close)
+
+	_, err := db.CreateGuild(ctx, queries.CreateGuildParams{GuildID: "10", Timezone: "UTC", OwnerID: "20"})
+	require.NoError(t, err)
+	ok, err := db.CreateWebhook(ctx, queries.CreateWebhookParams{GuildID: "10", Url: server.URL + "/ok", Secret: "secret", CreatedBy: "20"})
+	require.NoError(t, err)
+	flaky, err := db.CreateWebhook(ctx, queries.CreateWebhookParams{GuildID: "10", Url: server.URL + "/flaky", Secret: "secret", CreatedBy: "20"})
+	require.NoError(t, err)
+	_, err = db.CreateWebhook(ctx, queries.CreateWebhookParams{GuildID: "10", Url: server.URL + "/goals", Secret: "secret", EventTypes: "goal.created", CreatedBy: "20"})
+	require.NoError(t, err)
+
+	dispatcher := NewDispatcher(db, server.Client())
+	checkpoint, err := db.CreateCheckpoint(ctx, queries.CreateCheckpointParams{ScheduledAt: "2025-01-15T19:00:00Z", ChannelID: "30", GuildID: "10", DiscordUser: "20"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, dispatcher.Enqueue(events.NewCheckpointEvent(events.CheckpointCreated, *checkpoint)))
+
+	dispatcher.DeliverDue(time.Now())
+	require.Len(t, received, 2)
+
+	req := received[0]
+	assert.Equal(t, string(events.CheckpointCreated), req.Header.Get(HeaderEvent))
+	timestamp, err := strconv.ParseInt(req.Header.Get(HeaderTimestamp), 10, 64)
+	require.NoError(t, err)
+	assert.Equal(t, Sign("secret", timestamp, bodies[0]), req.Header.Get(HeaderSignature))
+
+	delivered, err := db.ListWebhookDeliveries(ctx, queries.ListWebhookDeliveriesParams{WebhookID: ok.ID, GuildID: "10", Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, delivered, 1)
+	assert.Equal(t, StatusDelivered, delivered[0].Status)
+
+	retried, err := db.ListWebhookDeliveries(ctx, queries.ListWebhookDeliveriesParams{WebhookID: flaky.ID, GuildID: "10", Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, retried, 1)
+	assert.Equal(t, StatusPending, retried[0].Status)
+	assert.Equal(t, int64(1), retried[0].Attempts)
+	assert.Equal(t, int64(http.StatusInternalServerError), retried[0].ResponseStatus.Int64)
+
+	// Not due yet, then due after the backoff
+	dispatcher.DeliverDue(time.Now())
+	assert.Len(t, received, 2)
+	failing = false
+	dispatcher.DeliverDue(time.Now().Add(Backoff(1) + time.Second))
+	assert.Len(t, received, 3)
+
+	retried, err = db.ListWebhookDeliveries(ctx, queries.ListWebhookDeliveriesParams{WebhookID: flaky.ID, GuildID: "10", Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, StatusDelivered, retried[0].Status)
+	assert.Equal(t, int64(2), retried[0].Attempts)
+}
+
+// TestBackoff tests that retry delays double and are capped
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, 30*time.Second, Backoff(1))
+	assert.Equal(t, time.Minute, Backoff(2))
+	assert.Equal(t, 4*time.Minute, Backoff(4))
+	assert.Equal(t, time.Hour, Backoff(20))
+}
+
+// TestParseEventTypes tests validation of webhook event filters
+func TestParseEventTypes(t *testing.T) {
+	types, err := ParseEventTypes(" checkpoint.created, goal.status_changed ")
+	require.NoError(t, err)
+	assert.Equal(t, "checkpoint.created,goal.status_changed", types)
+
+	types, err = ParseEventTypes("")
+	require.NoError(t, err)
+	assert.Empty(t, types)
+
+	_, err = ParseEventTypes("checkpoint.deleted")
+	assert.Error(t, err)
+}
+
+// TestValidateURL tests that webhooks must use https and can't name local or private addresses
+func TestValidateURL(t *testing.T) {
+	tests := []struct {
+		url string
+		err error
+	}{
+		{"https://example.com/hook", nil},
+		{"https://93.184.216.34/hook", nil},
+		{"http://example.com/hook", ErrInvalidURL},
+		{"ftp://example.com", ErrInvalidURL},
+		{"/hook", ErrInvalidURL},
+		{"https://localhost/hook", ErrInternalURL},
+		{"https://api.localhost./hook", ErrInternalURL},
+		{"https://127.0.0.1:8080/hook", ErrInternalURL},
+		{"https://[::1]/hook", ErrInternalURL},
+		{"https://169.254.169.254/latest/meta-data", ErrInternalURL},
+		{"https://10.0.0.5/hook", ErrInternalURL},
+		{"https://192.168.1.10/hook", ErrInternalURL},
+		{"https://[::ffff:172.16.0.1]/hook", ErrInternalURL},
+		{"https://0.0.0.0/hook", ErrInternalURL},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			assert.Equal(t, tt.err, ValidateURL(tt.url))
+		})
+	}
+}
+
+// TestClientRefusesInternal tests that deliveries never connect to local addresses, whatever the URL's host resolves to
+func TestClientRefusesInternal(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("webhook client reached a loopback server")
+	}))
+	defer server.Close()
+
+	_, err := newClient().Get(server.URL)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrInternalURL)
+}